@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/infra/repository"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+)
+
+const apiKeysUsage = "usage: chatservice apikeys create -user <id> -name <name> -scopes chat,history [-tenant <id>] [-expires 720h]|list [-user <id>]|revoke <key id>"
+
+// runAPIKeys executa o subcomando apikeys, args sao os argumentos apos "apikeys"
+func runAPIKeys(ctx context.Context, conn *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeysUsage)
+	}
+	keys := repository.NewAPIKeyRepositoryMySql(conn)
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		userID := flags.String("user", "", "owner user id")
+		tenantID := flags.String("tenant", "", "tenant id, empty for the default tenant")
+		name := flags.String("name", "", "key name")
+		scopes := flags.String("scopes", "", "comma separated scopes")
+		expires := flags.Duration("expires", 0, "key lifetime, 0 never expires")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		input := apikey.CreateAPIKeyInputDTO{
+			UserID:   *userID,
+			TenantID: *tenantID,
+			Name:     *name,
+			Scopes:   splitList(*scopes),
+		}
+		if *expires > 0 {
+			expiresAt := time.Now().Add(*expires)
+			input.ExpiresAt = &expiresAt
+		}
+		created, err := apikey.NewCreateAPIKeyUseCase(keys, repository.NewTenantRepositoryMySql(conn)).Execute(ctx, input)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("id\t%s\nkey\t%s\n", created.ID, created.Key)
+		fmt.Println("store the key now, it can not be shown again")
+	case "list":
+		flags := flag.NewFlagSet("list", flag.ContinueOnError)
+		userID := flags.String("user", "", "only list the keys of this user")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		list, err := apikey.NewListAPIKeysUseCase(keys).Execute(ctx, apikey.ListAPIKeysInputDTO{UserID: *userID})
+		if err != nil {
+			return err
+		}
+		for _, key := range list.Keys {
+			state := "active"
+			if key.RevokedAt != nil {
+				state = "revoked"
+			} else if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+				state = "expired"
+			}
+			lastUsed := "never"
+			if key.LastUsedAt != nil {
+				lastUsed = key.LastUsedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s\t%s...\t%s\t%s\t%s\t%s\tlast used %s\n", key.ID, key.Prefix, key.UserID, key.Name, strings.Join(key.Scopes, ","), state, lastUsed)
+		}
+	case "revoke":
+		if len(args) < 2 {
+			return errors.New(apiKeysUsage)
+		}
+		if err := apikey.NewRevokeAPIKeyUseCase(keys, nil).Execute(ctx, apikey.RevokeAPIKeyInputDTO{ID: args[1]}); err != nil {
+			return err
+		}
+		fmt.Printf("revoked %s\n", args[1])
+	default:
+		return errors.New(apiKeysUsage)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+const auditUsage = "usage: chatservice audit verify [-anchor-seq <seq> -anchor-hash <hash>]|export [-after <seq>] [-type <type>]"
+
+// runAudit executa o subcomando audit, args sao os argumentos apos "audit"
+func runAudit(ctx context.Context, auditGateway gateway.AuditGateway, args []string) error {
+	if len(args) == 0 {
+		return errors.New(auditUsage)
+	}
+	if auditGateway == nil {
+		return errors.New("audit log is disabled, set AUDIT_SINK")
+	}
+
+	switch args[0] {
+	case "verify":
+		flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+		anchorSeq := flags.Int64("anchor-seq", 0, "seq of a previously recorded event")
+		anchorHash := flags.String("anchor-hash", "", "hash recorded for the anchor event")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		result, err := audit.NewVerifyAuditLogUseCase(auditGateway).Execute(ctx, audit.VerifyAuditLogInputDTO{
+			AnchorSeq:  *anchorSeq,
+			AnchorHash: *anchorHash,
+		})
+		if err != nil {
+			return err
+		}
+		if !result.Valid {
+			return fmt.Errorf("audit log is broken at seq %d: %s (%d valid events before it)", result.BrokenAt, result.Reason, result.Events)
+		}
+		fmt.Printf("audit log is valid\nevents\t%d\nlast seq\t%d\nlast hash\t%s\n", result.Events, result.LastSeq, result.LastHash)
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		afterSeq := flags.Int64("after", 0, "export the events after this seq")
+		eventType := flags.String("type", "", "only export events of this type")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		//um evento json por linha, todas as paginas
+		exportUseCase := audit.NewExportAuditLogUseCase(auditGateway)
+		encoder := json.NewEncoder(os.Stdout)
+		input := audit.ExportAuditLogInputDTO{AfterSeq: *afterSeq, Type: *eventType}
+		for {
+			page, err := exportUseCase.Execute(ctx, input)
+			if err != nil {
+				return err
+			}
+			for _, event := range page.Events {
+				if err := encoder.Encode(event); err != nil {
+					return err
+				}
+			}
+			if page.NextSeq == 0 {
+				break
+			}
+			input.AfterSeq = page.NextSeq
+		}
+	default:
+		return errors.New(auditUsage)
+	}
+	return nil
+}
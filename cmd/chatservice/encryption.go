@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/infra/encryption"
+	"github.com/ruhancs/virtual-assistant/internal/infra/repository"
+)
+
+const encryptionUsage = "usage: chatservice encryption genkey|rotate [-batch 500]"
+
+// runEncryption executa o subcomando encryption, args sao os argumentos apos "encryption".
+// Rotacao: gerar a chave nova, incluir no keyfile como ativa, reiniciar o servico e rodar rotate.
+// A chave antiga so pode sair do keyfile depois que o rotate terminar
+func runEncryption(ctx context.Context, conn *sql.DB, keyring *encryption.Keyring, args []string) error {
+	if len(args) == 0 {
+		return errors.New(encryptionUsage)
+	}
+
+	switch args[0] {
+	case "genkey":
+		key, err := encryption.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+	case "rotate":
+		flags := flag.NewFlagSet("rotate", flag.ContinueOnError)
+		batch := flags.Int("batch", 500, "messages re-encrypted per batch")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if keyring == nil {
+			return errors.New("encryption is disabled, set ENCRYPTION_KEYFILE")
+		}
+		if *batch <= 0 {
+			return errors.New("batch must be positive")
+		}
+		chats := repository.NewChatRepositoryMySql(conn, keyring)
+		total, afterID := 0, ""
+		for {
+			updated, lastID, err := chats.ReencryptMessages(ctx, afterID, *batch)
+			total += updated
+			if err != nil {
+				return fmt.Errorf("rotation stopped after %d messages: %w", total, err)
+			}
+			if lastID == "" {
+				break
+			}
+			afterID = lastID
+			fmt.Printf("re-encrypted %d messages\n", total)
+		}
+		fmt.Printf("done, %d messages re-encrypted with key %s\n", total, keyring.ActiveKeyID())
+	default:
+		return errors.New(encryptionUsage)
+	}
+	return nil
+}
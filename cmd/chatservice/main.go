@@ -1,20 +1,48 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"expvar"
 	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/ruhancs/virtual-assistant/config"
+	"github.com/ruhancs/virtual-assistant/internal/infra/auth"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/infra/encryption"
 	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/server"
+	"github.com/ruhancs/virtual-assistant/internal/infra/middleware"
 	"github.com/ruhancs/virtual-assistant/internal/infra/repository"
+	"github.com/ruhancs/virtual-assistant/internal/infra/tlsconfig"
+	"github.com/ruhancs/virtual-assistant/internal/infra/vectorstore"
 	"github.com/ruhancs/virtual-assistant/internal/infra/web"
 	"github.com/ruhancs/virtual-assistant/internal/infra/web/webserver"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/assistant"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
 	chatcompletion "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion"
 	chatcompletionstream "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion_stream"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/knowledge"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/moderation"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/recall"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/retention"
+	chatshare "github.com/ruhancs/virtual-assistant/internal/usecase/chat_share"
+	prompttemplate "github.com/ruhancs/virtual-assistant/internal/usecase/prompt_template"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+	userdata "github.com/ruhancs/virtual-assistant/internal/usecase/user_data"
 
 	//chatcompletionstream "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion_stream"
 	"github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
@@ -30,45 +58,337 @@ func main() {
 	}
 	defer conn.Close()
 
-	repository := repository.NewChatRepositoryMySql(conn)
+	//chatservice migrate up|down|status|version
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), conn, configs.DBDriver, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//chatservice apikeys create|list|revoke
+	if len(os.Args) > 1 && os.Args[1] == "apikeys" {
+		if err := runAPIKeys(context.Background(), conn, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//cifragem do conteudo das msgs com as chaves mestras do keyfile, desligada sem ENCRYPTION_KEYFILE
+	keyring, err := encryption.LoadKeyring(configs.EncryptionKeyfile)
+	if err != nil {
+		panic(err)
+	}
+
+	//chatservice encryption genkey|rotate
+	if len(os.Args) > 1 && os.Args[1] == "encryption" {
+		if err := runEncryption(context.Background(), conn, keyring, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	//a busca usa o indice full text do mysql, que nao encontra as msgs cifradas
+	searchEnabled := configs.SearchEnabled
+	if keyring != nil && searchEnabled {
+		slog.Warn("message search is disabled because message content is encrypted", slog.String("encryption_keyfile", configs.EncryptionKeyfile))
+		searchEnabled = false
+	}
+
+	//log de auditoria encadeado por hash (mysql ou arquivo), desligado sem AUDIT_SINK
+	auditGateway, err := newAuditGateway(configs.AuditSink, configs.AuditFile, conn)
+	if err != nil {
+		panic(err)
+	}
+
+	//chatservice audit verify|export
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := runAudit(context.Background(), auditGateway, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var auditLogger *audit.Logger
+	if auditGateway != nil {
+		auditLogger = audit.NewLogger(auditGateway)
+	}
+
+	//retencao: chats inativos e msgs apagadas do contexto removidos conforme as regras, desligada sem RETENTION_FILE
+	retentionPolicies, err := retention.LoadPolicies(configs.RetentionFile)
+	if err != nil {
+		panic(err)
+	}
+	var purgeUseCase *retention.PurgeDataUseCase
+	if len(retentionPolicies) > 0 {
+		purgeUseCase = retention.NewPurgeDataUseCase(repository.NewChatRepositoryMySql(conn, keyring), retentionPolicies, configs.RetentionBatchSize, configs.RetentionMaxPerRun)
+	}
+
+	//chatservice retention run
+	if len(os.Args) > 1 && os.Args[1] == "retention" {
+		if err := runRetention(context.Background(), purgeUseCase, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	//chatservice users export|delete, pedidos de acesso e exclusao dos dados de um usuario
+	if len(os.Args) > 1 && os.Args[1] == "users" {
+		userDataRepository := repository.NewChatRepositoryMySql(conn, keyring)
+		exportUserUseCase := userdata.NewExportUserDataUseCase(userDataRepository, auditLogger)
+		deleteUserUseCase := userdata.NewDeleteUserDataUseCase(userDataRepository, nil, auditLogger)
+		if err := runUsers(context.Background(), exportUserUseCase, deleteUserUseCase, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if configs.AutoMigrate {
+		if err := autoMigrate(context.Background(), conn, configs.DBDriver); err != nil {
+			panic(err)
+		}
+	}
+
+	knowledgeRepository := repository.NewKnowledgeRepositoryMySql(conn)
+	promptTemplateRepository := repository.NewPromptTemplateRepositoryMySql(conn)
+	assistantRepository := repository.NewAssistantRepositoryMySql(conn)
+	tenantRepository := repository.NewTenantRepositoryMySql(conn)
+	apiKeyRepository := repository.NewAPIKeyRepositoryMySql(conn)
+	repository := repository.NewChatRepositoryMySql(conn, keyring)
 	client := openai.NewClient(configs.OpenAIApiKey)
 
 	chatConfig := chatcompletion.ChatCompletionConfigInputDTO{
-		Model:                configs.Model,
-		ModelMaxTokens:       configs.ModelMaxTokens,
-		Temperature:          float32(configs.Temperature),
-		TopP:                 float32(configs.TopP),
-		N:                    configs.N,
-		Stop:                 configs.Stop,
-		MaxTokens:            configs.MaxTokens,
-		InitialSystemMessage: configs.InitialChatMessage,
+		Model:                 configs.Model,
+		ModelMaxTokens:        configs.ModelMaxTokens,
+		Temperature:           float32(configs.Temperature),
+		TopP:                  float32(configs.TopP),
+		N:                     configs.N,
+		Stop:                  configs.Stop,
+		MaxTokens:             configs.MaxTokens,
+		InitialSystemMessage:  configs.InitialChatMessage,
+		SchemaMaxRetries:      configs.SchemaMaxRetries,
+		DefaultPromptTemplate: configs.DefaultPromptTemplate,
 	}
 	
 	chatConfigStream := chatcompletionstream.ChatCompletionConfigInputDTO{
-		Model:                configs.Model,
-		ModelMaxTokens:       configs.ModelMaxTokens,
-		Temperature:          float32(configs.Temperature),
-		TopP:                 float32(configs.TopP),
-		N:                    configs.N,
-		Stop:                 configs.Stop,
-		MaxTokens:            configs.MaxTokens,
-		InitialSystemMessage: configs.InitialChatMessage,
+		Model:                 configs.Model,
+		ModelMaxTokens:        configs.ModelMaxTokens,
+		Temperature:           float32(configs.Temperature),
+		TopP:                  float32(configs.TopP),
+		N:                     configs.N,
+		Stop:                  configs.Stop,
+		MaxTokens:             configs.MaxTokens,
+		InitialSystemMessage:  configs.InitialChatMessage,
+		SchemaMaxRetries:      configs.SchemaMaxRetries,
+		DefaultPromptTemplate: configs.DefaultPromptTemplate,
+	}
+
+	//recuperacao de msgs apagadas do contexto por similaridade, desligada sem EMBEDDING_PROVIDER
+	embedder, err := newEmbeddingProvider(configs.EmbeddingProvider, configs.EmbeddingModel, client)
+	if err != nil {
+		panic(err)
+	}
+	var recaller *recall.Recaller
+	//base de conhecimento usada como contexto das respostas, tambem depende do embedding
+	var retriever *knowledge.Retriever
+	vectorStore, err := newVectorStore(configs.VectorStore, configs.VectorStorePath, conn)
+	if err != nil {
+		panic(err)
+	}
+	//indice local (flat/hnsw) gravado em disco em segundo plano, nao a cada upsert
+	if local, ok := vectorStore.(*vectorstore.LocalStore); ok {
+		go runVectorStore(local, time.Duration(configs.VectorFlushInterval)*time.Second)
+	}
+	if embedder != nil {
+		recaller = recall.NewRecaller(embedder, repository, configs.RecallTopK, float32(configs.RecallMinScore))
+		retriever = knowledge.NewRetriever(knowledgeRepository, vectorStore, embedder, configs.KnowledgeBaseID, configs.RAGTopK, float32(configs.RAGMinScore))
+	}
+
+	//ferramentas que o modelo pode chamar durante a resposta
+	toolRegistry, toolServers, err := newToolRegistry(context.Background(), configs.Tools, configs.MaxToolRounds, configs.ToolsFile)
+	if err != nil {
+		panic(err)
+	}
+	for _, server := range toolServers {
+		defer server.Close()
+	}
+
+	//msg inicial dos chats a partir dos templates versionados
+	renderTemplateUseCase := prompttemplate.NewRenderPromptTemplateUseCase(promptTemplateRepository)
+
+	//tenants: token de api, cota mensal de tokens e chats isolados
+	tenantUsage := tenant.NewUsage(tenantRepository)
+	resolveTenantUseCase := tenant.NewResolveTenantUseCase(tenantRepository)
+
+	//tokens assinados (jwt) identificam o usuario e o tenant pelas claims
+	jwtVerifier, err := auth.NewJWTVerifier(configs.JWTSecret, configs.JWTJWKSFile, configs.JWTIssuer, configs.JWTAudience)
+	if err != nil {
+		panic(err)
+	}
+	//chaves de api por usuario, com cache para nao consultar o banco a cada requisicao
+	apiKeyCache := apikey.NewCache(time.Duration(configs.APIKeyCacheTTL) * time.Second)
+	resolveAPIKeyUseCase := apikey.NewResolveAPIKeyUseCase(apiKeyRepository, apiKeyCache)
+	//certificados de cliente (mtls) mapeados para usuario, tenant e escopos
+	certIdentities, err := auth.LoadCertIdentities(configs.TLSClientIdentities)
+	if err != nil {
+		panic(err)
+	}
+	authenticator := auth.NewAuthenticator(configs.AuthToken, resolveTenantUseCase, jwtVerifier, resolveAPIKeyUseCase, certIdentities, configs.JWTRequired)
+
+	//tls dos servidores http e grpc, os arquivos sao recarregados quando mudam
+	tlsReloader, err := tlsconfig.NewReloader(tlsconfig.Options{
+		CertFile:       configs.TLSCertFile,
+		KeyFile:        configs.TLSKeyFile,
+		ClientCAFile:   configs.TLSClientCAFile,
+		ClientAuth:     configs.TLSClientAuth,
+		ReloadInterval: time.Duration(configs.TLSReloadInterval) * time.Second,
+		Logger:         slog.Default(),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	//redacao dos dados pessoais antes de enviar as msgs ao provedor
+	redactionPatterns, err := redact.LoadPatterns(configs.RedactionPatternsFile)
+	if err != nil {
+		panic(err)
+	}
+	redactor, err := redact.NewRedactor(configs.Redaction, redactionPatterns, configs.RedactionKey, configs.RedactionStore)
+	if err != nil {
+		panic(err)
+	}
+
+	//moderacao da msg do usuario e da resposta, desligada sem nenhuma politica
+	inputPolicies, outputPolicies, err := newModerationPolicies(configs.ModerationProvider, configs.ModerationModel, configs.ModerationBlocklist, configs.ModerationMaxInput, configs.ModerationMaxOutput, client)
+	if err != nil {
+		panic(err)
+	}
+	moderator, err := moderation.NewModerator(inputPolicies, outputPolicies, configs.ModerationInputAction, configs.ModerationAction, configs.ModerationReplacement)
+	if err != nil {
+		panic(err)
 	}
 
 	//use case http
-	usecase := chatcompletion.NewChatCompletionUseCase(repository,client)
+	usecase := chatcompletion.NewChatCompletionUseCase(repository,client,recaller,retriever,toolRegistry,renderTemplateUseCase,assistantRepository,tenantUsage,redactor,moderator,auditLogger)
 
 	//usecase grpc
-	streamChan := make(chan chatcompletionstream.ChatCompletionOutputDTO)
-	streamUseCase := chatcompletionstream.NewChatCompletionUseCase(repository,client,streamChan)
+	streamUseCase := chatcompletionstream.NewChatCompletionUseCase(repository,client,recaller,retriever,toolRegistry,renderTemplateUseCase,assistantRepository,tenantUsage,redactor,moderator,auditLogger)
+
+	//cadeia unica de middlewares (id da requisicao, log, auditoria, panic, autenticacao e cota) do http e do grpc
+	mw := middleware.New(authenticator, tenantUsage, auditLogger, slog.Default())
+	chatPolicy := middleware.Policy{Scope: entity.ScopeChat, Quota: true}
+	historyPolicy := middleware.Policy{Scope: entity.ScopeHistory}
 
 	//config do web server com rota e handle
 	webserver := webserver.NewWebServer(":" + configs.WebServerPort)
-	webHandler := web.NewWebChatGPTHandler(*usecase,chatConfig,configs.AuthToken)
-	webserver.AddHandler("/chat", webHandler.Handle)
+	webserver.Use(mw.HTTP()...)
+	if tlsReloader != nil {
+		webserver.TLSConfig = tlsReloader.Config("h2", "http/1.1")
+	}
+	webHandler := web.NewWebChatGPTHandler(*usecase,chatConfig)
+	webserver.AddHandler("/chat", webHandler.Handle, mw.Route(chatPolicy)...)
+
+	shareUseCase := chatshare.NewShareChatUseCase(repository)
+	shareHandler := web.NewWebChatShareHandler(*shareUseCase)
+	webserver.AddHandler("/chat/share", shareHandler.Handle, mw.Route(middleware.Policy{Scope: entity.ScopeChat})...)
+
+	//historico paginado de chats e mensagens
+	listChatsUseCase := chathistory.NewListChatsUseCase(repository)
+	listMessagesUseCase := chathistory.NewListMessagesUseCase(repository)
+	historyHandler := web.NewWebChatHistoryHandler(*listChatsUseCase, *listMessagesUseCase)
+	webserver.AddHandler("/chats", historyHandler.ListChats, mw.Route(historyPolicy)...)
+	webserver.AddHandler("/chats/{chatID}/messages", historyHandler.ListMessages, mw.Route(historyPolicy)...)
+
+	//busca full text nas conversas do usuario, desligada com SEARCH_ENABLED=false ou com a cifragem responde ErrInvalidConfig
+	var searchGateway gateway.MessageSearchGateway
+	if searchEnabled {
+		searchGateway = repository
+	}
+	searchUseCase := chatsearch.NewSearchMessagesUseCase(searchGateway)
+	searchHandler := web.NewWebChatSearchHandler(*searchUseCase)
+	webserver.AddHandler("/search", searchHandler.Handle, mw.Route(historyPolicy)...)
+
+	//ingestao e remocao de documentos da base de conhecimento
+	if embedder != nil {
+		chunker := knowledge.NewChunker(configs.ChunkSize, configs.ChunkOverlap)
+		ingestUseCase := knowledge.NewIngestDocumentUseCase(knowledgeRepository, vectorStore, embedder, chunker)
+		deleteDocumentUseCase := knowledge.NewDeleteDocumentUseCase(knowledgeRepository, vectorStore)
+		documentHandler := web.NewWebDocumentHandler(*ingestUseCase, *deleteDocumentUseCase)
+		webserver.AddHandler("/documents", documentHandler.Ingest, mw.RequireToken(configs.AuthToken))
+		webserver.AddHandler("/documents/{documentID}", documentHandler.Delete, mw.RequireToken(configs.AuthToken))
+	}
+
+	//administracao dos templates de prompt, dos assistentes, dos tenants, das chaves de api, dos dados dos usuarios e da auditoria
+	if configs.AdminToken != "" {
+		admin := mw.Admin(configs.AdminToken)
+		createTemplateUseCase := prompttemplate.NewCreatePromptTemplateUseCase(promptTemplateRepository)
+		listTemplatesUseCase := prompttemplate.NewListPromptTemplatesUseCase(promptTemplateRepository)
+		activateTemplateUseCase := prompttemplate.NewActivatePromptTemplateUseCase(promptTemplateRepository)
+		deleteTemplateUseCase := prompttemplate.NewDeletePromptTemplateUseCase(promptTemplateRepository)
+		templateHandler := web.NewWebPromptTemplateHandler(*createTemplateUseCase, *listTemplatesUseCase, *activateTemplateUseCase, *deleteTemplateUseCase)
+		webserver.AddHandler("/admin/prompt-templates", templateHandler.Templates, admin...)
+		webserver.AddHandler("/admin/prompt-templates/{name}", templateHandler.Template, admin...)
+		webserver.AddHandler("/admin/prompt-templates/{name}/versions/{version}/activate", templateHandler.Activate, admin...)
+
+		//assistentes hospedados pelo servico, cada um com a propria config
+		createAssistantUseCase := assistant.NewCreateAssistantUseCase(assistantRepository, toolRegistry)
+		getAssistantUseCase := assistant.NewGetAssistantUseCase(assistantRepository)
+		listAssistantsUseCase := assistant.NewListAssistantsUseCase(assistantRepository)
+		updateAssistantUseCase := assistant.NewUpdateAssistantUseCase(assistantRepository, toolRegistry)
+		deleteAssistantUseCase := assistant.NewDeleteAssistantUseCase(assistantRepository)
+		assistantHandler := web.NewWebAssistantHandler(*createAssistantUseCase, *getAssistantUseCase, *listAssistantsUseCase, *updateAssistantUseCase, *deleteAssistantUseCase)
+		webserver.AddHandler("/admin/assistants", assistantHandler.Assistants, admin...)
+		webserver.AddHandler("/admin/assistants/{assistantID}", assistantHandler.Assistant, admin...)
+
+		createTenantUseCase := tenant.NewCreateTenantUseCase(tenantRepository, assistantRepository, toolRegistry)
+		listTenantsUseCase := tenant.NewListTenantsUseCase(tenantRepository)
+		updateTenantUseCase := tenant.NewUpdateTenantUseCase(tenantRepository, assistantRepository, toolRegistry)
+		deleteTenantUseCase := tenant.NewDeleteTenantUseCase(tenantRepository)
+		tenantHandler := web.NewWebTenantHandler(*createTenantUseCase, *listTenantsUseCase, *updateTenantUseCase, *deleteTenantUseCase)
+		webserver.AddHandler("/admin/tenants", tenantHandler.Tenants, admin...)
+		webserver.AddHandler("/admin/tenants/{tenantID}", tenantHandler.Tenant, admin...)
+
+		createAPIKeyUseCase := apikey.NewCreateAPIKeyUseCase(apiKeyRepository, tenantRepository)
+		listAPIKeysUseCase := apikey.NewListAPIKeysUseCase(apiKeyRepository)
+		revokeAPIKeyUseCase := apikey.NewRevokeAPIKeyUseCase(apiKeyRepository, apiKeyCache)
+		apiKeyHandler := web.NewWebAPIKeyHandler(*createAPIKeyUseCase, *listAPIKeysUseCase, *revokeAPIKeyUseCase)
+		webserver.AddHandler("/admin/api-keys", apiKeyHandler.Keys, admin...)
+		webserver.AddHandler("/admin/api-keys/{keyID}", apiKeyHandler.Key, admin...)
+
+		//exportacao e exclusao dos dados de um usuario (lgpd/gdpr)
+		exportUserUseCase := userdata.NewExportUserDataUseCase(repository, auditLogger)
+		deleteUserUseCase := userdata.NewDeleteUserDataUseCase(repository, apiKeyCache, auditLogger)
+		userDataHandler := web.NewWebUserDataHandler(*exportUserUseCase, *deleteUserUseCase)
+		webserver.AddHandler("/admin/users/{userID}/export", userDataHandler.Export, admin...)
+		webserver.AddHandler("/admin/users/{userID}", userDataHandler.User, admin...)
+
+		//metricas do processo (expvar), inclusive as linhas removidas pela retencao
+		webserver.AddHandler("/admin/metrics", expvar.Handler().ServeHTTP, admin...)
+
+		if auditGateway != nil {
+			exportAuditUseCase := audit.NewExportAuditLogUseCase(auditGateway)
+			verifyAuditUseCase := audit.NewVerifyAuditLogUseCase(auditGateway)
+			auditHandler := web.NewWebAuditHandler(*exportAuditUseCase, *verifyAuditUseCase)
+			webserver.AddHandler("/admin/audit", auditHandler.Export, admin...)
+			webserver.AddHandler("/admin/audit/verify", auditHandler.Verify, admin...)
+		}
+	}
+
+	if purgeUseCase != nil {
+		go startRetentionWorker(context.Background(), purgeUseCase, time.Duration(configs.RetentionInterval)*time.Second, configs.RetentionDryRun, slog.Default())
+	}
 
 	//config grpc server
-	grpcServer := server.NewGRPCServer(*streamUseCase,chatConfigStream,configs.GRPCServerPort,configs.AuthToken,streamChan)
+	grpcOptions := mw.GRPC(server.MethodPolicies)
+	if tlsReloader != nil {
+		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsReloader.Config("h2"))))
+	}
+	grpcServer := server.NewGRPCServer(*streamUseCase,chatConfigStream,configs.GRPCServerPort,*listChatsUseCase,*listMessagesUseCase,*searchUseCase,grpcOptions...)
 	fmt.Println("Running GRPC server on port: "+ configs.GRPCServerPort)
 	go grpcServer.Start()
 
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ruhancs/virtual-assistant/internal/infra/migration"
+	"github.com/ruhancs/virtual-assistant/sql/migrations"
+)
+
+const migrateUsage = "usage: chatservice migrate up|down [steps]|status|version|force <version> applied|pending"
+
+// dirtyHelp o DDL do mysql nao é transacional, a migration que falhou pode ter deixado parte das alteracoes
+const dirtyHelp = "the migration failed midway and MySQL DDL is not transactional, so part of its statements may have been applied: " +
+	"check the schema, finish or undo the changes by hand, then run chatservice migrate force <version> applied|pending"
+
+// runMigrate executa o subcomando migrate, args sao os argumentos apos "migrate"
+func runMigrate(ctx context.Context, conn *sql.DB, driver string, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := migration.NewMigrator(conn, driver, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, version := range applied {
+			fmt.Printf("applied %d\n", version)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, version := range reverted {
+			fmt.Printf("reverted %d\n", version)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		dirty := false
+		for _, s := range statuses {
+			state := "pending"
+			if s.Dirty {
+				state = "dirty"
+			} else if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%s\t%s\n", s.Migration.Version, s.Migration.Name, state)
+			dirty = dirty || s.Dirty
+		}
+		if dirty {
+			fmt.Println(dirtyHelp)
+		}
+	case "version":
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+			fmt.Println(dirtyHelp)
+		} else {
+			fmt.Println(version)
+		}
+	case "force":
+		if len(args) != 3 || (args[2] != "applied" && args[2] != "pending") {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if err := migrator.Force(ctx, version, args[2] == "applied"); err != nil {
+			return err
+		}
+		fmt.Printf("forced %d %s\n", version, args[2])
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// autoMigrate aplica as migrations pendentes ao subir o servidor quando AUTO_MIGRATE=true
+func autoMigrate(ctx context.Context, conn *sql.DB, driver string) error {
+	migrator, err := migration.NewMigrator(conn, driver, migrations.FS)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	for _, version := range applied {
+		fmt.Printf("applied migration %d\n", version)
+	}
+	return err
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	auditsink "github.com/ruhancs/virtual-assistant/internal/infra/audit"
+	"github.com/ruhancs/virtual-assistant/internal/infra/embedding"
+	"github.com/ruhancs/virtual-assistant/internal/infra/mcp"
+	moderationprovider "github.com/ruhancs/virtual-assistant/internal/infra/moderation"
+	"github.com/ruhancs/virtual-assistant/internal/infra/repository"
+	"github.com/ruhancs/virtual-assistant/internal/infra/vectorstore"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/moderation"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// newEmbeddingProvider retorna nil quando EMBEDDING_PROVIDER esta vazio
+func newEmbeddingProvider(provider string, model string, client *openai.Client) (gateway.EmbeddingProvider, error) {
+	switch provider {
+	case "":
+		return nil, nil
+	case "openai":
+		return embedding.NewOpenAIEmbedder(client, model), nil
+	case "local":
+		return embedding.NewLocalEmbedder(0), nil
+	}
+	return nil, fmt.Errorf("invalid embedding provider: %s", provider)
+}
+
+// newModerationPolicies politicas da msg do usuario e da resposta: blocklist, tamanho maximo e provedor de moderacao
+func newModerationPolicies(provider string, model string, blocklistFile string, maxInput int, maxOutput int, client *openai.Client) ([]moderation.Policy, []moderation.Policy, error) {
+	var input, output []moderation.Policy
+	blocklist, err := moderation.LoadBlocklist(blocklistFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blocklist != nil {
+		input = append(input, blocklist)
+		output = append(output, blocklist)
+	}
+	if limit := moderation.NewMaxLength(maxInput); limit != nil {
+		input = append(input, limit)
+	}
+	if limit := moderation.NewMaxLength(maxOutput); limit != nil {
+		output = append(output, limit)
+	}
+
+	var moderator gateway.ModerationProvider
+	switch provider {
+	case "":
+		return input, output, nil
+	case "openai":
+		moderator = moderationprovider.NewOpenAIModerator(client, model)
+	case "local":
+		moderator = moderationprovider.NewLocalModerator()
+	default:
+		return nil, nil, fmt.Errorf("invalid moderation provider: %s", provider)
+	}
+	input = append(input, moderation.NewProvider(moderator))
+	output = append(output, moderation.NewProvider(moderator))
+	return input, output, nil
+}
+
+// newVectorStore mysql guarda os vetores no banco, flat e hnsw mantem o indice em memoria gravado em path
+func newVectorStore(kind string, path string, conn *sql.DB) (gateway.VectorStore, error) {
+	switch kind {
+	case "", "mysql":
+		return repository.NewVectorRepositoryMySql(conn), nil
+	case vectorstore.KindFlat, vectorstore.KindHNSW:
+		if path == "" {
+			path = "data/vectors.gob"
+		}
+		return vectorstore.NewLocalStore(path, kind, vectorstore.DefaultHNSWConfig)
+	}
+	return nil, fmt.Errorf("invalid vector store: %s", kind)
+}
+
+// runVectorStore grava o indice local periodicamente e, no SIGINT/SIGTERM, uma ultima vez antes de sair
+func runVectorStore(store *vectorstore.LocalStore, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	store.Run(ctx, interval)
+	os.Exit(0)
+}
+
+// newAuditGateway onde fica o log de auditoria, retorna nil quando AUDIT_SINK esta vazio (auditoria desligada)
+func newAuditGateway(sink string, path string, conn *sql.DB) (gateway.AuditGateway, error) {
+	switch sink {
+	case "":
+		return nil, nil
+	case "mysql":
+		return repository.NewAuditRepositoryMySql(conn), nil
+	case "file":
+		if path == "" {
+			path = "data/audit.log"
+		}
+		return auditsink.NewFileSink(path), nil
+	}
+	return nil, fmt.Errorf("invalid audit sink: %s", sink)
+}
+
+// newToolRegistry registra as ferramentas builtin pelo nome e as dos servidores externos do TOOLS_FILE.
+// As builtin formam o perfil default, a menos que o TOOLS_FILE defina outro; as externas so sao liberadas pelos perfis.
+// Retorna nil quando nenhuma ferramenta foi configurada, os clients devem ser fechados no fim.
+// ctx controla a vida dos servidores externos
+func newToolRegistry(ctx context.Context, names []string, maxRounds int, toolsFile string) (*tools.Registry, []*mcp.Client, error) {
+	if len(names) == 0 && toolsFile == "" {
+		return nil, nil, nil
+	}
+	registry := tools.NewRegistry(maxRounds)
+	for _, name := range names {
+		tool, ok := tools.Builtin[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid tool: %s", name)
+		}
+		if err := registry.Register(tool); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := registry.SetProfile(tools.DefaultProfile, names); err != nil {
+		return nil, nil, err
+	}
+	if toolsFile == "" {
+		return registry, nil, nil
+	}
+
+	config, err := mcp.LoadConfig(toolsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var clients []*mcp.Client
+	closeAll := func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}
+	for _, server := range config.Servers {
+		client, err := mcp.Start(ctx, server)
+		if err == nil {
+			clients = append(clients, client)
+			listCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			err = mcp.RegisterTools(listCtx, registry, client, server)
+			cancel()
+		}
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+	}
+	for name, patterns := range config.Profiles {
+		if err := registry.SetProfile(name, patterns); err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+	}
+	return registry, clients, nil
+}
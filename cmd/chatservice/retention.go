@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/usecase/retention"
+)
+
+const retentionUsage = "usage: chatservice retention run [-dry-run]"
+
+// defaultRetentionInterval intervalo entre as execucoes do worker de retencao
+const defaultRetentionInterval = time.Hour
+
+// retentionMetrics totais do worker desde o inicio do processo, em /admin/metrics (expvar).
+// No dry run as linhas que seriam removidas vao para as chaves dry_run_*
+var retentionMetrics = expvar.NewMap("retention")
+
+// runRetention executa o subcomando retention, args sao os argumentos apos "retention"
+func runRetention(ctx context.Context, purge *retention.PurgeDataUseCase, args []string) error {
+	if len(args) == 0 || args[0] != "run" {
+		return errors.New(retentionUsage)
+	}
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the rows that would be purged")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if purge == nil {
+		return errors.New("retention is disabled, set RETENTION_FILE")
+	}
+	//o comando nao tem limite por execucao, roda ate acabar
+	for {
+		result, err := purge.Execute(ctx, retention.PurgeDataInputDTO{DryRun: *dryRun})
+		if err != nil {
+			return err
+		}
+		json.NewEncoder(os.Stdout).Encode(result)
+		if !result.Truncated || *dryRun {
+			return nil
+		}
+	}
+}
+
+// startRetentionWorker aplica as regras de retencao a cada intervalo ate o contexto acabar
+func startRetentionWorker(ctx context.Context, purge *retention.PurgeDataUseCase, interval time.Duration, dryRun bool, logger *slog.Logger) {
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runRetentionOnce(ctx, purge, dryRun, logger)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runRetentionOnce(ctx context.Context, purge *retention.PurgeDataUseCase, dryRun bool, logger *slog.Logger) {
+	result, err := purge.Execute(ctx, retention.PurgeDataInputDTO{DryRun: dryRun})
+	retentionMetrics.Add("runs", 1)
+	if err != nil {
+		retentionMetrics.Add("errors", 1)
+		logger.ErrorContext(ctx, "retention run failed", slog.String("error", err.Error()))
+	}
+	//execucao com erro ainda conta o que foi removido antes do erro
+	prefix := ""
+	if dryRun {
+		prefix = "dry_run_"
+	}
+	retentionMetrics.Add(prefix+"chats_deleted", int64(result.ChatsDeleted))
+	retentionMetrics.Add(prefix+"chats_anonymized", int64(result.ChatsAnonymized))
+	retentionMetrics.Add(prefix+"messages_deleted", int64(result.MessagesDeleted))
+	if err == nil {
+		logger.InfoContext(ctx, "retention run",
+			slog.Bool("dry_run", dryRun),
+			slog.Int("chats_deleted", result.ChatsDeleted),
+			slog.Int("chats_anonymized", result.ChatsAnonymized),
+			slog.Int("messages_deleted", result.MessagesDeleted),
+			slog.Bool("truncated", result.Truncated),
+			slog.Duration("duration", result.Duration))
+	}
+}
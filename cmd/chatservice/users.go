@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	userdata "github.com/ruhancs/virtual-assistant/internal/usecase/user_data"
+)
+
+const usersUsage = "usage: chatservice users export [-tenant id] [-format json|markdown|zip] [-out file] <user_id> | users delete [-tenant id] <user_id>"
+
+// runUsers executa o subcomando users, args sao os argumentos apos "users"
+func runUsers(ctx context.Context, export *userdata.ExportUserDataUseCase, remove *userdata.DeleteUserDataUseCase, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usersUsage)
+	}
+	switch args[0] {
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		tenantID := flags.String("tenant", "", "tenant of the user, empty is the default tenant")
+		format := flags.String("format", userdata.FormatJSON, "archive format: json, markdown or zip")
+		out := flags.String("out", "", "file to write the archive to, empty writes to stdout")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return errors.New(usersUsage)
+		}
+		if _, ok := userdata.ContentTypes[*format]; !ok {
+			return errors.New("invalid format, use json, markdown or zip")
+		}
+		result, err := export.Execute(ctx, userdata.ExportUserDataInputDTO{UserID: flags.Arg(0), TenantID: *tenantID})
+		if err != nil {
+			return err
+		}
+		body, err := userdata.Encode(result, *format)
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			_, err = os.Stdout.Write(body)
+			return err
+		}
+		return os.WriteFile(*out, body, 0600)
+	case "delete":
+		flags := flag.NewFlagSet("delete", flag.ContinueOnError)
+		tenantID := flags.String("tenant", "", "tenant of the user, empty is the default tenant")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return errors.New(usersUsage)
+		}
+		result, err := remove.Execute(ctx, userdata.DeleteUserDataInputDTO{UserID: flags.Arg(0), TenantID: *tenantID})
+		if result != nil {
+			json.NewEncoder(os.Stdout).Encode(result)
+		}
+		return err
+	}
+	return errors.New(usersUsage)
+}
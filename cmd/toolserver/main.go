@@ -0,0 +1,238 @@
+// toolserver servidor de ferramentas de exemplo no protocolo usado pelo internal/infra/mcp,
+// json-rpc com uma msg por linha no stdin/stdout. Usado para testar os plugins de ferramentas:
+//
+//	{"servers": [{"name": "sample", "command": "go", "args": ["run", "./cmd/toolserver"], "tool_timeouts": {"sleep": "5s"}}]}
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+	call        func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+var tools = []tool{
+	{
+		Name:        "echo",
+		Description: "Returns the given text unchanged.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+		call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return "", err
+			}
+			return in.Text, nil
+		},
+	},
+	{
+		Name:        "add",
+		Description: "Adds a list of numbers.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"numbers":{"type":"array","items":{"type":"number"}}},"required":["numbers"]}`),
+		call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Numbers []float64 `json:"numbers"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return "", err
+			}
+			var sum float64
+			for _, n := range in.Numbers {
+				sum += n
+			}
+			return fmt.Sprint(sum), nil
+		},
+	},
+	{
+		Name:        "word_count",
+		Description: "Counts the words of a text.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+		call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return "", err
+			}
+			return fmt.Sprint(len(strings.Fields(in.Text))), nil
+		},
+	},
+	{
+		//para testar os timeouts e o cancelamento
+		Name:        "sleep",
+		Description: "Waits the given number of seconds.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"seconds":{"type":"number"}},"required":["seconds"]}`),
+		call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var in struct {
+				Seconds float64 `json:"seconds"`
+			}
+			if err := json.Unmarshal(args, &in); err != nil {
+				return "", err
+			}
+			select {
+			case <-time.After(time.Duration(in.Seconds * float64(time.Second))):
+				return "done", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		},
+	},
+	{
+		Name:        "fail",
+		Description: "Always fails, for testing error handling.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{}}`),
+		call: func(ctx context.Context, args json.RawMessage) (string, error) {
+			return "", errors.New("this tool always fails")
+		},
+	},
+}
+
+type server struct {
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	running map[string]context.CancelFunc // chamadas em andamento pelo id, para o notifications/cancelled
+}
+
+func main() {
+	s := &server{running: make(map[string]context.CancelFunc)}
+	reader := bufio.NewReader(os.Stdin)
+	var wg sync.WaitGroup
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var req request
+			if jsonErr := json.Unmarshal(line, &req); jsonErr != nil {
+				s.reply(json.RawMessage("null"), nil, &rpcError{Code: -32700, Message: "parse error"})
+			} else {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.handle(req)
+				}()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	wg.Wait()
+}
+
+func (s *server) handle(req request) {
+	switch req.Method {
+	case "initialize":
+		s.reply(req.ID, map[string]interface{}{
+			"protocolVersion": "2024-11-05",
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "sample-toolserver", "version": "1.0.0"},
+		}, nil)
+	case "ping":
+		s.reply(req.ID, map[string]interface{}{}, nil)
+	case "tools/list":
+		s.reply(req.ID, map[string]interface{}{"tools": tools}, nil)
+	case "tools/call":
+		s.call(req)
+	case "notifications/cancelled":
+		var params struct {
+			RequestID json.RawMessage `json:"requestId"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.mu.Lock()
+		if cancel, ok := s.running[string(params.RequestID)]; ok {
+			cancel()
+		}
+		s.mu.Unlock()
+	default:
+		//notificacoes nao tem resposta
+		if len(req.ID) > 0 {
+			s.reply(req.ID, nil, &rpcError{Code: -32601, Message: "method not found"})
+		}
+	}
+}
+
+func (s *server) call(req request) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		s.reply(req.ID, nil, &rpcError{Code: -32602, Message: err.Error()})
+		return
+	}
+	var t *tool
+	for i := range tools {
+		if tools[i].Name == params.Name {
+			t = &tools[i]
+		}
+	}
+	if t == nil {
+		s.reply(req.ID, nil, &rpcError{Code: -32602, Message: "unknown tool " + params.Name})
+		return
+	}
+	if len(params.Arguments) == 0 {
+		params.Arguments = json.RawMessage("{}")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.running[string(req.ID)] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, string(req.ID))
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	text, err := t.call(ctx, params.Arguments)
+	//erro da ferramenta volta como resultado com isError, erro de protocolo volta como erro json-rpc
+	isError := err != nil
+	if isError {
+		text = err.Error()
+	}
+	s.reply(req.ID, map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": isError,
+	}, nil)
+}
+
+func (s *server) reply(id json.RawMessage, result interface{}, rpcErr *rpcError) {
+	res := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		res["error"] = rpcErr
+	} else {
+		res["result"] = result
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	os.Stdout.Write(append(b, '\n'))
+}
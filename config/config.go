@@ -3,24 +3,76 @@ package config
 import "github.com/spf13/viper"
 
 type conf struct {
-	DBDriver           string   `mapstructure:"DB_DRIVER"`
-	DBHost             string   `mapstructure:"DB_HOST"`
-	DBPort             string   `mapstructure:"DB_PORT"`
-	DBUser             string   `mapstructure:"DB_USER"`
-	DBPassword         string   `mapstructure:"DB_PASSWORD"`
-	DBName             string   `mapstructure:"DB_NAME"`
-	WebServerPort      string   `mapstructure:"WEB_SERVER_PORT"`
-	GRPCServerPort     string   `mapstructure:"GRPC_SERVER_PORT"`
-	InitialChatMessage string   `mapstructure:"INITIAL_CHAT_MESSAGE"`
-	OpenAIApiKey       string   `mapstructure:"OPENAI_API_KEY"`
-	Model              string   `mapstructure:"MODEL"`
-	ModelMaxTokens     int      `mapstructure:"MODEL_MAX_TOKENS"`
-	Temperature        float64  `mapstructure:"TEMPERATURE"`
-	TopP               float64  `mapstructure:"TOP_P"`
-	N                  int      `mapstructure:"N"`
-	Stop               []string `mapstructure:"STOP"`
-	MaxTokens          int      `mapstructure:"MAX_TOKENS"`
-	AuthToken          string   `mapstructure:"AUTH_TOKEN"`
+	DBDriver              string   `mapstructure:"DB_DRIVER"`
+	DBHost                string   `mapstructure:"DB_HOST"`
+	DBPort                string   `mapstructure:"DB_PORT"`
+	DBUser                string   `mapstructure:"DB_USER"`
+	DBPassword            string   `mapstructure:"DB_PASSWORD"`
+	DBName                string   `mapstructure:"DB_NAME"`
+	WebServerPort         string   `mapstructure:"WEB_SERVER_PORT"`
+	GRPCServerPort        string   `mapstructure:"GRPC_SERVER_PORT"`
+	InitialChatMessage    string   `mapstructure:"INITIAL_CHAT_MESSAGE"`
+	OpenAIApiKey          string   `mapstructure:"OPENAI_API_KEY"`
+	Model                 string   `mapstructure:"MODEL"`
+	ModelMaxTokens        int      `mapstructure:"MODEL_MAX_TOKENS"`
+	Temperature           float64  `mapstructure:"TEMPERATURE"`
+	TopP                  float64  `mapstructure:"TOP_P"`
+	N                     int      `mapstructure:"N"`
+	Stop                  []string `mapstructure:"STOP"`
+	MaxTokens             int      `mapstructure:"MAX_TOKENS"`
+	AuthToken             string   `mapstructure:"AUTH_TOKEN"`
+	AutoMigrate           bool     `mapstructure:"AUTO_MIGRATE"`
+	EmbeddingProvider     string   `mapstructure:"EMBEDDING_PROVIDER"` // openai, local ou vazio para desligar
+	EmbeddingModel        string   `mapstructure:"EMBEDDING_MODEL"`
+	RecallTopK            int      `mapstructure:"RECALL_TOP_K"`
+	RecallMinScore        float64  `mapstructure:"RECALL_MIN_SCORE"`
+	KnowledgeBaseID       string   `mapstructure:"KNOWLEDGE_BASE_ID"` // base padrao usada nas respostas
+	RAGTopK               int      `mapstructure:"RAG_TOP_K"`
+	RAGMinScore           float64  `mapstructure:"RAG_MIN_SCORE"`
+	ChunkSize             int      `mapstructure:"CHUNK_SIZE"`              // tokens por chunk dos documentos
+	ChunkOverlap          int      `mapstructure:"CHUNK_OVERLAP"`           // tokens repetidos entre chunks vizinhos
+	VectorStore           string   `mapstructure:"VECTOR_STORE"`            // mysql (padrao), flat ou hnsw
+	VectorStorePath       string   `mapstructure:"VECTOR_STORE_PATH"`       // arquivo do indice local (flat/hnsw)
+	VectorFlushInterval   int      `mapstructure:"VECTOR_FLUSH_INTERVAL"`   // segundos entre as gravacoes do indice local, 0 usa 5
+	Tools                 []string `mapstructure:"TOOLS"`                   // ferramentas builtin habilitadas, vazio desliga
+	MaxToolRounds         int      `mapstructure:"MAX_TOOL_ROUNDS"`         // rodadas de chamadas de ferramenta por resposta
+	ToolsFile             string   `mapstructure:"TOOLS_FILE"`              // json com servidores de ferramentas externos e perfis
+	SchemaMaxRetries      int      `mapstructure:"SCHEMA_MAX_RETRIES"`      // correcoes pedidas quando a resposta nao valida no response_schema
+	AdminToken            string   `mapstructure:"ADMIN_TOKEN"`             // token da api de administracao, vazio desliga as rotas /admin
+	DefaultPromptTemplate string   `mapstructure:"DEFAULT_PROMPT_TEMPLATE"` // template usado quando a requisicao nao escolhe um
+	JWTSecret             string   `mapstructure:"JWT_SECRET"`              // segredo dos tokens HS256, vazio desliga
+	JWTJWKSFile           string   `mapstructure:"JWT_JWKS_FILE"`           // jwks com as chaves publicas dos tokens RS256, vazio desliga
+	JWTIssuer             string   `mapstructure:"JWT_ISSUER"`              // iss exigido nos tokens, vazio nao valida
+	JWTAudience           string   `mapstructure:"JWT_AUDIENCE"`            // aud exigido nos tokens, vazio nao valida
+	JWTRequired           bool     `mapstructure:"JWT_REQUIRED"`            // padrao true, recusa AUTH_TOKEN e os tokens de tenant quando o jwt esta ligado
+	APIKeyCacheTTL        int      `mapstructure:"API_KEY_CACHE_TTL"`       // segundos que uma chave de api fica em cache, 0 usa 60
+	TLSCertFile           string   `mapstructure:"TLS_CERT_FILE"`           // certificado pem dos servidores http e grpc, vazio roda sem tls
+	TLSKeyFile            string   `mapstructure:"TLS_KEY_FILE"`            // chave privada pem do certificado
+	TLSClientCAFile       string   `mapstructure:"TLS_CLIENT_CA_FILE"`      // ca dos certificados de cliente (mtls)
+	TLSClientAuth         string   `mapstructure:"TLS_CLIENT_AUTH"`         // none (padrao), request ou require
+	TLSClientIdentities   string   `mapstructure:"TLS_CLIENT_IDENTITIES"`   // json que mapeia o subject dos certificados de cliente para usuario, tenant e escopos
+	TLSReloadInterval     int      `mapstructure:"TLS_RELOAD_INTERVAL"`     // segundos entre as checagens dos arquivos de certificado, 0 usa 10
+	Redaction             []string `mapstructure:"REDACTION"`               // detectores de dados pessoais (email, phone, card, cpf, cnpj), vazio desliga
+	RedactionPatternsFile string   `mapstructure:"REDACTION_PATTERNS_FILE"` // json com regex customizadas [{"name","pattern"}]
+	RedactionStore        string   `mapstructure:"REDACTION_STORE"`         // raw (padrao) salva o texto original, redacted salva com os placeholders
+	RedactionKey          string   `mapstructure:"REDACTION_KEY"`           // chave dos placeholders, vazio gera uma chave nova a cada inicio
+	ModerationProvider    string   `mapstructure:"MODERATION_PROVIDER"`     // openai, local ou vazio para nao consultar um provedor de moderacao
+	ModerationModel       string   `mapstructure:"MODERATION_MODEL"`
+	ModerationBlocklist   string   `mapstructure:"MODERATION_BLOCKLIST"`    // json com palavras e regex proibidas {"words","patterns"}
+	ModerationMaxInput    int      `mapstructure:"MODERATION_MAX_INPUT"`    // caracteres da msg do usuario, 0 sem limite
+	ModerationMaxOutput   int      `mapstructure:"MODERATION_MAX_OUTPUT"`   // caracteres da resposta, 0 sem limite
+	ModerationInputAction string   `mapstructure:"MODERATION_INPUT_ACTION"` // acao na msg do usuario sinalizada: reject (padrao) ou truncate
+	ModerationAction      string   `mapstructure:"MODERATION_ACTION"`       // acao na resposta sinalizada: reject (padrao), truncate ou replace
+	ModerationReplacement string   `mapstructure:"MODERATION_REPLACEMENT"`  // resposta usada no lugar do texto sinalizado
+	AuditSink             string   `mapstructure:"AUDIT_SINK"`              // mysql, file ou vazio para desligar o log de auditoria
+	AuditFile             string   `mapstructure:"AUDIT_FILE"`              // arquivo do sink file, vazio usa data/audit.log
+	EncryptionKeyfile     string   `mapstructure:"ENCRYPTION_KEYFILE"`      // json com as chaves mestras do conteudo das msgs, vazio grava em texto puro
+	SearchEnabled         bool     `mapstructure:"SEARCH_ENABLED"`          // busca full text nas msgs, padrao true sem ENCRYPTION_KEYFILE e false com ele
+	RetentionFile         string   `mapstructure:"RETENTION_FILE"`          // json com as regras de retencao por tenant e assistente, vazio desliga
+	RetentionInterval     int      `mapstructure:"RETENTION_INTERVAL"`      // segundos entre as execucoes do worker de retencao, 0 usa 3600
+	RetentionBatchSize    int      `mapstructure:"RETENTION_BATCH_SIZE"`    // linhas lidas por consulta, 0 usa 100
+	RetentionMaxPerRun    int      `mapstructure:"RETENTION_MAX_PER_RUN"`   // linhas removidas por execucao do worker, 0 usa 1000
+	RetentionDryRun       bool     `mapstructure:"RETENTION_DRY_RUN"`       // o worker somente conta o que seria removido
 }
 
 func LoadConfig(path string) (*conf, error) {
@@ -30,6 +82,8 @@ func LoadConfig(path string) (*conf, error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
+	//com o jwt ligado os tokens estaticos, que informam o usuario no corpo, so sao aceitos com JWT_REQUIRED=false
+	viper.SetDefault("JWT_REQUIRED", true)
 	err := viper.ReadInConfig()
 	if err != nil {
 		panic(err)
@@ -38,5 +92,9 @@ func LoadConfig(path string) (*conf, error) {
 	if err != nil {
 		panic(err)
 	}
+	//o indice full text do mysql so encontra as msgs em texto puro, com a cifragem a busca fica desligada por padrao
+	if !viper.IsSet("SEARCH_ENABLED") {
+		cfg.SearchEnabled = cfg.EncryptionKeyfile == ""
+	}
 	return cfg, nil
-}
\ No newline at end of file
+}
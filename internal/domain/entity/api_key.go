@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix prefixo das chaves de api dos usuarios, separa as chaves dos outros tokens no header authorization
+const APIKeyPrefix = "vak_"
+
+// tamanho do inicio da chave guardado em texto para o usuario reconhecer a chave na listagem
+const apiKeyDisplayLen = 12
+
+// APIKey chave de api de um usuario, guardada somente como hash. Autentica como o usuario dono,
+// no tenant e com os escopos da chave
+type APIKey struct {
+	ID         string
+	UserID     string
+	TenantID   string // vazio no tenant padrao
+	Name       string
+	Prefix     string // inicio da chave, para identificar a chave sem guardar o segredo
+	KeyHash    string // sha256 da chave, a chave so é mostrada na criacao
+	Scopes     []string
+	ExpiresAt  *time.Time // nil nao expira
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// NewAPIKey cria a chave de api, retornada somente aqui
+func NewAPIKey(userID string, tenantID string, name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := APIKeyPrefix + hex.EncodeToString(secret)
+	apiKey := &APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLen],
+		KeyHash:   HashAPIKey(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := apiKey.Validate(); err != nil {
+		return nil, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (k *APIKey) Validate() error {
+	if k.UserID == "" {
+		return fmt.Errorf("%w: user id is empty", ErrInvalidAPIKey)
+	}
+	if strings.TrimSpace(k.Name) == "" || len(k.Name) > 100 {
+		return fmt.Errorf("%w: name must have 1 to 100 characters", ErrInvalidAPIKey)
+	}
+	if len(k.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	for _, scope := range k.Scopes {
+		if !IsValidScope(scope) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, scope)
+		}
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(k.CreatedAt) {
+		return fmt.Errorf("%w: expiration must be in the future", ErrInvalidAPIKey)
+	}
+	return nil
+}
+
+// Active chave nao revogada e nao expirada
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// Principal usuario autenticado pela chave
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		UserID:   k.UserID,
+		TenantID: k.TenantID,
+		Scopes:   k.Scopes,
+	}
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Assistant bot hospedado pelo servico (suporte, vendas, ...), com a propria config de modelo,
+// msg inicial, ferramentas e base de conhecimento. Os chats criados com ele copiam a config
+type Assistant struct {
+	ID              string
+	Name            string
+	Description     string
+	Config          *ChatConfig
+	SystemPrompt    string // msg inicial de sistema dos chats
+	PromptTemplate  string // template da msg inicial, tem prioridade sobre SystemPrompt
+	ToolProfile     string // perfil de ferramentas liberadas, vazio usa o perfil padrao
+	KnowledgeBaseID string // base de conhecimento das respostas, vazio usa a base padrao
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func NewAssistant(name, description, systemPrompt string, config *ChatConfig) (*Assistant, error) {
+	assistant := &Assistant{
+		ID:           uuid.New().String(),
+		Name:         name,
+		Description:  description,
+		Config:       config,
+		SystemPrompt: systemPrompt,
+		CreatedAt:    time.Now(),
+	}
+	assistant.UpdatedAt = assistant.CreatedAt
+	if err := assistant.Validate(); err != nil {
+		return nil, err
+	}
+	return assistant, nil
+}
+
+func (a *Assistant) Validate() error {
+	if strings.TrimSpace(a.Name) == "" || len(a.Name) > 100 {
+		return fmt.Errorf("%w: name must have 1 to 100 characters", ErrInvalidAssistant)
+	}
+	if strings.TrimSpace(a.SystemPrompt) == "" && a.PromptTemplate == "" {
+		return fmt.Errorf("%w: system prompt or prompt template is required", ErrInvalidAssistant)
+	}
+	if a.PromptTemplate != "" && !validTemplateName.MatchString(a.PromptTemplate) {
+		return fmt.Errorf("%w: invalid prompt template name", ErrInvalidAssistant)
+	}
+	if a.Config == nil || a.Config.Model == nil || a.Config.Model.Name == "" {
+		return fmt.Errorf("%w: model is empty", ErrInvalidAssistant)
+	}
+	if a.Config.Model.MaxTokens <= 0 {
+		return fmt.Errorf("%w: model max tokens must be positive", ErrInvalidAssistant)
+	}
+	if a.Config.Temperature < 0 || a.Config.Temperature > 2 {
+		return fmt.Errorf("%w: invalid temperature must be (0 - 2)", ErrInvalidAssistant)
+	}
+	if a.Config.TopP < 0 || a.Config.TopP > 1 {
+		return fmt.Errorf("%w: invalid top_p must be (0 - 1)", ErrInvalidAssistant)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tipos de evento do log de auditoria
+const (
+	AuditCompletion = "chat.completion" // msg do usuario, resposta e config usada
+	AuditAccess     = "access"          // requisicao http ou rpc, com o chamador e o status
+	AuditAdmin      = "admin"           // operacao nas rotas de administracao
+	AuditPrivacy    = "privacy"         // exportacao ou exclusao dos dados de um usuario (lgpd/gdpr)
+)
+
+// AuditGenesisHash prev_hash do primeiro evento da cadeia
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditEvent evento do log de auditoria. Cada evento guarda o hash do anterior (PrevHash) e o proprio hash,
+// calculado sobre todos os campos: alterar, remover ou inserir um evento quebra a cadeia a partir dele
+type AuditEvent struct {
+	Seq       int64           `json:"seq"`
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	UserID    string          `json:"user_id,omitempty"`
+	TenantID  string          `json:"tenant_id,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Action    string          `json:"action"`             // rpc, ou metodo e rota http
+	Resource  string          `json:"resource,omitempty"` // id do chat, do tenant, etc
+	Data      json.RawMessage `json:"data,omitempty"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditFilter filtro da exportacao, AfterSeq é o cursor e zero nos tempos nao filtra
+type AuditFilter struct {
+	AfterSeq int64
+	Type     string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// NewAuditEvent evento ainda fora da cadeia, Seq, PrevHash e Hash sao definidos no Chain
+func NewAuditEvent(eventType string, action string, resource string, data json.RawMessage) *AuditEvent {
+	return &AuditEvent{
+		ID:       uuid.New().String(),
+		Type:     eventType,
+		Action:   action,
+		Resource: resource,
+		Data:     data,
+		//o banco guarda microssegundos, o hash tem que bater depois de ler de volta
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// Chain liga o evento ao anterior (nil no primeiro evento) e calcula o hash
+func (e *AuditEvent) Chain(prev *AuditEvent) {
+	e.Seq, e.PrevHash = 1, AuditGenesisHash
+	if prev != nil {
+		e.Seq, e.PrevHash = prev.Seq+1, prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash sha256 dos campos do evento em json, na ordem fixa abaixo
+func (e *AuditEvent) ComputeHash() string {
+	fields, _ := json.Marshal([]interface{}{
+		e.Seq,
+		e.ID,
+		e.Type,
+		e.UserID,
+		e.TenantID,
+		e.RequestID,
+		e.Action,
+		e.Resource,
+		string(e.Data),
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAuditEventChain(t *testing.T) {
+	first := NewAuditEvent(AuditAdmin, "POST /admin/tenants", "tenant-1", json.RawMessage(`{"name":"acme"}`))
+	first.Chain(nil)
+	if first.Seq != 1 || first.PrevHash != AuditGenesisHash || first.Hash != first.ComputeHash() || len(first.Hash) != 64 {
+		t.Fatalf("expected the first event linked to the genesis hash, got %+v", first)
+	}
+	second := NewAuditEvent(AuditAccess, "GET /chats", "", nil)
+	second.Chain(first)
+	if second.Seq != 2 || second.PrevHash != first.Hash || second.Hash == first.Hash {
+		t.Fatalf("expected the second event linked to the first, got %+v", second)
+	}
+}
+
+func TestAuditEventHashCoversEveryField(t *testing.T) {
+	base := AuditEvent{
+		Seq:       3,
+		ID:        "event-1",
+		Type:      AuditCompletion,
+		UserID:    "user-1",
+		TenantID:  "tenant-1",
+		RequestID: "request-1",
+		Action:    "ChatStream",
+		Resource:  "chat-1",
+		Data:      json.RawMessage(`{"tokens":10}`),
+		PrevHash:  AuditGenesisHash,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+	}
+	hash := base.ComputeHash()
+	if again := base.ComputeHash(); again != hash {
+		t.Fatalf("expected a deterministic hash, got %s and %s", hash, again)
+	}
+	//o mesmo instante em outro fuso tem o mesmo hash
+	local := base
+	local.CreatedAt = base.CreatedAt.In(time.FixedZone("BRT", -3*60*60))
+	if local.ComputeHash() != hash {
+		t.Fatal("expected the hash to ignore the time zone")
+	}
+
+	changes := map[string]func(e *AuditEvent){
+		"seq":        func(e *AuditEvent) { e.Seq = 4 },
+		"id":         func(e *AuditEvent) { e.ID = "event-2" },
+		"type":       func(e *AuditEvent) { e.Type = AuditAccess },
+		"user_id":    func(e *AuditEvent) { e.UserID = "user-2" },
+		"tenant_id":  func(e *AuditEvent) { e.TenantID = "tenant-2" },
+		"request_id": func(e *AuditEvent) { e.RequestID = "request-2" },
+		"action":     func(e *AuditEvent) { e.Action = "Chat" },
+		"resource":   func(e *AuditEvent) { e.Resource = "chat-2" },
+		"data":       func(e *AuditEvent) { e.Data = json.RawMessage(`{"tokens":11}`) },
+		"prev_hash":  func(e *AuditEvent) { e.PrevHash = hash },
+		"created_at": func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+	}
+	for field, change := range changes {
+		event := base
+		change(&event)
+		if event.ComputeHash() == hash {
+			t.Fatalf("expected a change in %s to change the hash", field)
+		}
+	}
+}
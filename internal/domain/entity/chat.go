@@ -1,7 +1,8 @@
 package entity
 
 import (
-	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -26,6 +27,14 @@ type Chat struct {
 	Status               string
 	TokenUsage           int //qnts token ja foram utilizados
 	Config               *ChatConfig
+	SharedWith           []string // usuarios, alem do dono, que podem acessar o chat
+	PromptTemplate       string   // template da msg inicial de sistema, vazio quando veio da config
+	TemplateVersion      int      // versao do PromptTemplate usada na criacao do chat
+	AssistantID          string   // assistente que atende o chat, vazio usa a config do servico
+	TenantID             string   // tenant dono do chat, vazio no tenant padrao
+	ToolProfile          string   // perfil de ferramentas resolvido na criacao (assistente ou tenant), vazio usa o perfil default
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
 
 func NewChat(userID string, initialSystemMessage *Message, chatConfig *ChatConfig) (*Chat, error) {
@@ -36,26 +45,34 @@ func NewChat(userID string, initialSystemMessage *Message, chatConfig *ChatConfi
 		Status:               "active",
 		Config:               chatConfig,
 		TokenUsage:           0,
+		CreatedAt:            time.Now(),
 	}
-	chat.AddMessage(initialSystemMessage)
-	
+	chat.UpdatedAt = chat.CreatedAt
 	if err := chat.Validate(); err != nil {
 		return nil,err
 	}
+
+	if err := chat.AddMessage(initialSystemMessage); err != nil {
+		return nil, err
+	}
 	return chat,nil
 }
 
 func (c *Chat) Validate() error {
 	if c.UserID == "" {
-		return errors.New("user id is empty")
+		return fmt.Errorf("%w: user id is empty", ErrInvalidChat)
 	}
 
 	if c.Status != "active" && c.Status != "ended" {
-		return errors.New("invalid status")
+		return fmt.Errorf("%w: invalid status", ErrInvalidChat)
+	}
+
+	if c.Config == nil || c.Config.Model == nil {
+		return fmt.Errorf("%w: model is empty", ErrInvalidConfig)
 	}
 
 	if c.Config.Temperature < 0 || c.Config.Temperature > 2 {
-		return errors.New("invalid temperature must be (0 - 2)")
+		return fmt.Errorf("%w: invalid temperature must be (0 - 2)", ErrInvalidConfig)
 	}
 
 	return nil
@@ -63,7 +80,12 @@ func (c *Chat) Validate() error {
 
 func (c *Chat) AddMessage(m *Message) error {
 	if c.Status == "ended" {
-		return errors.New("chat is ended, m=no more messages allowed")
+		return ErrChatEnded
+	}
+
+	//a msg sozinha nao cabe no contexto do modelo, apagar as outras nao resolve
+	if m.GetQTDTokens() > c.Config.Model.GetMaxToken() {
+		return ErrContextOverflow
 	}
 
 	//percorrer as msgs para verificar a quatidade de tokens, se nao excedeu o limite do modelo do chatgpt
@@ -78,6 +100,11 @@ func (c *Chat) AddMessage(m *Message) error {
 		//se nao tiver espaco para msg, apaga a mais antiga, e inseri na lista de menssagens apagadas
 		c.ErasedMessages = append(c.ErasedMessages, c.Messages[0])
 		c.Messages = c.Messages[1:] //apaga a msg 0
+		//resultados de ferramentas sem a msg que fez a chamada sao rejeitados pela api, saem junto
+		for len(c.Messages) > 0 && (c.Messages[0].Role == "tool" || c.Messages[0].Role == "function") {
+			c.ErasedMessages = append(c.ErasedMessages, c.Messages[0])
+			c.Messages = c.Messages[1:]
+		}
 		c.RefreshTokenUsage()
 	}
 	return nil
@@ -102,3 +129,40 @@ func (c *Chat) RefreshTokenUsage() {
 		c.TokenUsage += c.Messages[m].GetQTDTokens()
 	}
 }
+
+func (c *Chat) IsOwner(userID string) bool {
+	return userID != "" && c.UserID == userID
+}
+
+// CanAccess verifica se o usuario é dono do chat ou recebeu acesso compartilhado
+func (c *Chat) CanAccess(userID string) bool {
+	if c.IsOwner(userID) {
+		return true
+	}
+	for _, id := range c.SharedWith {
+		if userID != "" && id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Chat) Share(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user id to share is empty", ErrInvalidChat)
+	}
+	if c.CanAccess(userID) {
+		return nil
+	}
+	c.SharedWith = append(c.SharedWith, userID)
+	return nil
+}
+
+func (c *Chat) Unshare(userID string) {
+	for i, id := range c.SharedWith {
+		if id == userID {
+			c.SharedWith = append(c.SharedWith[:i], c.SharedWith[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultKnowledgeBaseID = "default"
+
+// Document documento da base de conhecimento, o texto é dividido em chunks para a busca
+type Document struct {
+	ID              string
+	KnowledgeBaseID string
+	Title           string
+	Source          string // url ou caminho de origem, opcional
+	ContentType     string // text/plain, text/markdown ou text/html
+	Tokens          int
+	CreatedAt       time.Time
+}
+
+// DocumentChunk trecho do texto extraido do documento, Start e End sao offsets em bytes no texto extraido
+type DocumentChunk struct {
+	ID              string
+	DocumentID      string
+	KnowledgeBaseID string
+	Index           int
+	Content         string
+	Tokens          int
+	Start           int
+	End             int
+	Model           string // modelo do embedding
+	Vector          []float32
+	CreatedAt       time.Time
+}
+
+// Citation referencia ao chunk usado como contexto na resposta
+type Citation struct {
+	DocumentID string
+	Title      string
+	ChunkIndex int
+	Start      int
+	End        int
+	Score      float32
+}
+
+func NewDocument(knowledgeBaseID, title, source, contentType string) (*Document, error) {
+	if knowledgeBaseID == "" {
+		knowledgeBaseID = DefaultKnowledgeBaseID
+	}
+	doc := &Document{
+		ID:              uuid.New().String(),
+		KnowledgeBaseID: knowledgeBaseID,
+		Title:           title,
+		Source:          source,
+		ContentType:     contentType,
+		CreatedAt:       time.Now(),
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (d *Document) Validate() error {
+	if d.Title == "" {
+		return fmt.Errorf("%w: title is empty", ErrInvalidDocument)
+	}
+	switch d.ContentType {
+	case "text/plain", "text/markdown", "text/html":
+	default:
+		return fmt.Errorf("%w: unsupported content type %q", ErrInvalidDocument, d.ContentType)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// MessageEmbedding vetor de uma msg do chat, usado para relembrar msgs apagadas do contexto
+type MessageEmbedding struct {
+	MessageID string
+	ChatID    string
+	Model     string // embeddings de modelos diferentes nao sao comparaveis
+	Vector    []float32
+	CreatedAt time.Time
+}
+
+// CosineSimilarity similaridade entre dois vetores (-1 a 1), 0 quando as dimensoes nao batem
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
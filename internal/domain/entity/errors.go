@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+// erros do dominio, as camadas de entrada (http/grpc) convertem para status code
+var (
+	ErrChatNotFound     = errors.New("chat not found")
+	ErrChatEnded        = errors.New("chat is ended, no more messages allowed")
+	ErrForbidden        = errors.New("user is not allowed to access this chat")
+	ErrInvalidChat      = errors.New("invalid chat")
+	ErrInvalidConfig    = errors.New("invalid chat config")
+	ErrInvalidMessage   = errors.New("invalid message")
+	ErrContextOverflow  = errors.New("message exceeds the model context window")
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+	ErrInvalidDocument  = errors.New("invalid document")
+	ErrDocumentNotFound = errors.New("document not found")
+	ErrInvalidOutput    = errors.New("model response does not match the response schema")
+	ErrContentRejected  = errors.New("content rejected by moderation")
+
+	ErrInvalidPromptTemplate  = errors.New("invalid prompt template")
+	ErrPromptTemplateNotFound = errors.New("prompt template not found")
+	ErrInvalidAssistant       = errors.New("invalid assistant")
+	ErrAssistantNotFound      = errors.New("assistant not found")
+	ErrInvalidTenant          = errors.New("invalid tenant")
+	ErrTenantNotFound         = errors.New("tenant not found")
+	ErrQuotaExceeded          = errors.New("tenant token quota exceeded")
+	ErrInvalidAPIKey          = errors.New("invalid api key")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrUnauthenticated        = errors.New("authorization token is invalid")
+	ErrMissingScope           = errors.New("authorization token does not grant the required scope")
+	ErrAuditConflict          = errors.New("audit log sequence already used")
+	ErrAuditTampered          = errors.New("audit log chain is broken")
+	ErrInvalidUser            = errors.New("invalid user id")
+
+	ErrProviderRateLimited = errors.New("model provider rate limit exceeded")
+	ErrProviderUnavailable = errors.New("model provider unavailable")
+	ErrProviderRejected    = errors.New("model provider rejected the request")
+)
+
+// ProviderError erro retornado pela api do modelo (openai), Kind é um dos ErrProvider*
+type ProviderError struct {
+	Kind       error
+	StatusCode int
+	Err        error
+}
+
+func NewProviderError(statusCode int, err error) *ProviderError {
+	kind := ErrProviderRejected
+	switch {
+	case statusCode == 429:
+		kind = ErrProviderRateLimited
+	case statusCode == 0 || statusCode >= 500:
+		kind = ErrProviderUnavailable
+	}
+	return &ProviderError{
+		Kind:       kind,
+		StatusCode: statusCode,
+		Err:        err,
+	}
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s (status %d): %v", e.Kind, e.StatusCode, e.Err)
+}
+
+func (e *ProviderError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
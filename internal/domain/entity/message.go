@@ -1,7 +1,7 @@
 package entity
 
 import (
-	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,12 +9,23 @@ import (
 )
 
 type Message struct {
-	ID        string
-	Role      string
-	Content   string
-	Tokens    int
-	Model     *Model
-	CreatedAt time.Time
+	ID         string
+	Role       string
+	Content    string
+	Tokens     int
+	Model      *Model
+	ToolCalls  []ToolCall          // ferramentas que o assistente pediu para executar
+	ToolCallID string              // chamada respondida por uma msg com role tool
+	Name       string              // ferramenta que gerou o resultado (role tool ou function)
+	Moderation []ModerationVerdict // resultado das politicas de moderacao da msg do usuario ou da resposta
+	CreatedAt  time.Time
+}
+
+// ToolCall pedido do modelo para executar uma ferramenta, Arguments é o json gerado pelo modelo
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 func NewMessage(role, content string, model *Model) (*Message,error) {
@@ -36,15 +47,74 @@ func NewMessage(role, content string, model *Model) (*Message,error) {
 	return msg,nil
 }
 
+// NewToolCallMessage msg do assistente pedindo a execucao de ferramentas, normalmente sem texto
+func NewToolCallMessage(content string, calls []ToolCall, model *Model) (*Message, error) {
+	tokens := tiktoken_go.CountTokens(model.GetModelName(), content)
+	for _, call := range calls {
+		tokens += tiktoken_go.CountTokens(model.GetModelName(), call.Name+call.Arguments)
+	}
+
+	msg := &Message{
+		ID:        uuid.New().String(),
+		Role:      "assistant",
+		Content:   content,
+		Tokens:    tokens,
+		Model:     model,
+		ToolCalls: calls,
+		CreatedAt: time.Now(),
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// NewToolResultMessage resultado da execucao da ferramenta pedida em callID
+func NewToolResultMessage(callID, name, content string, model *Model) (*Message, error) {
+	msg := &Message{
+		ID:         uuid.New().String(),
+		Role:       "tool",
+		Content:    content,
+		Tokens:     tiktoken_go.CountTokens(model.GetModelName(), content),
+		Model:      model,
+		ToolCallID: callID,
+		Name:       name,
+		CreatedAt:  time.Now(),
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
 func (m *Message) Validate() error {
-	if m.Role != "user" && m.Role != "system" && m.Role != "assistant" {
-		return errors.New("invalid role")
+	switch m.Role {
+	case "user", "system", "assistant":
+	case "tool":
+		if m.ToolCallID == "" {
+			return fmt.Errorf("%w: tool message without tool call id", ErrInvalidMessage)
+		}
+	case "function":
+		if m.Name == "" {
+			return fmt.Errorf("%w: function message without name", ErrInvalidMessage)
+		}
+	default:
+		return fmt.Errorf("%w: invalid role", ErrInvalidMessage)
+	}
+	if len(m.ToolCalls) > 0 && m.Role != "assistant" {
+		return fmt.Errorf("%w: only assistant messages can call tools", ErrInvalidMessage)
+	}
+	for _, call := range m.ToolCalls {
+		if call.ID == "" || call.Name == "" {
+			return fmt.Errorf("%w: tool call without id or name", ErrInvalidMessage)
+		}
 	}
-	if m.Content == "" {
-		return errors.New("content is empty")
+	//msg do assistente que so chama ferramentas nao tem texto
+	if m.Content == "" && len(m.ToolCalls) == 0 {
+		return fmt.Errorf("%w: content is empty", ErrInvalidMessage)
 	}
 	if m.CreatedAt.IsZero() {
-		return errors.New("created_at invalid")
+		return fmt.Errorf("%w: created_at invalid", ErrInvalidMessage)
 	}
 	return nil
 }
@@ -0,0 +1,28 @@
+package entity
+
+// etapas e acoes da moderacao
+const (
+	ModerationInput  = "input"  // msg do usuario, antes de ir ao modelo
+	ModerationOutput = "output" // resposta do modelo, antes de chegar ao usuario
+
+	ModerationAllow    = "allow"    // texto liberado
+	ModerationReject   = "reject"   // requisicao recusada com ErrContentRejected
+	ModerationTruncate = "truncate" // texto cortado antes do trecho sinalizado
+	ModerationReplace  = "replace"  // resposta trocada pela msg padrao
+)
+
+// ModerationVerdict resultado de uma politica de moderacao, salvo junto da msg
+type ModerationVerdict struct {
+	Policy     string   `json:"policy"`
+	Stage      string   `json:"stage"`
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Action     string   `json:"action"`
+}
+
+// ModerationResult resposta do provedor de moderacao
+type ModerationResult struct {
+	Flagged    bool
+	Categories []string
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+)
+
+// escopos exigidos dos tokens jwt
+const (
+	ScopeChat    = "chat"    // conversar e compartilhar chats
+	ScopeHistory = "history" // listar e buscar chats e mensagens
+)
+
+// Principal usuario autenticado por um token assinado, com a identidade vinda das claims verificadas
+type Principal struct {
+	UserID   string
+	TenantID string
+	Scopes   []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScope escopo conhecido pelo servico
+func IsValidScope(scope string) bool {
+	return scope == ScopeChat || scope == ScopeHistory
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal guarda o usuario autenticado na requisicao
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext usuario autenticado por token assinado, nil nos tokens estaticos
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// CallerUserID usuario da requisicao: o das claims quando autenticado por token assinado, chave de api ou certificado,
+// e user_id diferente no corpo é recusado. Sem principal vale o informado pelo cliente, que so é aceito dos tokens
+// estaticos de servico
+func CallerUserID(ctx context.Context, userID string) (string, error) {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		if userID != "" && userID != principal.UserID {
+			return "", fmt.Errorf("%w: user_id does not match the authenticated user", ErrForbidden)
+		}
+		return principal.UserID, nil
+	}
+	return userID, nil
+}
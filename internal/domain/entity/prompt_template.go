@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	validTemplateName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	// {{variavel}}, espacos dentro das chaves sao aceitos
+	templateVariable = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+)
+
+// PromptTemplate versao de um template da msg inicial de sistema dos chats.
+// Defaults sao os valores das variaveis que a requisicao nao informar
+type PromptTemplate struct {
+	Name        string
+	Version     int // atribuida pelo repositorio ao criar, sequencial por nome
+	Content     string
+	Description string
+	Defaults    map[string]string
+	Active      bool // versao usada quando a requisicao nao pede uma versao
+	CreatedAt   time.Time
+}
+
+func NewPromptTemplate(name, content, description string, defaults map[string]string) (*PromptTemplate, error) {
+	if defaults == nil {
+		defaults = map[string]string{}
+	}
+	template := &PromptTemplate{
+		Name:        name,
+		Content:     content,
+		Description: description,
+		Defaults:    defaults,
+		Active:      true,
+		CreatedAt:   time.Now(),
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (t *PromptTemplate) Validate() error {
+	if !validTemplateName.MatchString(t.Name) {
+		return fmt.Errorf("%w: name must be lowercase letters, numbers, _ or -", ErrInvalidPromptTemplate)
+	}
+	if strings.TrimSpace(t.Content) == "" {
+		return fmt.Errorf("%w: content is empty", ErrInvalidPromptTemplate)
+	}
+	//chaves abertas sem fechar indicam variavel digitada errada
+	if strings.Count(templateVariable.ReplaceAllString(t.Content, ""), "{{") > 0 {
+		return fmt.Errorf("%w: invalid variable syntax, use {{name}}", ErrInvalidPromptTemplate)
+	}
+	return nil
+}
+
+// Variables nomes das variaveis usadas no conteudo, ordenados
+func (t *PromptTemplate) Variables() []string {
+	seen := map[string]bool{}
+	var res []string
+	for _, match := range templateVariable.FindAllStringSubmatch(t.Content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			res = append(res, match[1])
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Render substitui as variaveis pelos valores informados ou pelos defaults, falta de valor é erro
+func (t *PromptTemplate) Render(values map[string]string) (string, error) {
+	var missing []string
+	seen := map[string]bool{}
+	res := templateVariable.ReplaceAllStringFunc(t.Content, func(s string) string {
+		name := templateVariable.FindStringSubmatch(s)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		if v, ok := t.Defaults[name]; ok {
+			return v
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return s
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: missing template variables: %s", ErrInvalidConfig, strings.Join(missing, ", "))
+	}
+	return res, nil
+}
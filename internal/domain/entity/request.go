@@ -0,0 +1,16 @@
+package entity
+
+import "context"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID guarda o id da requisicao, usado nos logs e na auditoria
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID id da requisicao atual, vazio fora dos middlewares
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
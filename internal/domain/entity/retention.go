@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// acoes da retencao nos chats inativos
+const (
+	RetentionDelete    = "delete"    // apaga o chat com as msgs, compartilhamentos e embeddings
+	RetentionAnonymize = "anonymize" // mantem o chat e as msgs sem conteudo e sem usuario, para estatisticas de uso
+)
+
+// RetentionPolicy regra de retencao de um tenant e/ou assistente, vazio vale para todos.
+// Zero em InactiveDays ou ErasedDays mantem os dados para sempre
+type RetentionPolicy struct {
+	TenantID     string `json:"tenant_id,omitempty"`
+	AssistantID  string `json:"assistant_id,omitempty"`
+	Action       string `json:"action"`        // delete (padrao) ou anonymize
+	InactiveDays int    `json:"inactive_days"` // dias sem atividade ate o chat ser apagado ou anonimizado
+	ErasedDays   int    `json:"erased_days"`   // dias ate as msgs que sairam do contexto serem apagadas
+}
+
+// InactiveChat chat candidato a retencao, Anonymized quando ja perdeu o usuario e o conteudo
+type InactiveChat struct {
+	ID          string
+	TenantID    string
+	AssistantID string
+	Anonymized  bool
+	UpdatedAt   time.Time
+}
+
+// ErasedMessage msg apagada do contexto candidata a retencao
+type ErasedMessage struct {
+	ID          string
+	ChatID      string
+	TenantID    string
+	AssistantID string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// prefixo dos tokens de api dos tenants, facilita identificar o token vazado em logs
+const tenantTokenPrefix = "tnt_"
+
+// Tenant empresa cliente atendida pela instalacao. Os chats sao isolados por tenant,
+// requisicoes com o token do servico (AUTH_TOKEN) usam o tenant padrao, de ID vazio
+type Tenant struct {
+	ID                 string
+	Name               string
+	TokenHash          string   // sha256 do token de api, o token so é mostrado na criacao
+	DefaultAssistantID string   // assistente dos chats novos que nao escolhem um
+	AllowedModels      []string // modelos liberados, vazio libera todos
+	MonthlyTokenQuota  int      // tokens por mes (UTC), 0 sem limite
+	ToolProfile        string   // perfil de ferramentas dos chats sem assistente ou cujo assistente nao define um
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type tenantContextKey struct{}
+
+// NewTenant cria o tenant e o token de api, retornado somente aqui
+func NewTenant(name string) (*Tenant, string, error) {
+	token, err := newTenantToken()
+	if err != nil {
+		return nil, "", err
+	}
+	tenant := &Tenant{
+		ID:        uuid.New().String(),
+		Name:      name,
+		TokenHash: HashTenantToken(token),
+		CreatedAt: time.Now(),
+	}
+	tenant.UpdatedAt = tenant.CreatedAt
+	if err := tenant.Validate(); err != nil {
+		return nil, "", err
+	}
+	return tenant, token, nil
+}
+
+func (t *Tenant) Validate() error {
+	if strings.TrimSpace(t.Name) == "" || len(t.Name) > 100 {
+		return fmt.Errorf("%w: name must have 1 to 100 characters", ErrInvalidTenant)
+	}
+	if t.MonthlyTokenQuota < 0 {
+		return fmt.Errorf("%w: monthly token quota must not be negative", ErrInvalidTenant)
+	}
+	for _, model := range t.AllowedModels {
+		if strings.TrimSpace(model) == "" {
+			return fmt.Errorf("%w: allowed models must not be empty", ErrInvalidTenant)
+		}
+	}
+	return nil
+}
+
+// AllowsModel informa se o tenant pode usar o modelo, tenant nil (padrao) usa qualquer modelo
+func (t *Tenant) AllowsModel(model string) bool {
+	if t == nil || len(t.AllowedModels) == 0 {
+		return true
+	}
+	for _, allowed := range t.AllowedModels {
+		if allowed == model {
+			return true
+		}
+	}
+	return false
+}
+
+func HashTenantToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTenantToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return tenantTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// ContextWithTenant guarda o tenant autenticado na requisicao
+func ContextWithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext tenant da requisicao, nil no tenant padrao
+func TenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantContextKey{}).(*Tenant)
+	return tenant
+}
+
+// TenantID id do tenant da requisicao, vazio no tenant padrao. Usado nos filtros de todas as queries de chats
+func TenantID(ctx context.Context) string {
+	if tenant := TenantFromContext(ctx); tenant != nil {
+		return tenant.ID
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// UserData tudo que o servico guarda de um usuario em um tenant. O servico nao guarda feedback das respostas,
+// o uso de tokens fica no TokenUsage dos chats
+type UserData struct {
+	UserID      string
+	TenantID    string  // vazio no tenant padrao
+	Chats       []*Chat // chats do usuario com as msgs ativas e as apagadas do contexto
+	SharedChats []ChatShare
+	APIKeys     []*APIKey
+}
+
+// ChatShare chat de outro usuario compartilhado com o usuario
+type ChatShare struct {
+	ChatID    string
+	TenantID  string
+	CreatedAt time.Time
+}
+
+// UserDataDeletion quantidade de registros apagados de um usuario
+type UserDataDeletion struct {
+	Chats     int
+	Messages  int
+	Shares    int      // acessos do usuario a chats de outros usuarios
+	APIKeyIDs []string // chaves apagadas, para limpar o cache da autenticacao
+}
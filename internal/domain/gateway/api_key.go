@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type APIKeyGateway interface {
+	CreateAPIKey(ctx context.Context, key *entity.APIKey) error
+	// FindAPIKeyByHash busca a chave pelo hash, inclusive revogadas e expiradas
+	FindAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	// ListAPIKeys lista as chaves do usuario, userID vazio lista todas
+	ListAPIKeys(ctx context.Context, userID string) ([]*entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error
+}
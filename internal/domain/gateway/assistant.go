@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type AssistantGateway interface {
+	CreateAssistant(ctx context.Context, assistant *entity.Assistant) error
+	FindAssistantByID(ctx context.Context, assistantID string) (*entity.Assistant, error)
+	// ListAssistants todos os assistentes, ordenados pelo nome
+	ListAssistants(ctx context.Context) ([]*entity.Assistant, error)
+	SaveAssistant(ctx context.Context, assistant *entity.Assistant) error
+	DeleteAssistant(ctx context.Context, assistantID string) error
+}
@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// AuditGateway log de auditoria somente de insercao (mysql ou arquivo)
+type AuditGateway interface {
+	// AppendAuditEvent liga o evento ao ultimo da cadeia (Chain) e grava, de forma atomica.
+	// Retorna ErrAuditConflict quando outro processo gravou o mesmo Seq, o evento pode ser gravado de novo
+	AppendAuditEvent(ctx context.Context, event *entity.AuditEvent) error
+	// ListAuditEvents eventos em ordem de Seq a partir do cursor do filtro
+	ListAuditEvents(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, error)
+}
@@ -2,12 +2,32 @@ package gateway
 
 import (
 	"context"
+	"time"
 
 	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
 )
 
+// ChatCursor posicao na listagem de chats, ordenada por updated_at desc
+type ChatCursor struct {
+	UpdatedAt time.Time
+	ID        string
+}
+
+// HistoryMessage msg do historico do chat com a posicao na conversa, incluindo as apagadas do contexto
+type HistoryMessage struct {
+	Message *entity.Message
+	Order   int
+	Erased  bool
+}
+
 type ChatGateway interface {
 	CreateChat(ctx context.Context, chat *entity.Chat) error
 	FindChatByID(ctx context.Context, chatID string) (*entity.Chat,error)
 	SaveChat(ctx context.Context, chat *entity.Chat) error
+	// FindChatSummaryByID retorna o chat sem carregar as mensagens
+	FindChatSummaryByID(ctx context.Context, chatID string) (*entity.Chat, error)
+	// ListChatsByUserID lista os chats do usuario (proprios e compartilhados) depois do cursor, sem mensagens
+	ListChatsByUserID(ctx context.Context, userID string, after *ChatCursor, limit int) ([]*entity.Chat, error)
+	// ListMessagesByChatID lista as mensagens do chat da mais recente para a mais antiga, antes da posicao before
+	ListMessagesByChatID(ctx context.Context, chatID string, before *int, limit int) ([]*HistoryMessage, error)
 }
\ No newline at end of file
@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// EmbeddingProvider gera os vetores dos textos (openai, local deterministico, ...)
+type EmbeddingProvider interface {
+	ModelName() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+type MessageEmbeddingGateway interface {
+	SaveMessageEmbeddings(ctx context.Context, embeddings []*entity.MessageEmbedding) error
+	FindMessageEmbeddingsByChatID(ctx context.Context, chatID string, model string) ([]*entity.MessageEmbedding, error)
+}
@@ -0,0 +1,15 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type KnowledgeGateway interface {
+	CreateDocument(ctx context.Context, doc *entity.Document, chunks []*entity.DocumentChunk) error
+	FindDocumentByID(ctx context.Context, documentID string) (*entity.Document, error)
+	DeleteDocument(ctx context.Context, documentID string) error
+	FindChunkByID(ctx context.Context, chunkID string) (*entity.DocumentChunk, error)
+	FindChunksByDocumentID(ctx context.Context, documentID string) ([]*entity.DocumentChunk, error)
+}
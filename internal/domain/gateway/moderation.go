@@ -0,0 +1,12 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// ModerationProvider classifica o texto em categorias de conteudo inseguro (openai, local, ...)
+type ModerationProvider interface {
+	Moderate(ctx context.Context, text string) (*entity.ModerationResult, error)
+}
@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type PromptTemplateGateway interface {
+	// CreatePromptTemplate grava a proxima versao do template (preenche Version) e a torna a versao ativa
+	CreatePromptTemplate(ctx context.Context, template *entity.PromptTemplate) error
+	// FindPromptTemplate busca a versao do template, version 0 busca a versao ativa
+	FindPromptTemplate(ctx context.Context, name string, version int) (*entity.PromptTemplate, error)
+	// ListPromptTemplates versao ativa de cada template
+	ListPromptTemplates(ctx context.Context) ([]*entity.PromptTemplate, error)
+	// ListPromptTemplateVersions todas as versoes do template, da mais nova para a mais antiga
+	ListPromptTemplateVersions(ctx context.Context, name string) ([]*entity.PromptTemplate, error)
+	ActivatePromptTemplate(ctx context.Context, name string, version int) error
+	DeletePromptTemplate(ctx context.Context, name string) error
+}
@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// RetentionGateway varredura e remocao dos dados vencidos, em todos os tenants
+type RetentionGateway interface {
+	// ListInactiveChats chats sem atividade desde before, em ordem de updated_at e id a partir do cursor (nil no inicio)
+	ListInactiveChats(ctx context.Context, before time.Time, includeAnonymized bool, after *ChatCursor, limit int) ([]*entity.InactiveChat, error)
+	// DeleteInactiveChat e AnonymizeChat nao alteram o chat que teve atividade depois de listado, retornando false
+	DeleteInactiveChat(ctx context.Context, chat *entity.InactiveChat) (bool, error)
+	AnonymizeChat(ctx context.Context, chat *entity.InactiveChat) (bool, error)
+	// ListErasedMessages msgs apagadas do contexto criadas antes de before, em ordem de id, sem a msg inicial dos chats
+	ListErasedMessages(ctx context.Context, before time.Time, afterID string, limit int) ([]*entity.ErasedMessage, error)
+	DeleteErasedMessage(ctx context.Context, messageID string) (bool, error)
+}
@@ -0,0 +1,31 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// MessageSearch filtros da busca nas mensagens do usuario, campos vazios nao filtram
+type MessageSearch struct {
+	UserID string
+	Query  string
+	ChatID string
+	Role   string
+	From   time.Time
+	To     time.Time
+	Offset int
+	Limit  int
+}
+
+type MessageSearchResult struct {
+	ChatID  string
+	Message *entity.Message
+	Erased  bool
+	Score   float64
+}
+
+type MessageSearchGateway interface {
+	SearchMessages(ctx context.Context, search MessageSearch) ([]*MessageSearchResult, error)
+}
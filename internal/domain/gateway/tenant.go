@@ -0,0 +1,20 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type TenantGateway interface {
+	CreateTenant(ctx context.Context, tenant *entity.Tenant) error
+	FindTenantByID(ctx context.Context, tenantID string) (*entity.Tenant, error)
+	// FindTenantByTokenHash busca o tenant dono do token de api, usado na autenticacao
+	FindTenantByTokenHash(ctx context.Context, tokenHash string) (*entity.Tenant, error)
+	ListTenants(ctx context.Context) ([]*entity.Tenant, error)
+	SaveTenant(ctx context.Context, tenant *entity.Tenant) error
+	DeleteTenant(ctx context.Context, tenantID string) error
+	// AddTenantUsage soma os tokens usados pelo tenant no periodo (YYYY-MM)
+	AddTenantUsage(ctx context.Context, tenantID string, period string, tokens int) error
+	FindTenantUsage(ctx context.Context, tenantID string, period string) (int, error)
+}
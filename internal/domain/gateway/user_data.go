@@ -0,0 +1,15 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// UserDataGateway dados de um usuario no tenant da requisicao (entity.TenantID), para os pedidos de acesso e exclusao (lgpd/gdpr)
+type UserDataGateway interface {
+	FindUserData(ctx context.Context, userID string) (*entity.UserData, error)
+	// DeleteUserData apaga numa transacao os chats do usuario (com msgs, compartilhamentos e embeddings),
+	// os compartilhamentos com ele e as chaves de api
+	DeleteUserData(ctx context.Context, userID string) (*entity.UserDataDeletion, error)
+}
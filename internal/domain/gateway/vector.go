@@ -0,0 +1,45 @@
+package gateway
+
+import "context"
+
+// VectorRecord vetor indexado com os metadados usados nos filtros das buscas
+type VectorRecord struct {
+	ID       string
+	Vector   []float32
+	Metadata map[string]string
+}
+
+// VectorQuery busca os TopK vetores mais proximos com score >= MinScore (TopK <= 0 retorna todos),
+// somente registros com todos os pares de Filter nos metadados entram no resultado
+type VectorQuery struct {
+	Vector   []float32
+	TopK     int
+	MinScore float32
+	Filter   map[string]string
+}
+
+type VectorMatch struct {
+	ID       string
+	Score    float32
+	Metadata map[string]string
+}
+
+// Matches indica se os metadados atendem o filtro da busca
+func (q VectorQuery) Matches(metadata map[string]string) bool {
+	for k, v := range q.Filter {
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// VectorStore armazena vetores separados por namespace, vetores de namespaces diferentes
+// (ex: modelos de embedding diferentes) nunca sao comparados
+type VectorStore interface {
+	// Upsert insere os registros, substituindo os que ja existem com o mesmo ID
+	Upsert(ctx context.Context, namespace string, records []VectorRecord) error
+	Delete(ctx context.Context, namespace string, ids []string) error
+	// Query retorna os registros mais proximos, do maior para o menor score
+	Query(ctx context.Context, namespace string, query VectorQuery) ([]VectorMatch, error)
+}
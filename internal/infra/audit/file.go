@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// maxLineSize tamanho maximo de um evento no arquivo
+const maxLineSize = 16 << 20
+
+// FileSink log de auditoria em arquivo, um evento json por linha aberto somente para append.
+// Feito para uma instancia do servico por arquivo, varias instancias devem usar o mysql
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	last *entity.AuditEvent
+	read bool
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (f *FileSink) AppendAuditEvent(ctx context.Context, event *entity.AuditEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last, err := f.lastEvent()
+	if err != nil {
+		return err
+	}
+	event.Chain(last)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing audit log file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error writing audit log file: %w", err)
+	}
+	f.last = event
+	return nil
+}
+
+// lastEvent le o arquivo inteiro somente na primeira chamada, depois usa o ultimo evento gravado
+func (f *FileSink) lastEvent() (*entity.AuditEvent, error) {
+	if f.read {
+		return f.last, nil
+	}
+	err := f.scan(func(event *entity.AuditEvent) bool {
+		f.last = event
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.read = true
+	return f.last, nil
+}
+
+func (f *FileSink) ListAuditEvents(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	var res []*entity.AuditEvent
+	err := f.scan(func(event *entity.AuditEvent) bool {
+		if event.Seq <= filter.AfterSeq || (filter.Type != "" && event.Type != filter.Type) ||
+			(!filter.From.IsZero() && event.CreatedAt.Before(filter.From)) || (!filter.To.IsZero() && event.CreatedAt.After(filter.To)) {
+			return true
+		}
+		res = append(res, event)
+		return filter.Limit <= 0 || len(res) < filter.Limit
+	})
+	return res, err
+}
+
+// scan le os eventos em ordem ate fn retornar false, arquivo inexistente é um log vazio
+func (f *FileSink) scan(fn func(event *entity.AuditEvent) bool) error {
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error opening audit log file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event entity.AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("%w: invalid event at line %d of %s", entity.ErrAuditTampered, line, f.path)
+		}
+		if !fn(&event) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading audit log file: %w", err)
+	}
+	return nil
+}
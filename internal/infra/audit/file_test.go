@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+func appendEvents(t *testing.T, sink *FileSink, from, to int) {
+	t.Helper()
+	for i := from; i <= to; i++ {
+		eventType := entity.AuditAccess
+		if i%2 == 0 {
+			eventType = entity.AuditAdmin
+		}
+		event := entity.NewAuditEvent(eventType, "GET /chats", fmt.Sprintf("chat-%d", i), json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)))
+		if err := sink.AppendAuditEvent(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileSinkKeepsTheChainOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	appendEvents(t, NewFileSink(path), 1, 5)
+	//outra instancia continua a cadeia a partir do ultimo evento do arquivo
+	sink := NewFileSink(path)
+	appendEvents(t, sink, 6, 10)
+
+	events, err := sink.ListAuditEvents(context.Background(), entity.AuditFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("expected 10 events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.Seq != int64(i+1) || event.Resource != fmt.Sprintf("chat-%d", i+1) {
+			t.Fatalf("expected event %d in order, got seq %d %s", i+1, event.Seq, event.Resource)
+		}
+		if i > 0 && event.PrevHash != events[i-1].Hash {
+			t.Fatalf("event %d is not linked to the previous one", event.Seq)
+		}
+	}
+
+	page, err := sink.ListAuditEvents(context.Background(), entity.AuditFilter{AfterSeq: 3, Type: entity.AuditAdmin, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].Seq != 4 || page[1].Seq != 6 {
+		t.Fatalf("expected admin events 4 and 6, got %v", page)
+	}
+
+	output, err := audit.NewVerifyAuditLogUseCase(sink).Execute(context.Background(), audit.VerifyAuditLogInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.Valid || output.LastSeq != 10 {
+		t.Fatalf("expected a valid chain up to 10, got %+v", output)
+	}
+}
+
+func TestFileSinkTamperedLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink := NewFileSink(path)
+	appendEvents(t, sink, 1, 6)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	//dado do quarto evento alterado direto no arquivo
+	if !strings.Contains(lines[3], `"data":{"n":4}`) {
+		t.Fatalf("unexpected line %s", lines[3])
+	}
+	lines[3] = strings.Replace(lines[3], `"data":{"n":4}`, `"data":{"n":40}`, 1)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output, err := audit.NewVerifyAuditLogUseCase(NewFileSink(path)).Execute(context.Background(), audit.VerifyAuditLogInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Valid || output.BrokenAt != 4 || output.Events != 3 {
+		t.Fatalf("expected the chain broken at 4, got %+v", output)
+	}
+
+	//linha que nao é json nao é ignorada
+	lines[1] = "not json"
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSink(path).ListAuditEvents(context.Background(), entity.AuditFilter{}); !errors.Is(err, entity.ErrAuditTampered) {
+		t.Fatalf("expected ErrAuditTampered, got %v", err)
+	}
+}
+
+func TestFileSinkMissingFileIsEmpty(t *testing.T) {
+	sink := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	events, err := sink.ListAuditEvents(context.Background(), entity.AuditFilter{})
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected an empty log, got %v %v", events, err)
+	}
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+)
+
+// Authenticator valida o header authorization das requisicoes http e dos metadados grpc.
+// Tokens "Bearer <jwt>" identificam o usuario, o tenant e os escopos pelas claims verificadas e as chaves
+// de api (vak_..., com ou sem Bearer) pelos dados da chave. Sem header, o certificado de cliente verificado
+// no mtls identifica o chamador pelo subject.
+// Os tokens estaticos (AUTH_TOKEN e tokens de tenant) sao credenciais de servico, que informam o usuario no corpo.
+// Com o jwt ligado eles sao recusados por padrao (JWT_REQUIRED), a identidade por usuario vem somente das credenciais
+// com principal
+type Authenticator struct {
+	AuthToken   string
+	Tenants     *tenant.ResolveTenantUseCase // opcional, nil aceita somente o token do servico
+	JWT         *JWTVerifier                 // opcional, nil desliga os tokens assinados
+	APIKeys     *apikey.ResolveAPIKeyUseCase // opcional, nil desliga as chaves de api
+	ClientCerts *CertIdentities              // opcional, nil nao autentica pelo certificado do cliente
+	JWTRequired bool                         // com JWT ligado, recusa os tokens estaticos (as chaves de api e os certificados continuam aceitos)
+}
+
+func NewAuthenticator(token string, tenants *tenant.ResolveTenantUseCase, verifier *JWTVerifier, apiKeys *apikey.ResolveAPIKeyUseCase, clientCerts *CertIdentities, jwtRequired bool) *Authenticator {
+	return &Authenticator{
+		AuthToken:   token,
+		Tenants:     tenants,
+		JWT:         verifier,
+		APIKeys:     apiKeys,
+		ClientCerts: clientCerts,
+		JWTRequired: jwtRequired && verifier != nil,
+	}
+}
+
+// Authenticate retorna o contexto com o usuario e o tenant do token, scope é o escopo exigido dos tokens assinados e chaves de api
+func (a *Authenticator) Authenticate(ctx context.Context, authorization string, scope string) (context.Context, error) {
+	if key := strings.TrimPrefix(authorization, "Bearer "); a.APIKeys != nil && strings.HasPrefix(key, entity.APIKeyPrefix) {
+		return a.authenticateAPIKey(ctx, key, scope)
+	}
+	if a.JWT != nil && strings.HasPrefix(authorization, "Bearer ") {
+		return a.authenticateJWT(ctx, strings.TrimPrefix(authorization, "Bearer "), scope)
+	}
+	if a.JWTRequired {
+		return nil, entity.ErrUnauthenticated
+	}
+
+	if authorization == "" {
+		return nil, entity.ErrUnauthenticated
+	}
+	//comparacao em tempo constante, AUTH_TOKEN vazio nao aceita nada
+	if a.AuthToken != "" && subtle.ConstantTimeCompare([]byte(authorization), []byte(a.AuthToken)) == 1 {
+		return ctx, nil
+	}
+	if a.Tenants == nil {
+		return nil, entity.ErrUnauthenticated
+	}
+	org, err := a.Tenants.Execute(ctx, tenant.ResolveTenantInputDTO{Token: authorization})
+	if err != nil {
+		return nil, tenantError(err)
+	}
+	return entity.ContextWithTenant(ctx, org), nil
+}
+
+// AuthenticateCertificate autentica pelo certificado de cliente ja verificado no handshake tls
+func (a *Authenticator) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate, scope string) (context.Context, error) {
+	if a.ClientCerts == nil {
+		return nil, entity.ErrUnauthenticated
+	}
+	principal := a.ClientCerts.Principal(cert)
+	if principal == nil {
+		return nil, fmt.Errorf("%w: client certificate %q is not mapped to an identity", entity.ErrUnauthenticated, cert.Subject.String())
+	}
+	return a.withPrincipal(ctx, principal, scope)
+}
+
+func (a *Authenticator) authenticateJWT(ctx context.Context, token string, scope string) (context.Context, error) {
+	principal, err := a.JWT.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return a.withPrincipal(ctx, principal, scope)
+}
+
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, key string, scope string) (context.Context, error) {
+	apiKey, err := a.APIKeys.Execute(ctx, key)
+	if errors.Is(err, entity.ErrAPIKeyNotFound) {
+		return nil, entity.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a.withPrincipal(ctx, apiKey.Principal(), scope)
+}
+
+// withPrincipal confere o escopo e coloca o usuario e o tenant dele no contexto
+func (a *Authenticator) withPrincipal(ctx context.Context, principal *entity.Principal, scope string) (context.Context, error) {
+	if !principal.HasScope(scope) {
+		return nil, fmt.Errorf("%w: %s", entity.ErrMissingScope, scope)
+	}
+	ctx = entity.ContextWithPrincipal(ctx, principal)
+	if principal.TenantID == "" {
+		return ctx, nil
+	}
+	if a.Tenants == nil {
+		return nil, fmt.Errorf("%w: tenants are not enabled", entity.ErrUnauthenticated)
+	}
+	org, err := a.Tenants.Execute(ctx, tenant.ResolveTenantInputDTO{ID: principal.TenantID})
+	if err != nil {
+		return nil, tenantError(err)
+	}
+	return entity.ContextWithTenant(ctx, org), nil
+}
+
+// tenantError tenant desconhecido é credencial invalida, os demais erros sao internos
+func tenantError(err error) error {
+	if errors.Is(err, entity.ErrTenantNotFound) {
+		return entity.ErrUnauthenticated
+	}
+	return fmt.Errorf("error resolving tenant: %w", err)
+}
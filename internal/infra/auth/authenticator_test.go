@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func TestAuthenticateStaticToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantErr       bool
+	}{
+		{name: "matching token", token: "secret", authorization: "secret"},
+		{name: "wrong token", token: "secret", authorization: "secreT", wantErr: true},
+		{name: "missing header", token: "secret", authorization: "", wantErr: true},
+		{name: "empty configured token", token: "", authorization: "", wantErr: true},
+		{name: "empty configured token with header", token: "", authorization: "anything", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthenticator(tt.token, nil, nil, nil, nil, false)
+			_, err := a.Authenticate(context.Background(), tt.authorization, entity.ScopeChat)
+			if tt.wantErr && !errors.Is(err, entity.ErrUnauthenticated) {
+				t.Fatalf("expected ErrUnauthenticated, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCallerUserIDRejectsAnotherUser(t *testing.T) {
+	ctx := entity.ContextWithPrincipal(context.Background(), &entity.Principal{UserID: "alice", Scopes: []string{entity.ScopeChat}})
+
+	if userID, err := entity.CallerUserID(ctx, ""); err != nil || userID != "alice" {
+		t.Fatalf("expected alice from the principal, got %q %v", userID, err)
+	}
+	if userID, err := entity.CallerUserID(ctx, "alice"); err != nil || userID != "alice" {
+		t.Fatalf("expected alice, got %q %v", userID, err)
+	}
+	if _, err := entity.CallerUserID(ctx, "bob"); !errors.Is(err, entity.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user, got %v", err)
+	}
+	//sem principal (token de servico) o usuario do corpo é aceito
+	if userID, err := entity.CallerUserID(context.Background(), "bob"); err != nil || userID != "bob" {
+		t.Fatalf("expected bob from a service token, got %q %v", userID, err)
+	}
+}
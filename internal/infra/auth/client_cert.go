@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// certIdentity identidade de um certificado de cliente (mtls), subject é o DN completo
+// ("CN=billing,O=Acme") ou somente o common name ("billing")
+type certIdentity struct {
+	Subject  string   `json:"subject"`
+	UserID   string   `json:"user_id"`
+	TenantID string   `json:"tenant_id,omitempty"`
+	Scopes   []string `json:"scopes"`
+}
+
+// CertIdentities mapeia o subject dos certificados de cliente verificados para o usuario, o tenant e os escopos
+type CertIdentities struct {
+	identities map[string]*entity.Principal
+}
+
+// LoadCertIdentities le o json com a lista de identidades, arquivo vazio retorna nil (certificados nao autenticam)
+func LoadCertIdentities(path string) (*CertIdentities, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading client certificate identities file: %w", err)
+	}
+	var list []certIdentity
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error parsing client certificate identities file: %w", err)
+	}
+
+	identities := &CertIdentities{identities: map[string]*entity.Principal{}}
+	for _, identity := range list {
+		if identity.Subject == "" || identity.UserID == "" {
+			return nil, fmt.Errorf("client certificate identity requires subject and user_id")
+		}
+		for _, scope := range identity.Scopes {
+			if !entity.IsValidScope(scope) {
+				return nil, fmt.Errorf("client certificate identity %q has invalid scope %q", identity.Subject, scope)
+			}
+		}
+		identities.identities[identity.Subject] = &entity.Principal{
+			UserID:   identity.UserID,
+			TenantID: identity.TenantID,
+			Scopes:   identity.Scopes,
+		}
+	}
+	return identities, nil
+}
+
+// Principal identidade do certificado, procura pelo DN completo e depois pelo common name. nil quando nao mapeado
+func (c *CertIdentities) Principal(cert *x509.Certificate) *entity.Principal {
+	if principal, ok := c.identities[cert.Subject.String()]; ok {
+		return principal
+	}
+	if cert.Subject.CommonName == "" {
+		return nil
+	}
+	return c.identities[cert.Subject.CommonName]
+}
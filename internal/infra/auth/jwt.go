@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// leeway tolerancia de relogio entre o emissor e o servico na validacao de exp e nbf
+const leeway = 30 * time.Second
+
+// claims do token, sub é o id do usuario e scope a lista de escopos separados por espaco (oauth2)
+type claims struct {
+	jwt.RegisteredClaims
+	TenantID string `json:"tenant_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+}
+
+// JWTVerifier valida tokens HS256 com o segredo compartilhado e RS256 com as chaves publicas do jwks
+type JWTVerifier struct {
+	secret []byte
+	keys   map[string]*rsa.PublicKey //chaves do jwks por kid
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier retorna nil quando nao tem segredo nem jwks, issuer e audience vazios nao sao validados
+func NewJWTVerifier(secret string, jwksFile string, issuer string, audience string) (*JWTVerifier, error) {
+	if secret == "" && jwksFile == "" {
+		return nil, nil
+	}
+	verifier := &JWTVerifier{keys: map[string]*rsa.PublicKey{}}
+	var methods []string
+	if secret != "" {
+		verifier.secret = []byte(secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if jwksFile != "" {
+		keys, err := loadJWKS(jwksFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	//somente os algoritmos configurados, evita a troca de RS256 por HS256 com a chave publica como segredo
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	verifier.parser = jwt.NewParser(options...)
+	return verifier, nil
+}
+
+// Verify valida a assinatura e as claims do token e retorna o usuario autenticado
+func (v *JWTVerifier) Verify(token string) (*entity.Principal, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(token, &c, v.key); err != nil {
+		return nil, fmt.Errorf("%w: %s", entity.ErrUnauthenticated, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", entity.ErrUnauthenticated)
+	}
+	return &entity.Principal{
+		UserID:   c.Subject,
+		TenantID: c.TenantID,
+		Scopes:   strings.Fields(c.Scope),
+	}, nil
+}
+
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		//jwks com uma chave so aceita tokens sem kid
+		if kid == "" && len(v.keys) == 1 {
+			for _, key := range v.keys {
+				return key, nil
+			}
+		}
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}
+	return nil, errors.New("unexpected signing method")
+}
+
+type jwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// loadJWKS le as chaves RSA de assinatura do arquivo jwks (RFC 7517)
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading jwks file: %w", err)
+	}
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("error parsing jwks file: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of jwks key %q: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of jwks key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks file %s has no RSA signing keys", path)
+	}
+	return keys, nil
+}
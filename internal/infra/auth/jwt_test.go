@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+const testSecret = "test-secret"
+
+// writeJWKS grava o jwks com a chave publica no kid informado
+func writeJWKS(t *testing.T, keys map[string]*rsa.PublicKey) string {
+	t.Helper()
+	var set jwks
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}{kid, "RSA", "sig", base64.RawURLEncoding.EncodeToString(key.N.Bytes()), base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())})
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, c jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// validClaims claims validas, mudadas por cada caso
+func validClaims(change func(c jwt.MapClaims)) jwt.MapClaims {
+	c := jwt.MapClaims{
+		"sub":       "alice",
+		"tenant_id": "acme",
+		"scope":     "chat admin",
+		"iss":       "https://issuer.example.com",
+		"aud":       "virtual-assistant",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	if change != nil {
+		change(c)
+	}
+	return c
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM})
+	jwksFile := writeJWKS(t, map[string]*rsa.PublicKey{"k1": &rsaKey.PublicKey, "k2": &otherKey.PublicKey})
+
+	secretOnly, err := NewJWTVerifier(testSecret, "", "https://issuer.example.com", "virtual-assistant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksOnly, err := NewJWTVerifier("", jwksFile, "https://issuer.example.com", "virtual-assistant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte(testSecret)
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		wantErr  bool
+	}{
+		{"hs256", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(nil)), false},
+		{"wrong secret", secretOnly, sign(t, jwt.SigningMethodHS256, []byte("other"), "", validClaims(nil)), true},
+		{"hs512 not allowed", secretOnly, sign(t, jwt.SigningMethodHS512, secret, "", validClaims(nil)), true},
+		{"alg none", secretOnly, sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims(nil)), true},
+		{"rs256 without jwks", secretOnly, sign(t, jwt.SigningMethodRS256, rsaKey, "k1", validClaims(nil)), true},
+		{"rs256", jwksOnly, sign(t, jwt.SigningMethodRS256, rsaKey, "k1", validClaims(nil)), false},
+		{"rs256 second key", jwksOnly, sign(t, jwt.SigningMethodRS256, otherKey, "k2", validClaims(nil)), false},
+		{"rs256 key of another kid", jwksOnly, sign(t, jwt.SigningMethodRS256, otherKey, "k1", validClaims(nil)), true},
+		{"rs256 unknown kid", jwksOnly, sign(t, jwt.SigningMethodRS256, rsaKey, "k3", validClaims(nil)), true},
+		{"rs256 without kid and many keys", jwksOnly, sign(t, jwt.SigningMethodRS256, rsaKey, "", validClaims(nil)), true},
+		{"hs256 with the public key as secret", jwksOnly, sign(t, jwt.SigningMethodHS256, publicPEM, "k1", validClaims(nil)), true},
+		{"missing exp", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { delete(c, "exp") })), true},
+		{"expired within leeway", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Second).Unix() })), false},
+		{"expired after leeway", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-leeway - 10*time.Second).Unix() })), true},
+		{"not before within leeway", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(10 * time.Second).Unix() })), false},
+		{"not before after leeway", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["nbf"] = time.Now().Add(leeway + 10*time.Second).Unix() })), true},
+		{"wrong issuer", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })), true},
+		{"wrong audience", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { c["aud"] = "other-service" })), true},
+		{"missing subject", secretOnly, sign(t, jwt.SigningMethodHS256, secret, "", validClaims(func(c jwt.MapClaims) { delete(c, "sub") })), true},
+		{"malformed", secretOnly, "not.a.token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := tt.verifier.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, entity.ErrUnauthenticated) {
+					t.Fatalf("expected ErrUnauthenticated, got %v %+v", err, principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if principal.UserID != "alice" || principal.TenantID != "acme" || len(principal.Scopes) != 2 || principal.Scopes[1] != "admin" {
+				t.Fatalf("unexpected principal %+v", principal)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierSingleKeyWithoutKid(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewJWTVerifier(testSecret, writeJWKS(t, map[string]*rsa.PublicKey{"k1": &rsaKey.PublicKey}), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//jwks com uma chave aceita token sem kid, e o segredo continua valendo
+	for _, token := range []string{
+		sign(t, jwt.SigningMethodRS256, rsaKey, "", validClaims(nil)),
+		sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", validClaims(nil)),
+	} {
+		if _, err := verifier.Verify(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	verifier, err := NewJWTVerifier("", "", "", "")
+	if err != nil || verifier != nil {
+		t.Fatalf("expected jwt disabled, got %v %v", verifier, err)
+	}
+	if _, err := NewJWTVerifier("", filepath.Join(t.TempDir(), "missing.json"), "", ""); err == nil {
+		t.Fatal("expected an error for a missing jwks file")
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(`{"keys":[{"kid":"k1","kty":"EC","crv":"P-256"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewJWTVerifier("", path, "", ""); err == nil {
+		t.Fatal("expected an error for a jwks without RSA signing keys")
+	}
+}
@@ -5,9 +5,61 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type ApiKey struct {
+	ID         string
+	UserID     string
+	TenantID   string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     json.RawMessage
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+type Assistant struct {
+	ID               string
+	Name             string
+	Description      string
+	Model            string
+	ModelMaxTokens   int32
+	Temperature      float64
+	TopP             float64
+	N                int32
+	Stop             json.RawMessage
+	MaxTokens        int32
+	PresencePenalty  float64
+	FrequencyPenalty float64
+	SystemPrompt     string
+	PromptTemplate   string
+	ToolProfile      string
+	KnowledgeBaseID  string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type AuditLog struct {
+	Seq       int64
+	ID        string
+	Type      string
+	UserID    string
+	TenantID  string
+	RequestID string
+	Action    string
+	Resource  string
+	Data      string
+	PrevHash  string
+	Hash      string
+	CreatedAt time.Time
+}
+
 type Chat struct {
 	ID               string
 	UserID           string
@@ -19,22 +71,109 @@ type Chat struct {
 	Temperature      float64
 	TopP             float64
 	N                int32
-	Stop             string
+	Stop             json.RawMessage
 	MaxTokens        int32
 	PresencePenalty  float64
 	FrequencyPenalty float64
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	PromptTemplate   string
+	TemplateVersion  int32
+	AssistantID      string
+	TenantID         string
+	ToolProfile      string
 }
 
-type Message struct {
-	ID        string
+type ChatShare struct {
 	ChatID    string
-	Role      string
-	Content   string
-	Tokens    int32
-	Model     string
-	Erased    bool
-	OrderMsg  int32
+	UserID    string
 	CreatedAt time.Time
 }
+
+type Document struct {
+	ID              string
+	KnowledgeBaseID string
+	Title           string
+	Source          string
+	ContentType     string
+	Tokens          int32
+	CreatedAt       time.Time
+}
+
+type DocumentChunk struct {
+	ID              string
+	DocumentID      string
+	KnowledgeBaseID string
+	ChunkIndex      int32
+	Content         string
+	Tokens          int32
+	StartOffset     int32
+	EndOffset       int32
+	Model           string
+	Dimensions      int32
+	Vector          []byte
+	CreatedAt       time.Time
+}
+
+type Message struct {
+	ID         string
+	ChatID     string
+	Role       string
+	Content    string
+	Tokens     int32
+	Model      string
+	Erased     bool
+	OrderMsg   int32
+	CreatedAt  time.Time
+	ToolCalls  json.RawMessage
+	ToolCallID string
+	Name       string
+	Moderation json.RawMessage
+	KeyID      string
+}
+
+type MessageEmbedding struct {
+	MessageID  string
+	ChatID     string
+	Model      string
+	Dimensions int32
+	Vector     []byte
+	CreatedAt  time.Time
+}
+
+type PromptTemplate struct {
+	Name        string
+	Version     int32
+	Content     string
+	Description string
+	Defaults    json.RawMessage
+	Active      bool
+	CreatedAt   time.Time
+}
+
+type Tenant struct {
+	ID                 string
+	Name               string
+	TokenHash          string
+	DefaultAssistantID string
+	AllowedModels      json.RawMessage
+	MonthlyTokenQuota  int32
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	ToolProfile        string
+}
+
+type TenantUsage struct {
+	TenantID string
+	Period   string
+	Tokens   int64
+}
+
+type Vector struct {
+	Namespace  string
+	ID         string
+	Dimensions int32
+	Vector     []byte
+	Metadata   json.RawMessage
+	UpdatedAt  time.Time
+}
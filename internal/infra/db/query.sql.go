@@ -7,25 +7,103 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
-const addMessage = `-- name: AddMessage :exec
-INSERT INTO messages (id, chat_id, role, content, tokens, model, erased, order_msg, created_at) VALUES(?,?,?,?,?,?,?,?,?)
+const activatePromptTemplateVersion = `-- name: ActivatePromptTemplateVersion :execrows
+UPDATE prompt_templates SET active = 1 WHERE name = ? AND version = ?
 `
 
-type AddMessageParams struct {
-	ID        string
+type ActivatePromptTemplateVersionParams struct {
+	Name    string
+	Version int32
+}
+
+func (q *Queries) ActivatePromptTemplateVersion(ctx context.Context, arg ActivatePromptTemplateVersionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, activatePromptTemplateVersion, arg.Name, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addChatShare = `-- name: AddChatShare :exec
+INSERT INTO chat_shares (chat_id, user_id, created_at) VALUES(?,?,?)
+`
+
+type AddChatShareParams struct {
 	ChatID    string
-	Role      string
-	Content   string
-	Tokens    int32
-	Model     string
-	Erased    bool
-	OrderMsg  int32
+	UserID    string
 	CreatedAt time.Time
 }
 
+func (q *Queries) AddChatShare(ctx context.Context, arg AddChatShareParams) error {
+	_, err := q.db.ExecContext(ctx, addChatShare, arg.ChatID, arg.UserID, arg.CreatedAt)
+	return err
+}
+
+const addDocumentChunk = `-- name: AddDocumentChunk :exec
+INSERT INTO document_chunks
+    (id, document_id, knowledge_base_id, chunk_index, content, tokens, start_offset, end_offset, model, dimensions, vector, created_at)
+    VALUES(?,?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type AddDocumentChunkParams struct {
+	ID              string
+	DocumentID      string
+	KnowledgeBaseID string
+	ChunkIndex      int32
+	Content         string
+	Tokens          int32
+	StartOffset     int32
+	EndOffset       int32
+	Model           string
+	Dimensions      int32
+	Vector          []byte
+	CreatedAt       time.Time
+}
+
+func (q *Queries) AddDocumentChunk(ctx context.Context, arg AddDocumentChunkParams) error {
+	_, err := q.db.ExecContext(ctx, addDocumentChunk,
+		arg.ID,
+		arg.DocumentID,
+		arg.KnowledgeBaseID,
+		arg.ChunkIndex,
+		arg.Content,
+		arg.Tokens,
+		arg.StartOffset,
+		arg.EndOffset,
+		arg.Model,
+		arg.Dimensions,
+		arg.Vector,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const addMessage = `-- name: AddMessage :exec
+INSERT INTO messages (id, chat_id, role, content, tokens, model, erased, order_msg, created_at, tool_calls, tool_call_id, name, moderation, key_id) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type AddMessageParams struct {
+	ID         string
+	ChatID     string
+	Role       string
+	Content    string
+	Tokens     int32
+	Model      string
+	Erased     bool
+	OrderMsg   int32
+	CreatedAt  time.Time
+	ToolCalls  json.RawMessage
+	ToolCallID string
+	Name       string
+	Moderation json.RawMessage
+	KeyID      string
+}
+
 func (q *Queries) AddMessage(ctx context.Context, arg AddMessageParams) error {
 	_, err := q.db.ExecContext(ctx, addMessage,
 		arg.ID,
@@ -37,14 +115,231 @@ func (q *Queries) AddMessage(ctx context.Context, arg AddMessageParams) error {
 		arg.Erased,
 		arg.OrderMsg,
 		arg.CreatedAt,
+		arg.ToolCalls,
+		arg.ToolCallID,
+		arg.Name,
+		arg.Moderation,
+		arg.KeyID,
+	)
+	return err
+}
+
+const addMessageEmbedding = `-- name: AddMessageEmbedding :exec
+INSERT INTO message_embeddings (message_id, chat_id, model, dimensions, vector, created_at) VALUES(?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE dimensions = VALUES(dimensions), vector = VALUES(vector), created_at = VALUES(created_at)
+`
+
+type AddMessageEmbeddingParams struct {
+	MessageID  string
+	ChatID     string
+	Model      string
+	Dimensions int32
+	Vector     []byte
+	CreatedAt  time.Time
+}
+
+func (q *Queries) AddMessageEmbedding(ctx context.Context, arg AddMessageEmbeddingParams) error {
+	_, err := q.db.ExecContext(ctx, addMessageEmbedding,
+		arg.MessageID,
+		arg.ChatID,
+		arg.Model,
+		arg.Dimensions,
+		arg.Vector,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const addTenantUsage = `-- name: AddTenantUsage :exec
+INSERT INTO tenant_usage (tenant_id, period, tokens) VALUES(?,?,?)
+ON DUPLICATE KEY UPDATE tokens = tokens + VALUES(tokens)
+`
+
+type AddTenantUsageParams struct {
+	TenantID string
+	Period   string
+	Tokens   int64
+}
+
+func (q *Queries) AddTenantUsage(ctx context.Context, arg AddTenantUsageParams) error {
+	_, err := q.db.ExecContext(ctx, addTenantUsage,
+		arg.TenantID,
+		arg.Period,
+		arg.Tokens,
+	)
+	return err
+}
+
+const anonymizeChat = `-- name: AnonymizeChat :execrows
+UPDATE chats SET user_id = '', status = 'ended' WHERE id = ? AND updated_at = ?
+`
+
+type AnonymizeChatParams struct {
+	ID        string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) AnonymizeChat(ctx context.Context, arg AnonymizeChatParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeChat,
+		arg.ID,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const anonymizeChatMessages = `-- name: AnonymizeChatMessages :exec
+UPDATE messages SET content = '', key_id = '', tool_calls = JSON_ARRAY(), name = '' WHERE chat_id = ?
+`
+
+func (q *Queries) AnonymizeChatMessages(ctx context.Context, chatID string) error {
+	_, err := q.db.ExecContext(ctx, anonymizeChatMessages, chatID)
+	return err
+}
+
+const countMessagesByOwner = `-- name: CountMessagesByOwner :one
+SELECT COUNT(*) FROM messages m JOIN chats c ON c.id = m.chat_id WHERE c.tenant_id = ? AND c.user_id = ?
+`
+
+type CountMessagesByOwnerParams struct {
+	TenantID string
+	UserID   string
+}
+
+func (q *Queries) CountMessagesByOwner(ctx context.Context, arg CountMessagesByOwnerParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countMessagesByOwner, arg.TenantID, arg.UserID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIKey = `-- name: CreateAPIKey :exec
+INSERT INTO api_keys (id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at) VALUES(?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type CreateAPIKeyParams struct {
+	ID         string
+	UserID     string
+	TenantID   string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     json.RawMessage
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+	CreatedAt  time.Time
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, createAPIKey,
+		arg.ID,
+		arg.UserID,
+		arg.TenantID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scopes,
+		arg.ExpiresAt,
+		arg.LastUsedAt,
+		arg.RevokedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createAssistant = `-- name: CreateAssistant :exec
+INSERT INTO assistants (id, name, description, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, system_prompt, prompt_template, tool_profile, knowledge_base_id, created_at, updated_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type CreateAssistantParams struct {
+	ID               string
+	Name             string
+	Description      string
+	Model            string
+	ModelMaxTokens   int32
+	Temperature      float64
+	TopP             float64
+	N                int32
+	Stop             json.RawMessage
+	MaxTokens        int32
+	PresencePenalty  float64
+	FrequencyPenalty float64
+	SystemPrompt     string
+	PromptTemplate   string
+	ToolProfile      string
+	KnowledgeBaseID  string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) CreateAssistant(ctx context.Context, arg CreateAssistantParams) error {
+	_, err := q.db.ExecContext(ctx, createAssistant,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Model,
+		arg.ModelMaxTokens,
+		arg.Temperature,
+		arg.TopP,
+		arg.N,
+		arg.Stop,
+		arg.MaxTokens,
+		arg.PresencePenalty,
+		arg.FrequencyPenalty,
+		arg.SystemPrompt,
+		arg.PromptTemplate,
+		arg.ToolProfile,
+		arg.KnowledgeBaseID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :exec
+INSERT INTO audit_log (seq, id, type, user_id, tenant_id, request_id, action, resource, data, prev_hash, hash, created_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?)
+`
+
+type CreateAuditEventParams struct {
+	Seq       int64
+	ID        string
+	Type      string
+	UserID    string
+	TenantID  string
+	RequestID string
+	Action    string
+	Resource  string
+	Data      string
+	PrevHash  string
+	Hash      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEvent,
+		arg.Seq,
+		arg.ID,
+		arg.Type,
+		arg.UserID,
+		arg.TenantID,
+		arg.RequestID,
+		arg.Action,
+		arg.Resource,
+		arg.Data,
+		arg.PrevHash,
+		arg.Hash,
+		arg.CreatedAt,
 	)
 	return err
 }
 
 const createChat = `-- name: CreateChat :exec
 INSERT INTO chats 
-    (id, user_id, initial_message_id, status, token_usage, model, model_max_tokens,temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at)
-    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+    (id, user_id, initial_message_id, status, token_usage, model, model_max_tokens,temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at, prompt_template, template_version, assistant_id, tenant_id, tool_profile)
+    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 `
 
 type CreateChatParams struct {
@@ -58,12 +353,17 @@ type CreateChatParams struct {
 	Temperature      float64
 	TopP             float64
 	N                int32
-	Stop             string
+	Stop             json.RawMessage
 	MaxTokens        int32
 	PresencePenalty  float64
 	FrequencyPenalty float64
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	PromptTemplate   string
+	TemplateVersion  int32
+	AssistantID      string
+	TenantID         string
+	ToolProfile      string
 }
 
 func (q *Queries) CreateChat(ctx context.Context, arg CreateChatParams) error {
@@ -84,62 +384,1424 @@ func (q *Queries) CreateChat(ctx context.Context, arg CreateChatParams) error {
 		arg.FrequencyPenalty,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.PromptTemplate,
+		arg.TemplateVersion,
+		arg.AssistantID,
+		arg.TenantID,
+		arg.ToolProfile,
+	)
+	return err
+}
+
+const createDocument = `-- name: CreateDocument :exec
+INSERT INTO documents (id, knowledge_base_id, title, source, content_type, tokens, created_at) VALUES(?,?,?,?,?,?,?)
+`
+
+type CreateDocumentParams struct {
+	ID              string
+	KnowledgeBaseID string
+	Title           string
+	Source          string
+	ContentType     string
+	Tokens          int32
+	CreatedAt       time.Time
+}
+
+func (q *Queries) CreateDocument(ctx context.Context, arg CreateDocumentParams) error {
+	_, err := q.db.ExecContext(ctx, createDocument,
+		arg.ID,
+		arg.KnowledgeBaseID,
+		arg.Title,
+		arg.Source,
+		arg.ContentType,
+		arg.Tokens,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createPromptTemplate = `-- name: CreatePromptTemplate :exec
+INSERT INTO prompt_templates (name, version, content, description, defaults, active, created_at) VALUES(?,?,?,?,?,?,?)
+`
+
+type CreatePromptTemplateParams struct {
+	Name        string
+	Version     int32
+	Content     string
+	Description string
+	Defaults    json.RawMessage
+	Active      bool
+	CreatedAt   time.Time
+}
+
+func (q *Queries) CreatePromptTemplate(ctx context.Context, arg CreatePromptTemplateParams) error {
+	_, err := q.db.ExecContext(ctx, createPromptTemplate,
+		arg.Name,
+		arg.Version,
+		arg.Content,
+		arg.Description,
+		arg.Defaults,
+		arg.Active,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createTenant = `-- name: CreateTenant :exec
+INSERT INTO tenants (id, name, token_hash, default_assistant_id, allowed_models, monthly_token_quota, created_at, updated_at, tool_profile) VALUES(?,?,?,?,?,?,?,?,?)
+`
+
+type CreateTenantParams struct {
+	ID                 string
+	Name               string
+	TokenHash          string
+	DefaultAssistantID string
+	AllowedModels      json.RawMessage
+	MonthlyTokenQuota  int32
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	ToolProfile        string
+}
+
+func (q *Queries) CreateTenant(ctx context.Context, arg CreateTenantParams) error {
+	_, err := q.db.ExecContext(ctx, createTenant,
+		arg.ID,
+		arg.Name,
+		arg.TokenHash,
+		arg.DefaultAssistantID,
+		arg.AllowedModels,
+		arg.MonthlyTokenQuota,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ToolProfile,
 	)
 	return err
 }
 
-const deleteChatMessages = `-- name: DeleteChatMessages :exec
-DELETE FROM messages WHERE chat_id = ?
+const deactivatePromptTemplates = `-- name: DeactivatePromptTemplates :exec
+UPDATE prompt_templates SET active = 0 WHERE name = ?
+`
+
+func (q *Queries) DeactivatePromptTemplates(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deactivatePromptTemplates, name)
+	return err
+}
+
+const deleteAPIKeysByUserID = `-- name: DeleteAPIKeysByUserID :execrows
+DELETE FROM api_keys WHERE tenant_id = ? AND user_id = ?
+`
+
+type DeleteAPIKeysByUserIDParams struct {
+	TenantID string
+	UserID   string
+}
+
+func (q *Queries) DeleteAPIKeysByUserID(ctx context.Context, arg DeleteAPIKeysByUserIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAPIKeysByUserID, arg.TenantID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAssistant = `-- name: DeleteAssistant :execrows
+DELETE FROM assistants WHERE id = ?
+`
+
+func (q *Queries) DeleteAssistant(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAssistant, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteChatEmbeddings = `-- name: DeleteChatEmbeddings :exec
+DELETE FROM message_embeddings WHERE chat_id = ?
+`
+
+func (q *Queries) DeleteChatEmbeddings(ctx context.Context, chatID string) error {
+	_, err := q.db.ExecContext(ctx, deleteChatEmbeddings, chatID)
+	return err
+}
+
+const deleteChatsByOwner = `-- name: DeleteChatsByOwner :execrows
+DELETE FROM chats WHERE tenant_id = ? AND user_id = ?
+`
+
+type DeleteChatsByOwnerParams struct {
+	TenantID string
+	UserID   string
+}
+
+func (q *Queries) DeleteChatsByOwner(ctx context.Context, arg DeleteChatsByOwnerParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChatsByOwner, arg.TenantID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteChatShares = `-- name: DeleteChatShares :exec
+DELETE FROM chat_shares WHERE chat_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?)
+`
+
+type DeleteChatSharesParams struct {
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) DeleteChatShares(ctx context.Context, arg DeleteChatSharesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteChatShares, arg.ChatID, arg.TenantID)
+	return err
+}
+
+const deleteChatSharesByUserID = `-- name: DeleteChatSharesByUserID :execrows
+DELETE FROM chat_shares WHERE user_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?)
+`
+
+type DeleteChatSharesByUserIDParams struct {
+	UserID   string
+	TenantID string
+}
+
+func (q *Queries) DeleteChatSharesByUserID(ctx context.Context, arg DeleteChatSharesByUserIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteChatSharesByUserID, arg.UserID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteDocument = `-- name: DeleteDocument :exec
+DELETE FROM documents WHERE id = ?
+`
+
+func (q *Queries) DeleteDocument(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteDocument, id)
+	return err
+}
+
+const deleteErasedMessage = `-- name: DeleteErasedMessage :execrows
+DELETE FROM messages WHERE id = ? AND erased = 1
+`
+
+func (q *Queries) DeleteErasedMessage(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteErasedMessage, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteInactiveChat = `-- name: DeleteInactiveChat :execrows
+DELETE FROM chats WHERE id = ? AND updated_at = ?
+`
+
+type DeleteInactiveChatParams struct {
+	ID        string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) DeleteInactiveChat(ctx context.Context, arg DeleteInactiveChatParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteInactiveChat,
+		arg.ID,
+		arg.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMessageEmbeddings = `-- name: DeleteMessageEmbeddings :exec
+DELETE FROM message_embeddings WHERE message_id = ?
 `
 
-func (q *Queries) DeleteChatMessages(ctx context.Context, chatID string) error {
-	_, err := q.db.ExecContext(ctx, deleteChatMessages, chatID)
+func (q *Queries) DeleteMessageEmbeddings(ctx context.Context, messageID string) error {
+	_, err := q.db.ExecContext(ctx, deleteMessageEmbeddings, messageID)
 	return err
 }
 
-const deleteErasedChatMessages = `-- name: DeleteErasedChatMessages :exec
-DELETE FROM messages WHERE erased=1 and chat_id = ?
+const deletePromptTemplate = `-- name: DeletePromptTemplate :execrows
+DELETE FROM prompt_templates WHERE name = ?
+`
+
+func (q *Queries) DeletePromptTemplate(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePromptTemplate, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTenant = `-- name: DeleteTenant :execrows
+DELETE FROM tenants WHERE id = ?
+`
+
+func (q *Queries) DeleteTenant(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTenant, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteVector = `-- name: DeleteVector :exec
+DELETE FROM vectors WHERE namespace = ? AND id = ?
 `
 
-func (q *Queries) DeleteErasedChatMessages(ctx context.Context, chatID string) error {
-	_, err := q.db.ExecContext(ctx, deleteErasedChatMessages, chatID)
+type DeleteVectorParams struct {
+	Namespace string
+	ID        string
+}
+
+func (q *Queries) DeleteVector(ctx context.Context, arg DeleteVectorParams) error {
+	_, err := q.db.ExecContext(ctx, deleteVector, arg.Namespace, arg.ID)
 	return err
 }
 
-const findChatByID = `-- name: FindChatByID :one
-SELECT id, user_id, initial_message_id, status, token_usage, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at FROM chats WHERE id = ?
+const findActivePromptTemplate = `-- name: FindActivePromptTemplate :one
+SELECT name, version, content, description, defaults, active, created_at FROM prompt_templates WHERE name = ? AND active = 1
+`
+
+func (q *Queries) FindActivePromptTemplate(ctx context.Context, name string) (PromptTemplate, error) {
+	row := q.db.QueryRowContext(ctx, findActivePromptTemplate, name)
+	var i PromptTemplate
+	err := row.Scan(
+		&i.Name,
+		&i.Version,
+		&i.Content,
+		&i.Description,
+		&i.Defaults,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findAllMessagesByChatID = `-- name: FindAllMessagesByChatID :many
+SELECT m.id, m.chat_id, m.role, m.content, m.tokens, m.model, m.erased, m.order_msg, m.created_at, m.tool_calls, m.tool_call_id, m.name, m.moderation, m.key_id FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg
+`
+
+type FindAllMessagesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) FindAllMessagesByChatID(ctx context.Context, arg FindAllMessagesByChatIDParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, findAllMessagesByChatID, arg.ChatID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.Role,
+			&i.Content,
+			&i.Tokens,
+			&i.Model,
+			&i.Erased,
+			&i.OrderMsg,
+			&i.CreatedAt,
+			&i.ToolCalls,
+			&i.ToolCallID,
+			&i.Name,
+			&i.Moderation,
+			&i.KeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findAPIKeyByHash = `-- name: FindAPIKeyByHash :one
+SELECT id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE key_hash = ?
+`
+
+func (q *Queries) FindAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, findAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TenantID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findAssistantByID = `-- name: FindAssistantByID :one
+SELECT id, name, description, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, system_prompt, prompt_template, tool_profile, knowledge_base_id, created_at, updated_at FROM assistants WHERE id = ?
+`
+
+func (q *Queries) FindAssistantByID(ctx context.Context, id string) (Assistant, error) {
+	row := q.db.QueryRowContext(ctx, findAssistantByID, id)
+	var i Assistant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Model,
+		&i.ModelMaxTokens,
+		&i.Temperature,
+		&i.TopP,
+		&i.N,
+		&i.Stop,
+		&i.MaxTokens,
+		&i.PresencePenalty,
+		&i.FrequencyPenalty,
+		&i.SystemPrompt,
+		&i.PromptTemplate,
+		&i.ToolProfile,
+		&i.KnowledgeBaseID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const findChatByID = `-- name: FindChatByID :one
+SELECT id, user_id, initial_message_id, status, token_usage, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at, prompt_template, template_version, assistant_id, tenant_id, tool_profile FROM chats WHERE id = ? AND tenant_id = ?
+`
+
+type FindChatByIDParams struct {
+	ID       string
+	TenantID string
+}
+
+func (q *Queries) FindChatByID(ctx context.Context, arg FindChatByIDParams) (Chat, error) {
+	row := q.db.QueryRowContext(ctx, findChatByID, arg.ID, arg.TenantID)
+	var i Chat
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.InitialMessageID,
+		&i.Status,
+		&i.TokenUsage,
+		&i.Model,
+		&i.ModelMaxTokens,
+		&i.Temperature,
+		&i.TopP,
+		&i.N,
+		&i.Stop,
+		&i.MaxTokens,
+		&i.PresencePenalty,
+		&i.FrequencyPenalty,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PromptTemplate,
+		&i.TemplateVersion,
+		&i.AssistantID,
+		&i.TenantID,
+		&i.ToolProfile,
+	)
+	return i, err
+}
+
+const findChatSharesByChatID = `-- name: FindChatSharesByChatID :many
+SELECT s.user_id FROM chat_shares s
+    JOIN chats c ON c.id = s.chat_id
+WHERE s.chat_id = ? AND c.tenant_id = ?
+ORDER BY s.user_id ASC
+`
+
+type FindChatSharesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) FindChatSharesByChatID(ctx context.Context, arg FindChatSharesByChatIDParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, findChatSharesByChatID, arg.ChatID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findChunkByID = `-- name: FindChunkByID :one
+SELECT id, document_id, knowledge_base_id, chunk_index, content, tokens, start_offset, end_offset, model, dimensions, vector, created_at FROM document_chunks WHERE id = ?
+`
+
+func (q *Queries) FindChunkByID(ctx context.Context, id string) (DocumentChunk, error) {
+	row := q.db.QueryRowContext(ctx, findChunkByID, id)
+	var i DocumentChunk
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.KnowledgeBaseID,
+		&i.ChunkIndex,
+		&i.Content,
+		&i.Tokens,
+		&i.StartOffset,
+		&i.EndOffset,
+		&i.Model,
+		&i.Dimensions,
+		&i.Vector,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findChunksByDocumentID = `-- name: FindChunksByDocumentID :many
+SELECT id, document_id, knowledge_base_id, chunk_index, content, tokens, start_offset, end_offset, model, dimensions, vector, created_at FROM document_chunks WHERE document_id = ? ORDER BY chunk_index
+`
+
+func (q *Queries) FindChunksByDocumentID(ctx context.Context, documentID string) ([]DocumentChunk, error) {
+	rows, err := q.db.QueryContext(ctx, findChunksByDocumentID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentChunk
+	for rows.Next() {
+		var i DocumentChunk
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.KnowledgeBaseID,
+			&i.ChunkIndex,
+			&i.Content,
+			&i.Tokens,
+			&i.StartOffset,
+			&i.EndOffset,
+			&i.Model,
+			&i.Dimensions,
+			&i.Vector,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findDocumentByID = `-- name: FindDocumentByID :one
+SELECT id, knowledge_base_id, title, source, content_type, tokens, created_at FROM documents WHERE id = ?
+`
+
+func (q *Queries) FindDocumentByID(ctx context.Context, id string) (Document, error) {
+	row := q.db.QueryRowContext(ctx, findDocumentByID, id)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.KnowledgeBaseID,
+		&i.Title,
+		&i.Source,
+		&i.ContentType,
+		&i.Tokens,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findErasedMessagesByChatID = `-- name: FindErasedMessagesByChatID :many
+SELECT m.id, m.chat_id, m.role, m.content, m.tokens, m.model, m.erased, m.order_msg, m.created_at, m.tool_calls, m.tool_call_id, m.name, m.moderation, m.key_id FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 1 AND m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg ASC
+`
+
+type FindErasedMessagesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) FindErasedMessagesByChatID(ctx context.Context, arg FindErasedMessagesByChatIDParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, findErasedMessagesByChatID, arg.ChatID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.Role,
+			&i.Content,
+			&i.Tokens,
+			&i.Model,
+			&i.Erased,
+			&i.OrderMsg,
+			&i.CreatedAt,
+			&i.ToolCalls,
+			&i.ToolCallID,
+			&i.Name,
+			&i.Moderation,
+			&i.KeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findLastAuditEvent = `-- name: FindLastAuditEvent :one
+SELECT seq, id, type, user_id, tenant_id, request_id, action, resource, data, prev_hash, hash, created_at FROM audit_log ORDER BY seq DESC LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) FindLastAuditEvent(ctx context.Context) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, findLastAuditEvent)
+	var i AuditLog
+	err := row.Scan(
+		&i.Seq,
+		&i.ID,
+		&i.Type,
+		&i.UserID,
+		&i.TenantID,
+		&i.RequestID,
+		&i.Action,
+		&i.Resource,
+		&i.Data,
+		&i.PrevHash,
+		&i.Hash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findLatestPromptTemplateVersion = `-- name: FindLatestPromptTemplateVersion :one
+SELECT CAST(COALESCE(MAX(version), 0) AS SIGNED) FROM prompt_templates WHERE name = ? FOR UPDATE
+`
+
+func (q *Queries) FindLatestPromptTemplateVersion(ctx context.Context, name string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, findLatestPromptTemplateVersion, name)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const findMessageEmbeddingsByChatID = `-- name: FindMessageEmbeddingsByChatID :many
+SELECT e.message_id, e.chat_id, e.model, e.dimensions, e.vector, e.created_at FROM message_embeddings e
+    JOIN chats c ON c.id = e.chat_id
+WHERE e.chat_id = ? AND c.tenant_id = ? AND e.model = ?
+`
+
+type FindMessageEmbeddingsByChatIDParams struct {
+	ChatID   string
+	TenantID string
+	Model    string
+}
+
+func (q *Queries) FindMessageEmbeddingsByChatID(ctx context.Context, arg FindMessageEmbeddingsByChatIDParams) ([]MessageEmbedding, error) {
+	rows, err := q.db.QueryContext(ctx, findMessageEmbeddingsByChatID, arg.ChatID, arg.TenantID, arg.Model)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MessageEmbedding
+	for rows.Next() {
+		var i MessageEmbedding
+		if err := rows.Scan(
+			&i.MessageID,
+			&i.ChatID,
+			&i.Model,
+			&i.Dimensions,
+			&i.Vector,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findMessagesByChatID = `-- name: FindMessagesByChatID :many
+SELECT m.id, m.chat_id, m.role, m.content, m.tokens, m.model, m.erased, m.order_msg, m.created_at, m.tool_calls, m.tool_call_id, m.name, m.moderation, m.key_id FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 0 AND m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg ASC
+`
+
+type FindMessagesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) FindMessagesByChatID(ctx context.Context, arg FindMessagesByChatIDParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, findMessagesByChatID, arg.ChatID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.Role,
+			&i.Content,
+			&i.Tokens,
+			&i.Model,
+			&i.Erased,
+			&i.OrderMsg,
+			&i.CreatedAt,
+			&i.ToolCalls,
+			&i.ToolCallID,
+			&i.Name,
+			&i.Moderation,
+			&i.KeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findMessageStatesByChatID = `-- name: FindMessageStatesByChatID :many
+SELECT m.id, m.erased, m.order_msg FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ?
+`
+
+type FindMessageStatesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+}
+
+type FindMessageStatesByChatIDRow struct {
+	ID       string
+	Erased   bool
+	OrderMsg int32
+}
+
+func (q *Queries) FindMessageStatesByChatID(ctx context.Context, arg FindMessageStatesByChatIDParams) ([]FindMessageStatesByChatIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, findMessageStatesByChatID, arg.ChatID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindMessageStatesByChatIDRow
+	for rows.Next() {
+		var i FindMessageStatesByChatIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Erased,
+			&i.OrderMsg,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findPromptTemplateVersion = `-- name: FindPromptTemplateVersion :one
+SELECT name, version, content, description, defaults, active, created_at FROM prompt_templates WHERE name = ? AND version = ?
+`
+
+type FindPromptTemplateVersionParams struct {
+	Name    string
+	Version int32
+}
+
+func (q *Queries) FindPromptTemplateVersion(ctx context.Context, arg FindPromptTemplateVersionParams) (PromptTemplate, error) {
+	row := q.db.QueryRowContext(ctx, findPromptTemplateVersion, arg.Name, arg.Version)
+	var i PromptTemplate
+	err := row.Scan(
+		&i.Name,
+		&i.Version,
+		&i.Content,
+		&i.Description,
+		&i.Defaults,
+		&i.Active,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findTenantByID = `-- name: FindTenantByID :one
+SELECT id, name, token_hash, default_assistant_id, allowed_models, monthly_token_quota, created_at, updated_at, tool_profile FROM tenants WHERE id = ?
+`
+
+func (q *Queries) FindTenantByID(ctx context.Context, id string) (Tenant, error) {
+	row := q.db.QueryRowContext(ctx, findTenantByID, id)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.DefaultAssistantID,
+		&i.AllowedModels,
+		&i.MonthlyTokenQuota,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ToolProfile,
+	)
+	return i, err
+}
+
+const findTenantByTokenHash = `-- name: FindTenantByTokenHash :one
+SELECT id, name, token_hash, default_assistant_id, allowed_models, monthly_token_quota, created_at, updated_at, tool_profile FROM tenants WHERE token_hash = ?
+`
+
+func (q *Queries) FindTenantByTokenHash(ctx context.Context, tokenHash string) (Tenant, error) {
+	row := q.db.QueryRowContext(ctx, findTenantByTokenHash, tokenHash)
+	var i Tenant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.TokenHash,
+		&i.DefaultAssistantID,
+		&i.AllowedModels,
+		&i.MonthlyTokenQuota,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ToolProfile,
+	)
+	return i, err
+}
+
+const findTenantUsage = `-- name: FindTenantUsage :one
+SELECT CAST(COALESCE(SUM(tokens), 0) AS SIGNED) FROM tenant_usage WHERE tenant_id = ? AND period = ?
+`
+
+type FindTenantUsageParams struct {
+	TenantID string
+	Period   string
+}
+
+func (q *Queries) FindTenantUsage(ctx context.Context, arg FindTenantUsageParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, findTenantUsage, arg.TenantID, arg.Period)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const findVectorsByNamespace = `-- name: FindVectorsByNamespace :many
+SELECT namespace, id, dimensions, vector, metadata, updated_at FROM vectors WHERE namespace = ?
+`
+
+func (q *Queries) FindVectorsByNamespace(ctx context.Context, namespace string) ([]Vector, error) {
+	rows, err := q.db.QueryContext(ctx, findVectorsByNamespace, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Vector
+	for rows.Next() {
+		var i Vector
+		if err := rows.Scan(
+			&i.Namespace,
+			&i.ID,
+			&i.Dimensions,
+			&i.Vector,
+			&i.Metadata,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActivePromptTemplates = `-- name: ListActivePromptTemplates :many
+SELECT name, version, content, description, defaults, active, created_at FROM prompt_templates WHERE active = 1 ORDER BY name
+`
+
+func (q *Queries) ListActivePromptTemplates(ctx context.Context) ([]PromptTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, listActivePromptTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PromptTemplate
+	for rows.Next() {
+		var i PromptTemplate
+		if err := rows.Scan(
+			&i.Name,
+			&i.Version,
+			&i.Content,
+			&i.Description,
+			&i.Defaults,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TenantID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIKeysByUserID = `-- name: ListAPIKeysByUserID :many
+SELECT id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE user_id = ? ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAPIKeysByUserID(ctx context.Context, userID string) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeysByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TenantID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAssistants = `-- name: ListAssistants :many
+SELECT id, name, description, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, system_prompt, prompt_template, tool_profile, knowledge_base_id, created_at, updated_at FROM assistants ORDER BY name
+`
+
+func (q *Queries) ListAssistants(ctx context.Context) ([]Assistant, error) {
+	rows, err := q.db.QueryContext(ctx, listAssistants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Assistant
+	for rows.Next() {
+		var i Assistant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Model,
+			&i.ModelMaxTokens,
+			&i.Temperature,
+			&i.TopP,
+			&i.N,
+			&i.Stop,
+			&i.MaxTokens,
+			&i.PresencePenalty,
+			&i.FrequencyPenalty,
+			&i.SystemPrompt,
+			&i.PromptTemplate,
+			&i.ToolProfile,
+			&i.KnowledgeBaseID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT seq, id, type, user_id, tenant_id, request_id, action, resource, data, prev_hash, hash, created_at FROM audit_log
+WHERE seq > ?
+    AND (? = '' OR type = ?)
+    AND created_at >= ? AND created_at <= ?
+ORDER BY seq
+LIMIT ?
+`
+
+type ListAuditEventsParams struct {
+	AfterSeq    int64
+	Type        string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       int32
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents,
+		arg.AfterSeq,
+		arg.Type,
+		arg.Type,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.Seq,
+			&i.ID,
+			&i.Type,
+			&i.UserID,
+			&i.TenantID,
+			&i.RequestID,
+			&i.Action,
+			&i.Resource,
+			&i.Data,
+			&i.PrevHash,
+			&i.Hash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChatsByOwner = `-- name: ListChatsByOwner :many
+SELECT id, user_id, initial_message_id, status, token_usage, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at, prompt_template, template_version, assistant_id, tenant_id, tool_profile FROM chats WHERE tenant_id = ? AND user_id = ? ORDER BY created_at, id
+`
+
+type ListChatsByOwnerParams struct {
+	TenantID string
+	UserID   string
+}
+
+func (q *Queries) ListChatsByOwner(ctx context.Context, arg ListChatsByOwnerParams) ([]Chat, error) {
+	rows, err := q.db.QueryContext(ctx, listChatsByOwner, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chat
+	for rows.Next() {
+		var i Chat
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.InitialMessageID,
+			&i.Status,
+			&i.TokenUsage,
+			&i.Model,
+			&i.ModelMaxTokens,
+			&i.Temperature,
+			&i.TopP,
+			&i.N,
+			&i.Stop,
+			&i.MaxTokens,
+			&i.PresencePenalty,
+			&i.FrequencyPenalty,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PromptTemplate,
+			&i.TemplateVersion,
+			&i.AssistantID,
+			&i.TenantID,
+			&i.ToolProfile,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChatsByUserID = `-- name: ListChatsByUserID :many
+SELECT id, user_id, initial_message_id, status, token_usage, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at, prompt_template, template_version, assistant_id, tenant_id, tool_profile FROM chats
+WHERE tenant_id = ?
+    AND (user_id = ? OR id IN (SELECT chat_id FROM chat_shares WHERE chat_shares.user_id = ?))
+    AND (updated_at < ? OR (updated_at = ? AND id < ?))
+ORDER BY updated_at DESC, id DESC
+LIMIT ?
+`
+
+type ListChatsByUserIDParams struct {
+	TenantID  string
+	UserID    string
+	UpdatedAt time.Time
+	ID        string
+	Limit     int32
+}
+
+func (q *Queries) ListChatsByUserID(ctx context.Context, arg ListChatsByUserIDParams) ([]Chat, error) {
+	rows, err := q.db.QueryContext(ctx, listChatsByUserID,
+		arg.TenantID,
+		arg.UserID,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chat
+	for rows.Next() {
+		var i Chat
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.InitialMessageID,
+			&i.Status,
+			&i.TokenUsage,
+			&i.Model,
+			&i.ModelMaxTokens,
+			&i.Temperature,
+			&i.TopP,
+			&i.N,
+			&i.Stop,
+			&i.MaxTokens,
+			&i.PresencePenalty,
+			&i.FrequencyPenalty,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PromptTemplate,
+			&i.TemplateVersion,
+			&i.AssistantID,
+			&i.TenantID,
+			&i.ToolProfile,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChatSharesByUserID = `-- name: ListChatSharesByUserID :many
+SELECT s.chat_id, c.tenant_id, s.created_at FROM chat_shares s
+    JOIN chats c ON c.id = s.chat_id
+WHERE c.tenant_id = ? AND s.user_id = ?
+ORDER BY s.created_at, s.chat_id
+`
+
+type ListChatSharesByUserIDParams struct {
+	TenantID string
+	UserID   string
+}
+
+type ListChatSharesByUserIDRow struct {
+	ChatID    string
+	TenantID  string
+	CreatedAt time.Time
+}
+
+func (q *Queries) ListChatSharesByUserID(ctx context.Context, arg ListChatSharesByUserIDParams) ([]ListChatSharesByUserIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChatSharesByUserID, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChatSharesByUserIDRow
+	for rows.Next() {
+		var i ListChatSharesByUserIDRow
+		if err := rows.Scan(
+			&i.ChatID,
+			&i.TenantID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listErasedMessagesBefore = `-- name: ListErasedMessagesBefore :many
+SELECT m.id, m.chat_id, c.tenant_id, c.assistant_id, m.created_at FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 1 AND m.created_at < ? AND m.id <> c.initial_message_id AND m.id > ?
+ORDER BY m.id
+LIMIT ?
+`
+
+type ListErasedMessagesBeforeParams struct {
+	Before  time.Time
+	AfterID string
+	Limit   int32
+}
+
+type ListErasedMessagesBeforeRow struct {
+	ID          string
+	ChatID      string
+	TenantID    string
+	AssistantID string
+	CreatedAt   time.Time
+}
+
+func (q *Queries) ListErasedMessagesBefore(ctx context.Context, arg ListErasedMessagesBeforeParams) ([]ListErasedMessagesBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listErasedMessagesBefore,
+		arg.Before,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListErasedMessagesBeforeRow
+	for rows.Next() {
+		var i ListErasedMessagesBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.TenantID,
+			&i.AssistantID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInactiveChats = `-- name: ListInactiveChats :many
+SELECT id, tenant_id, assistant_id, user_id, updated_at FROM chats
+WHERE updated_at < ?
+    AND (user_id <> '' OR ? = TRUE)
+    AND (updated_at > ? OR (updated_at = ? AND id > ?))
+ORDER BY updated_at, id
+LIMIT ?
+`
+
+type ListInactiveChatsParams struct {
+	Before            time.Time
+	IncludeAnonymized bool
+	AfterUpdatedAt    time.Time
+	AfterID           string
+	Limit             int32
+}
+
+type ListInactiveChatsRow struct {
+	ID          string
+	TenantID    string
+	AssistantID string
+	UserID      string
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) ListInactiveChats(ctx context.Context, arg ListInactiveChatsParams) ([]ListInactiveChatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInactiveChats,
+		arg.Before,
+		arg.IncludeAnonymized,
+		arg.AfterUpdatedAt,
+		arg.AfterUpdatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInactiveChatsRow
+	for rows.Next() {
+		var i ListInactiveChatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.AssistantID,
+			&i.UserID,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMessagesByChatID = `-- name: ListMessagesByChatID :many
+SELECT m.id, m.chat_id, m.role, m.content, m.tokens, m.model, m.erased, m.order_msg, m.created_at, m.tool_calls, m.tool_call_id, m.name, m.moderation, m.key_id FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ? AND m.order_msg < ?
+ORDER BY m.order_msg DESC
+LIMIT ?
 `
 
-func (q *Queries) FindChatByID(ctx context.Context, id string) (Chat, error) {
-	row := q.db.QueryRowContext(ctx, findChatByID, id)
-	var i Chat
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.InitialMessageID,
-		&i.Status,
-		&i.TokenUsage,
-		&i.Model,
-		&i.ModelMaxTokens,
-		&i.Temperature,
-		&i.TopP,
-		&i.N,
-		&i.Stop,
-		&i.MaxTokens,
-		&i.PresencePenalty,
-		&i.FrequencyPenalty,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type ListMessagesByChatIDParams struct {
+	ChatID   string
+	TenantID string
+	OrderMsg int32
+	Limit    int32
 }
 
-const findErasedMessagesByChatID = `-- name: FindErasedMessagesByChatID :many
-SELECT id, chat_id, role, content, tokens, model, erased, order_msg, created_at FROM messages WHERE erased=1 and chat_id = ? order by order_msg asc
-`
-
-func (q *Queries) FindErasedMessagesByChatID(ctx context.Context, chatID string) ([]Message, error) {
-	rows, err := q.db.QueryContext(ctx, findErasedMessagesByChatID, chatID)
+func (q *Queries) ListMessagesByChatID(ctx context.Context, arg ListMessagesByChatIDParams) ([]Message, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesByChatID,
+		arg.ChatID,
+		arg.TenantID,
+		arg.OrderMsg,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -157,6 +1819,11 @@ func (q *Queries) FindErasedMessagesByChatID(ctx context.Context, chatID string)
 			&i.Erased,
 			&i.OrderMsg,
 			&i.CreatedAt,
+			&i.ToolCalls,
+			&i.ToolCallID,
+			&i.Name,
+			&i.Moderation,
+			&i.KeyID,
 		); err != nil {
 			return nil, err
 		}
@@ -171,29 +1838,157 @@ func (q *Queries) FindErasedMessagesByChatID(ctx context.Context, chatID string)
 	return items, nil
 }
 
-const findMessagesByChatID = `-- name: FindMessagesByChatID :many
-SELECT id, chat_id, role, content, tokens, model, erased, order_msg, created_at FROM messages WHERE erased=0 and chat_id = ? order by order_msg asc
+const listMessagesForRotation = `-- name: ListMessagesForRotation :many
+SELECT id, content, tool_calls, key_id FROM messages WHERE key_id <> ? AND id > ? ORDER BY id LIMIT ?
 `
 
-func (q *Queries) FindMessagesByChatID(ctx context.Context, chatID string) ([]Message, error) {
-	rows, err := q.db.QueryContext(ctx, findMessagesByChatID, chatID)
+type ListMessagesForRotationParams struct {
+	KeyID string
+	ID    string
+	Limit int32
+}
+
+type ListMessagesForRotationRow struct {
+	ID        string
+	Content   string
+	ToolCalls json.RawMessage
+	KeyID     string
+}
+
+func (q *Queries) ListMessagesForRotation(ctx context.Context, arg ListMessagesForRotationParams) ([]ListMessagesForRotationRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMessagesForRotation,
+		arg.KeyID,
+		arg.ID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Message
+	var items []ListMessagesForRotationRow
 	for rows.Next() {
-		var i Message
+		var i ListMessagesForRotationRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.ChatID,
-			&i.Role,
 			&i.Content,
-			&i.Tokens,
-			&i.Model,
-			&i.Erased,
-			&i.OrderMsg,
+			&i.ToolCalls,
+			&i.KeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPromptTemplateVersions = `-- name: ListPromptTemplateVersions :many
+SELECT name, version, content, description, defaults, active, created_at FROM prompt_templates WHERE name = ? ORDER BY version DESC
+`
+
+func (q *Queries) ListPromptTemplateVersions(ctx context.Context, name string) ([]PromptTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, listPromptTemplateVersions, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PromptTemplate
+	for rows.Next() {
+		var i PromptTemplate
+		if err := rows.Scan(
+			&i.Name,
+			&i.Version,
+			&i.Content,
+			&i.Description,
+			&i.Defaults,
+			&i.Active,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantAPIKeysByUserID = `-- name: ListTenantAPIKeysByUserID :many
+SELECT id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE tenant_id = ? AND user_id = ? ORDER BY created_at DESC
+`
+
+type ListTenantAPIKeysByUserIDParams struct {
+	TenantID string
+	UserID   string
+}
+
+func (q *Queries) ListTenantAPIKeysByUserID(ctx context.Context, arg ListTenantAPIKeysByUserIDParams) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listTenantAPIKeysByUserID, arg.TenantID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TenantID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenants = `-- name: ListTenants :many
+SELECT id, name, token_hash, default_assistant_id, allowed_models, monthly_token_quota, created_at, updated_at, tool_profile FROM tenants ORDER BY name
+`
+
+func (q *Queries) ListTenants(ctx context.Context) ([]Tenant, error) {
+	rows, err := q.db.QueryContext(ctx, listTenants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tenant
+	for rows.Next() {
+		var i Tenant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.TokenHash,
+			&i.DefaultAssistantID,
+			&i.AllowedModels,
+			&i.MonthlyTokenQuota,
 			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ToolProfile,
 		); err != nil {
 			return nil, err
 		}
@@ -208,8 +2003,94 @@ func (q *Queries) FindMessagesByChatID(ctx context.Context, chatID string) ([]Me
 	return items, nil
 }
 
+const markMessageErased = `-- name: MarkMessageErased :exec
+UPDATE messages SET erased = 1 WHERE id = ? AND chat_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?)
+`
+
+type MarkMessageErasedParams struct {
+	ID       string
+	ChatID   string
+	TenantID string
+}
+
+func (q *Queries) MarkMessageErased(ctx context.Context, arg MarkMessageErasedParams) error {
+	_, err := q.db.ExecContext(ctx, markMessageErased,
+		arg.ID,
+		arg.ChatID,
+		arg.TenantID,
+	)
+	return err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL
+`
+
+type RevokeAPIKeyParams struct {
+	RevokedAt sql.NullTime
+	ID        string
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeAPIKey,
+		arg.RevokedAt,
+		arg.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const saveAssistant = `-- name: SaveAssistant :exec
+UPDATE assistants SET name = ?, description = ?, model = ?, model_max_tokens = ?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, system_prompt = ?, prompt_template = ?, tool_profile = ?, knowledge_base_id = ?, updated_at = ? WHERE id = ?
+`
+
+type SaveAssistantParams struct {
+	Name             string
+	Description      string
+	Model            string
+	ModelMaxTokens   int32
+	Temperature      float64
+	TopP             float64
+	N                int32
+	Stop             json.RawMessage
+	MaxTokens        int32
+	PresencePenalty  float64
+	FrequencyPenalty float64
+	SystemPrompt     string
+	PromptTemplate   string
+	ToolProfile      string
+	KnowledgeBaseID  string
+	UpdatedAt        time.Time
+	ID               string
+}
+
+func (q *Queries) SaveAssistant(ctx context.Context, arg SaveAssistantParams) error {
+	_, err := q.db.ExecContext(ctx, saveAssistant,
+		arg.Name,
+		arg.Description,
+		arg.Model,
+		arg.ModelMaxTokens,
+		arg.Temperature,
+		arg.TopP,
+		arg.N,
+		arg.Stop,
+		arg.MaxTokens,
+		arg.PresencePenalty,
+		arg.FrequencyPenalty,
+		arg.SystemPrompt,
+		arg.PromptTemplate,
+		arg.ToolProfile,
+		arg.KnowledgeBaseID,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const saveChat = `-- name: SaveChat :exec
-UPDATE chats SET user_id = ?, initial_message_id = ?, status = ?, token_usage = ?, model = ?, model_max_tokens=?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, updated_at = ? WHERE id = ?
+UPDATE chats SET user_id = ?, initial_message_id = ?, status = ?, token_usage = ?, model = ?, model_max_tokens=?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, updated_at = ? WHERE id = ? AND tenant_id = ?
 `
 
 type SaveChatParams struct {
@@ -222,12 +2103,13 @@ type SaveChatParams struct {
 	Temperature      float64
 	TopP             float64
 	N                int32
-	Stop             string
+	Stop             json.RawMessage
 	MaxTokens        int32
 	PresencePenalty  float64
 	FrequencyPenalty float64
 	UpdatedAt        time.Time
 	ID               string
+	TenantID         string
 }
 
 func (q *Queries) SaveChat(ctx context.Context, arg SaveChatParams) error {
@@ -247,6 +2129,189 @@ func (q *Queries) SaveChat(ctx context.Context, arg SaveChatParams) error {
 		arg.FrequencyPenalty,
 		arg.UpdatedAt,
 		arg.ID,
+		arg.TenantID,
+	)
+	return err
+}
+
+const saveTenant = `-- name: SaveTenant :exec
+UPDATE tenants SET name = ?, default_assistant_id = ?, allowed_models = ?, monthly_token_quota = ?, tool_profile = ?, updated_at = ? WHERE id = ?
+`
+
+type SaveTenantParams struct {
+	Name               string
+	DefaultAssistantID string
+	AllowedModels      json.RawMessage
+	MonthlyTokenQuota  int32
+	ToolProfile        string
+	UpdatedAt          time.Time
+	ID                 string
+}
+
+func (q *Queries) SaveTenant(ctx context.Context, arg SaveTenantParams) error {
+	_, err := q.db.ExecContext(ctx, saveTenant,
+		arg.Name,
+		arg.DefaultAssistantID,
+		arg.AllowedModels,
+		arg.MonthlyTokenQuota,
+		arg.ToolProfile,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const searchMessages = `-- name: SearchMessages :many
+SELECT m.id, m.chat_id, m.role, m.content, m.key_id, m.erased, m.order_msg, m.created_at,
+    MATCH(m.content) AGAINST (? IN NATURAL LANGUAGE MODE) AS score
+FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE MATCH(m.content) AGAINST (? IN NATURAL LANGUAGE MODE)
+    AND c.tenant_id = ?
+    AND (c.user_id = ? OR c.id IN (SELECT chat_id FROM chat_shares WHERE chat_shares.user_id = ?))
+    AND (? = '' OR m.chat_id = ?)
+    AND (? = '' OR m.role = ?)
+    AND m.created_at >= ? AND m.created_at <= ?
+ORDER BY score DESC, m.created_at DESC, m.id DESC
+LIMIT ? OFFSET ?
+`
+
+type SearchMessagesParams struct {
+	Query       string
+	TenantID    string
+	UserID      string
+	ChatID      string
+	Role        string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       int32
+	Offset      int32
+}
+
+type SearchMessagesRow struct {
+	ID        string
+	ChatID    string
+	Role      string
+	Content   string
+	KeyID     string
+	Erased    bool
+	OrderMsg  int32
+	CreatedAt time.Time
+	Score     float64
+}
+
+func (q *Queries) SearchMessages(ctx context.Context, arg SearchMessagesParams) ([]SearchMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMessages,
+		arg.Query,
+		arg.Query,
+		arg.TenantID,
+		arg.UserID,
+		arg.UserID,
+		arg.ChatID,
+		arg.ChatID,
+		arg.Role,
+		arg.Role,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMessagesRow
+	for rows.Next() {
+		var i SearchMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.Role,
+			&i.Content,
+			&i.KeyID,
+			&i.Erased,
+			&i.OrderMsg,
+			&i.CreatedAt,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys SET last_used_at = ? WHERE id = ?
+`
+
+type TouchAPIKeyParams struct {
+	LastUsedAt sql.NullTime
+	ID         string
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey,
+		arg.LastUsedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMessageContent = `-- name: UpdateMessageContent :execrows
+UPDATE messages SET content = ?, tool_calls = ?, key_id = ? WHERE id = ? AND key_id = ?
+`
+
+type UpdateMessageContentParams struct {
+	Content   string
+	ToolCalls json.RawMessage
+	KeyID     string
+	ID        string
+	KeyID_2   string
+}
+
+func (q *Queries) UpdateMessageContent(ctx context.Context, arg UpdateMessageContentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateMessageContent,
+		arg.Content,
+		arg.ToolCalls,
+		arg.KeyID,
+		arg.ID,
+		arg.KeyID_2,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertVector = `-- name: UpsertVector :exec
+INSERT INTO vectors (namespace, id, dimensions, vector, metadata, updated_at) VALUES(?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE dimensions = VALUES(dimensions), vector = VALUES(vector), metadata = VALUES(metadata), updated_at = VALUES(updated_at)
+`
+
+type UpsertVectorParams struct {
+	Namespace  string
+	ID         string
+	Dimensions int32
+	Vector     []byte
+	Metadata   json.RawMessage
+	UpdatedAt  time.Time
+}
+
+func (q *Queries) UpsertVector(ctx context.Context, arg UpsertVectorParams) error {
+	_, err := q.db.ExecContext(ctx, upsertVector,
+		arg.Namespace,
+		arg.ID,
+		arg.Dimensions,
+		arg.Vector,
+		arg.Metadata,
+		arg.UpdatedAt,
 	)
 	return err
 }
@@ -0,0 +1,94 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func embedOne(t *testing.T, e *LocalEmbedder, text string) []float32 {
+	t.Helper()
+	vectors, err := e.Embed(context.Background(), []string{text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vectors[0]
+}
+
+func TestLocalEmbedderDefaults(t *testing.T) {
+	e := NewLocalEmbedder(0)
+	if e.Dimensions != 256 || e.ModelName() != "local-hash-256" {
+		t.Fatalf("expected 256 dimensions, got %d %q", e.Dimensions, e.ModelName())
+	}
+	if got := NewLocalEmbedder(64).ModelName(); got != "local-hash-64" {
+		t.Fatalf("expected the dimensions in the model name, got %q", got)
+	}
+}
+
+func TestLocalEmbedderIsDeterministic(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog"
+	a := embedOne(t, NewLocalEmbedder(128), text)
+	b := embedOne(t, NewLocalEmbedder(128), text)
+	if len(a) != 128 {
+		t.Fatalf("expected 128 dimensions, got %d", len(a))
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("expected the same vector for the same text")
+	}
+	//caixa e pontuacao nao mudam as palavras
+	if c := embedOne(t, NewLocalEmbedder(128), "the QUICK brown fox, jumps over the lazy dog!"); !reflect.DeepEqual(a, c) {
+		t.Fatal("expected case and punctuation to be ignored")
+	}
+}
+
+func TestLocalEmbedderNormalizes(t *testing.T) {
+	e := NewLocalEmbedder(64)
+	v := embedOne(t, e, "refund policy for damaged orders")
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+	if math.Abs(norm-1) > 1e-6 {
+		t.Fatalf("expected a unit vector, got norm %f", math.Sqrt(norm))
+	}
+
+	//sem palavras nao ha direcao, o vetor fica zerado
+	for _, f := range embedOne(t, e, " ... !? ") {
+		if f != 0 {
+			t.Fatalf("expected a zero vector for text without words, got %f", f)
+		}
+	}
+}
+
+func TestLocalEmbedderWordOverlap(t *testing.T) {
+	e := NewLocalEmbedder(256)
+	vectors, err := e.Embed(context.Background(), []string{
+		"how do I reset my password",
+		"reset the password of my account",
+		"weather forecast for tomorrow",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 3 {
+		t.Fatalf("expected one vector per text, got %d", len(vectors))
+	}
+	related := entity.CosineSimilarity(vectors[0], vectors[1])
+	unrelated := entity.CosineSimilarity(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Fatalf("expected shared words to score higher, got %f <= %f", related, unrelated)
+	}
+	if self := entity.CosineSimilarity(vectors[0], vectors[0]); math.Abs(float64(self)-1) > 1e-6 {
+		t.Fatalf("expected similarity 1 with itself, got %f", self)
+	}
+}
+
+func TestLocalEmbedderEmptyBatch(t *testing.T) {
+	vectors, err := NewLocalEmbedder(0).Embed(context.Background(), nil)
+	if err != nil || len(vectors) != 0 {
+		t.Fatalf("expected no vectors, got %v %v", vectors, err)
+	}
+}
@@ -0,0 +1,70 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type OpenAIEmbedder struct {
+	Client *openai.Client
+	Model  string
+}
+
+func NewOpenAIEmbedder(client *openai.Client, model string) *OpenAIEmbedder {
+	if model == "" {
+		model = "text-embedding-ada-002"
+	}
+	return &OpenAIEmbedder{
+		Client: client,
+		Model:  model,
+	}
+}
+
+func (e *OpenAIEmbedder) ModelName() string {
+	return e.Model
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	//a openai recomenda trocar quebras de linha por espaco
+	input := make([]string, len(texts))
+	for i, text := range texts {
+		input[i] = strings.ReplaceAll(text, "\n", " ")
+	}
+
+	resp, err := e.Client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: input,
+		Model: openai.EmbeddingModel(e.Model),
+	})
+	if err != nil {
+		return nil, providerError(err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		vectors[data.Index] = data.Embedding
+	}
+	return vectors, nil
+}
+
+func providerError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return entity.NewProviderError(apiErr.HTTPStatusCode, err)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return entity.NewProviderError(reqErr.HTTPStatusCode, err)
+	}
+	return entity.NewProviderError(0, err)
+}
@@ -0,0 +1,186 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// KeySize chaves mestras e chaves de dados sao AES-256
+const KeySize = 32
+
+// version primeiro byte do conteudo cifrado, muda se o formato mudar
+const version byte = 1
+
+// keyfile arquivo json das chaves mestras: {"active": "2024-01", "keys": {"2024-01": "<base64>"}}.
+// As chaves antigas ficam no arquivo ate a rotacao recifrar todas as msgs com a ativa
+type keyfile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// Keyring cifra o conteudo com envelope: cada valor tem a propria chave de dados aleatoria, que é cifrada
+// pela chave mestra ativa e guardada junto do conteudo. O id da chave mestra fica na linha do banco
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// LoadKeyring le o arquivo das chaves mestras, arquivo vazio retorna nil (conteudo em texto puro)
+func LoadKeyring(path string) (*Keyring, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption keyfile: %w", err)
+	}
+	var file keyfile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing encryption keyfile: %w", err)
+	}
+	keys := map[string][]byte{}
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q is not valid base64", id)
+		}
+		keys[id] = key
+	}
+	return NewKeyring(file.Active, keys)
+}
+
+// NewKeyring active é o id da chave mestra usada para cifrar, as demais somente decifram
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not in the keyfile", active)
+	}
+	k := &Keyring{active: active, keys: map[string]cipher.AEAD{}}
+	for id, key := range keys {
+		if id == "" || len(id) > 64 {
+			return nil, fmt.Errorf("encryption key id %q must have 1 to 64 characters", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("encryption key %q must have %d bytes", id, KeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// GenerateKey chave aleatoria em base64, no formato do keyfile
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ActiveKeyID id da chave mestra que cifra os valores novos
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt cifra o texto com a chave mestra ativa. aad amarra o valor a linha (id da msg),
+// conteudo copiado para outra linha nao decifra. Retorna o valor em base64 e o id da chave mestra.
+// Keyring nil deixa o texto puro e retorna o id vazio
+func (k *Keyring) Encrypt(plaintext string, aad string) (string, string, error) {
+	if k == nil {
+		return plaintext, "", nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", "", err
+	}
+	content, err := newAEAD(dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	master := k.keys[k.active]
+
+	//versao | nonce da chave de dados | chave de dados cifrada | nonce do conteudo | conteudo cifrado
+	out := []byte{version}
+	out, err = seal(master, out, dataKey, []byte(k.active))
+	if err != nil {
+		return "", "", err
+	}
+	out, err = seal(content, out, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), k.active, nil
+}
+
+func seal(aead cipher.AEAD, dst []byte, plaintext []byte, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, aad), nil
+}
+
+// Decrypt decifra o valor cifrado pela chave mestra keyID. keyID vazio é texto puro e volta como esta
+func (k *Keyring) Decrypt(ciphertext string, keyID string, aad string) (string, error) {
+	if keyID == "" {
+		return ciphertext, nil
+	}
+	if k == nil {
+		return "", fmt.Errorf("content is encrypted with key %q but encryption is not configured", keyID)
+	}
+	master, ok := k.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("encryption key %q is not in the keyfile", keyID)
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) == 0 || data[0] != version {
+		return "", errors.New("invalid encrypted content")
+	}
+	wrappedSize := master.NonceSize() + KeySize + master.Overhead()
+	if len(data) < 1+wrappedSize {
+		return "", errors.New("invalid encrypted content")
+	}
+	dataKey, err := open(master, data[1:1+wrappedSize], []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	content, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(content, data[1+wrappedSize:], []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func open(aead cipher.AEAD, data []byte, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted content")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, errors.New("encrypted content failed authentication")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,201 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func newTestKeyring(t *testing.T, active string, ids ...string) *Keyring {
+	t.Helper()
+	keys := map[string][]byte{}
+	//a chave depende so do id, o mesmo id tem a mesma chave em keyrings diferentes
+	for _, id := range ids {
+		keys[id] = testKey(id[len(id)-1])
+	}
+	k, err := NewKeyring(active, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, "k1", "k1")
+	for _, plaintext := range []string{"", "hello", strings.Repeat("long content ", 1000), "acentuação 😀"} {
+		ciphertext, keyID, err := k.Encrypt(plaintext, "message-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keyID != "k1" || (plaintext != "" && strings.Contains(ciphertext, plaintext)) {
+			t.Fatalf("expected content encrypted with k1, got %q %s", ciphertext, keyID)
+		}
+		got, err := k.Decrypt(ciphertext, keyID, "message-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != plaintext {
+			t.Fatalf("expected %q, got %q", plaintext, got)
+		}
+	}
+
+	//cada valor tem chave de dados e nonce proprios
+	first, _, _ := k.Encrypt("hello", "message-1")
+	second, _, _ := k.Encrypt("hello", "message-1")
+	if first == second {
+		t.Fatal("expected different ciphertexts for the same content")
+	}
+}
+
+func TestKeyringDecryptErrors(t *testing.T) {
+	k := newTestKeyring(t, "k1", "k1", "k2")
+	ciphertext, _, err := k.Encrypt("hello", "message-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(ciphertext)
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 1
+	wrappedKey := append([]byte{}, data...)
+	wrappedKey[20] ^= 1
+	badVersion := append([]byte{}, data...)
+	badVersion[0] = 9
+
+	tests := []struct {
+		name       string
+		ciphertext string
+		keyID      string
+		aad        string
+	}{
+		{"wrong key id", ciphertext, "k2", "message-1"},
+		{"unknown key id", ciphertext, "k3", "message-1"},
+		{"wrong aad", ciphertext, "k1", "message-2"},
+		{"tampered content", base64.StdEncoding.EncodeToString(tampered), "k1", "message-1"},
+		{"tampered data key", base64.StdEncoding.EncodeToString(wrappedKey), "k1", "message-1"},
+		{"unknown version", base64.StdEncoding.EncodeToString(badVersion), "k1", "message-1"},
+		{"truncated", base64.StdEncoding.EncodeToString(data[:30]), "k1", "message-1"},
+		{"not base64", "not base64!", "k1", "message-1"},
+		{"empty", "", "k1", "message-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := k.Decrypt(tt.ciphertext, tt.keyID, tt.aad); err == nil {
+				t.Fatalf("expected an error, got %q", got)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old := newTestKeyring(t, "k1", "k1")
+	ciphertext, keyID, err := old.Encrypt("hello", "message-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//k2 ativa, k1 somente decifra os valores antigos
+	rotated := newTestKeyring(t, "k2", "k1", "k2")
+	if rotated.ActiveKeyID() != "k2" {
+		t.Fatalf("expected k2 active, got %s", rotated.ActiveKeyID())
+	}
+	got, err := rotated.Decrypt(ciphertext, keyID, "message-1")
+	if err != nil || got != "hello" {
+		t.Fatalf("expected the old value decrypted, got %q %v", got, err)
+	}
+	reencrypted, newKeyID, err := rotated.Encrypt(got, "message-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newKeyID != "k2" {
+		t.Fatalf("expected the value encrypted with k2, got %s", newKeyID)
+	}
+	//o id da chave mestra esta amarrado a chave de dados
+	if _, err := rotated.Decrypt(reencrypted, "k1", "message-1"); err == nil {
+		t.Fatal("expected the k2 value to fail with k1")
+	}
+
+	//sem k1 no arquivo o valor antigo nao decifra mais
+	withoutOld := newTestKeyring(t, "k2", "k2")
+	if _, err := withoutOld.Decrypt(ciphertext, keyID, "message-1"); err == nil {
+		t.Fatal("expected an error for a removed key")
+	}
+	if got, err := withoutOld.Decrypt(reencrypted, newKeyID, "message-1"); err != nil || got != "hello" {
+		t.Fatalf("expected the rotated value decrypted, got %q %v", got, err)
+	}
+}
+
+func TestNilKeyringIsPlaintext(t *testing.T) {
+	var k *Keyring
+	ciphertext, keyID, err := k.Encrypt("hello", "message-1")
+	if err != nil || ciphertext != "hello" || keyID != "" {
+		t.Fatalf("expected plaintext, got %q %q %v", ciphertext, keyID, err)
+	}
+	if got, err := k.Decrypt("hello", "", "message-1"); err != nil || got != "hello" {
+		t.Fatalf("expected plaintext, got %q %v", got, err)
+	}
+	if _, err := k.Decrypt("c2VjcmV0", "k1", "message-1"); err == nil {
+		t.Fatal("expected an error for encrypted content without a keyring")
+	}
+	//texto puro gravado antes da criptografia continua legivel
+	if got, err := newTestKeyring(t, "k1", "k1").Decrypt("hello", "", "message-1"); err != nil || got != "hello" {
+		t.Fatalf("expected plaintext, got %q %v", got, err)
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name   string
+		active string
+		keys   map[string][]byte
+	}{
+		{"active missing", "k2", map[string][]byte{"k1": testKey(1)}},
+		{"no keys", "", nil},
+		{"short key", "k1", map[string][]byte{"k1": testKey(1)[:16]}},
+		{"empty id", "k1", map[string][]byte{"k1": testKey(1), "": testKey(2)}},
+		{"long id", "k1", map[string][]byte{"k1": testKey(1), strings.Repeat("k", 65): testKey(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.active, tt.keys); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	k, err := LoadKeyring("")
+	if err != nil || k != nil {
+		t.Fatalf("expected encryption disabled, got %v %v", k, err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(`{"active":"2024-01","keys":{"2024-01":"`+key+`"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	k, err = LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.ActiveKeyID() != "2024-01" {
+		t.Fatalf("expected the active key from the file, got %s", k.ActiveKeyID())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"active":"2024-01","keys":{"2024-01":"not base64!"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyring(path); err == nil {
+		t.Fatal("expected an error for a key that is not base64")
+	}
+}
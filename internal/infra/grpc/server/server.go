@@ -3,13 +3,14 @@ package server
 import (
 	"net"
 
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
 	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/pb"
 	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/service"
+	"github.com/ruhancs/virtual-assistant/internal/infra/middleware"
 	chatcompletionstream "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion_stream"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-	"google.golang.org/grpc/metadata"
 )
 
 type GRPCServer struct {
@@ -17,50 +18,32 @@ type GRPCServer struct {
 	ChatConfig                  chatcompletionstream.ChatCompletionConfigInputDTO
 	ChatService                 service.ChatService
 	Port                        string
-	AuthToken                   string
-	StreamChannel               chan chatcompletionstream.ChatCompletionOutputDTO
+	Options                     []grpc.ServerOption
 }
 
 
-func NewGRPCServer(usecase chatcompletionstream.ChatCompletionUseCase, config chatcompletionstream.ChatCompletionConfigInputDTO, port string,authToken string, channel chan chatcompletionstream.ChatCompletionOutputDTO) *GRPCServer {
-	chatService := service.NewChatService(usecase,config,channel)
+func NewGRPCServer(usecase chatcompletionstream.ChatCompletionUseCase, config chatcompletionstream.ChatCompletionConfigInputDTO, port string, listChats chathistory.ListChatsUseCase, listMessages chathistory.ListMessagesUseCase, search chatsearch.SearchMessagesUseCase, opts ...grpc.ServerOption) *GRPCServer {
+	chatService := service.NewChatService(usecase,config,listChats,listMessages,search)
 	return &GRPCServer{
 		ChatCompletionStreamUseCase: usecase,
 		ChatConfig: config,
 		ChatService: *chatService,
 		Port: port,
-		AuthToken: authToken,
-		StreamChannel: channel,
+		Options: opts,
 	}
 }
 
-func (g *GRPCServer)AuthMiddleware(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	ctx := ss.Context()
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return status.Error(codes.Unauthenticated, "metadata is not provided")
-	}
-
-	token := md.Get("authorization")
-	if len(token) == 0 {
-		return status.Error(codes.Unauthenticated, "authorization token is not provided")
-	}
-
-	if token[0] != g.AuthToken {
-		return status.Error(codes.Unauthenticated, "authorization token is invalid")
-	}
-
-	return handler(srv, ss)
+// MethodPolicies escopo exigido e checagem de cota de cada rpc, rpcs fora do mapa sao recusadas
+var MethodPolicies = map[string]middleware.Policy{
+	pb.ChatService_ChatStream_FullMethodName:     {Scope: entity.ScopeChat, Quota: true},
+	pb.ChatService_ListChats_FullMethodName:      {Scope: entity.ScopeHistory},
+	pb.ChatService_ListMessages_FullMethodName:   {Scope: entity.ScopeHistory},
+	pb.ChatService_SearchMessages_FullMethodName: {Scope: entity.ScopeHistory},
 }
 
 func (gs *GRPCServer) Start() {
-	//authenticacao
-	opts := []grpc.ServerOption{
-		grpc.StreamInterceptor(gs.AuthMiddleware),
-	}
-
-	//opts... esta a middleware de autheticacao
-	grpcServer := grpc.NewServer(opts...)
+	//opts tem os interceptors unary e stream (autenticacao, log, etc)
+	grpcServer := grpc.NewServer(gs.Options...)
 	//registrar o servidor no grpc
 	pb.RegisterChatServiceServer(grpcServer, &gs.ChatService)
 
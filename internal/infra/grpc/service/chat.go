@@ -1,62 +1,107 @@
 package service
 
 import (
+	"encoding/json"
+
 	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/pb"
 	chatcompletionstream "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion_stream"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
 )
 
 type ChatService struct {
 	pb.UnimplementedChatServiceServer
 	ChatCompletionStreamUseCase chatcompletionstream.ChatCompletionUseCase
 	ChatConfig                  chatcompletionstream.ChatCompletionConfigInputDTO
-	StreamChannel               chan chatcompletionstream.ChatCompletionOutputDTO
+	ListChatsUseCase            chathistory.ListChatsUseCase
+	ListMessagesUseCase         chathistory.ListMessagesUseCase
+	SearchMessagesUseCase       chatsearch.SearchMessagesUseCase
 }
 
-func NewChatService(usecase chatcompletionstream.ChatCompletionUseCase, config chatcompletionstream.ChatCompletionConfigInputDTO, channel chan chatcompletionstream.ChatCompletionOutputDTO) *ChatService {
+func NewChatService(usecase chatcompletionstream.ChatCompletionUseCase, config chatcompletionstream.ChatCompletionConfigInputDTO, listChats chathistory.ListChatsUseCase, listMessages chathistory.ListMessagesUseCase, search chatsearch.SearchMessagesUseCase) *ChatService {
 	return &ChatService{
 		ChatCompletionStreamUseCase: usecase,
 		ChatConfig:                  config,
-		StreamChannel:               channel,
+		ListChatsUseCase:            listChats,
+		ListMessagesUseCase:         listMessages,
+		SearchMessagesUseCase:       search,
 	}
 }
 
 func (c *ChatService) ChatStream(req *pb.ChatRequest, stream pb.ChatService_ChatStreamServer) error {
 	chatConfig := chatcompletionstream.ChatCompletionConfigInputDTO{
-		Model:                c.ChatConfig.Model,
-		ModelMaxTokens:       c.ChatConfig.ModelMaxTokens,
-		Temperature:          c.ChatConfig.Temperature,
-		TopP:                 c.ChatConfig.TopP,
-		N:                    c.ChatConfig.N,
-		Stop:                 c.ChatConfig.Stop,
-		MaxTokens:            c.ChatConfig.MaxTokens,
-		InitialSystemMessage: c.ChatConfig.InitialSystemMessage,
+		Model:                 c.ChatConfig.Model,
+		ModelMaxTokens:        c.ChatConfig.ModelMaxTokens,
+		Temperature:           c.ChatConfig.Temperature,
+		TopP:                  c.ChatConfig.TopP,
+		N:                     c.ChatConfig.N,
+		Stop:                  c.ChatConfig.Stop,
+		MaxTokens:             c.ChatConfig.MaxTokens,
+		InitialSystemMessage:  c.ChatConfig.InitialSystemMessage,
+		SchemaMaxRetries:      c.ChatConfig.SchemaMaxRetries,
+		DefaultPromptTemplate: c.ChatConfig.DefaultPromptTemplate,
 	}
 
 	input := chatcompletionstream.ChatCompletionInputDTO{
 		UserMessage: req.GetUserMessage(),
 		UserID: req.GetUserId(),
 		ChatID: req.GetChatId(),
+		KnowledgeBaseID: req.GetKnowledgeBaseId(),
+		ResponseSchema: json.RawMessage(req.GetResponseSchema()),
+		PromptTemplate: req.GetPromptTemplate(),
+		PromptTemplateVersion: int(req.GetPromptTemplateVersion()),
+		TemplateVariables: req.GetTemplateVariables(),
+		AssistantID: req.GetAssistantId(),
 		Config: chatConfig,
 	}
 
 	ctx := stream.Context()
 
-	//le o tudo que é recebido no canal, os dados sao enviados pelo usecase (ChatCompletionStreamUseCase.execute(ctx,input))
+	//canal somente desta chamada: le tudo que o usecase envia (ChatCompletionStreamUseCase.Execute) e manda para o cliente
+	channel := make(chan chatcompletionstream.ChatCompletionOutputDTO)
+	sent := make(chan struct{})
 	go func ()  {
-		for msg := range c.StreamChannel {
-			stream.Send(&pb.ChatResponse{
+		defer close(sent)
+		for msg := range channel {
+			res := &pb.ChatResponse{
 				ChatId: msg.ChatID,
 				UserId: msg.UserID,
 				Content: msg.Content,
-			})
+				Citations: citations(msg.Citations),
+			}
+			if len(msg.Object) > 0 {
+				object := string(msg.Object)
+				res.Object = &object
+			}
+			//cliente desconectado: continua lendo o canal para o usecase nao travar, o ctx cancela a resposta
+			stream.Send(res)
 		}
 	}()
 
-	//envia as respostas do chat gpt para o canal
-	_,err := c.ChatCompletionStreamUseCase.Execute(ctx,input)
+	//envia as respostas do chat gpt para o canal, fechado quando o usecase termina
+	_,err := c.ChatCompletionStreamUseCase.Execute(ctx,input,channel)
+	close(channel)
+	<-sent
 	if err != nil {
-		return err
+		return ToStatusError(err)
 	}
 
 	return nil
 }
+
+
+func citations(citations []chatcompletionstream.CitationOutputDTO) []*pb.Citation {
+	res := make([]*pb.Citation, len(citations))
+	for i, c := range citations {
+		res[i] = &pb.Citation{
+			Index:      int32(c.Index),
+			DocumentId: c.DocumentID,
+			Title:      c.Title,
+			ChunkIndex: int32(c.ChunkIndex),
+			Start:      int32(c.Start),
+			End:        int32(c.End),
+			Score:      c.Score,
+		}
+	}
+	return res
+}
\ No newline at end of file
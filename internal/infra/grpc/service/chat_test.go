@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/pb"
+	chatcompletionstream "github.com/ruhancs/virtual-assistant/internal/usecase/chat_completion_stream"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
+	openai "github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc"
+)
+
+// memoryChats chats em memoria, no lugar do repositorio
+type memoryChats struct {
+	gateway.ChatGateway
+	mu    sync.Mutex
+	chats map[string]*entity.Chat
+}
+
+func (m *memoryChats) FindChatByID(ctx context.Context, chatID string) (*entity.Chat, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if chat, ok := m.chats[chatID]; ok {
+		return chat, nil
+	}
+	return nil, entity.ErrChatNotFound
+}
+
+func (m *memoryChats) CreateChat(ctx context.Context, chat *entity.Chat) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chats[chat.ID] = chat
+	return nil
+}
+
+func (m *memoryChats) SaveChat(ctx context.Context, chat *entity.Chat) error {
+	return nil
+}
+
+// echoProvider responde em stream repetindo a ultima msg do usuario, uma palavra por pedaco
+func echoProvider(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+			return
+		}
+		word := request.Messages[len(request.Messages)-1].Content
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 20; i++ {
+			chunk := openai.ChatCompletionStreamResponse{
+				ID:      "chunk",
+				Object:  "chat.completion.chunk",
+				Model:   request.Model,
+				Choices: []openai.ChatCompletionStreamChoice{{Delta: openai.ChatCompletionStreamChoiceDelta{Content: word + " "}}},
+			}
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+// recordingStream guarda as respostas enviadas ao cliente
+type recordingStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	mu        sync.Mutex
+	responses []*pb.ChatResponse
+}
+
+func (s *recordingStream) Context() context.Context { return s.ctx }
+
+func (s *recordingStream) Send(res *pb.ChatResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, res)
+	return nil
+}
+
+func (s *recordingStream) sent() []*pb.ChatResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pb.ChatResponse(nil), s.responses...)
+}
+
+func TestChatStreamUsesOneChannelPerCall(t *testing.T) {
+	provider := echoProvider(t)
+	defer provider.Close()
+	config := openai.DefaultConfig("test")
+	config.BaseURL = provider.URL + "/v1"
+	usecase := chatcompletionstream.NewChatCompletionUseCase(&memoryChats{chats: map[string]*entity.Chat{}}, openai.NewClientWithConfig(config), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	service := NewChatService(*usecase, chatcompletionstream.ChatCompletionConfigInputDTO{
+		Model:                "gpt-3.5-turbo",
+		ModelMaxTokens:       4096,
+		MaxTokens:            100,
+		InitialSystemMessage: "echo the user",
+	}, chathistory.ListChatsUseCase{}, chathistory.ListMessagesUseCase{}, chatsearch.SearchMessagesUseCase{})
+
+	words := []string{"alpha", "beta", "gamma", "delta"}
+	streams := make([]*recordingStream, len(words))
+	var wg sync.WaitGroup
+	for i, word := range words {
+		streams[i] = &recordingStream{ctx: context.Background()}
+		wg.Add(1)
+		go func(word string, stream *recordingStream) {
+			defer wg.Done()
+			if err := service.ChatStream(&pb.ChatRequest{UserId: "user-" + word, UserMessage: word}, stream); err != nil {
+				t.Error(err)
+			}
+		}(word, streams[i])
+	}
+	wg.Wait()
+
+	for i, word := range words {
+		responses := streams[i].sent()
+		if len(responses) != 20 {
+			t.Fatalf("%s: expected 20 chunks, got %d", word, len(responses))
+		}
+		for _, res := range responses {
+			if res.UserId != "user-"+word || strings.Trim(strings.ReplaceAll(res.Content, word, ""), " ") != "" {
+				t.Fatalf("%s: got a chunk of another call: %+v", word, res)
+			}
+		}
+	}
+
+	//nada é enviado depois que o ChatStream retorna, nem com outra chamada usando o servico
+	if err := service.ChatStream(&pb.ChatRequest{UserId: "user-late", UserMessage: "late"}, &recordingStream{ctx: context.Background()}); err != nil {
+		t.Fatal(err)
+	}
+	for i, word := range words {
+		if n := len(streams[i].sent()); n != 20 {
+			t.Fatalf("%s: finished stream received more chunks, got %d", word, n)
+		}
+	}
+}
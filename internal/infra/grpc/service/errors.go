@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "virtual-assistant"
+
+// grpcError converte os erros do dominio em codes do grpc e no reason do ErrorInfo
+func grpcError(err error) (codes.Code, string) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, "CANCELED"
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, "DEADLINE_EXCEEDED"
+	case errors.Is(err, entity.ErrChatNotFound):
+		return codes.NotFound, "CHAT_NOT_FOUND"
+	case errors.Is(err, entity.ErrDocumentNotFound):
+		return codes.NotFound, "DOCUMENT_NOT_FOUND"
+	case errors.Is(err, entity.ErrPromptTemplateNotFound):
+		return codes.NotFound, "PROMPT_TEMPLATE_NOT_FOUND"
+	case errors.Is(err, entity.ErrAssistantNotFound):
+		return codes.NotFound, "ASSISTANT_NOT_FOUND"
+	case errors.Is(err, entity.ErrTenantNotFound):
+		return codes.NotFound, "TENANT_NOT_FOUND"
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		return codes.NotFound, "API_KEY_NOT_FOUND"
+	case errors.Is(err, entity.ErrUnauthenticated):
+		return codes.Unauthenticated, "UNAUTHENTICATED"
+	case errors.Is(err, entity.ErrForbidden),
+		errors.Is(err, entity.ErrMissingScope):
+		return codes.PermissionDenied, "FORBIDDEN"
+	case errors.Is(err, entity.ErrChatEnded):
+		return codes.FailedPrecondition, "CHAT_ENDED"
+	case errors.Is(err, entity.ErrInvalidChat),
+		errors.Is(err, entity.ErrInvalidConfig),
+		errors.Is(err, entity.ErrInvalidMessage),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, entity.ErrInvalidDocument),
+		errors.Is(err, entity.ErrInvalidPromptTemplate),
+		errors.Is(err, entity.ErrInvalidAssistant),
+		errors.Is(err, entity.ErrInvalidTenant),
+		errors.Is(err, entity.ErrInvalidAPIKey):
+		return codes.InvalidArgument, "INVALID_ARGUMENT"
+	case errors.Is(err, entity.ErrContextOverflow):
+		return codes.OutOfRange, "CONTEXT_OVERFLOW"
+	case errors.Is(err, entity.ErrContentRejected):
+		return codes.FailedPrecondition, "CONTENT_REJECTED"
+	case errors.Is(err, entity.ErrQuotaExceeded):
+		return codes.ResourceExhausted, "QUOTA_EXCEEDED"
+	case errors.Is(err, entity.ErrProviderRateLimited):
+		return codes.ResourceExhausted, "PROVIDER_RATE_LIMITED"
+	case errors.Is(err, entity.ErrProviderUnavailable):
+		return codes.Unavailable, "PROVIDER_UNAVAILABLE"
+	case errors.Is(err, entity.ErrProviderRejected):
+		return codes.FailedPrecondition, "PROVIDER_REJECTED"
+	case errors.Is(err, entity.ErrInvalidOutput):
+		return codes.Aborted, "INVALID_OUTPUT"
+	}
+	return codes.Internal, "INTERNAL"
+}
+
+// ToStatusError converte o erro do dominio no status do grpc, usado tambem pelos interceptors do servidor
+func ToStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	code, reason := grpcError(err)
+	message := err.Error()
+	//nao expor detalhes de erros internos (db, etc) para o cliente
+	if code == codes.Internal {
+		message = "internal server error"
+	}
+
+	st := status.New(code, message)
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
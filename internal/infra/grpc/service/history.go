@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/pb"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *ChatService) ListChats(ctx context.Context, req *pb.ListChatsRequest) (*pb.ListChatsResponse, error) {
+	result, err := c.ListChatsUseCase.Execute(ctx, chathistory.ListChatsInputDTO{
+		UserID: req.GetUserId(),
+		Cursor: req.GetCursor(),
+		Limit:  int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+
+	res := &pb.ListChatsResponse{NextCursor: result.NextCursor}
+	for _, chat := range result.Chats {
+		res.Chats = append(res.Chats, &pb.ChatSummary{
+			ChatId:     chat.ChatID,
+			UserId:     chat.UserID,
+			Status:     chat.Status,
+			Model:      chat.Model,
+			TokenUsage: int32(chat.TokenUsage),
+			CreatedAt:  timestamppb.New(chat.CreatedAt),
+			UpdatedAt:  timestamppb.New(chat.UpdatedAt),
+
+			PromptTemplate:  chat.PromptTemplate,
+			TemplateVersion: int32(chat.TemplateVersion),
+			AssistantId:     chat.AssistantID,
+		})
+	}
+	return res, nil
+}
+
+func (c *ChatService) ListMessages(ctx context.Context, req *pb.ListMessagesRequest) (*pb.ListMessagesResponse, error) {
+	result, err := c.ListMessagesUseCase.Execute(ctx, chathistory.ListMessagesInputDTO{
+		ChatID: req.GetChatId(),
+		UserID: req.GetUserId(),
+		Cursor: req.GetCursor(),
+		Limit:  int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+
+	res := &pb.ListMessagesResponse{ChatId: result.ChatID, NextCursor: result.NextCursor}
+	for _, msg := range result.Messages {
+		res.Messages = append(res.Messages, &pb.HistoryMessage{
+			Id:        msg.ID,
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Tokens:    int32(msg.Tokens),
+			Erased:    msg.Erased,
+			CreatedAt: timestamppb.New(msg.CreatedAt),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/pb"
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (c *ChatService) SearchMessages(ctx context.Context, req *pb.SearchMessagesRequest) (*pb.SearchMessagesResponse, error) {
+	result, err := c.SearchMessagesUseCase.Execute(ctx, chatsearch.SearchMessagesInputDTO{
+		UserID: req.GetUserId(),
+		Query:  req.GetQuery(),
+		ChatID: req.GetChatId(),
+		Role:   req.GetRole(),
+		From:   timestampOrZero(req.GetFrom()),
+		To:     timestampOrZero(req.GetTo()),
+		Cursor: req.GetCursor(),
+		Limit:  int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+
+	res := &pb.SearchMessagesResponse{NextCursor: result.NextCursor}
+	for _, r := range result.Results {
+		res.Results = append(res.Results, &pb.SearchResult{
+			ChatId:    r.ChatID,
+			MessageId: r.MessageID,
+			Role:      r.Role,
+			Snippet:   r.Snippet,
+			Erased:    r.Erased,
+			Score:     r.Score,
+			CreatedAt: timestamppb.New(r.CreatedAt),
+		})
+	}
+	return res, nil
+}
+
+func timestampOrZero(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,446 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// versao do protocolo enviada no initialize
+	protocolVersion = "2024-11-05"
+	// tempo maximo do handshake de initialize
+	startTimeout = 30 * time.Second
+	// quedas seguidas sem chamada com sucesso antes de marcar o servidor como falho
+	maxRestarts = 3
+)
+
+var (
+	ErrClosed = errors.New("tool server closed")
+	ErrFailed = errors.New("tool server failed")
+)
+
+// RPCError erro retornado pelo servidor no formato json-rpc
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// message qualquer msg recebida do servidor: resposta, request ou notificacao
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// ToolInfo ferramenta anunciada pelo servidor no tools/list
+type ToolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// Client servidor de ferramentas rodando como subprocesso, json-rpc com uma msg json por linha no stdin/stdout.
+// O servidor que cai é iniciado de novo na proxima chamada, depois de maxRestarts quedas seguidas
+// sem nenhuma chamada com sucesso ele fica marcado como falho e as chamadas retornam ErrFailed
+type Client struct {
+	Name string
+
+	config ServerConfig
+	ctx    context.Context // vida do servidor, cancelado mata o processo
+
+	mu       sync.Mutex
+	conn     *conn
+	restarts int
+	closed   bool
+	failed   error
+}
+
+// conn uma execucao do subprocesso
+type conn struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	done    chan struct{}
+	err     error
+}
+
+// Start inicia o servidor e faz o handshake de initialize. ctx controla a vida do servidor,
+// quando ele é cancelado o processo é morto; a inicializacao é limitada a startTimeout
+func Start(ctx context.Context, config ServerConfig) (*Client, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("tool server %s: command is empty", config.Name)
+	}
+	c := &Client{
+		Name:   config.Name,
+		config: config,
+		ctx:    ctx,
+	}
+	conn, err := c.start()
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	return c, nil
+}
+
+func (c *Client) start() (*conn, error) {
+	cmd := exec.CommandContext(c.ctx, c.config.Command, c.config.Args...)
+	cmd.Dir = c.config.Dir
+	cmd.Env = os.Environ()
+	for k, v := range c.config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting tool server %s: %w", c.Name, err)
+	}
+
+	conn := &conn{
+		name:    c.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go conn.readLoop(stdout)
+
+	ctx, cancel := context.WithTimeout(c.ctx, startTimeout)
+	defer cancel()
+	if err := conn.initialize(ctx); err != nil {
+		conn.close()
+		return nil, fmt.Errorf("error initializing tool server %s: %w", c.Name, err)
+	}
+	return conn, nil
+}
+
+// connection conexao ativa, reiniciando o servidor que caiu
+func (c *Client) connection() (*conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, fmt.Errorf("%w: %s", ErrClosed, c.Name)
+	}
+	if c.failed != nil {
+		return nil, c.failed
+	}
+	select {
+	case <-c.conn.done:
+	default:
+		return c.conn, nil
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	crash := c.conn.closedErr()
+	c.conn.close()
+	if c.restarts >= maxRestarts {
+		c.failed = fmt.Errorf("%w: %s stopped %d times in a row: %s", ErrFailed, c.Name, c.restarts+1, crash)
+		slog.Error("tool server failed", slog.String("server", c.Name), slog.String("error", crash.Error()))
+		return nil, c.failed
+	}
+	c.restarts++
+	slog.Warn("restarting tool server", slog.String("server", c.Name), slog.Int("restart", c.restarts), slog.String("error", crash.Error()))
+	conn, err := c.start()
+	if err != nil {
+		//o proximo pedido tenta de novo ate o limite
+		c.conn.mu.Lock()
+		c.conn.err = err
+		c.conn.mu.Unlock()
+		return nil, err
+	}
+	c.conn = conn
+	return conn, nil
+}
+
+// Err erro que marcou o servidor como falho, nil enquanto ele pode ser usado
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failed
+}
+
+func (c *Client) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	err = conn.call(ctx, method, params, out)
+	//resposta do servidor, mesmo com erro json-rpc, mostra que ele esta de pe
+	var rpcErr *RPCError
+	if err == nil || errors.As(err, &rpcErr) {
+		c.mu.Lock()
+		c.restarts = 0
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *conn) initialize(ctx context.Context) error {
+	params := map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]string{
+			"name":    "virtual-assistant",
+			"version": "1.0.0",
+		},
+	}
+	if err := c.call(ctx, "initialize", params, nil); err != nil {
+		return err
+	}
+	return c.notify("notifications/initialized", nil)
+}
+
+// ListTools ferramentas do servidor, seguindo a paginacao por cursor
+func (c *Client) ListTools(ctx context.Context) ([]ToolInfo, error) {
+	var res []ToolInfo
+	cursor := ""
+	for {
+		params := map[string]string{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page struct {
+			Tools      []ToolInfo `json:"tools"`
+			NextCursor string     `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		res = append(res, page.Tools...)
+		if page.NextCursor == "" {
+			return res, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool executa a ferramenta e junta o conteudo texto do resultado.
+// Resultado marcado como isError volta como erro com o texto do servidor
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	params := map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	}
+	var result struct {
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", params, &result); err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			parts = append(parts, content.Text)
+			continue
+		}
+		//imagens e outros binarios nao sao enviados ao modelo
+		parts = append(parts, fmt.Sprintf("[%s content %s omitted]", content.Type, content.MimeType))
+	}
+	text := strings.Join(parts, "\n")
+	if result.IsError {
+		if text == "" {
+			text = "tool returned an error"
+		}
+		return "", errors.New(text)
+	}
+	return text, nil
+}
+
+// Close encerra o servidor, as chamadas seguintes retornam ErrClosed
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.conn.close()
+	return nil
+}
+
+// close encerra o stdin e espera o servidor sair, depois de 2s o processo é morto
+func (c *conn) close() {
+	c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		c.cmd.Process.Kill()
+		<-c.done
+	}
+	c.cmd.Wait()
+}
+
+func (c *conn) call(ctx context.Context, method string, params interface{}, out interface{}) error {
+	ch := make(chan *message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(request{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(msg.Result, out); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	case <-c.done:
+		return c.closedErr()
+	case <-ctx.Done():
+		//avisa o servidor para parar o trabalho, a resposta que chegar depois é descartada
+		c.notify("notifications/cancelled", map[string]interface{}{
+			"requestId": id,
+			"reason":    ctx.Err().Error(),
+		})
+		return ctx.Err()
+	}
+}
+
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(request{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *conn) write(req interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.stdin.Write(b); err != nil {
+		return fmt.Errorf("%w: %s", ErrClosed, err)
+	}
+	return nil
+}
+
+func (c *conn) readLoop(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	var readErr error
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			c.handle(line)
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+
+	c.mu.Lock()
+	if errors.Is(readErr, io.EOF) {
+		c.err = fmt.Errorf("%w: %s", ErrClosed, c.name)
+	} else {
+		c.err = fmt.Errorf("%w: %s: %s", ErrClosed, c.name, readErr)
+	}
+	c.mu.Unlock()
+	close(c.done)
+}
+
+func (c *conn) handle(line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		//linhas que nao sao json (logs no stdout) sao ignoradas
+		return
+	}
+
+	if msg.Method != "" {
+		//requests do servidor, so o ping é suportado. Notificacoes nao tem id e sao ignoradas
+		if len(msg.ID) == 0 {
+			return
+		}
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID}
+		if msg.Method == "ping" {
+			res["result"] = map[string]interface{}{}
+		} else {
+			res["error"] = RPCError{Code: -32601, Message: "method not found"}
+		}
+		c.write(res)
+		return
+	}
+
+	id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	c.mu.Unlock()
+	if ok {
+		select {
+		case ch <- &msg:
+		default:
+		}
+	}
+}
+
+func (c *conn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
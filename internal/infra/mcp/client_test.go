@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+// buildToolServer compila o cmd/toolserver de exemplo para os testes
+func buildToolServer(t *testing.T) string {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found")
+	}
+	bin := filepath.Join(t.TempDir(), "toolserver")
+	out, err := exec.Command(goBin, "build", "-o", bin, "../../../cmd/toolserver").CombinedOutput()
+	if err != nil {
+		t.Fatalf("error building toolserver: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func startToolServer(t *testing.T, ctx context.Context, bin string) *Client {
+	t.Helper()
+	client, err := Start(ctx, ServerConfig{Name: "sample", Command: bin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// kill derruba o processo atual, como uma queda do servidor
+func kill(t *testing.T, client *Client) {
+	t.Helper()
+	client.mu.Lock()
+	conn := client.conn
+	client.mu.Unlock()
+	conn.cmd.Process.Kill()
+	<-conn.done
+}
+
+func TestClientCallsToolServer(t *testing.T) {
+	bin := buildToolServer(t)
+	client := startToolServer(t, context.Background(), bin)
+	ctx := context.Background()
+
+	infos, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 5 {
+		t.Fatalf("expected the 5 sample tools, got %d", len(infos))
+	}
+
+	text, err := client.CallTool(ctx, "echo", []byte(`{"text":"hello"}`))
+	if err != nil || text != "hello" {
+		t.Fatalf("expected hello, got %q %v", text, err)
+	}
+	text, err = client.CallTool(ctx, "add", []byte(`{"numbers":[1,2,3.5]}`))
+	if err != nil || text != "6.5" {
+		t.Fatalf("expected 6.5, got %q %v", text, err)
+	}
+	if _, err := client.CallTool(ctx, "fail", nil); err == nil || err.Error() != "this tool always fails" {
+		t.Fatalf("expected the tool error, got %v", err)
+	}
+	var rpcErr *RPCError
+	if _, err := client.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) {
+		t.Fatalf("expected a json-rpc error for an unknown tool, got %v", err)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallTool(callCtx, "sleep", []byte(`{"seconds":5}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the call to time out, got %v", err)
+	}
+}
+
+func TestRegisterToolsAllowList(t *testing.T) {
+	bin := buildToolServer(t)
+	config := ServerConfig{Name: "sample", Command: bin, Allow: []string{"echo", "word_*"}}
+	client := startToolServer(t, context.Background(), bin)
+
+	registry := tools.NewRegistry(0)
+	if err := RegisterTools(context.Background(), registry, client, config); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range registry.Tools() {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 2 || names[0] != "sample_echo" || names[1] != "sample_word_count" {
+		t.Fatalf("expected only the allowed tools, got %v", names)
+	}
+}
+
+func TestClientRestartsCrashedServer(t *testing.T) {
+	bin := buildToolServer(t)
+	client := startToolServer(t, context.Background(), bin)
+	ctx := context.Background()
+
+	kill(t, client)
+	text, err := client.CallTool(ctx, "echo", []byte(`{"text":"back"}`))
+	if err != nil || text != "back" {
+		t.Fatalf("expected the restarted server to answer, got %q %v", text, err)
+	}
+	if client.restarts != 0 {
+		t.Fatalf("expected the restart count to reset after a successful call, got %d", client.restarts)
+	}
+}
+
+func TestClientMarksServerAsFailed(t *testing.T) {
+	bin := buildToolServer(t)
+	client := startToolServer(t, context.Background(), bin)
+
+	//quedas seguidas sem nenhuma chamada com sucesso
+	for i := 0; i < maxRestarts; i++ {
+		kill(t, client)
+		if _, err := client.connection(); err != nil {
+			t.Fatalf("restart %d: %v", i+1, err)
+		}
+	}
+	kill(t, client)
+	if _, err := client.CallTool(context.Background(), "echo", []byte(`{"text":"x"}`)); !errors.Is(err, ErrFailed) {
+		t.Fatalf("expected ErrFailed, got %v", err)
+	}
+	if !errors.Is(client.Err(), ErrFailed) {
+		t.Fatalf("expected the client to report the failure, got %v", client.Err())
+	}
+}
+
+func TestClientContextStopsServer(t *testing.T) {
+	bin := buildToolServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	client := startToolServer(t, ctx, bin)
+
+	cancel()
+	select {
+	case <-client.conn.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server still running after the context was cancelled")
+	}
+	if _, err := client.CallTool(context.Background(), "echo", []byte(`{"text":"x"}`)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	client.Close()
+	if _, err := client.CallTool(context.Background(), "echo", []byte(`{"text":"x"}`)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+}
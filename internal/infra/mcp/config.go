@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config arquivo TOOLS_FILE com os servidores de ferramentas externos e os perfis de ferramentas por chat.
+// O perfil do chat vem do assistente ou do tenant, sem nenhum vale o default; ferramenta fora dos perfis nao é liberada
+//
+//	{
+//	  "servers": [{"name": "files", "command": "./toolserver", "timeout": "10s", "tool_timeouts": {"slow": "1m"}, "allow": ["read_*"]}],
+//	  "profiles": {"default": ["current_time", "files_*"], "support": ["files_read_*"]}
+//	}
+type Config struct {
+	Servers  []ServerConfig      `json:"servers"`
+	Profiles map[string][]string `json:"profiles"`
+}
+
+// ServerConfig servidor de ferramentas iniciado como subprocesso.
+// As ferramentas sao registradas como <name>_<ferramenta>, Allow filtra pelo nome original (vazio libera todas)
+type ServerConfig struct {
+	Name         string              `json:"name"`
+	Command      string              `json:"command"`
+	Args         []string            `json:"args"`
+	Env          map[string]string   `json:"env"`
+	Dir          string              `json:"dir"`
+	Timeout      Duration            `json:"timeout"`
+	ToolTimeouts map[string]Duration `json:"tool_timeouts"`
+	Allow        []string            `json:"allow"`
+}
+
+// Duration duracao no formato do time.ParseDuration (ex: 30s)
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tools file: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("invalid tools file %s: %w", path, err)
+	}
+	names := map[string]bool{}
+	for _, server := range config.Servers {
+		if server.Name == "" {
+			return nil, fmt.Errorf("invalid tools file %s: server without name", path)
+		}
+		if names[server.Name] {
+			return nil, fmt.Errorf("invalid tools file %s: duplicated server %s", path, server.Name)
+		}
+		names[server.Name] = true
+	}
+	return &config, nil
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// RegisterTools descobre as ferramentas do servidor e registra as liberadas no registry
+func RegisterTools(ctx context.Context, registry *tools.Registry, client *Client, config ServerConfig) error {
+	infos, err := client.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing tools of %s: %w", config.Name, err)
+	}
+	for _, info := range infos {
+		if len(config.Allow) > 0 && !tools.Match(config.Allow, info.Name) {
+			continue
+		}
+		timeout := time.Duration(config.Timeout)
+		if t, ok := config.ToolTimeouts[info.Name]; ok {
+			timeout = time.Duration(t)
+		}
+		name := info.Name
+		err := registry.Register(tools.Tool{
+			Name:        toolName(config.Name, info.Name),
+			Description: info.Description,
+			Parameters:  info.InputSchema,
+			Timeout:     timeout,
+			Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+				return client.CallTool(ctx, name, arguments)
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toolName nome com o prefixo do servidor, ajustado ao formato aceito pela api (letras, numeros, _ e -, ate 64)
+func toolName(server string, tool string) string {
+	name := invalidNameChars.ReplaceAllString(server+"_"+tool, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
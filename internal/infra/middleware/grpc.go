@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/grpc/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC opcoes do servidor grpc com a cadeia nas rpcs unary e stream. policies tem a regra de cada rpc
+// pelo nome completo do metodo, rpc sem policy é recusada
+func (m *Middleware) GRPC(policies map[string]Policy) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(m.unaryRequestID, m.unaryLogging, m.unaryRecover, m.unaryAuth(policies)),
+		grpc.ChainStreamInterceptor(m.streamRequestID, m.streamLogging, m.streamRecover, m.streamAuth(policies)),
+	}
+}
+
+// wrappedStream troca o contexto do stream pelo contexto com os dados dos interceptors
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (m *Middleware) unaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, id := withRequestID(ctx, incomingRequestID(ctx))
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+	return handler(ctx, req)
+}
+
+func (m *Middleware) streamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, id := withRequestID(ss.Context(), incomingRequestID(ss.Context()))
+	ss.SetHeader(metadata.Pairs(RequestIDHeader, id))
+	return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (m *Middleware) unaryLogging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, authenticated := withAuthTracker(ctx)
+	resp, err := handler(ctx, req)
+	m.logRPC(authenticated.context(ctx), info.FullMethod, start, err)
+	return resp, err
+}
+
+func (m *Middleware) streamLogging(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, authenticated := withAuthTracker(ss.Context())
+	err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	m.logRPC(authenticated.context(ctx), info.FullMethod, start, err)
+	return err
+}
+
+func (m *Middleware) logRPC(ctx context.Context, method string, start time.Time, err error) {
+	attrs := append([]any{
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	}, logAttrs(ctx)...)
+	m.Logger.InfoContext(ctx, "grpc request", attrs...)
+	access := Access{Status: status.Code(err).String(), DurationMS: time.Since(start).Milliseconds()}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		access.RemoteAddr = p.Addr.String()
+	}
+	m.recordAccess(ctx, entity.AuditAccess, method, "", access)
+}
+
+func (m *Middleware) unaryRecover(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = m.recovered(ctx, info.FullMethod, rec)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (m *Middleware) streamRecover(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = m.recovered(ss.Context(), info.FullMethod, rec)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func (m *Middleware) recovered(ctx context.Context, method string, rec interface{}) error {
+	m.Logger.ErrorContext(ctx, "panic serving grpc request",
+		slog.String("method", method), slog.Any("panic", rec), slog.String("request_id", RequestIDFromContext(ctx)), slog.String("stack", string(debug.Stack())))
+	return service.ToStatusError(fmt.Errorf("panic: %v", rec))
+}
+
+func (m *Middleware) unaryAuth(policies map[string]Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := m.authenticate(ctx, policies, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (m *Middleware) streamAuth(policies map[string]Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := m.authenticate(ss.Context(), policies, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate autentica a rpc com a policy do metodo e checa a cota do tenant
+func (m *Middleware) authenticate(ctx context.Context, policies map[string]Policy, method string) (context.Context, error) {
+	policy, ok := policies[method]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "method is not allowed")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := md.Get("authorization")
+	var err error
+	if cert := peerClientCert(ctx); cert != nil && len(token) == 0 {
+		ctx, err = m.Auth.AuthenticateCertificate(ctx, cert, policy.Scope)
+	} else if len(token) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization token is not provided")
+	} else {
+		ctx, err = m.Auth.Authenticate(ctx, token[0], policy.Scope)
+	}
+	if err != nil {
+		return nil, service.ToStatusError(err)
+	}
+	trackAuth(ctx)
+	if policy.Quota {
+		if err := m.Usage.Check(ctx); err != nil {
+			return nil, service.ToStatusError(err)
+		}
+	}
+	return ctx, nil
+}
+
+// peerClientCert certificado verificado do cliente da conexao grpc (mtls)
+func peerClientCert(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return verifiedClientCert(&info.State)
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/web"
+)
+
+// HTTP middlewares aplicados em todas as rotas, na ordem
+func (m *Middleware) HTTP() []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{m.RequestID, m.Logging, m.Recover}
+}
+
+// Route middlewares da rota conforme a policy
+func (m *Middleware) Route(policy Policy) []func(http.Handler) http.Handler {
+	middlewares := []func(http.Handler) http.Handler{m.Authenticate(policy.Scope)}
+	if policy.Quota {
+		middlewares = append(middlewares, m.Quota)
+	}
+	return middlewares
+}
+
+func (m *Middleware) RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, id := withRequestID(r.Context(), r.Header.Get(RequestIDHeader))
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// maxAuditBody tamanho maximo do corpo das requisicoes de administracao gravado na auditoria
+const maxAuditBody = 64 << 10
+
+// Logging uma linha por requisicao com status e duracao, e o evento de acesso no log de auditoria
+func (m *Middleware) Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		//o usuario e o tenant so existem depois da autenticacao, que roda dentro da rota
+		ctx, authenticated := withAuthTracker(r.Context())
+		next.ServeHTTP(ww, r.WithContext(ctx))
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := append([]any{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+		}, logAttrs(authenticated.context(ctx))...)
+		m.Logger.InfoContext(r.Context(), "http request", attrs...)
+		if !authenticated.audited {
+			m.recordAccess(authenticated.context(ctx), entity.AuditAccess, routeAction(r), r.URL.Path, Access{
+				Status:     strconv.Itoa(status),
+				DurationMS: time.Since(start).Milliseconds(),
+				RemoteAddr: r.RemoteAddr,
+			})
+		}
+	})
+}
+
+// routeAction metodo e rota do chi ("POST /admin/tenants/{tenantID}"), o path quando a rota nao existe
+func routeAction(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return r.Method + " " + rctx.RoutePattern()
+	}
+	return r.Method + " " + r.URL.Path
+}
+
+// AdminOperation dados do evento de auditoria das rotas de administracao
+type AdminOperation struct {
+	Access
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// AuditAdmin grava as operacoes de administracao com o corpo da requisicao, no lugar do evento de acesso.
+// Roda depois do RequireToken, somente as operacoes autorizadas viram eventos de administracao
+func (m *Middleware) AuditAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.Audit == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		//erro de leitura fica para o handler, que recebe o que foi lido e o resto do corpo
+		body, _ := io.ReadAll(io.LimitReader(r.Body, maxAuditBody))
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		operation := AdminOperation{Access: Access{
+			Status:     strconv.Itoa(status),
+			DurationMS: time.Since(start).Milliseconds(),
+			RemoteAddr: r.RemoteAddr,
+		}}
+		//corpo que nao é json (ou foi cortado) vai como string
+		if len(body) > 0 {
+			operation.Body = body
+			if !json.Valid(body) {
+				operation.Body, _ = json.Marshal(string(body))
+			}
+		}
+		if tracker, ok := r.Context().Value(authTrackerContextKey{}).(*authTracker); ok {
+			tracker.audited = true
+		}
+		m.recordAccess(r.Context(), entity.AuditAdmin, routeAction(r), r.URL.Path, operation)
+	})
+}
+
+// Recover responde 500 no formato de erro da api quando o handler entra em panic
+func (m *Middleware) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				m.Logger.ErrorContext(r.Context(), "panic serving http request",
+					slog.Any("panic", rec), slog.String("request_id", RequestIDFromContext(r.Context())), slog.String("stack", string(debug.Stack())))
+				web.WriteDomainError(w, fmt.Errorf("panic: %v", rec))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authenticate valida o token (jwt, chave de api ou tokens estaticos), ou o certificado do cliente quando
+// a requisicao nao tem token, e coloca o usuario e o tenant no contexto
+func (m *Middleware) Authenticate(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var ctx context.Context
+			var err error
+			if cert := verifiedClientCert(r.TLS); cert != nil && r.Header.Get("Authorization") == "" {
+				ctx, err = m.Auth.AuthenticateCertificate(r.Context(), cert, scope)
+			} else {
+				ctx, err = m.Auth.Authenticate(r.Context(), r.Header.Get("Authorization"), scope)
+			}
+			if err != nil {
+				web.WriteDomainError(w, err)
+				return
+			}
+			trackAuth(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Admin middlewares das rotas de administracao: token de administracao e auditoria das operacoes
+func (m *Middleware) Admin(token string) []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{m.RequireToken(token), m.AuditAdmin}
+}
+
+// RequireToken rotas de administracao e de servico, aceitas somente com o token informado. Token vazio recusa tudo
+func (m *Middleware) RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := r.Header.Get("Authorization")
+			if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				web.WriteDomainError(w, entity.ErrUnauthenticated)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Quota recusa a requisicao quando o tenant ja usou a cota de tokens do mes
+func (m *Middleware) Quota(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Usage.Check(r.Context()); err != nil {
+			web.WriteDomainError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
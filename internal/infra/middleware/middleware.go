@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/auth"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+)
+
+// RequestIDHeader header http (e metadado grpc, em minusculas) com o id da requisicao
+const RequestIDHeader = "X-Request-Id"
+
+// Policy regras de uma rota http ou rpc: escopo exigido dos tokens de usuario e se a cota do tenant é checada
+type Policy struct {
+	Scope string
+	Quota bool
+}
+
+// Middleware cadeia unica do servico, aplicada como middleware do chi e como interceptors unary e stream do grpc:
+// id da requisicao, log, auditoria, recuperacao de panic, autenticacao e cota
+type Middleware struct {
+	Auth   *auth.Authenticator
+	Usage  *tenant.Usage // opcional, nil nao checa a cota dos tenants
+	Audit  *audit.Logger // opcional, nil nao grava os acessos no log de auditoria
+	Logger *slog.Logger
+}
+
+func New(authenticator *auth.Authenticator, usage *tenant.Usage, auditLogger *audit.Logger, logger *slog.Logger) *Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Middleware{
+		Auth:   authenticator,
+		Usage:  usage,
+		Audit:  auditLogger,
+		Logger: logger,
+	}
+}
+
+// RequestIDFromContext id da requisicao atual, vazio fora dos middlewares
+func RequestIDFromContext(ctx context.Context) string {
+	return entity.RequestID(ctx)
+}
+
+// withRequestID usa o id enviado pelo cliente ou gera um novo
+func withRequestID(ctx context.Context, id string) (context.Context, string) {
+	if id == "" || len(id) > 128 {
+		id = uuid.New().String()
+	}
+	return entity.ContextWithRequestID(ctx, id), id
+}
+
+// logAttrs identificacao do chamador nos logs
+func logAttrs(ctx context.Context) []any {
+	attrs := []any{slog.String("request_id", RequestIDFromContext(ctx))}
+	if principal := entity.PrincipalFromContext(ctx); principal != nil {
+		attrs = append(attrs, slog.String("user_id", principal.UserID))
+	}
+	if tenantID := entity.TenantID(ctx); tenantID != "" {
+		attrs = append(attrs, slog.String("tenant_id", tenantID))
+	}
+	return attrs
+}
+
+type authTrackerContextKey struct{}
+
+// authTracker guarda o contexto autenticado para o log, que roda fora da autenticacao da rota
+type authTracker struct {
+	ctx     context.Context
+	audited bool // a rota ja gravou o proprio evento de auditoria (rotas de administracao)
+}
+
+func withAuthTracker(ctx context.Context) (context.Context, *authTracker) {
+	tracker := &authTracker{}
+	return context.WithValue(ctx, authTrackerContextKey{}, tracker), tracker
+}
+
+func trackAuth(ctx context.Context) {
+	if tracker, ok := ctx.Value(authTrackerContextKey{}).(*authTracker); ok {
+		tracker.ctx = ctx
+	}
+}
+
+// context contexto autenticado, ou o fallback quando a requisicao nao foi autenticada
+func (t *authTracker) context(fallback context.Context) context.Context {
+	if t.ctx != nil {
+		return t.ctx
+	}
+	return fallback
+}
+
+// Access dados do evento de auditoria de acesso
+type Access struct {
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+}
+
+// recordAccess grava o acesso no log de auditoria, a falha na gravacao vai somente para o log
+func (m *Middleware) recordAccess(ctx context.Context, eventType string, action string, resource string, data interface{}) {
+	if err := m.Audit.Record(ctx, eventType, action, resource, data); err != nil {
+		m.Logger.ErrorContext(ctx, "audit record failed", append([]any{slog.String("error", err.Error())}, logAttrs(ctx)...)...)
+	}
+}
+
+// verifiedClientCert certificado do cliente validado pela ca no handshake (mtls), nil sem tls ou sem certificado
+func verifiedClientCert(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
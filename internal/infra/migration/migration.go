@@ -0,0 +1,321 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const createSchemaTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    dirty BOOLEAN NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+var ErrDirty = errors.New("database is dirty, fix the failed migration and run migrate force <version> applied|pending")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration *Migration
+	Applied   bool
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []*Migration
+	// mysql faz commit implicito em DDL, nesse caso a migration roda fora de transacao
+	// e fica marcada como dirty ate terminar. Se falhar no meio, os statements ja executados nao sao desfeitos:
+	// a versao continua dirty e o schema tem que ser conferido e corrigido a mao antes do Force
+	Transactional bool
+	Driver        string
+}
+
+func NewMigrator(database *sql.DB, driver string, files fs.FS) (*Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		DB:            database,
+		Migrations:    migrations,
+		Transactional: supportsTransactionalDDL(driver),
+		Driver:        driver,
+	}, nil
+}
+
+func supportsTransactionalDDL(driver string) bool {
+	switch driver {
+	case "sqlite", "sqlite3":
+		return true
+	}
+	return false
+}
+
+// Load le os arquivos NNNNNN_nome.up.sql / NNNNNN_nome.down.sql e ordena pela versao
+func Load(files fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		prefix, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{
+				Version: version,
+				Name:    strings.TrimSuffix(rest, "."+direction+".sql"),
+			}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m *Migrator) ensureSchemaTable(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, createSchemaTable)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]Status, error) {
+	rows, err := m.DB.QueryContext(ctx, "SELECT version, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := map[int64]Status{}
+	for rows.Next() {
+		var version int64
+		var s Status
+		if err := rows.Scan(&version, &s.Dirty, &s.AppliedAt); err != nil {
+			return nil, err
+		}
+		s.Applied = true
+		res[version] = s
+	}
+	return res, rows.Err()
+}
+
+// Version retorna a maior versao aplicada, 0 quando nenhuma migration rodou
+func (m *Migrator) Version(ctx context.Context) (int64, bool, error) {
+	if err := m.ensureSchemaTable(ctx); err != nil {
+		return 0, false, err
+	}
+	var version int64
+	var dirty bool
+	err := m.DB.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return version, dirty, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureSchemaTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		s := applied[migration.Version]
+		s.Migration = migration
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Up aplica todas as migrations pendentes em ordem e retorna as versoes aplicadas
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	if err := m.ensureSchemaTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range applied {
+		if s.Dirty {
+			return nil, ErrDirty
+		}
+	}
+
+	var done []int64
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, migration, migration.Up, true); err != nil {
+			return done, fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration.Version)
+	}
+	return done, nil
+}
+
+// Down desfaz as ultimas steps migrations aplicadas
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int64, error) {
+	if err := m.ensureSchemaTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range applied {
+		if s.Dirty {
+			return nil, ErrDirty
+		}
+	}
+
+	var done []int64
+	for i := len(m.Migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return done, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		if err := m.run(ctx, migration, migration.Down, false); err != nil {
+			return done, fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration.Version)
+	}
+	return done, nil
+}
+
+func (m *Migrator) run(ctx context.Context, migration *Migration, query string, up bool) error {
+	if m.Transactional {
+		tx, err := m.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+		if err := m.record(ctx, tx, migration.Version, up); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	//marca como dirty antes de rodar, se falhar no meio a versao fica travada ate correcao manual
+	if err := m.mark(ctx, m.DB, migration.Version, true); err != nil {
+		return err
+	}
+	if _, err := m.DB.ExecContext(ctx, query); err != nil {
+		return err
+	}
+	return m.record(ctx, m.DB, migration.Version, up)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (m *Migrator) record(ctx context.Context, db execer, version int64, up bool) error {
+	if !up {
+		_, err := db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", version)
+		return err
+	}
+	return m.mark(ctx, db, version, false)
+}
+
+// mark grava a versao e o flag dirty num statement so, a versao nunca some da tabela entre a marcacao e o fim da migration
+func (m *Migrator) mark(ctx context.Context, db execer, version int64, dirty bool) error {
+	query := "INSERT INTO schema_migrations (version, dirty, applied_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE dirty = ?, applied_at = ?"
+	if m.Transactional {
+		query = "INSERT INTO schema_migrations (version, dirty, applied_at) VALUES (?, ?, ?) ON CONFLICT (version) DO UPDATE SET dirty = ?, applied_at = ?"
+	}
+	now := time.Now()
+	_, err := db.ExecContext(ctx, query, version, dirty, now, dirty, now)
+	return err
+}
+
+// Force limpa a versao dirty depois da correcao manual do schema: applied grava a migration como aplicada,
+// senao ela volta a ficar pendente e roda de novo no proximo up
+func (m *Migrator) Force(ctx context.Context, version int64, applied bool) error {
+	if err := m.ensureSchemaTable(ctx); err != nil {
+		return err
+	}
+	found := false
+	for _, migration := range m.Migrations {
+		if migration.Version == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("migration %d does not exist", version)
+	}
+	return m.record(ctx, m.DB, version, applied)
+}
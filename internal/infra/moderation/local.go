@@ -0,0 +1,33 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// LocalModerator moderacao deterministica sem chamada externa, usada em testes e ambientes sem acesso a openai.
+// Marca somente os textos com o marcador "[moderation:<categoria>]", nao classifica o conteudo
+type LocalModerator struct{}
+
+var localMarker = regexp.MustCompile(`\[moderation:([a-z/-]+)\]`)
+
+func NewLocalModerator() *LocalModerator {
+	return &LocalModerator{}
+}
+
+func (m *LocalModerator) Moderate(ctx context.Context, text string) (*entity.ModerationResult, error) {
+	seen := map[string]bool{}
+	result := &entity.ModerationResult{}
+	for _, match := range localMarker.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			result.Categories = append(result.Categories, match[1])
+		}
+	}
+	sort.Strings(result.Categories)
+	result.Flagged = len(result.Categories) > 0
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// OpenAIModerator classifica o texto no endpoint de moderacao da openai
+type OpenAIModerator struct {
+	Client *openai.Client
+	Model  string
+}
+
+func NewOpenAIModerator(client *openai.Client, model string) *OpenAIModerator {
+	if model == "" {
+		model = openai.ModerationTextLatest
+	}
+	return &OpenAIModerator{
+		Client: client,
+		Model:  model,
+	}
+}
+
+func (m *OpenAIModerator) Moderate(ctx context.Context, text string) (*entity.ModerationResult, error) {
+	resp, err := m.Client.Moderations(ctx, openai.ModerationRequest{
+		Input: text,
+		Model: m.Model,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error moderating text: %w", err)
+	}
+	result := &entity.ModerationResult{}
+	for _, r := range resp.Results {
+		if !r.Flagged {
+			continue
+		}
+		result.Flagged = true
+		categories, err := flaggedCategories(r.Categories)
+		if err != nil {
+			return nil, err
+		}
+		result.Categories = append(result.Categories, categories...)
+	}
+	return result, nil
+}
+
+// flaggedCategories nomes das categorias marcadas, pelas tags json da api (hate, self-harm/intent, ...)
+func flaggedCategories(categories openai.ResultCategories) ([]string, error) {
+	data, err := json.Marshal(categories)
+	if err != nil {
+		return nil, err
+	}
+	var flags map[string]bool
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return nil, err
+	}
+	var res []string
+	for name, flagged := range flags {
+		if flagged {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
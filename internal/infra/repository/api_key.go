@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+type APIKeyRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewAPIKeyRepositoryMySql(database *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *entity.APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+	return r.Queries.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    key.KeyHash,
+		Scopes:     scopes,
+		ExpiresAt:  nullTime(key.ExpiresAt),
+		LastUsedAt: nullTime(key.LastUsedAt),
+		RevokedAt:  nullTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt,
+	})
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	row, err := r.Queries.FindAPIKeyByHash(ctx, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toAPIKeyEntity(row)
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	var rows []db.ApiKey
+	var err error
+	if userID == "" {
+		rows, err = r.Queries.ListAPIKeys(ctx)
+	} else {
+		rows, err = r.Queries.ListAPIKeysByUserID(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.APIKey, 0, len(rows))
+	for _, row := range rows {
+		key, err := toAPIKeyEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, key)
+	}
+	return res, nil
+}
+
+// RevokeAPIKey revoga a chave, chave inexistente ou ja revogada retorna ErrAPIKeyNotFound
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, keyID string, revokedAt time.Time) error {
+	affected, err := r.Queries.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		RevokedAt: sql.NullTime{Time: revokedAt, Valid: true},
+		ID:        keyID,
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, keyID string, usedAt time.Time) error {
+	return r.Queries.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+		LastUsedAt: sql.NullTime{Time: usedAt, Valid: true},
+		ID:         keyID,
+	})
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func toAPIKeyEntity(row db.ApiKey) (*entity.APIKey, error) {
+	var scopes []string
+	if err := json.Unmarshal(row.Scopes, &scopes); err != nil {
+		return nil, err
+	}
+	return &entity.APIKey{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		TenantID:   row.TenantID,
+		Name:       row.Name,
+		Prefix:     row.Prefix,
+		KeyHash:    row.KeyHash,
+		Scopes:     scopes,
+		ExpiresAt:  timePtr(row.ExpiresAt),
+		LastUsedAt: timePtr(row.LastUsedAt),
+		RevokedAt:  timePtr(row.RevokedAt),
+		CreatedAt:  row.CreatedAt,
+	}, nil
+}
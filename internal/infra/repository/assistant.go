@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// codigo do mysql para violacao de chave unica
+const mysqlDuplicateEntry = 1062
+
+type AssistantRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewAssistantRepositoryMySql(database *sql.DB) *AssistantRepository {
+	return &AssistantRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+func (r *AssistantRepository) CreateAssistant(ctx context.Context, assistant *entity.Assistant) error {
+	stop, err := json.Marshal(stopSequences(assistant.Config.Stop))
+	if err != nil {
+		return err
+	}
+	err = r.Queries.CreateAssistant(ctx, db.CreateAssistantParams{
+		ID:               assistant.ID,
+		Name:             assistant.Name,
+		Description:      assistant.Description,
+		Model:            assistant.Config.Model.Name,
+		ModelMaxTokens:   int32(assistant.Config.Model.MaxTokens),
+		Temperature:      float64(assistant.Config.Temperature),
+		TopP:             float64(assistant.Config.TopP),
+		N:                int32(assistant.Config.N),
+		Stop:             stop,
+		MaxTokens:        int32(assistant.Config.MaxTokens),
+		PresencePenalty:  float64(assistant.Config.PresencePenalty),
+		FrequencyPenalty: float64(assistant.Config.FrequencyPenalty),
+		SystemPrompt:     assistant.SystemPrompt,
+		PromptTemplate:   assistant.PromptTemplate,
+		ToolProfile:      assistant.ToolProfile,
+		KnowledgeBaseID:  assistant.KnowledgeBaseID,
+		CreatedAt:        assistant.CreatedAt,
+		UpdatedAt:        assistant.UpdatedAt,
+	})
+	return duplicateName(err, assistant.Name)
+}
+
+func (r *AssistantRepository) FindAssistantByID(ctx context.Context, assistantID string) (*entity.Assistant, error) {
+	row, err := r.Queries.FindAssistantByID(ctx, assistantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrAssistantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toAssistantEntity(row)
+}
+
+func (r *AssistantRepository) ListAssistants(ctx context.Context) ([]*entity.Assistant, error) {
+	rows, err := r.Queries.ListAssistants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.Assistant, 0, len(rows))
+	for _, row := range rows {
+		assistant, err := toAssistantEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, assistant)
+	}
+	return res, nil
+}
+
+func (r *AssistantRepository) SaveAssistant(ctx context.Context, assistant *entity.Assistant) error {
+	stop, err := json.Marshal(stopSequences(assistant.Config.Stop))
+	if err != nil {
+		return err
+	}
+	err = r.Queries.SaveAssistant(ctx, db.SaveAssistantParams{
+		Name:             assistant.Name,
+		Description:      assistant.Description,
+		Model:            assistant.Config.Model.Name,
+		ModelMaxTokens:   int32(assistant.Config.Model.MaxTokens),
+		Temperature:      float64(assistant.Config.Temperature),
+		TopP:             float64(assistant.Config.TopP),
+		N:                int32(assistant.Config.N),
+		Stop:             stop,
+		MaxTokens:        int32(assistant.Config.MaxTokens),
+		PresencePenalty:  float64(assistant.Config.PresencePenalty),
+		FrequencyPenalty: float64(assistant.Config.FrequencyPenalty),
+		SystemPrompt:     assistant.SystemPrompt,
+		PromptTemplate:   assistant.PromptTemplate,
+		ToolProfile:      assistant.ToolProfile,
+		KnowledgeBaseID:  assistant.KnowledgeBaseID,
+		UpdatedAt:        assistant.UpdatedAt,
+		ID:               assistant.ID,
+	})
+	return duplicateName(err, assistant.Name)
+}
+
+// DeleteAssistant remove o assistente, os chats dele continuam com a config copiada na criacao
+// mas novas msgs falham com ErrAssistantNotFound
+func (r *AssistantRepository) DeleteAssistant(ctx context.Context, assistantID string) error {
+	affected, err := r.Queries.DeleteAssistant(ctx, assistantID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrAssistantNotFound
+	}
+	return nil
+}
+
+func duplicateName(err error, name string) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return fmt.Errorf("%w: name %s already exists", entity.ErrInvalidAssistant, name)
+	}
+	return err
+}
+
+func toAssistantEntity(row db.Assistant) (*entity.Assistant, error) {
+	var stop []string
+	if err := json.Unmarshal(row.Stop, &stop); err != nil {
+		return nil, err
+	}
+	if len(stop) == 0 {
+		stop = nil
+	}
+	return &entity.Assistant{
+		ID:          row.ID,
+		Name:        row.Name,
+		Description: row.Description,
+		Config: &entity.ChatConfig{
+			Model: &entity.Model{
+				Name:      row.Model,
+				MaxTokens: int(row.ModelMaxTokens),
+			},
+			Temperature:      float32(row.Temperature),
+			TopP:             float32(row.TopP),
+			N:                int(row.N),
+			Stop:             stop,
+			MaxTokens:        int(row.MaxTokens),
+			PresencePenalty:  float32(row.PresencePenalty),
+			FrequencyPenalty: float32(row.FrequencyPenalty),
+		},
+		SystemPrompt:    row.SystemPrompt,
+		PromptTemplate:  row.PromptTemplate,
+		ToolProfile:     row.ToolProfile,
+		KnowledgeBaseID: row.KnowledgeBaseID,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}, nil
+}
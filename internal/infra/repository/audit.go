@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// mysqlDeadlock transacao escolhida como vitima de um deadlock, pode ser repetida
+const mysqlDeadlock = 1213
+
+type AuditRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewAuditRepositoryMySql(database *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+// AppendAuditEvent o ultimo evento é lido com FOR UPDATE na transacao da insercao, as gravacoes concorrentes
+// esperam o commit. Com o log vazio nao ha linha para travar, o seq repetido retorna ErrAuditConflict
+func (r *AuditRepository) AppendAuditEvent(ctx context.Context, event *entity.AuditEvent) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	var last *entity.AuditEvent
+	row, err := queries.FindLastAuditEvent(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		last = toAuditEventEntity(row)
+	}
+	event.Chain(last)
+
+	err = queries.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		Seq:       event.Seq,
+		ID:        event.ID,
+		Type:      event.Type,
+		UserID:    event.UserID,
+		TenantID:  event.TenantID,
+		RequestID: event.RequestID,
+		Action:    event.Action,
+		Resource:  event.Resource,
+		Data:      string(event.Data),
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+		CreatedAt: event.CreatedAt,
+	})
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && (mysqlErr.Number == mysqlDuplicateEntry || mysqlErr.Number == mysqlDeadlock) {
+		return entity.ErrAuditConflict
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	params := db.ListAuditEventsParams{
+		AfterSeq:    filter.AfterSeq,
+		Type:        filter.Type,
+		CreatedFrom: filter.From,
+		CreatedTo:   filter.To,
+		Limit:       int32(filter.Limit),
+	}
+	if params.CreatedFrom.IsZero() {
+		params.CreatedFrom = time.Unix(0, 0)
+	}
+	if params.CreatedTo.IsZero() {
+		params.CreatedTo = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	rows, err := r.Queries.ListAuditEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, toAuditEventEntity(row))
+	}
+	return res, nil
+}
+
+func toAuditEventEntity(row db.AuditLog) *entity.AuditEvent {
+	event := &entity.AuditEvent{
+		Seq:       row.Seq,
+		ID:        row.ID,
+		Type:      row.Type,
+		UserID:    row.UserID,
+		TenantID:  row.TenantID,
+		RequestID: row.RequestID,
+		Action:    row.Action,
+		Resource:  row.Resource,
+		PrevHash:  row.PrevHash,
+		Hash:      row.Hash,
+		CreatedAt: row.CreatedAt.UTC(),
+	}
+	if row.Data != "" {
+		event.Data = json.RawMessage(row.Data)
+	}
+	return event
+}
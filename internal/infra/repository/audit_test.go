@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+func appendAuditEvents(t *testing.T, repo *AuditRepository, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		event := entity.NewAuditEvent(entity.AuditAccess, "GET /chats", fmt.Sprintf("chat-%d", i), json.RawMessage(fmt.Sprintf(`{"n":%d}`, i)))
+		if i%3 == 0 {
+			event.Type = entity.AuditAdmin
+		}
+		if err := repo.AppendAuditEvent(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditRepositoryKeepsTheChainOrder(t *testing.T) {
+	_, database := newFakeDB()
+	appendAuditEvents(t, NewAuditRepositoryMySql(database), 4)
+	//outra instancia continua do ultimo evento gravado
+	repo := NewAuditRepositoryMySql(database)
+	appendAuditEvents(t, repo, 5)
+
+	events, err := repo.ListAuditEvents(context.Background(), entity.AuditFilter{Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 9 {
+		t.Fatalf("expected 9 events, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.Seq != int64(i+1) || event.Hash != event.ComputeHash() {
+			t.Fatalf("expected event %d in order with its hash, got seq %d", i+1, event.Seq)
+		}
+		if i > 0 && event.PrevHash != events[i-1].Hash {
+			t.Fatalf("event %d is not linked to the previous one", event.Seq)
+		}
+	}
+
+	page, err := repo.ListAuditEvents(context.Background(), entity.AuditFilter{AfterSeq: 3, Type: entity.AuditAdmin, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Seq != 7 {
+		t.Fatalf("expected the admin event 7, got %v", page)
+	}
+
+	output, err := audit.NewVerifyAuditLogUseCase(repo).Execute(context.Background(), audit.VerifyAuditLogInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.Valid || output.LastSeq != 9 {
+		t.Fatalf("expected a valid chain up to 9, got %+v", output)
+	}
+}
+
+func TestAuditRepositoryTamperedRow(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewAuditRepositoryMySql(database)
+	appendAuditEvents(t, repo, 6)
+
+	//linha alterada direto no banco
+	fake.audit[4][auditColData] = `{"n":50}`
+	output, err := audit.NewVerifyAuditLogUseCase(repo).Execute(context.Background(), audit.VerifyAuditLogInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Valid || output.BrokenAt != 5 || output.Events != 4 {
+		t.Fatalf("expected the chain broken at 5, got %+v", output)
+	}
+}
+
+func TestAuditRepositorySeqConflict(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewAuditRepositoryMySql(database)
+	appendAuditEvents(t, repo, 2)
+
+	//outro processo gravou o mesmo seq, nada fica gravado e o evento pode ser gravado de novo
+	fake.fail["CreateAuditEvent"] = &mysql.MySQLError{Number: mysqlDuplicateEntry}
+	event := entity.NewAuditEvent(entity.AuditAccess, "GET /chats", "", nil)
+	if err := repo.AppendAuditEvent(context.Background(), event); !errors.Is(err, entity.ErrAuditConflict) {
+		t.Fatalf("expected ErrAuditConflict, got %v", err)
+	}
+	delete(fake.fail, "CreateAuditEvent")
+	if err := repo.AppendAuditEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Seq != 3 || len(fake.audit) != 3 {
+		t.Fatalf("expected the event stored once as seq 3, got seq %d and %d rows", event.Seq, len(fake.audit))
+	}
+}
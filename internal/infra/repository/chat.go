@@ -3,32 +3,58 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
 	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+	"github.com/ruhancs/virtual-assistant/internal/infra/encryption"
 )
 
 type ChatRepository struct {
 	DB      *sql.DB
 	Queries *db.Queries
+	Keyring *encryption.Keyring // opcional, nil grava o conteudo das msgs em texto puro
 }
 
-func NewChatRepositoryMySql(database *sql.DB) *ChatRepository {
+func NewChatRepositoryMySql(database *sql.DB, keyring *encryption.Keyring) *ChatRepository {
 	return &ChatRepository{
 		DB:      database,
 		Queries: db.New(database),
+		Keyring: keyring,
 	}
 }
 
 func (r *ChatRepository) CreateChat(ctx context.Context, chat *entity.Chat) error {
-	err := r.Queries.CreateChat(
+	stop, err := json.Marshal(stopSequences(chat.Config.Stop))
+	if err != nil {
+		return err
+	}
+	if chat.CreatedAt.IsZero() {
+		chat.CreatedAt = time.Now()
+		chat.UpdatedAt = chat.CreatedAt
+	}
+	//o chat pertence ao tenant da requisicao
+	chat.TenantID = entity.TenantID(ctx)
+
+	//chat, compartilhamentos e msg inicial na mesma transacao, sem a msg inicial o chat nao é restaurado
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	err = queries.CreateChat(
 		ctx,
 		db.CreateChatParams{
 			ID:               chat.ID,
 			UserID:           chat.UserID,
-			InitialMessageID: chat.InitialSystemMessage.Content,
+			InitialMessageID: chat.InitialSystemMessage.ID,
 			Status:           chat.Status,
 			TokenUsage:       int32(chat.TokenUsage),
 			Model:            chat.Config.Model.Name,
@@ -36,106 +62,205 @@ func (r *ChatRepository) CreateChat(ctx context.Context, chat *entity.Chat) erro
 			Temperature:      float64(chat.Config.Temperature),
 			TopP:             float64(chat.Config.TopP),
 			N:                int32(chat.Config.N),
-			Stop:             chat.Config.Stop[0],
+			Stop:             stop,
 			MaxTokens:        int32(chat.Config.MaxTokens),
 			PresencePenalty:  float64(chat.Config.PresencePenalty),
 			FrequencyPenalty: float64(chat.Config.FrequencyPenalty),
-			CreatedAt:        time.Now(),
-			UpdatedAt:        time.Now(),
+			CreatedAt:        chat.CreatedAt,
+			UpdatedAt:        chat.UpdatedAt,
+			PromptTemplate:   chat.PromptTemplate,
+			TemplateVersion:  int32(chat.TemplateVersion),
+			AssistantID:      chat.AssistantID,
+			TenantID:         chat.TenantID,
+			ToolProfile:      chat.ToolProfile,
 		},
 	)
 	if err != nil {
 		return err
 	}
 
-	//cria msg inicial para iniciar o chat
-	err = r.Queries.AddMessage(
-		ctx,
-		db.AddMessageParams{
-			ID:        chat.InitialSystemMessage.ID,
+	for _, userID := range chat.SharedWith {
+		err = queries.AddChatShare(ctx, db.AddChatShareParams{
 			ChatID:    chat.ID,
-			Content:   chat.InitialSystemMessage.Content,
-			Role:      chat.InitialSystemMessage.Role,
-			Tokens:    int32(chat.InitialSystemMessage.Tokens),
-			CreatedAt: chat.InitialSystemMessage.CreatedAt,
-		},
-	)
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	//cria msg inicial para iniciar o chat
+	params, err := r.messageParams(chat, chat.InitialSystemMessage, 0, false)
+	if err != nil {
+		return err
+	}
+	err = queries.AddMessage(ctx, params)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
+// FindChatByID busca o chat no tenant da requisicao, chat de outro tenant retorna ErrChatNotFound
 func (r *ChatRepository) FindChatByID(ctx context.Context, chatID string) (*entity.Chat,error) {
-	chat := &entity.Chat{}
-	
-	res,err := r.Queries.FindChatByID(ctx,chatID)
+	res,err := r.Queries.FindChatByID(ctx, db.FindChatByIDParams{ID: chatID, TenantID: entity.TenantID(ctx)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrChatNotFound
+	}
 	if err != nil {
-		return nil, errors.New("chat not found")
+		return nil, err
 	}
 
 	//passar o chat model para chat entity
-	chat.ID = res.ID
-	chat.UserID = res.UserID
-	chat.Status = res.Status
-	chat.TokenUsage = int(res.TokenUsage)
-	chat.Config = &entity.ChatConfig{
-		Model: &entity.Model{
-			Name: res.Model,
-			MaxTokens: int(res.ModelMaxTokens),
-		},
-		Temperature: float32(res.Temperature),
-		TopP: float32(res.TopP),
-		N: int(res.N),
-		Stop: []string{res.Stop},
-		MaxTokens: int(res.MaxTokens),
-		PresencePenalty: float32(res.PresencePenalty),
-		FrequencyPenalty: float32(res.FrequencyPenalty),
+	chat, err := toChatEntity(res)
+	if err != nil {
+		return nil, err
+	}
+
+	//usuarios com acesso compartilhado ao chat
+	chat.SharedWith, err = r.Queries.FindChatSharesByChatID(ctx, db.FindChatSharesByChatIDParams{ChatID: chatID, TenantID: entity.TenantID(ctx)})
+	if err != nil {
+		return nil, err
 	}
 
 	//pegar as messages do chat pelo id
-	messages,err := r.Queries.FindMessagesByChatID(ctx, chatID)
+	messages,err := r.Queries.FindMessagesByChatID(ctx, db.FindMessagesByChatIDParams{ChatID: chatID, TenantID: entity.TenantID(ctx)})
 	if err != nil {
 		return nil,err
 	}
 
 	//adicionar as messages do chat model no chat entity, menssagens ativas do chat
 	for _,msg := range messages {
-		chat.Messages = append(chat.Messages, &entity.Message{
-			ID: msg.ID,
-			Content: msg.Content,
-			Role: msg.Role,
-			Tokens: int(msg.Tokens),
-			Model: &entity.Model{Name: msg.Model,},
-			CreatedAt: msg.CreatedAt,
-		})
+		message, err := r.toMessageEntity(msg, chat.Config.Model)
+		if err != nil {
+			return nil, err
+		}
+		chat.Messages = append(chat.Messages, message)
 	}
 
 	//menssagens apagadas do chat
-	errasedMessages,err := r.Queries.FindErasedMessagesByChatID(ctx,chatID)
+	errasedMessages,err := r.Queries.FindErasedMessagesByChatID(ctx, db.FindErasedMessagesByChatIDParams{ChatID: chatID, TenantID: entity.TenantID(ctx)})
 	if err != nil {
 		return nil,err
 	}
 
-	//adicionar as messages do chat model no chat entity, menssagens ativas do chat
+	//adicionar as messages apagadas do chat model no chat entity
 	for _,msg := range errasedMessages {
-		chat.ErasedMessages = append(chat.Messages, &entity.Message{
-			ID: msg.ID,
-			Content: msg.Content,
-			Role: msg.Role,
-			Tokens: int(msg.Tokens),
-			Model: &entity.Model{Name: msg.Model,},
-			CreatedAt: msg.CreatedAt,
-		})
+		message, err := r.toMessageEntity(msg, chat.Config.Model)
+		if err != nil {
+			return nil, err
+		}
+		chat.ErasedMessages = append(chat.ErasedMessages, message)
+	}
+
+	//a msg inicial de sistema pode estar no contexto ou ja ter sido apagada dele
+	for _, msg := range append(chat.Messages, chat.ErasedMessages...) {
+		if msg.ID == res.InitialMessageID {
+			chat.InitialSystemMessage = msg
+			break
+		}
+	}
+	return chat, nil
+}
+
+func (r *ChatRepository) FindChatSummaryByID(ctx context.Context, chatID string) (*entity.Chat, error) {
+	res, err := r.Queries.FindChatByID(ctx, db.FindChatByIDParams{ID: chatID, TenantID: entity.TenantID(ctx)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrChatNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	chat, err := toChatEntity(res)
+	if err != nil {
+		return nil, err
+	}
+
+	chat.SharedWith, err = r.Queries.FindChatSharesByChatID(ctx, db.FindChatSharesByChatIDParams{ChatID: chatID, TenantID: entity.TenantID(ctx)})
+	if err != nil {
+		return nil, err
 	}
 	return chat, nil
 }
 
+func (r *ChatRepository) ListChatsByUserID(ctx context.Context, userID string, after *gateway.ChatCursor, limit int) ([]*entity.Chat, error) {
+	//sem cursor comeca do chat atualizado mais recentemente
+	params := db.ListChatsByUserIDParams{
+		TenantID:  entity.TenantID(ctx),
+		UserID:    userID,
+		UpdatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		Limit:     int32(limit),
+	}
+	if after != nil {
+		params.UpdatedAt = after.UpdatedAt
+		params.ID = after.ID
+	}
+
+	rows, err := r.Queries.ListChatsByUserID(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	chats := make([]*entity.Chat, 0, len(rows))
+	for _, row := range rows {
+		chat, err := toChatEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+	return chats, nil
+}
+
+func (r *ChatRepository) ListMessagesByChatID(ctx context.Context, chatID string, before *int, limit int) ([]*gateway.HistoryMessage, error) {
+	params := db.ListMessagesByChatIDParams{
+		ChatID:   chatID,
+		TenantID: entity.TenantID(ctx),
+		OrderMsg: math.MaxInt32,
+		Limit:    int32(limit),
+	}
+	if before != nil {
+		params.OrderMsg = int32(*before)
+	}
+
+	rows, err := r.Queries.ListMessagesByChatID(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*gateway.HistoryMessage, 0, len(rows))
+	for _, row := range rows {
+		message, err := r.toMessageEntity(row, nil)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &gateway.HistoryMessage{
+			Message: message,
+			Order:   int(row.OrderMsg),
+			Erased:  row.Erased,
+		})
+	}
+	return messages, nil
+}
+
 func (r *ChatRepository) SaveChat(ctx context.Context, chat *entity.Chat) error {
+	//as msgs sao regravadas pelo id do chat, entao o tenant é conferido antes de qualquer escrita
+	tenantID := entity.TenantID(ctx)
+	if chat.TenantID != tenantID {
+		return entity.ErrChatNotFound
+	}
+	stop, err := json.Marshal(stopSequences(chat.Config.Stop))
+	if err != nil {
+		return err
+	}
+
 	params := db.SaveChatParams{
 		ID:               chat.ID,
 		UserID:           chat.UserID,
+		InitialMessageID: initialMessageID(chat),
 		Status:           chat.Status,
 		TokenUsage:       int32(chat.TokenUsage),
 		Model:            chat.Config.Model.Name,
@@ -143,73 +268,214 @@ func (r *ChatRepository) SaveChat(ctx context.Context, chat *entity.Chat) error
 		Temperature:      float64(chat.Config.Temperature),
 		TopP:             float64(chat.Config.TopP),
 		N:                int32(chat.Config.N),
-		Stop:             chat.Config.Stop[0],
+		Stop:             stop,
 		MaxTokens:        int32(chat.Config.MaxTokens),
 		PresencePenalty:  float64(chat.Config.PresencePenalty),
 		FrequencyPenalty: float64(chat.Config.FrequencyPenalty),
 		UpdatedAt:        time.Now(),
+		TenantID:         tenantID,
+	}
+	chat.UpdatedAt = params.UpdatedAt
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
 
-	err := r.Queries.SaveChat(
+	err = queries.SaveChat(
 		ctx,
 		params,
 	)
 	if err != nil {
 		return err
 	}
-	// delete messages
-	err = r.Queries.DeleteChatMessages(ctx, chat.ID)
+	// save shares
+	err = queries.DeleteChatShares(ctx, db.DeleteChatSharesParams{ChatID: chat.ID, TenantID: tenantID})
 	if err != nil {
 		return err
 	}
-	// delete erased messages
-	err = r.Queries.DeleteErasedChatMessages(ctx, chat.ID)
+	for _, userID := range chat.SharedWith {
+		err = queries.AddChatShare(ctx, db.AddChatShareParams{
+			ChatID:    chat.ID,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// save messages, as ja gravadas nao sao regravadas (nem cifradas de novo), somente marcadas como apagadas
+	states, err := queries.FindMessageStatesByChatID(ctx, db.FindMessageStatesByChatIDParams{ChatID: chat.ID, TenantID: tenantID})
 	if err != nil {
 		return err
 	}
-	// save messages
-	i := 0
-	for _, message := range chat.Messages {
-		err = r.Queries.AddMessage(
-			ctx,
-			db.AddMessageParams{
-				ID:        message.ID,
-				ChatID:    chat.ID,
-				Content:   message.Content,
-				Role:      message.Role,
-				Tokens:    int32(message.Tokens),
-				Model:     chat.Config.Model.Name,
-				CreatedAt: message.CreatedAt,
-				OrderMsg:  int32(i),
-				Erased:    false,
-			},
-		)
+	saved := make(map[string]bool, len(states))
+	next := 0
+	for _, state := range states {
+		saved[state.ID] = state.Erased
+		if int(state.OrderMsg) >= next {
+			next = int(state.OrderMsg) + 1
+		}
+	}
+	// order_msg é a posicao no historico completo (apagadas vem antes das ativas), as novas entram depois da ultima gravada
+	save := func(message *entity.Message, erased bool) error {
+		wasErased, ok := saved[message.ID]
+		if ok {
+			if erased && !wasErased {
+				return queries.MarkMessageErased(ctx, db.MarkMessageErasedParams{ID: message.ID, ChatID: chat.ID, TenantID: tenantID})
+			}
+			return nil
+		}
+		params, err := r.messageParams(chat, message, next, erased)
 		if err != nil {
 			return err
 		}
-		i++
+		next++
+		return queries.AddMessage(ctx, params)
 	}
-	// save erased messages
-	i = 0
 	for _, message := range chat.ErasedMessages {
-		err = r.Queries.AddMessage(
-			ctx,
-			db.AddMessageParams{
-				ID:        message.ID,
-				ChatID:    chat.ID,
-				Content:   message.Content,
-				Role:      message.Role,
-				Tokens:    int32(message.Tokens),
-				Model:     chat.Config.Model.Name,
-				CreatedAt: message.CreatedAt,
-				OrderMsg:  int32(i),
-				Erased:    true,
-			},
-		)
-		if err != nil {
+		if err := save(message, true); err != nil {
+			return err
+		}
+	}
+	for _, message := range chat.Messages {
+		if err := save(message, false); err != nil {
 			return err
 		}
-		i++
 	}
-	return nil
+	return tx.Commit()
+}
+
+// messageParams msg no formato do banco, com o conteudo e as chamadas de ferramenta cifrados quando o keyring esta configurado
+func (r *ChatRepository) messageParams(chat *entity.Chat, message *entity.Message, order int, erased bool) (db.AddMessageParams, error) {
+	content, keyID, err := r.Keyring.Encrypt(message.Content, message.ID)
+	if err != nil {
+		return db.AddMessageParams{}, err
+	}
+	//colunas json not null, sem chamadas ou veredictos grava uma lista vazia
+	toolCalls, err := r.encryptToolCalls(message.ToolCalls, message.ID)
+	if err != nil {
+		return db.AddMessageParams{}, err
+	}
+	moderation := json.RawMessage("[]")
+	if len(message.Moderation) > 0 {
+		moderation, err = json.Marshal(message.Moderation)
+		if err != nil {
+			return db.AddMessageParams{}, err
+		}
+	}
+	return db.AddMessageParams{
+		ID:         message.ID,
+		ChatID:     chat.ID,
+		Content:    content,
+		Role:       message.Role,
+		Tokens:     int32(message.Tokens),
+		Model:      messageModelName(message, chat.Config.Model),
+		CreatedAt:  message.CreatedAt,
+		OrderMsg:   int32(order),
+		Erased:     erased,
+		ToolCalls:  toolCalls,
+		ToolCallID: message.ToolCallID,
+		Name:       message.Name,
+		Moderation: moderation,
+		KeyID:      keyID,
+	}, nil
+}
+
+func toChatEntity(res db.Chat) (*entity.Chat, error) {
+	var stop []string
+	if err := json.Unmarshal(res.Stop, &stop); err != nil {
+		return nil, err
+	}
+	if len(stop) == 0 {
+		stop = nil
+	}
+
+	return &entity.Chat{
+		ID:         res.ID,
+		UserID:     res.UserID,
+		Status:     res.Status,
+		TokenUsage: int(res.TokenUsage),
+		Config: &entity.ChatConfig{
+			Model: &entity.Model{
+				Name:      res.Model,
+				MaxTokens: int(res.ModelMaxTokens),
+			},
+			Temperature:      float32(res.Temperature),
+			TopP:             float32(res.TopP),
+			N:                int(res.N),
+			Stop:             stop,
+			MaxTokens:        int(res.MaxTokens),
+			PresencePenalty:  float32(res.PresencePenalty),
+			FrequencyPenalty: float32(res.FrequencyPenalty),
+		},
+		PromptTemplate:  res.PromptTemplate,
+		TemplateVersion: int(res.TemplateVersion),
+		AssistantID:     res.AssistantID,
+		TenantID:        res.TenantID,
+		ToolProfile:     res.ToolProfile,
+		CreatedAt:       res.CreatedAt,
+		UpdatedAt:       res.UpdatedAt,
+	}, nil
+}
+
+// toMessageEntity msg do banco no formato da entidade, o conteudo é decifrado com a chave da linha
+func (r *ChatRepository) toMessageEntity(msg db.Message, chatModel *entity.Model) (*entity.Message, error) {
+	content, err := r.Keyring.Decrypt(msg.Content, msg.KeyID, msg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", msg.ID, err)
+	}
+	model := &entity.Model{Name: msg.Model}
+	if chatModel != nil && chatModel.Name == msg.Model {
+		model = chatModel
+	}
+	toolCalls, err := r.decryptToolCalls(msg.ToolCalls, msg.KeyID, msg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", msg.ID, err)
+	}
+	var moderation []entity.ModerationVerdict
+	if len(msg.Moderation) > 0 {
+		if err := json.Unmarshal(msg.Moderation, &moderation); err != nil {
+			return nil, err
+		}
+	}
+	if len(moderation) == 0 {
+		moderation = nil
+	}
+	return &entity.Message{
+		ID:         msg.ID,
+		Content:    content,
+		Role:       msg.Role,
+		Tokens:     int(msg.Tokens),
+		Model:      model,
+		ToolCalls:  toolCalls,
+		ToolCallID: msg.ToolCallID,
+		Name:       msg.Name,
+		Moderation: moderation,
+		CreatedAt:  msg.CreatedAt,
+	}, nil
+}
+
+func messageModelName(message *entity.Message, chatModel *entity.Model) string {
+	if message.Model != nil && message.Model.Name != "" {
+		return message.Model.Name
+	}
+	return chatModel.Name
+}
+
+func initialMessageID(chat *entity.Chat) string {
+	if chat.InitialSystemMessage == nil {
+		return ""
+	}
+	return chat.InitialSystemMessage.ID
+}
+
+// stop e salvo como lista json, nil vira [] para a coluna nunca ficar null
+func stopSequences(stop []string) []string {
+	if stop == nil {
+		return []string{}
+	}
+	return stop
 }
@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+	"github.com/ruhancs/virtual-assistant/internal/infra/encryption"
+)
+
+func newTestKey() []byte {
+	key := make([]byte, encryption.KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	return key
+}
+
+func newTestKeyring(t *testing.T) *encryption.Keyring {
+	t.Helper()
+	keyring, err := encryption.NewKeyring("k1", map[string][]byte{"k1": newTestKey()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func testMessage(id, role, content string, tokens int, model *entity.Model) *entity.Message {
+	return &entity.Message{
+		ID:        id,
+		Role:      role,
+		Content:   content,
+		Tokens:    tokens,
+		Model:     model,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestMessageWithoutToolCallsIsNotStoredAsNull(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, nil)
+	ctx := context.Background()
+
+	model := entity.NewModel("gpt-3.5-turbo", 100)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range fake.chatMessages(chat.ID) {
+		if row[9] == nil || row[12] == nil {
+			t.Fatalf("tool_calls and moderation are NOT NULL, got %v and %v", row[9], row[12])
+		}
+	}
+
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.InitialSystemMessage == nil || got.InitialSystemMessage.ToolCalls != nil || got.InitialSystemMessage.Moderation != nil {
+		t.Fatalf("expected the initial message without tool calls or verdicts, got %+v", got.InitialSystemMessage)
+	}
+}
+
+func TestSaveChatOnlyInsertsNewMessages(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, newTestKeyring(t))
+	ctx := context.Background()
+
+	model := entity.NewModel("gpt-3.5-turbo", 10)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	chat.AddMessage(testMessage("m1", "user", "hello", 4, model))
+	if err := repo.SaveChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	content := map[string]string{}
+	for _, row := range fake.chatMessages(chat.ID) {
+		content[row[messageColID].(string)] = row[3].(string)
+	}
+
+	//m2 nao cabe no contexto e apaga a msg de sistema
+	chat.AddMessage(testMessage("m2", "assistant", "hi there", 4, model))
+	if len(chat.ErasedMessages) != 1 {
+		t.Fatalf("expected the system message to be erased, got %d erased", len(chat.ErasedMessages))
+	}
+	if err := repo.SaveChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := fake.execCount("AddMessage"); n != 3 {
+		t.Fatalf("expected each message to be inserted once, got %d inserts", n)
+	}
+	if n := fake.execCount("MarkMessageErased"); n != 1 {
+		t.Fatalf("expected the system message to be marked as erased, got %d updates", n)
+	}
+	for _, row := range fake.chatMessages(chat.ID) {
+		id := row[messageColID].(string)
+		if old, ok := content[id]; ok && old != row[3].(string) {
+			t.Fatalf("message %s was encrypted again", id)
+		}
+		if want := id == "m0"; row[messageColErase].(bool) != want {
+			t.Fatalf("message %s erased = %v", id, row[messageColErase])
+		}
+	}
+
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.ErasedMessages) != 1 || got.ErasedMessages[0].ID != "m0" {
+		t.Fatalf("expected m0 erased, got %v", got.ErasedMessages)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].ID != "m1" || got.Messages[1].ID != "m2" {
+		t.Fatalf("expected m1 and m2 in order, got %v", got.Messages)
+	}
+	if got.Messages[1].Content != "hi there" {
+		t.Fatalf("expected the decrypted content, got %q", got.Messages[1].Content)
+	}
+}
+
+func TestCreateChatRoundTrip(t *testing.T) {
+	_, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, nil)
+	ctx := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "tenant-1"})
+
+	model := entity.NewModel("gpt-4", 8192)
+	config := &entity.ChatConfig{
+		Model:            model,
+		Temperature:      0.7,
+		TopP:             0.9,
+		N:                2,
+		Stop:             []string{"\n\n", "END"},
+		MaxTokens:        512,
+		PresencePenalty:  0.5,
+		FrequencyPenalty: -0.25,
+	}
+	initial := testMessage("system-1", "system", "you are a helpful assistant", 6, model)
+	chat, err := entity.NewChat("user-1", initial, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat.SharedWith = []string{"user-3", "user-2"}
+	chat.PromptTemplate = "support"
+	chat.TemplateVersion = 3
+	chat.AssistantID = "assistant-1"
+	chat.ToolProfile = "files"
+	chat.CreatedAt = time.Date(2024, 5, 6, 7, 8, 9, 123000, time.UTC)
+	chat.UpdatedAt = chat.CreatedAt.Add(time.Minute)
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != chat.ID || got.UserID != "user-1" || got.Status != "active" || got.TokenUsage != chat.TokenUsage {
+		t.Fatalf("unexpected chat %+v", got)
+	}
+	if !reflect.DeepEqual(got.Config, config) {
+		t.Fatalf("expected config %+v %+v, got %+v %+v", config, config.Model, got.Config, got.Config.Model)
+	}
+	if !reflect.DeepEqual(got.SharedWith, []string{"user-2", "user-3"}) {
+		t.Fatalf("expected the shares, got %v", got.SharedWith)
+	}
+	if got.PromptTemplate != "support" || got.TemplateVersion != 3 || got.AssistantID != "assistant-1" {
+		t.Fatalf("expected the template and assistant, got %q %d %q", got.PromptTemplate, got.TemplateVersion, got.AssistantID)
+	}
+	if got.TenantID != "tenant-1" || got.ToolProfile != "files" {
+		t.Fatalf("expected tenant-1 and files, got %q %q", got.TenantID, got.ToolProfile)
+	}
+	if !got.CreatedAt.Equal(chat.CreatedAt) || !got.UpdatedAt.Equal(chat.UpdatedAt) {
+		t.Fatalf("expected %v %v, got %v %v", chat.CreatedAt, chat.UpdatedAt, got.CreatedAt, got.UpdatedAt)
+	}
+	if got.InitialSystemMessage == nil || got.InitialSystemMessage.ID != "system-1" {
+		t.Fatalf("expected the initial system message system-1, got %+v", got.InitialSystemMessage)
+	}
+	if len(got.Messages) != 1 || got.Messages[0] != got.InitialSystemMessage || len(got.ErasedMessages) != 0 {
+		t.Fatalf("expected only the initial message in the context, got %v %v", got.Messages, got.ErasedMessages)
+	}
+	msg := got.InitialSystemMessage
+	if msg.Role != "system" || msg.Content != initial.Content || msg.Tokens != 6 || msg.Model != got.Config.Model || !msg.CreatedAt.Equal(initial.CreatedAt) {
+		t.Fatalf("expected the initial message %+v, got %+v", initial, msg)
+	}
+
+	//outro tenant nao enxerga o chat
+	other := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "tenant-2"})
+	if _, err := repo.FindChatByID(other, chat.ID); !errors.Is(err, entity.ErrChatNotFound) {
+		t.Fatalf("expected ErrChatNotFound in another tenant, got %v", err)
+	}
+}
+
+func TestCreateChatIsAtomic(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, nil)
+	ctx := context.Background()
+
+	model := entity.NewModel("gpt-3.5-turbo", 4096)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat.SharedWith = []string{"user-2"}
+	fake.fail["AddMessage"] = errors.New("connection lost")
+	if err := repo.CreateChat(ctx, chat); err == nil {
+		t.Fatal("expected the insert of the initial message to fail")
+	}
+
+	//sem a msg inicial nada do chat fica gravado
+	if _, err := repo.FindChatByID(ctx, chat.ID); !errors.Is(err, entity.ErrChatNotFound) {
+		t.Fatalf("expected ErrChatNotFound after the failed create, got %v", err)
+	}
+	if shares := fake.shares[chat.ID]; len(shares) != 0 {
+		t.Fatalf("expected no shares after the failed create, got %v", shares)
+	}
+
+	delete(fake.fail, "AddMessage")
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatalf("expected the chat to be created again, got %v", err)
+	}
+	if _, err := repo.FindChatByID(ctx, chat.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateChatRoundTripWithoutStop(t *testing.T) {
+	_, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, nil)
+	ctx := context.Background()
+
+	model := entity.NewModel("gpt-3.5-turbo", 4096)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Config.Stop != nil || got.SharedWith != nil || got.TenantID != "" {
+		t.Fatalf("expected no stop list, shares or tenant, got %v %v %q", got.Config.Stop, got.SharedWith, got.TenantID)
+	}
+}
+
+func TestMessageQueriesAreScopedToTenant(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, nil)
+	ctx := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "tenant-1"})
+	other := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "tenant-2"})
+
+	model := entity.NewModel("gpt-3.5-turbo", 4096)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat.SharedWith = []string{"user-2"}
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+
+	if messages, err := repo.ListMessagesByChatID(ctx, chat.ID, nil, 10); err != nil || len(messages) != 1 {
+		t.Fatalf("expected the message in the chat tenant, got %d %v", len(messages), err)
+	}
+	if messages, err := repo.ListMessagesByChatID(other, chat.ID, nil, 10); err != nil || len(messages) != 0 {
+		t.Fatalf("expected no messages in another tenant, got %d %v", len(messages), err)
+	}
+
+	//escritas pelo id do chat tambem conferem o tenant
+	if err := repo.Queries.MarkMessageErased(other, db.MarkMessageErasedParams{ID: "m0", ChatID: chat.ID, TenantID: "tenant-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Queries.DeleteChatShares(other, db.DeleteChatSharesParams{ChatID: chat.ID, TenantID: "tenant-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if row := fake.chatMessages(chat.ID)[0]; row[messageColErase].(bool) {
+		t.Fatal("message erased through another tenant")
+	}
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.SharedWith, []string{"user-2"}) {
+		t.Fatalf("shares deleted through another tenant, got %v", got.SharedWith)
+	}
+}
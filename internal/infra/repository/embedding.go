@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+func (r *ChatRepository) SaveMessageEmbeddings(ctx context.Context, embeddings []*entity.MessageEmbedding) error {
+	for _, e := range embeddings {
+		createdAt := e.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		err := r.Queries.AddMessageEmbedding(ctx, db.AddMessageEmbeddingParams{
+			MessageID:  e.MessageID,
+			ChatID:     e.ChatID,
+			Model:      e.Model,
+			Dimensions: int32(len(e.Vector)),
+			Vector:     encodeVector(e.Vector),
+			CreatedAt:  createdAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ChatRepository) FindMessageEmbeddingsByChatID(ctx context.Context, chatID string, model string) ([]*entity.MessageEmbedding, error) {
+	rows, err := r.Queries.FindMessageEmbeddingsByChatID(ctx, db.FindMessageEmbeddingsByChatIDParams{
+		ChatID:   chatID,
+		TenantID: entity.TenantID(ctx),
+		Model:    model,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([]*entity.MessageEmbedding, 0, len(rows))
+	for _, row := range rows {
+		vector, err := decodeVector(row.Vector, int(row.Dimensions))
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %w", row.MessageID, err)
+		}
+		embeddings = append(embeddings, &entity.MessageEmbedding{
+			MessageID: row.MessageID,
+			ChatID:    row.ChatID,
+			Model:     row.Model,
+			Vector:    vector,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return embeddings, nil
+}
+
+// vetores sao salvos como float32 little endian
+func encodeVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+func decodeVector(b []byte, dimensions int) ([]float32, error) {
+	if len(b) != 4*dimensions {
+		return nil, fmt.Errorf("invalid vector size %d for %d dimensions", len(b), dimensions)
+	}
+	v := make([]float32, dimensions)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// ReencryptMessages recifra com a chave mestra ativa um lote de msgs cifradas por outra chave ou em texto puro,
+// a partir do id afterID. Retorna quantas msgs foram recifradas e o id da ultima lida, vazio quando nao sobrou nenhuma.
+// Msg regravada pelo SaveChat durante a rotacao ja usa a chave ativa e é ignorada
+func (r *ChatRepository) ReencryptMessages(ctx context.Context, afterID string, limit int) (int, string, error) {
+	if r.Keyring == nil {
+		return 0, "", errors.New("encryption is not configured")
+	}
+	rows, err := r.Queries.ListMessagesForRotation(ctx, db.ListMessagesForRotationParams{
+		KeyID: r.Keyring.ActiveKeyID(),
+		ID:    afterID,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	updated, lastID := 0, ""
+	for _, row := range rows {
+		lastID = row.ID
+		plaintext, err := r.Keyring.Decrypt(row.Content, row.KeyID, row.ID)
+		if err != nil {
+			return updated, lastID, fmt.Errorf("message %s: %w", row.ID, err)
+		}
+		toolCalls, err := r.decryptToolCalls(row.ToolCalls, row.KeyID, row.ID)
+		if err != nil {
+			return updated, lastID, fmt.Errorf("message %s: %w", row.ID, err)
+		}
+		content, keyID, err := r.Keyring.Encrypt(plaintext, row.ID)
+		if err != nil {
+			return updated, lastID, fmt.Errorf("message %s: %w", row.ID, err)
+		}
+		encryptedToolCalls, err := r.encryptToolCalls(toolCalls, row.ID)
+		if err != nil {
+			return updated, lastID, fmt.Errorf("message %s: %w", row.ID, err)
+		}
+		affected, err := r.Queries.UpdateMessageContent(ctx, db.UpdateMessageContentParams{
+			Content:   content,
+			ToolCalls: encryptedToolCalls,
+			KeyID:     keyID,
+			ID:        row.ID,
+			KeyID_2:   row.KeyID,
+		})
+		if err != nil {
+			return updated, lastID, err
+		}
+		updated += int(affected)
+	}
+	return updated, lastID, nil
+}
+
+// toolCallsAAD os argumentos das chamadas sao cifrados com outro aad, nao podem ser trocados com o conteudo da msg
+func toolCallsAAD(messageID string) string {
+	return messageID + "/tool_calls"
+}
+
+// encryptToolCalls chamadas de ferramenta no formato da coluna tool_calls. Os argumentos levam texto do usuario,
+// com o keyring configurado a lista é cifrada com a chave ativa e gravada como uma string json.
+// Sem chamadas grava uma lista vazia
+func (r *ChatRepository) encryptToolCalls(toolCalls []entity.ToolCall, messageID string) (json.RawMessage, error) {
+	if len(toolCalls) == 0 {
+		return json.RawMessage("[]"), nil
+	}
+	data, err := json.Marshal(toolCalls)
+	if err != nil {
+		return nil, err
+	}
+	if r.Keyring == nil {
+		return data, nil
+	}
+	ciphertext, _, err := r.Keyring.Encrypt(string(data), toolCallsAAD(messageID))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ciphertext)
+}
+
+// decryptToolCalls le a coluna tool_calls: lista json em texto puro ou string json cifrada pela chave keyID
+func (r *ChatRepository) decryptToolCalls(raw json.RawMessage, keyID string, messageID string) ([]entity.ToolCall, error) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var ciphertext string
+		if err := json.Unmarshal(raw, &ciphertext); err != nil {
+			return nil, err
+		}
+		plaintext, err := r.Keyring.Decrypt(ciphertext, keyID, toolCallsAAD(messageID))
+		if err != nil {
+			return nil, err
+		}
+		raw = json.RawMessage(plaintext)
+	}
+	var toolCalls []entity.ToolCall
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &toolCalls); err != nil {
+			return nil, err
+		}
+	}
+	if len(toolCalls) == 0 {
+		return nil, nil
+	}
+	return toolCalls, nil
+}
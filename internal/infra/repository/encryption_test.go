@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/encryption"
+)
+
+func chatWithToolCall(t *testing.T) *entity.Chat {
+	t.Helper()
+	model := entity.NewModel("gpt-3.5-turbo", 4096)
+	chat, err := entity.NewChat("user-1", testMessage("m0", "system", "be brief", 4, model), &entity.ChatConfig{Model: model})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := testMessage("m1", "assistant", "", 4, model)
+	call.ToolCalls = []entity.ToolCall{{ID: "call-1", Name: "lookup_order", Arguments: `{"email":"ana@example.com"}`}}
+	chat.AddMessage(call)
+	return chat
+}
+
+func TestToolCallsAreEncrypted(t *testing.T) {
+	fake, database := newFakeDB()
+	repo := NewChatRepositoryMySql(database, newTestKeyring(t))
+	ctx := context.Background()
+
+	chat := chatWithToolCall(t)
+	if err := repo.CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range fake.chatMessages(chat.ID) {
+		raw := row[messageColToolCalls].([]byte)
+		if strings.Contains(string(raw), "ana@example.com") || strings.Contains(string(raw), "lookup_order") {
+			t.Fatalf("tool calls of %s stored in plaintext: %s", row[messageColID], raw)
+		}
+		if !json.Valid(raw) {
+			t.Fatalf("tool_calls of %s is not valid json: %s", row[messageColID], raw)
+		}
+	}
+
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Messages[1].ToolCalls, chat.Messages[1].ToolCalls) {
+		t.Fatalf("expected the decrypted tool calls, got %+v", got.Messages[1].ToolCalls)
+	}
+	if got.Messages[0].ToolCalls != nil {
+		t.Fatalf("expected no tool calls in the system message, got %+v", got.Messages[0].ToolCalls)
+	}
+
+	//o conteudo cifrado de uma coluna nao decifra na outra
+	for _, row := range fake.chatMessages(chat.ID) {
+		if row[messageColID] == "m1" {
+			var ciphertext string
+			if err := json.Unmarshal(row[messageColToolCalls].([]byte), &ciphertext); err != nil {
+				t.Fatal(err)
+			}
+			row[messageColContent] = ciphertext
+		}
+	}
+	if _, err := repo.FindChatByID(ctx, chat.ID); err == nil {
+		t.Fatal("expected tool calls copied to the content column to fail authentication")
+	}
+}
+
+func TestReencryptMessagesRotatesToolCalls(t *testing.T) {
+	fake, database := newFakeDB()
+	ctx := context.Background()
+
+	//msgs gravadas em texto puro e depois recifradas com a chave k1
+	chat := chatWithToolCall(t)
+	if err := NewChatRepositoryMySql(database, nil).CreateChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewChatRepositoryMySql(database, nil).SaveChat(ctx, chat); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewChatRepositoryMySql(database, newTestKeyring(t))
+	updated, lastID, err := repo.ReencryptMessages(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 2 || lastID != "m1" {
+		t.Fatalf("expected 2 messages up to m1, got %d %q", updated, lastID)
+	}
+	for _, row := range fake.chatMessages(chat.ID) {
+		if row[messageColKeyID] != "k1" || strings.Contains(string(row[messageColToolCalls].([]byte)), "lookup_order") {
+			t.Fatalf("expected %s encrypted with k1, got %v %s", row[messageColID], row[messageColKeyID], row[messageColToolCalls])
+		}
+	}
+
+	//rotacao para a chave k2, k1 continua no keyring para decifrar
+	key := make([]byte, encryption.KeySize)
+	keyring, err := encryption.NewKeyring("k2", map[string][]byte{"k1": newTestKey(), "k2": key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo = NewChatRepositoryMySql(database, keyring)
+	if updated, _, err := repo.ReencryptMessages(ctx, "", 10); err != nil || updated != 2 {
+		t.Fatalf("expected 2 messages rotated, got %d %v", updated, err)
+	}
+	if updated, lastID, err := repo.ReencryptMessages(ctx, "", 10); err != nil || updated != 0 || lastID != "" {
+		t.Fatalf("expected nothing left to rotate, got %d %q %v", updated, lastID, err)
+	}
+
+	got, err := repo.FindChatByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Messages[0].Content != "be brief" || !reflect.DeepEqual(got.Messages[1].ToolCalls, chat.Messages[1].ToolCalls) {
+		t.Fatalf("expected the rotated messages to decrypt, got %q %+v", got.Messages[0].Content, got.Messages[1].ToolCalls)
+	}
+}
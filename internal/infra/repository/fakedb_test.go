@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// fakeDB banco em memoria para os testes do repositorio. As queries sao reconhecidas pelo nome do sqlc
+// (-- name: X) e somente as tabelas chats, chat_shares, messages e audit_log sao simuladas
+type fakeDB struct {
+	mu       sync.Mutex
+	chats    map[string][]driver.Value // colunas na ordem da tabela chats
+	shares   map[string][]string
+	messages [][]driver.Value // colunas na ordem da tabela messages
+	audit    [][]driver.Value // colunas na ordem da tabela audit_log
+	execs    map[string]int   // execucoes por query
+	fail     map[string]error // erro retornado pela query, para simular falhas no meio de uma transacao
+}
+
+// colunas usadas pelo fakeDB
+const (
+	chatColID       = 0
+	chatColUpdated  = 15
+	chatColTenant   = 19
+	messageColID        = 0
+	messageColChat      = 1
+	messageColContent   = 3
+	messageColErase     = 6
+	messageColOrder     = 7
+	messageColToolCalls = 9
+	messageColKeyID     = 13
+	auditColSeq         = 0
+	auditColType        = 2
+	auditColData        = 8
+	auditColCreated     = 11
+)
+
+func newFakeDB() (*fakeDB, *sql.DB) {
+	f := &fakeDB{
+		chats:  map[string][]driver.Value{},
+		shares: map[string][]string{},
+		execs:  map[string]int{},
+		fail:   map[string]error{},
+	}
+	return f, sql.OpenDB(fakeConnector{f})
+}
+
+func queryName(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return ""
+	}
+	return fields[2]
+}
+
+// values copia os args, []byte nil vira NULL como no driver do mysql
+func values(args []driver.NamedValue) []driver.Value {
+	res := make([]driver.Value, len(args))
+	for i, arg := range args {
+		res[i] = arg.Value
+		if b, ok := arg.Value.([]byte); ok {
+			if b == nil {
+				res[i] = nil
+			} else {
+				res[i] = append([]byte(nil), b...)
+			}
+		}
+	}
+	return res
+}
+
+func (f *fakeDB) exec(name string, args []driver.Value) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs[name]++
+	if err := f.fail[name]; err != nil {
+		return 0, err
+	}
+	switch name {
+	case "CreateChat":
+		id := args[chatColID].(string)
+		if _, ok := f.chats[id]; ok {
+			return 0, fmt.Errorf("fakedb: duplicate chat %s", id)
+		}
+		f.chats[id] = args
+		return 1, nil
+	case "SaveChat":
+		//user_id ate frequency_penalty, updated_at, id e tenant_id
+		row, ok := f.chats[args[14].(string)]
+		if !ok || row[chatColTenant] != args[15] {
+			return 0, nil
+		}
+		copy(row[1:14], args[0:13])
+		row[chatColUpdated] = args[13]
+		return 1, nil
+	case "AddChatShare":
+		chatID := args[0].(string)
+		f.shares[chatID] = append(f.shares[chatID], args[1].(string))
+		return 1, nil
+	case "DeleteChatShares":
+		if !f.inTenant(args[0], args[1]) {
+			return 0, nil
+		}
+		n := len(f.shares[args[0].(string)])
+		delete(f.shares, args[0].(string))
+		return int64(n), nil
+	case "AddMessage":
+		for _, row := range f.messages {
+			if row[messageColID] == args[messageColID] {
+				return 0, fmt.Errorf("fakedb: duplicate message %s", args[messageColID])
+			}
+		}
+		f.messages = append(f.messages, args)
+		return 1, nil
+	case "MarkMessageErased":
+		for _, row := range f.messages {
+			if row[messageColID] == args[0] && row[messageColChat] == args[1] && f.inTenant(args[1], args[2]) {
+				row[messageColErase] = true
+				return 1, nil
+			}
+		}
+		return 0, nil
+	case "CreateAuditEvent":
+		for _, row := range f.audit {
+			if row[auditColSeq] == args[auditColSeq] {
+				return 0, &mysql.MySQLError{Number: mysqlDuplicateEntry, Message: "Duplicate entry for key 'PRIMARY'"}
+			}
+		}
+		f.audit = append(f.audit, args)
+		return 1, nil
+	case "UpdateMessageContent":
+		//content, tool_calls, key_id, id e key_id lido antes
+		for _, row := range f.messages {
+			if row[messageColID] == args[3] && row[messageColKeyID] == args[4] {
+				row[messageColContent], row[messageColToolCalls], row[messageColKeyID] = args[0], args[1], args[2]
+				return 1, nil
+			}
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("fakedb: query %q not supported", name)
+}
+
+func (f *fakeDB) query(name string, args []driver.Value) (*fakeRows, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch name {
+	case "FindChatByID":
+		row, ok := f.chats[args[0].(string)]
+		if !ok || row[chatColTenant] != args[1] {
+			return &fakeRows{}, nil
+		}
+		return &fakeRows{rows: [][]driver.Value{row}}, nil
+	case "FindChatSharesByChatID":
+		if !f.inTenant(args[0], args[1]) {
+			return &fakeRows{}, nil
+		}
+		users := append([]string(nil), f.shares[args[0].(string)]...)
+		sort.Strings(users)
+		rows := &fakeRows{}
+		for _, user := range users {
+			rows.rows = append(rows.rows, []driver.Value{user})
+		}
+		return rows, nil
+	case "FindMessagesByChatID", "FindErasedMessagesByChatID":
+		var rows [][]driver.Value
+		for _, row := range f.messages {
+			if row[messageColChat] != args[0] || !f.inTenant(args[0], args[1]) {
+				continue
+			}
+			erased := row[messageColErase].(bool)
+			if (name == "FindMessagesByChatID" && erased) || (name == "FindErasedMessagesByChatID" && !erased) {
+				continue
+			}
+			rows = append(rows, row)
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i][messageColOrder].(int64) < rows[j][messageColOrder].(int64)
+		})
+		return &fakeRows{rows: rows}, nil
+	case "ListMessagesByChatID":
+		//chat_id, tenant_id, order_msg < ? e limit, da mais recente para a mais antiga
+		var rows [][]driver.Value
+		for _, row := range f.messages {
+			if row[messageColChat] == args[0] && f.inTenant(args[0], args[1]) && row[messageColOrder].(int64) < args[2].(int64) {
+				rows = append(rows, row)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i][messageColOrder].(int64) > rows[j][messageColOrder].(int64)
+		})
+		if limit := int(args[3].(int64)); len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return &fakeRows{rows: rows}, nil
+	case "FindMessageStatesByChatID":
+		rows := &fakeRows{}
+		for _, row := range f.messages {
+			if row[messageColChat] == args[0] && f.inTenant(args[0], args[1]) {
+				rows.rows = append(rows.rows, []driver.Value{row[messageColID], row[messageColErase], row[messageColOrder]})
+			}
+		}
+		return rows, nil
+	case "FindLastAuditEvent":
+		rows := &fakeRows{}
+		for _, row := range f.audit {
+			if len(rows.rows) == 0 || row[auditColSeq].(int64) > rows.rows[0][auditColSeq].(int64) {
+				rows.rows = [][]driver.Value{row}
+			}
+		}
+		return rows, nil
+	case "ListAuditEvents":
+		//seq > ?, tipo (duas vezes), created_at entre os dois tempos e limit
+		var rows [][]driver.Value
+		for _, row := range f.audit {
+			created := row[auditColCreated].(time.Time)
+			if row[auditColSeq].(int64) > args[0].(int64) && (args[1] == "" || row[auditColType] == args[2]) &&
+				!created.Before(args[3].(time.Time)) && !created.After(args[4].(time.Time)) {
+				rows = append(rows, row)
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][auditColSeq].(int64) < rows[j][auditColSeq].(int64) })
+		if limit := int(args[5].(int64)); len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return &fakeRows{rows: rows}, nil
+	case "ListMessagesForRotation":
+		var rows [][]driver.Value
+		for _, row := range f.messages {
+			if row[messageColKeyID] != args[0] && row[messageColID].(string) > args[1].(string) {
+				rows = append(rows, []driver.Value{row[messageColID], row[messageColContent], row[messageColToolCalls], row[messageColKeyID]})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0].(string) < rows[j][0].(string) })
+		if limit := int(args[2].(int64)); len(rows) > limit {
+			rows = rows[:limit]
+		}
+		return &fakeRows{rows: rows}, nil
+	}
+	return nil, fmt.Errorf("fakedb: query %q not supported", name)
+}
+
+// inTenant o chat existe no tenant, como o join com chats das queries de msgs
+func (f *fakeDB) inTenant(chatID, tenantID driver.Value) bool {
+	row, ok := f.chats[chatID.(string)]
+	return ok && row[chatColTenant] == tenantID
+}
+
+// chatMessages msgs gravadas do chat, na ordem de insercao
+func (f *fakeDB) chatMessages(chatID string) [][]driver.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res [][]driver.Value
+	for _, row := range f.messages {
+		if row[messageColChat] == chatID {
+			res = append(res, row)
+		}
+	}
+	return res
+}
+
+func (f *fakeDB) execCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.execs[name]
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                            { return fakeDriver{c.db} }
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d.db}, nil }
+
+// fakeConn com uma conexao so, a transacao guarda uma copia das tabelas e o rollback a restaura
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakedb: prepare not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return c.db.begin(), nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	n, err := c.db.exec(queryName(query), values(args))
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(queryName(query), values(args))
+}
+
+type fakeTx struct {
+	db       *fakeDB
+	chats    map[string][]driver.Value
+	shares   map[string][]string
+	messages [][]driver.Value
+	audit    [][]driver.Value
+}
+
+func copyRows(rows [][]driver.Value) [][]driver.Value {
+	res := make([][]driver.Value, len(rows))
+	for i, row := range rows {
+		res[i] = append([]driver.Value(nil), row...)
+	}
+	return res
+}
+
+func (f *fakeDB) begin() *fakeTx {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx := &fakeTx{db: f, chats: map[string][]driver.Value{}, shares: map[string][]string{}, messages: copyRows(f.messages), audit: copyRows(f.audit)}
+	for id, row := range f.chats {
+		tx.chats[id] = append([]driver.Value(nil), row...)
+	}
+	for id, users := range f.shares {
+		tx.shares[id] = append([]string(nil), users...)
+	}
+	return tx
+}
+
+func (tx *fakeTx) Commit() error { return nil }
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.chats, tx.db.shares, tx.db.messages, tx.db.audit = tx.chats, tx.shares, tx.messages, tx.audit
+	return nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+type KnowledgeRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewKnowledgeRepositoryMySql(database *sql.DB) *KnowledgeRepository {
+	return &KnowledgeRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+// CreateDocument salva o documento e os chunks na mesma transacao
+func (r *KnowledgeRepository) CreateDocument(ctx context.Context, doc *entity.Document, chunks []*entity.DocumentChunk) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	err = queries.CreateDocument(ctx, db.CreateDocumentParams{
+		ID:              doc.ID,
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		Title:           doc.Title,
+		Source:          doc.Source,
+		ContentType:     doc.ContentType,
+		Tokens:          int32(doc.Tokens),
+		CreatedAt:       doc.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		err = queries.AddDocumentChunk(ctx, db.AddDocumentChunkParams{
+			ID:              chunk.ID,
+			DocumentID:      doc.ID,
+			KnowledgeBaseID: doc.KnowledgeBaseID,
+			ChunkIndex:      int32(chunk.Index),
+			Content:         chunk.Content,
+			Tokens:          int32(chunk.Tokens),
+			StartOffset:     int32(chunk.Start),
+			EndOffset:       int32(chunk.End),
+			Model:           chunk.Model,
+			Dimensions:      int32(len(chunk.Vector)),
+			Vector:          encodeVector(chunk.Vector),
+			CreatedAt:       chunk.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *KnowledgeRepository) FindDocumentByID(ctx context.Context, documentID string) (*entity.Document, error) {
+	row, err := r.Queries.FindDocumentByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	return &entity.Document{
+		ID:              row.ID,
+		KnowledgeBaseID: row.KnowledgeBaseID,
+		Title:           row.Title,
+		Source:          row.Source,
+		ContentType:     row.ContentType,
+		Tokens:          int(row.Tokens),
+		CreatedAt:       row.CreatedAt,
+	}, nil
+}
+
+// DeleteDocument os chunks sao removidos em cascata
+func (r *KnowledgeRepository) DeleteDocument(ctx context.Context, documentID string) error {
+	return r.Queries.DeleteDocument(ctx, documentID)
+}
+
+func (r *KnowledgeRepository) FindChunkByID(ctx context.Context, chunkID string) (*entity.DocumentChunk, error) {
+	row, err := r.Queries.FindChunkByID(ctx, chunkID)
+	if err != nil {
+		//chunk de documento ja removido
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	return toChunkEntity(row)
+}
+
+func (r *KnowledgeRepository) FindChunksByDocumentID(ctx context.Context, documentID string) ([]*entity.DocumentChunk, error) {
+	rows, err := r.Queries.FindChunksByDocumentID(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]*entity.DocumentChunk, 0, len(rows))
+	for _, row := range rows {
+		chunk, err := toChunkEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func toChunkEntity(row db.DocumentChunk) (*entity.DocumentChunk, error) {
+	vector, err := decodeVector(row.Vector, int(row.Dimensions))
+	if err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", row.ID, err)
+	}
+	return &entity.DocumentChunk{
+		ID:              row.ID,
+		DocumentID:      row.DocumentID,
+		KnowledgeBaseID: row.KnowledgeBaseID,
+		Index:           int(row.ChunkIndex),
+		Content:         row.Content,
+		Tokens:          int(row.Tokens),
+		Start:           int(row.StartOffset),
+		End:             int(row.EndOffset),
+		Model:           row.Model,
+		Vector:          vector,
+		CreatedAt:       row.CreatedAt,
+	}, nil
+}
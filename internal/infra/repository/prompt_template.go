@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+type PromptTemplateRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewPromptTemplateRepositoryMySql(database *sql.DB) *PromptTemplateRepository {
+	return &PromptTemplateRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+// CreatePromptTemplate numera a versao e troca a versao ativa na mesma transacao, o FOR UPDATE serializa criacoes do mesmo nome
+func (r *PromptTemplateRepository) CreatePromptTemplate(ctx context.Context, template *entity.PromptTemplate) error {
+	defaults, err := json.Marshal(template.Defaults)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	latest, err := queries.FindLatestPromptTemplateVersion(ctx, template.Name)
+	if err != nil {
+		return err
+	}
+	if template.Active {
+		if err := queries.DeactivatePromptTemplates(ctx, template.Name); err != nil {
+			return err
+		}
+	}
+	err = queries.CreatePromptTemplate(ctx, db.CreatePromptTemplateParams{
+		Name:        template.Name,
+		Version:     int32(latest + 1),
+		Content:     template.Content,
+		Description: template.Description,
+		Defaults:    defaults,
+		Active:      template.Active,
+		CreatedAt:   template.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	template.Version = int(latest + 1)
+	return nil
+}
+
+func (r *PromptTemplateRepository) FindPromptTemplate(ctx context.Context, name string, version int) (*entity.PromptTemplate, error) {
+	var row db.PromptTemplate
+	var err error
+	if version == 0 {
+		row, err = r.Queries.FindActivePromptTemplate(ctx, name)
+	} else {
+		row, err = r.Queries.FindPromptTemplateVersion(ctx, db.FindPromptTemplateVersionParams{
+			Name:    name,
+			Version: int32(version),
+		})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrPromptTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toPromptTemplateEntity(row)
+}
+
+func (r *PromptTemplateRepository) ListPromptTemplates(ctx context.Context) ([]*entity.PromptTemplate, error) {
+	rows, err := r.Queries.ListActivePromptTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toPromptTemplateEntities(rows)
+}
+
+func (r *PromptTemplateRepository) ListPromptTemplateVersions(ctx context.Context, name string) ([]*entity.PromptTemplate, error) {
+	rows, err := r.Queries.ListPromptTemplateVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, entity.ErrPromptTemplateNotFound
+	}
+	return toPromptTemplateEntities(rows)
+}
+
+// ActivatePromptTemplate torna a versao a ativa, usado para voltar a uma versao anterior
+func (r *PromptTemplateRepository) ActivatePromptTemplate(ctx context.Context, name string, version int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	if err := queries.DeactivatePromptTemplates(ctx, name); err != nil {
+		return err
+	}
+	affected, err := queries.ActivatePromptTemplateVersion(ctx, db.ActivatePromptTemplateVersionParams{
+		Name:    name,
+		Version: int32(version),
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrPromptTemplateNotFound
+	}
+	return tx.Commit()
+}
+
+// DeletePromptTemplate apaga todas as versoes, os chats criados com ele mantem a msg inicial ja renderizada
+func (r *PromptTemplateRepository) DeletePromptTemplate(ctx context.Context, name string) error {
+	affected, err := r.Queries.DeletePromptTemplate(ctx, name)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrPromptTemplateNotFound
+	}
+	return nil
+}
+
+func toPromptTemplateEntities(rows []db.PromptTemplate) ([]*entity.PromptTemplate, error) {
+	res := make([]*entity.PromptTemplate, 0, len(rows))
+	for _, row := range rows {
+		template, err := toPromptTemplateEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, template)
+	}
+	return res, nil
+}
+
+func toPromptTemplateEntity(row db.PromptTemplate) (*entity.PromptTemplate, error) {
+	defaults := map[string]string{}
+	if err := json.Unmarshal(row.Defaults, &defaults); err != nil {
+		return nil, err
+	}
+	return &entity.PromptTemplate{
+		Name:        row.Name,
+		Version:     int(row.Version),
+		Content:     row.Content,
+		Description: row.Description,
+		Defaults:    defaults,
+		Active:      row.Active,
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+func (r *ChatRepository) ListInactiveChats(ctx context.Context, before time.Time, includeAnonymized bool, after *gateway.ChatCursor, limit int) ([]*entity.InactiveChat, error) {
+	params := db.ListInactiveChatsParams{
+		Before:            before,
+		IncludeAnonymized: includeAnonymized,
+		AfterUpdatedAt:    time.Unix(0, 0),
+		Limit:             int32(limit),
+	}
+	if after != nil {
+		params.AfterUpdatedAt = after.UpdatedAt
+		params.AfterID = after.ID
+	}
+	rows, err := r.Queries.ListInactiveChats(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	chats := make([]*entity.InactiveChat, 0, len(rows))
+	for _, row := range rows {
+		chats = append(chats, &entity.InactiveChat{
+			ID:          row.ID,
+			TenantID:    row.TenantID,
+			AssistantID: row.AssistantID,
+			Anonymized:  row.UserID == "",
+			UpdatedAt:   row.UpdatedAt,
+		})
+	}
+	return chats, nil
+}
+
+// DeleteInactiveChat msgs, compartilhamentos e embeddings saem em cascata com o chat
+func (r *ChatRepository) DeleteInactiveChat(ctx context.Context, chat *entity.InactiveChat) (bool, error) {
+	affected, err := r.Queries.DeleteInactiveChat(ctx, db.DeleteInactiveChatParams{ID: chat.ID, UpdatedAt: chat.UpdatedAt})
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// AnonymizeChat tira o usuario e encerra o chat, apaga o conteudo das msgs, os compartilhamentos e os embeddings
+// (que representam o conteudo) na mesma transacao. Tokens, modelo e config ficam para as estatisticas
+func (r *ChatRepository) AnonymizeChat(ctx context.Context, chat *entity.InactiveChat) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	affected, err := queries.AnonymizeChat(ctx, db.AnonymizeChatParams{ID: chat.ID, UpdatedAt: chat.UpdatedAt})
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	if err := queries.AnonymizeChatMessages(ctx, chat.ID); err != nil {
+		return false, err
+	}
+	if err := queries.DeleteChatShares(ctx, db.DeleteChatSharesParams{ChatID: chat.ID, TenantID: chat.TenantID}); err != nil {
+		return false, err
+	}
+	if err := queries.DeleteChatEmbeddings(ctx, chat.ID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *ChatRepository) ListErasedMessages(ctx context.Context, before time.Time, afterID string, limit int) ([]*entity.ErasedMessage, error) {
+	rows, err := r.Queries.ListErasedMessagesBefore(ctx, db.ListErasedMessagesBeforeParams{
+		Before:  before,
+		AfterID: afterID,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]*entity.ErasedMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &entity.ErasedMessage{
+			ID:          row.ID,
+			ChatID:      row.ChatID,
+			TenantID:    row.TenantID,
+			AssistantID: row.AssistantID,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return messages, nil
+}
+
+// DeleteErasedMessage apaga a msg e o embedding dela, usado para relembrar a msg no chat
+func (r *ChatRepository) DeleteErasedMessage(ctx context.Context, messageID string) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	affected, err := queries.DeleteErasedMessage(ctx, messageID)
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	if err := queries.DeleteMessageEmbeddings(ctx, messageID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// SearchMessages busca full text (FULLTEXT index) nas mensagens, inclusive apagadas, dos chats do usuario no tenant da requisicao
+func (r *ChatRepository) SearchMessages(ctx context.Context, search gateway.MessageSearch) ([]*gateway.MessageSearchResult, error) {
+	params := db.SearchMessagesParams{
+		Query:       search.Query,
+		TenantID:    entity.TenantID(ctx),
+		UserID:      search.UserID,
+		ChatID:      search.ChatID,
+		Role:        search.Role,
+		CreatedFrom: search.From,
+		CreatedTo:   search.To,
+		Limit:       int32(search.Limit),
+		Offset:      int32(search.Offset),
+	}
+	if params.CreatedFrom.IsZero() {
+		params.CreatedFrom = time.Unix(0, 0)
+	}
+	if params.CreatedTo.IsZero() {
+		params.CreatedTo = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	rows, err := r.Queries.SearchMessages(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*gateway.MessageSearchResult, 0, len(rows))
+	for _, row := range rows {
+		//o indice full text so encontra as msgs em texto puro, por isso o servico nao liga a busca junto com a cifragem
+		content, err := r.Keyring.Decrypt(row.Content, row.KeyID, row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %w", row.ID, err)
+		}
+		results = append(results, &gateway.MessageSearchResult{
+			ChatID: row.ChatID,
+			Message: &entity.Message{
+				ID:        row.ID,
+				Role:      row.Role,
+				Content:   content,
+				CreatedAt: row.CreatedAt,
+			},
+			Erased: row.Erased,
+			Score:  row.Score,
+		})
+	}
+	return results, nil
+}
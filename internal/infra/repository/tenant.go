@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+type TenantRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewTenantRepositoryMySql(database *sql.DB) *TenantRepository {
+	return &TenantRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+func (r *TenantRepository) CreateTenant(ctx context.Context, tenant *entity.Tenant) error {
+	models, err := json.Marshal(allowedModels(tenant.AllowedModels))
+	if err != nil {
+		return err
+	}
+	return r.Queries.CreateTenant(ctx, db.CreateTenantParams{
+		ID:                 tenant.ID,
+		Name:               tenant.Name,
+		TokenHash:          tenant.TokenHash,
+		DefaultAssistantID: tenant.DefaultAssistantID,
+		AllowedModels:      models,
+		MonthlyTokenQuota:  int32(tenant.MonthlyTokenQuota),
+		CreatedAt:          tenant.CreatedAt,
+		UpdatedAt:          tenant.UpdatedAt,
+		ToolProfile:        tenant.ToolProfile,
+	})
+}
+
+func (r *TenantRepository) FindTenantByID(ctx context.Context, tenantID string) (*entity.Tenant, error) {
+	row, err := r.Queries.FindTenantByID(ctx, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toTenantEntity(row)
+}
+
+func (r *TenantRepository) FindTenantByTokenHash(ctx context.Context, tokenHash string) (*entity.Tenant, error) {
+	row, err := r.Queries.FindTenantByTokenHash(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toTenantEntity(row)
+}
+
+func (r *TenantRepository) ListTenants(ctx context.Context) ([]*entity.Tenant, error) {
+	rows, err := r.Queries.ListTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.Tenant, 0, len(rows))
+	for _, row := range rows {
+		tenant, err := toTenantEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, tenant)
+	}
+	return res, nil
+}
+
+func (r *TenantRepository) SaveTenant(ctx context.Context, tenant *entity.Tenant) error {
+	models, err := json.Marshal(allowedModels(tenant.AllowedModels))
+	if err != nil {
+		return err
+	}
+	return r.Queries.SaveTenant(ctx, db.SaveTenantParams{
+		Name:               tenant.Name,
+		DefaultAssistantID: tenant.DefaultAssistantID,
+		AllowedModels:      models,
+		MonthlyTokenQuota:  int32(tenant.MonthlyTokenQuota),
+		ToolProfile:        tenant.ToolProfile,
+		UpdatedAt:          tenant.UpdatedAt,
+		ID:                 tenant.ID,
+	})
+}
+
+// DeleteTenant remove o tenant e o uso, os chats dele ficam inacessiveis
+func (r *TenantRepository) DeleteTenant(ctx context.Context, tenantID string) error {
+	affected, err := r.Queries.DeleteTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrTenantNotFound
+	}
+	return nil
+}
+
+func (r *TenantRepository) AddTenantUsage(ctx context.Context, tenantID string, period string, tokens int) error {
+	return r.Queries.AddTenantUsage(ctx, db.AddTenantUsageParams{
+		TenantID: tenantID,
+		Period:   period,
+		Tokens:   int64(tokens),
+	})
+}
+
+func (r *TenantRepository) FindTenantUsage(ctx context.Context, tenantID string, period string) (int, error) {
+	tokens, err := r.Queries.FindTenantUsage(ctx, db.FindTenantUsageParams{
+		TenantID: tenantID,
+		Period:   period,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(tokens), nil
+}
+
+// allowedModels lista vazia em vez de null na coluna json
+func allowedModels(models []string) []string {
+	if models == nil {
+		return []string{}
+	}
+	return models
+}
+
+func toTenantEntity(row db.Tenant) (*entity.Tenant, error) {
+	var models []string
+	if err := json.Unmarshal(row.AllowedModels, &models); err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		models = nil
+	}
+	return &entity.Tenant{
+		ID:                 row.ID,
+		Name:               row.Name,
+		TokenHash:          row.TokenHash,
+		DefaultAssistantID: row.DefaultAssistantID,
+		AllowedModels:      models,
+		MonthlyTokenQuota:  int(row.MonthlyTokenQuota),
+		ToolProfile:        row.ToolProfile,
+		CreatedAt:          row.CreatedAt,
+		UpdatedAt:          row.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// FindUserData chats do usuario no tenant da requisicao com o conteudo decifrado, compartilhamentos e chaves de api
+func (r *ChatRepository) FindUserData(ctx context.Context, userID string) (*entity.UserData, error) {
+	tenantID := entity.TenantID(ctx)
+	data := &entity.UserData{UserID: userID, TenantID: tenantID}
+
+	rows, err := r.Queries.ListChatsByOwner(ctx, db.ListChatsByOwnerParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		chat, err := toChatEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		chat.SharedWith, err = r.Queries.FindChatSharesByChatID(ctx, db.FindChatSharesByChatIDParams{ChatID: chat.ID, TenantID: tenantID})
+		if err != nil {
+			return nil, err
+		}
+		messages, err := r.Queries.FindAllMessagesByChatID(ctx, db.FindAllMessagesByChatIDParams{ChatID: chat.ID, TenantID: tenantID})
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range messages {
+			message, err := r.toMessageEntity(msg, chat.Config.Model)
+			if err != nil {
+				return nil, err
+			}
+			if msg.ID == row.InitialMessageID {
+				chat.InitialSystemMessage = message
+			}
+			if msg.Erased {
+				chat.ErasedMessages = append(chat.ErasedMessages, message)
+			} else {
+				chat.Messages = append(chat.Messages, message)
+			}
+		}
+		data.Chats = append(data.Chats, chat)
+	}
+
+	shares, err := r.Queries.ListChatSharesByUserID(ctx, db.ListChatSharesByUserIDParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shares {
+		data.SharedChats = append(data.SharedChats, entity.ChatShare{
+			ChatID:    share.ChatID,
+			TenantID:  share.TenantID,
+			CreatedAt: share.CreatedAt,
+		})
+	}
+
+	keys, err := r.Queries.ListTenantAPIKeysByUserID(ctx, db.ListTenantAPIKeysByUserIDParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range keys {
+		key, err := toAPIKeyEntity(row)
+		if err != nil {
+			return nil, err
+		}
+		data.APIKeys = append(data.APIKeys, key)
+	}
+	return data, nil
+}
+
+// DeleteUserData apaga somente no tenant da requisicao, msgs, compartilhamentos e embeddings dos chats saem em cascata com o chat
+func (r *ChatRepository) DeleteUserData(ctx context.Context, userID string) (*entity.UserDataDeletion, error) {
+	tenantID := entity.TenantID(ctx)
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	deletion := &entity.UserDataDeletion{}
+	messages, err := queries.CountMessagesByOwner(ctx, db.CountMessagesByOwnerParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	deletion.Messages = int(messages)
+	chats, err := queries.DeleteChatsByOwner(ctx, db.DeleteChatsByOwnerParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	deletion.Chats = int(chats)
+	shares, err := queries.DeleteChatSharesByUserID(ctx, db.DeleteChatSharesByUserIDParams{UserID: userID, TenantID: tenantID})
+	if err != nil {
+		return nil, err
+	}
+	deletion.Shares = int(shares)
+
+	keys, err := queries.ListTenantAPIKeysByUserID(ctx, db.ListTenantAPIKeysByUserIDParams{TenantID: tenantID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		deletion.APIKeyIDs = append(deletion.APIKeyIDs, key.ID)
+	}
+	if _, err := queries.DeleteAPIKeysByUserID(ctx, db.DeleteAPIKeysByUserIDParams{TenantID: tenantID, UserID: userID}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
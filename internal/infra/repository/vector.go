@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/infra/db"
+)
+
+// VectorRepository vector store na tabela vectors, a consulta carrega o namespace e compara
+// todos os vetores (forca bruta), adequado para bases de ate algumas dezenas de milhares de vetores
+type VectorRepository struct {
+	DB      *sql.DB
+	Queries *db.Queries
+}
+
+func NewVectorRepositoryMySql(database *sql.DB) *VectorRepository {
+	return &VectorRepository{
+		DB:      database,
+		Queries: db.New(database),
+	}
+}
+
+func (r *VectorRepository) Upsert(ctx context.Context, namespace string, records []gateway.VectorRecord) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := r.Queries.WithTx(tx)
+
+	now := time.Now()
+	for _, record := range records {
+		metadata, err := json.Marshal(metadataOrEmpty(record.Metadata))
+		if err != nil {
+			return err
+		}
+		err = queries.UpsertVector(ctx, db.UpsertVectorParams{
+			Namespace:  namespace,
+			ID:         record.ID,
+			Dimensions: int32(len(record.Vector)),
+			Vector:     encodeVector(record.Vector),
+			Metadata:   metadata,
+			UpdatedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *VectorRepository) Delete(ctx context.Context, namespace string, ids []string) error {
+	for _, id := range ids {
+		err := r.Queries.DeleteVector(ctx, db.DeleteVectorParams{
+			Namespace: namespace,
+			ID:        id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *VectorRepository) Query(ctx context.Context, namespace string, query gateway.VectorQuery) ([]gateway.VectorMatch, error) {
+	rows, err := r.Queries.FindVectorsByNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []gateway.VectorMatch
+	for _, row := range rows {
+		var metadata map[string]string
+		if err := json.Unmarshal(row.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("vector %s: invalid metadata: %w", row.ID, err)
+		}
+		if !query.Matches(metadata) {
+			continue
+		}
+		vector, err := decodeVector(row.Vector, int(row.Dimensions))
+		if err != nil {
+			return nil, fmt.Errorf("vector %s: %w", row.ID, err)
+		}
+		score := entity.CosineSimilarity(query.Vector, vector)
+		if score < query.MinScore {
+			continue
+		}
+		matches = append(matches, gateway.VectorMatch{ID: row.ID, Score: score, Metadata: metadata})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if query.TopK > 0 && len(matches) > query.TopK {
+		matches = matches[:query.TopK]
+	}
+	return matches, nil
+}
+
+func metadataOrEmpty(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return metadata
+}
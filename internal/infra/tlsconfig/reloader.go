@@ -0,0 +1,198 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval intervalo minimo entre as checagens dos arquivos de certificado
+const DefaultReloadInterval = 10 * time.Second
+
+// modos de verificacao do certificado do cliente
+const (
+	ClientAuthNone    = "none"    // nao pede certificado do cliente
+	ClientAuthRequest = "request" // verifica o certificado quando o cliente envia (mtls opcional)
+	ClientAuthRequire = "require" // exige certificado valido do cliente (mtls)
+)
+
+// Options arquivos pem do servidor e da ca dos clientes
+type Options struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string // ca que assina os certificados dos clientes, obrigatoria com mtls
+	ClientAuth     string // none (padrao), request ou require
+	ReloadInterval time.Duration
+	Logger         *slog.Logger
+}
+
+// Reloader mantem o certificado do servidor e a ca dos clientes em memoria e relê os arquivos quando
+// eles mudam no disco, sem reiniciar o servico. A checagem roda nos handshakes, no maximo uma vez por intervalo,
+// e um arquivo invalido mantem os certificados anteriores
+type Reloader struct {
+	options    Options
+	clientAuth tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+// NewReloader retorna nil quando o certificado nao esta configurado (servidores sem tls)
+func NewReloader(options Options) (*Reloader, error) {
+	if options.CertFile == "" && options.KeyFile == "" {
+		return nil, nil
+	}
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, errors.New("tls requires both certificate and key files")
+	}
+	clientAuth, err := parseClientAuth(options.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	if clientAuth != tls.NoClientCert && options.ClientCAFile == "" {
+		return nil, fmt.Errorf("tls client auth %q requires a client ca file", options.ClientAuth)
+	}
+	if options.ReloadInterval <= 0 {
+		options.ReloadInterval = DefaultReloadInterval
+	}
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+
+	reloader := &Reloader{options: options, clientAuth: clientAuth}
+	if err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("invalid tls client auth %q, use none, request or require", mode)
+}
+
+// Reload le os arquivos de novo, em caso de erro os certificados atuais continuam valendo
+func (r *Reloader) Reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.options.ClientCAFile != "" {
+		data, err := os.ReadFile(r.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("error reading tls client ca file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("tls client ca file %s has no certificates", r.options.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.checkedAt = time.Now()
+	return nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.options.CertFile, r.options.KeyFile}
+	if r.options.ClientCAFile != "" {
+		files = append(files, r.options.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) stat() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading tls file: %w", err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// maybeReload relê os arquivos quando algum mudou desde a ultima carga
+func (r *Reloader) maybeReload() {
+	r.mu.Lock()
+	if time.Since(r.checkedAt) < r.options.ReloadInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.checkedAt = time.Now()
+	current := r.modTimes
+	r.mu.Unlock()
+
+	modTimes, err := r.stat()
+	if err != nil {
+		r.options.Logger.Error("tls reload failed, keeping current certificates", slog.String("error", err.Error()))
+		return
+	}
+	changed := false
+	for file, modTime := range modTimes {
+		if !modTime.Equal(current[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := r.Reload(); err != nil {
+		r.options.Logger.Error("tls reload failed, keeping current certificates", slog.String("error", err.Error()))
+		return
+	}
+	r.options.Logger.Info("tls certificates reloaded", slog.String("cert_file", r.options.CertFile))
+}
+
+// Config config tls do servidor, nextProtos sao os protocolos do alpn (h2 no grpc, h2 e http/1.1 no http).
+// Cada handshake usa o certificado e a ca carregados no momento
+func (r *Reloader) Config(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.certificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.maybeReload()
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    r.clientCAs,
+				NextProtos:   nextProtos,
+			}, nil
+		},
+	}
+}
+
+// certificate certificado atual, usado tambem pelo http.Server para saber que o tls tem certificado
+func (r *Reloader) certificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
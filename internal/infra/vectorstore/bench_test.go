@@ -0,0 +1,112 @@
+package vectorstore
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// benchmarks das implementacoes locais com vetores agrupados em clusters: tempo de upsert e de consulta,
+// e o recall@k do hnsw em relacao a busca exata (metrica recall@10)
+//
+//	go test -run '^$' -bench . ./internal/infra/vectorstore/
+const (
+	benchVectors  = 10000
+	benchDims     = 256
+	benchClusters = 100
+	benchTopK     = 10
+)
+
+var bench struct {
+	once    sync.Once
+	records []gateway.VectorRecord
+	probes  [][]float32
+	stores  map[string]*LocalStore
+}
+
+// benchData gera os vetores e indexa uma vez, o custo nao entra nos benchmarks de consulta
+func benchData(b *testing.B) {
+	b.Helper()
+	bench.once.Do(func() {
+		rng := rand.New(rand.NewSource(42))
+		bench.records = clusteredRecords(rng, benchVectors, benchDims, benchClusters)
+		bench.probes = probes(rng, bench.records, 200)
+		bench.stores = map[string]*LocalStore{}
+		for _, kind := range []string{KindFlat, KindHNSW} {
+			store, err := NewLocalStore("", kind, DefaultHNSWConfig)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := upsertBatches(store, bench.records); err != nil {
+				b.Fatal(err)
+			}
+			bench.stores[kind] = store
+		}
+	})
+}
+
+// upsertBatches upsert em lotes de 500, como numa ingestao de documentos
+func upsertBatches(store *LocalStore, records []gateway.VectorRecord) error {
+	for start := 0; start < len(records); start += 500 {
+		end := min(start+500, len(records))
+		if err := store.Upsert(context.Background(), "bench", records[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BenchmarkUpsert(b *testing.B) {
+	benchData(b)
+	for _, kind := range []string{KindFlat, KindHNSW} {
+		b.Run(kind, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				store, err := NewLocalStore("", kind, DefaultHNSWConfig)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := upsertBatches(store, bench.records); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	benchData(b)
+	filters := []struct {
+		name   string
+		filter map[string]string
+	}{
+		{"all", nil},
+		{"filter10", map[string]string{"group": "3"}},
+	}
+	for _, kind := range []string{KindFlat, KindHNSW} {
+		for _, f := range filters {
+			b.Run(kind+"/"+f.name, func(b *testing.B) {
+				store := bench.stores[kind]
+				ctx := context.Background()
+				for i := 0; i < b.N; i++ {
+					_, err := store.Query(ctx, "bench", gateway.VectorQuery{
+						Vector: bench.probes[i%len(bench.probes)],
+						TopK:   benchTopK,
+						Filter: f.filter,
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				if kind == KindHNSW {
+					b.StopTimer()
+					flat := bench.stores[KindFlat].index["bench"].(*flatIndex)
+					hnsw := store.index["bench"].(*hnswIndex)
+					b.ReportMetric(recall(flat, hnsw, bench.probes, benchTopK, f.filter), "recall@10")
+				}
+			})
+		}
+	}
+}
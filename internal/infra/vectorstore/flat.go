@@ -0,0 +1,70 @@
+package vectorstore
+
+import (
+	"sort"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// flatIndex busca exata (forca bruta), compara a consulta com todos os vetores do namespace
+type flatIndex struct {
+	Dimensions int
+	Records    map[string]*flatRecord
+}
+
+type flatRecord struct {
+	Vector   []float32 // normalizado, o produto escalar é a similaridade de cosseno
+	Metadata map[string]string
+}
+
+func newFlatIndex() *flatIndex {
+	return &flatIndex{
+		Records: make(map[string]*flatRecord),
+	}
+}
+
+func (f *flatIndex) dimensions() int {
+	return f.Dimensions
+}
+
+func (f *flatIndex) size() int {
+	return len(f.Records)
+}
+
+func (f *flatIndex) add(id string, vector []float32, metadata map[string]string) {
+	f.Dimensions = len(vector)
+	f.Records[id] = &flatRecord{Vector: vector, Metadata: metadata}
+}
+
+func (f *flatIndex) remove(id string) {
+	delete(f.Records, id)
+}
+
+func (f *flatIndex) search(query gateway.VectorQuery) []gateway.VectorMatch {
+	top := &topK{k: query.TopK}
+	for id, record := range f.Records {
+		if !query.Matches(record.Metadata) {
+			continue
+		}
+		score := dot(query.Vector, record.Vector)
+		if score < query.MinScore {
+			continue
+		}
+		top.add(gateway.VectorMatch{ID: id, Score: score, Metadata: record.Metadata})
+	}
+	return top.result()
+}
+
+// topMatches ordena por score (id desempata para o resultado ser estavel) e corta em k
+func topMatches(matches []gateway.VectorMatch, k int) []gateway.VectorMatch {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
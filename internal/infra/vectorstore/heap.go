@@ -0,0 +1,87 @@
+package vectorstore
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type candidate struct {
+	node  int32
+	score float32
+}
+
+// maxHeap candidatos a expandir, o mais proximo primeiro
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// minHeap melhores resultados encontrados, o mais distante no topo para ser descartado
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+}
+
+// topK guarda os k melhores resultados sem ordenar todos os registros, k <= 0 guarda todos
+type topK struct {
+	k       int
+	matches matchHeap
+}
+
+func (t *topK) add(m gateway.VectorMatch) {
+	if t.k <= 0 || len(t.matches) < t.k {
+		heap.Push(&t.matches, m)
+		return
+	}
+	if m.Score > t.matches[0].Score || (m.Score == t.matches[0].Score && m.ID < t.matches[0].ID) {
+		t.matches[0] = m
+		heap.Fix(&t.matches, 0)
+	}
+}
+
+func (t *topK) result() []gateway.VectorMatch {
+	return topMatches(t.matches, t.k)
+}
+
+// matchHeap pior resultado no topo
+type matchHeap []gateway.VectorMatch
+
+func (h matchHeap) Len() int { return len(h) }
+func (h matchHeap) Less(i, j int) bool {
+	if h[i].Score != h[j].Score {
+		return h[i].Score < h[j].Score
+	}
+	return h[i].ID > h[j].ID
+}
+func (h matchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(gateway.VectorMatch)) }
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
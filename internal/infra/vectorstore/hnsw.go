@@ -0,0 +1,328 @@
+package vectorstore
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// HNSWConfig parametros do grafo, valores maiores aumentam o recall e o custo de insercao/busca
+type HNSWConfig struct {
+	M              int // vizinhos por no nas camadas superiores, 2*M na camada 0
+	EfConstruction int // candidatos avaliados na insercao
+	EfSearch       int // candidatos avaliados na busca
+}
+
+var DefaultHNSWConfig = HNSWConfig{
+	M:              16,
+	EfConstruction: 100,
+	EfSearch:       64,
+}
+
+// hnswIndex busca aproximada em grafo navegavel de varias camadas (Hierarchical Navigable Small World).
+// Remocoes apenas marcam o no, que continua no grafo para a navegacao ate o indice ser compactado
+type hnswIndex struct {
+	Config     HNSWConfig
+	Dimensions int
+	Nodes      []*hnswNode
+	IDs        map[string]int32 // id -> no vivo
+	Entry      int32            // -1 com o grafo vazio
+	MaxLevel   int
+	Deleted    int
+
+	rng *rand.Rand
+}
+
+type hnswNode struct {
+	ID       string
+	Vector   []float32 // normalizado, o produto escalar é a similaridade de cosseno
+	Metadata map[string]string
+	Friends  [][]int32 // vizinhos por camada
+	Deleted  bool
+}
+
+func newHNSWIndex(config HNSWConfig) *hnswIndex {
+	if config.M <= 1 {
+		config.M = DefaultHNSWConfig.M
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = DefaultHNSWConfig.EfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = DefaultHNSWConfig.EfSearch
+	}
+	return &hnswIndex{
+		Config: config,
+		IDs:    make(map[string]int32),
+		Entry:  -1,
+	}
+}
+
+func (h *hnswIndex) dimensions() int {
+	return h.Dimensions
+}
+
+func (h *hnswIndex) size() int {
+	return len(h.IDs)
+}
+
+func (h *hnswIndex) add(id string, vector []float32, metadata map[string]string) {
+	h.remove(id)
+	h.Dimensions = len(vector)
+	if h.rng == nil {
+		h.rng = rand.New(rand.NewSource(int64(len(h.Nodes)) + 1))
+	}
+
+	level := h.randomLevel()
+	node := &hnswNode{
+		ID:       id,
+		Vector:   vector,
+		Metadata: metadata,
+		Friends:  make([][]int32, level+1),
+	}
+	n := int32(len(h.Nodes))
+	h.Nodes = append(h.Nodes, node)
+	h.IDs[id] = n
+
+	if h.Entry < 0 {
+		h.Entry = n
+		h.MaxLevel = level
+		return
+	}
+
+	//desce pelas camadas acima do nivel do no pelo caminho guloso
+	ep := h.Entry
+	for lc := h.MaxLevel; lc > level; lc-- {
+		ep = h.greedy(vector, ep, lc)
+	}
+
+	eps := []int32{ep}
+	for lc := min(level, h.MaxLevel); lc >= 0; lc-- {
+		candidates := h.searchLayer(vector, eps, h.Config.EfConstruction, lc)
+		neighbors := h.selectNeighbors(candidates, h.maxFriends(lc))
+		node.Friends[lc] = make([]int32, 0, len(neighbors))
+		for _, c := range neighbors {
+			node.Friends[lc] = append(node.Friends[lc], c.node)
+			h.link(c.node, n, lc)
+		}
+		eps = eps[:0]
+		for _, c := range candidates {
+			eps = append(eps, c.node)
+		}
+	}
+
+	if level > h.MaxLevel {
+		h.Entry = n
+		h.MaxLevel = level
+	}
+}
+
+func (h *hnswIndex) remove(id string) {
+	n, ok := h.IDs[id]
+	if !ok {
+		return
+	}
+	h.Nodes[n].Deleted = true
+	h.Nodes[n].Metadata = nil
+	delete(h.IDs, id)
+	h.Deleted++
+
+	//com metade do grafo removido a busca fica lenta e imprecisa, reconstroi so com os nos vivos
+	if h.Deleted > len(h.IDs) {
+		h.compact()
+	}
+}
+
+func (h *hnswIndex) compact() {
+	nodes := h.Nodes
+	*h = *newHNSWIndex(h.Config)
+	for _, node := range nodes {
+		if !node.Deleted {
+			h.add(node.ID, node.Vector, node.Metadata)
+		}
+	}
+}
+
+func (h *hnswIndex) search(query gateway.VectorQuery) []gateway.VectorMatch {
+	if h.Entry < 0 {
+		return nil
+	}
+	if query.TopK <= 0 {
+		return h.exhaustive(query)
+	}
+
+	ep := h.Entry
+	for lc := h.MaxLevel; lc > 0; lc-- {
+		ep = h.greedy(query.Vector, ep, lc)
+	}
+	ef := max(h.Config.EfSearch, query.TopK)
+
+	//com filtro ou nos removidos parte dos candidatos é descartada, amplia a busca algumas vezes
+	//antes de cair na busca exata, que garante o resultado mesmo com filtros muito restritivos
+	for attempt := 0; attempt < 3; attempt++ {
+		matches, excluded := h.searchFiltered(query, ep, ef)
+		if len(matches) >= query.TopK || !excluded || ef >= len(h.Nodes) {
+			return topMatches(matches, query.TopK)
+		}
+		ef *= 4
+	}
+	return h.exhaustive(query)
+}
+
+func (h *hnswIndex) searchFiltered(query gateway.VectorQuery, ep int32, ef int) ([]gateway.VectorMatch, bool) {
+	excluded := false
+	var matches []gateway.VectorMatch
+	for _, c := range h.searchLayer(query.Vector, []int32{ep}, ef, 0) {
+		node := h.Nodes[c.node]
+		if node.Deleted || !query.Matches(node.Metadata) {
+			excluded = true
+			continue
+		}
+		if c.score < query.MinScore {
+			continue
+		}
+		matches = append(matches, gateway.VectorMatch{ID: node.ID, Score: c.score, Metadata: node.Metadata})
+	}
+	return matches, excluded
+}
+
+func (h *hnswIndex) exhaustive(query gateway.VectorQuery) []gateway.VectorMatch {
+	top := &topK{k: query.TopK}
+	for _, n := range h.IDs {
+		node := h.Nodes[n]
+		if !query.Matches(node.Metadata) {
+			continue
+		}
+		score := dot(query.Vector, node.Vector)
+		if score < query.MinScore {
+			continue
+		}
+		top.add(gateway.VectorMatch{ID: node.ID, Score: score, Metadata: node.Metadata})
+	}
+	return top.result()
+}
+
+func (h *hnswIndex) randomLevel() int {
+	ml := 1 / math.Log(float64(h.Config.M))
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * ml))
+}
+
+func (h *hnswIndex) maxFriends(level int) int {
+	if level == 0 {
+		return 2 * h.Config.M
+	}
+	return h.Config.M
+}
+
+// greedy caminha na camada ate o no mais proximo da consulta
+func (h *hnswIndex) greedy(vector []float32, ep int32, level int) int32 {
+	best := dot(vector, h.Nodes[ep].Vector)
+	for changed := true; changed; {
+		changed = false
+		for _, f := range h.Nodes[ep].Friends[level] {
+			if score := dot(vector, h.Nodes[f].Vector); score > best {
+				best, ep, changed = score, f, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer retorna os ef nos mais proximos encontrados na camada, do maior para o menor score
+func (h *hnswIndex) searchLayer(vector []float32, eps []int32, ef int, level int) []candidate {
+	visited := make([]bool, len(h.Nodes))
+	candidates := &maxHeap{}
+	results := &minHeap{}
+	for _, ep := range eps {
+		visited[ep] = true
+		c := candidate{node: ep, score: dot(vector, h.Nodes[ep].Vector)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+	}
+	for results.Len() > ef {
+		heap.Pop(results)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && c.score < (*results)[0].score {
+			break
+		}
+		for _, f := range h.Nodes[c.node].Friends[level] {
+			if visited[f] {
+				continue
+			}
+			visited[f] = true
+			score := dot(vector, h.Nodes[f].Vector)
+			if results.Len() < ef || score > (*results)[0].score {
+				heap.Push(candidates, candidate{node: f, score: score})
+				heap.Push(results, candidate{node: f, score: score})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	res := make([]candidate, results.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(results).(candidate)
+	}
+	return res
+}
+
+// selectNeighbors heuristica do artigo: so aceita o candidato se ele estiver mais perto do no
+// do que dos vizinhos ja escolhidos, o que mantem arestas em direcoes diferentes
+func (h *hnswIndex) selectNeighbors(candidates []candidate, m int) []candidate {
+	selected := make([]candidate, 0, m)
+	var skipped []candidate
+	for _, c := range candidates {
+		if len(selected) == m {
+			break
+		}
+		keep := true
+		for _, s := range selected {
+			if dot(h.Nodes[c.node].Vector, h.Nodes[s.node].Vector) > c.score {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+	//completa com os descartados para nao deixar o no com poucas arestas
+	for _, c := range skipped {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// link adiciona a aresta from -> to, podando os vizinhos mais distantes de from quando passa do limite da camada
+func (h *hnswIndex) link(from, to int32, level int) {
+	node := h.Nodes[from]
+	node.Friends[level] = append(node.Friends[level], to)
+	limit := h.maxFriends(level)
+	if len(node.Friends[level]) <= limit {
+		return
+	}
+
+	candidates := make([]candidate, len(node.Friends[level]))
+	for i, f := range node.Friends[level] {
+		candidates[i] = candidate{node: f, score: dot(node.Vector, h.Nodes[f].Vector)}
+	}
+	//a poda usa a mesma heuristica da insercao, manter so os mais proximos corta as arestas entre
+	//grupos de vetores parecidos e deixa partes do grafo inalcancaveis
+	sortCandidates(candidates)
+	node.Friends[level] = node.Friends[level][:0]
+	for _, c := range h.selectNeighbors(candidates, limit) {
+		node.Friends[level] = append(node.Friends[level], c.node)
+	}
+}
@@ -0,0 +1,170 @@
+package vectorstore
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// clusteredRecords vetores agrupados em clusters, embeddings reais nao sao uniformes
+func clusteredRecords(rng *rand.Rand, n, dims, clusters int) []gateway.VectorRecord {
+	centroids := make([][]float32, clusters)
+	for i := range centroids {
+		centroids[i] = randomVector(rng, dims, nil, 1)
+	}
+	records := make([]gateway.VectorRecord, n)
+	for i := range records {
+		records[i] = gateway.VectorRecord{
+			ID:       strconv.Itoa(i),
+			Vector:   normalize(randomVector(rng, dims, centroids[rng.Intn(clusters)], 0.3)),
+			Metadata: map[string]string{"group": strconv.Itoa(i % 10)},
+		}
+	}
+	return records
+}
+
+// randomVector ruido normal com desvio sigma em volta de center (origem quando nil)
+func randomVector(rng *rand.Rand, dims int, center []float32, sigma float64) []float32 {
+	v := make([]float32, dims)
+	for i := range v {
+		v[i] = float32(rng.NormFloat64() * sigma)
+		if center != nil {
+			v[i] += center[i]
+		}
+	}
+	return v
+}
+
+func buildIndexes(records []gateway.VectorRecord) (*flatIndex, *hnswIndex) {
+	flat, hnsw := newFlatIndex(), newHNSWIndex(DefaultHNSWConfig)
+	for _, r := range records {
+		flat.add(r.ID, r.Vector, r.Metadata)
+		hnsw.add(r.ID, r.Vector, r.Metadata)
+	}
+	return flat, hnsw
+}
+
+// recall fracao dos k vizinhos exatos que o hnsw tambem retorna
+func recall(flat *flatIndex, hnsw *hnswIndex, probes [][]float32, k int, filter map[string]string) float64 {
+	var found, total int
+	for _, probe := range probes {
+		query := gateway.VectorQuery{Vector: probe, TopK: k, Filter: filter}
+		seen := map[string]bool{}
+		for _, m := range hnsw.search(query) {
+			seen[m.ID] = true
+		}
+		for _, m := range flat.search(query) {
+			if seen[m.ID] {
+				found++
+			}
+			total++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(found) / float64(total)
+}
+
+func probes(rng *rand.Rand, records []gateway.VectorRecord, n int) [][]float32 {
+	res := make([][]float32, n)
+	for i := range res {
+		res[i] = normalize(randomVector(rng, len(records[0].Vector), records[rng.Intn(len(records))].Vector, 0.1))
+	}
+	return res
+}
+
+func TestHNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	records := clusteredRecords(rng, 1000, 32, 20)
+	flat, hnsw := buildIndexes(records)
+	queries := probes(rng, records, 50)
+
+	if r := recall(flat, hnsw, queries, 10, nil); r < 0.9 {
+		t.Fatalf("expected recall@10 >= 0.9, got %.3f", r)
+	}
+	//filtro de 10% dos vetores amplia a busca ou cai na exata
+	if r := recall(flat, hnsw, queries, 10, map[string]string{"group": "3"}); r < 0.9 {
+		t.Fatalf("expected filtered recall@10 >= 0.9, got %.3f", r)
+	}
+}
+
+func TestHNSWRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	records := clusteredRecords(rng, 300, 16, 5)
+	flat, hnsw := buildIndexes(records)
+
+	removed := map[string]bool{}
+	for _, r := range records[:100] {
+		hnsw.remove(r.ID)
+		flat.remove(r.ID)
+		removed[r.ID] = true
+	}
+	hnsw.remove("missing")
+
+	//menos da metade removida: os nos continuam no grafo, somente marcados
+	if hnsw.size() != 200 || hnsw.Deleted != 100 || len(hnsw.Nodes) != 300 {
+		t.Fatalf("expected 200 live and 100 deleted nodes, got size %d deleted %d nodes %d", hnsw.size(), hnsw.Deleted, len(hnsw.Nodes))
+	}
+	for _, r := range records[:100] {
+		for _, topK := range []int{10, 0} {
+			for _, m := range hnsw.search(gateway.VectorQuery{Vector: r.Vector, TopK: topK}) {
+				if removed[m.ID] {
+					t.Fatalf("removed vector %s returned by the search (topK %d)", m.ID, topK)
+				}
+			}
+		}
+	}
+	if r := recall(flat, hnsw, probes(rng, records[100:], 30), 10, nil); r < 0.9 {
+		t.Fatalf("expected recall@10 >= 0.9 after removals, got %.3f", r)
+	}
+}
+
+func TestHNSWCompact(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	records := clusteredRecords(rng, 300, 16, 5)
+	flat, hnsw := buildIndexes(records)
+
+	//passando da metade o grafo é reconstruido somente com os nos vivos
+	for _, r := range records[:151] {
+		hnsw.remove(r.ID)
+		flat.remove(r.ID)
+	}
+	if hnsw.size() != 149 || hnsw.Deleted != 0 || len(hnsw.Nodes) != 149 {
+		t.Fatalf("expected a compacted index with 149 nodes, got size %d deleted %d nodes %d", hnsw.size(), hnsw.Deleted, len(hnsw.Nodes))
+	}
+	for id, n := range hnsw.IDs {
+		if node := hnsw.Nodes[n]; node.ID != id || node.Deleted || node.Metadata == nil {
+			t.Fatalf("inconsistent node %d for %s: %+v", n, id, node)
+		}
+	}
+	if r := recall(flat, hnsw, probes(rng, records[151:], 30), 10, map[string]string{"group": "4"}); r < 0.9 {
+		t.Fatalf("expected filtered recall@10 >= 0.9 after compaction, got %.3f", r)
+	}
+
+	//o indice compactado continua aceitando insercoes
+	hnsw.add("new", records[0].Vector, nil)
+	if matches := hnsw.search(gateway.VectorQuery{Vector: records[0].Vector, TopK: 1}); len(matches) != 1 || matches[0].ID != "new" {
+		t.Fatalf("expected the new vector, got %v", matches)
+	}
+}
+
+func TestHNSWAddReplaces(t *testing.T) {
+	hnsw := newHNSWIndex(DefaultHNSWConfig)
+	hnsw.add("a", normalize([]float32{1, 0, 0}), map[string]string{"v": "1"})
+	hnsw.add("b", normalize([]float32{0, 1, 0}), nil)
+	hnsw.add("a", normalize([]float32{0, 0, 1}), map[string]string{"v": "2"})
+
+	if hnsw.size() != 2 {
+		t.Fatalf("expected 2 vectors, got %d", hnsw.size())
+	}
+	matches := hnsw.search(gateway.VectorQuery{Vector: []float32{0, 0, 1}, TopK: 1})
+	if len(matches) != 1 || matches[0].ID != "a" || matches[0].Metadata["v"] != "2" {
+		t.Fatalf("expected the replaced vector, got %v", matches)
+	}
+	if matches := hnsw.search(gateway.VectorQuery{Vector: []float32{1, 0, 0}, TopK: 2, MinScore: 0.5}); len(matches) != 0 {
+		t.Fatalf("expected the old vector to be gone, got %v", matches)
+	}
+}
@@ -0,0 +1,314 @@
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+const (
+	KindFlat = "flat" // busca exata
+	KindHNSW = "hnsw" // busca aproximada, mais rapida com muitos vetores
+)
+
+// DefaultFlushInterval intervalo padrao entre as gravacoes do indice em disco
+const DefaultFlushInterval = 5 * time.Second
+
+type index interface {
+	dimensions() int
+	size() int
+	add(id string, vector []float32, metadata map[string]string)
+	remove(id string)
+	search(query gateway.VectorQuery) []gateway.VectorMatch
+}
+
+// LocalStore vector store em memoria no proprio processo, sem banco de vetores externo.
+// Com Path informado o indice é carregado do arquivo na criacao e gravado pelo Flush, as alteracoes
+// somente marcam o indice como sujo para nao serializar o arquivo inteiro a cada upsert
+type LocalStore struct {
+	Path   string
+	Kind   string
+	HNSW   HNSWConfig
+	mu     sync.RWMutex
+	index  map[string]index
+	dirty  atomic.Bool // alterado desde o ultimo Flush
+	saveMu sync.Mutex  // um Flush por vez gravando o arquivo
+}
+
+// snapshot formato do arquivo em disco (gob)
+type snapshot struct {
+	Kind string
+	Flat map[string]*flatIndex
+	HNSW map[string]*hnswIndex
+}
+
+func NewLocalStore(path string, kind string, config HNSWConfig) (*LocalStore, error) {
+	if kind == "" {
+		kind = KindFlat
+	}
+	if kind != KindFlat && kind != KindHNSW {
+		return nil, fmt.Errorf("invalid vector index kind: %s", kind)
+	}
+	s := &LocalStore{
+		Path:  path,
+		Kind:  kind,
+		HNSW:  config,
+		index: make(map[string]index),
+	}
+	if path == "" {
+		return s, nil
+	}
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("error loading vector index %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *LocalStore) Upsert(ctx context.Context, namespace string, records []gateway.VectorRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.index[namespace]
+	dims := 0
+	if idx != nil && idx.size() > 0 {
+		dims = idx.dimensions()
+	}
+	for _, r := range records {
+		if len(r.Vector) == 0 {
+			return fmt.Errorf("vector %s is empty", r.ID)
+		}
+		if dims == 0 {
+			dims = len(r.Vector)
+		}
+		if len(r.Vector) != dims {
+			return fmt.Errorf("vector %s has %d dimensions, namespace %s uses %d", r.ID, len(r.Vector), namespace, dims)
+		}
+	}
+
+	if idx == nil {
+		idx = s.newIndex()
+		s.index[namespace] = idx
+	}
+	for _, r := range records {
+		idx.add(r.ID, normalize(r.Vector), r.Metadata)
+	}
+	s.dirty.Store(true)
+	return nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, namespace string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.index[namespace]
+	if idx == nil {
+		return nil
+	}
+	for _, id := range ids {
+		idx.remove(id)
+	}
+	s.dirty.Store(true)
+	return nil
+}
+
+func (s *LocalStore) Query(ctx context.Context, namespace string, query gateway.VectorQuery) ([]gateway.VectorMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := s.index[namespace]
+	if idx == nil || idx.size() == 0 {
+		return nil, nil
+	}
+	if len(query.Vector) != idx.dimensions() {
+		return nil, fmt.Errorf("query has %d dimensions, namespace %s uses %d", len(query.Vector), namespace, idx.dimensions())
+	}
+	query.Vector = normalize(query.Vector)
+	return idx.search(query), nil
+}
+
+func (s *LocalStore) newIndex() index {
+	if s.Kind == KindHNSW {
+		return newHNSWIndex(s.HNSW)
+	}
+	return newFlatIndex()
+}
+
+func (s *LocalStore) load() error {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(f).Decode(&snap); err != nil {
+		return err
+	}
+	//arquivo gravado com outro tipo de indice é reconstruido no tipo configurado
+	for namespace, idx := range snap.Flat {
+		if idx.Records == nil {
+			idx.Records = make(map[string]*flatRecord)
+		}
+		s.index[namespace] = s.convert(idx, snap.Kind)
+	}
+	for namespace, idx := range snap.HNSW {
+		if idx.IDs == nil {
+			idx.IDs = make(map[string]int32)
+		}
+		s.index[namespace] = s.convert(idx, snap.Kind)
+	}
+	return nil
+}
+
+func (s *LocalStore) convert(idx index, kind string) index {
+	if kind == s.Kind {
+		return idx
+	}
+	converted := s.newIndex()
+	switch from := idx.(type) {
+	case *flatIndex:
+		for id, r := range from.Records {
+			converted.add(id, r.Vector, r.Metadata)
+		}
+	case *hnswIndex:
+		for id, n := range from.IDs {
+			converted.add(id, from.Nodes[n].Vector, from.Nodes[n].Metadata)
+		}
+	}
+	return converted
+}
+
+// Run grava o indice a cada interval e uma ultima vez quando ctx termina
+func (s *LocalStore) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush()
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+func (s *LocalStore) flush() {
+	if err := s.Flush(); err != nil {
+		slog.Error("error saving vector index", slog.String("path", s.Path), slog.String("error", err.Error()))
+	}
+}
+
+// Flush grava o indice em disco quando houve alteracao desde a ultima gravacao.
+// O snapshot é serializado com o lock de leitura, a escrita e o fsync acontecem fora dele
+func (s *LocalStore) Flush() error {
+	if s.Path == "" {
+		return nil
+	}
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.RLock()
+	if !s.dirty.Swap(false) {
+		s.mu.RUnlock()
+		return nil
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(s.snapshot())
+	s.mu.RUnlock()
+	if err == nil {
+		err = s.write(buf.Bytes())
+	}
+	if err != nil {
+		//tenta de novo no proximo Flush
+		s.dirty.Store(true)
+	}
+	return err
+}
+
+func (s *LocalStore) snapshot() *snapshot {
+	snap := &snapshot{Kind: s.Kind, Flat: map[string]*flatIndex{}, HNSW: map[string]*hnswIndex{}}
+	for namespace, idx := range s.index {
+		switch idx := idx.(type) {
+		case *flatIndex:
+			snap.Flat[namespace] = idx
+		case *hnswIndex:
+			snap.HNSW[namespace] = idx
+		}
+	}
+	return snap
+}
+
+// write grava em arquivo temporario e renomeia, o arquivo nunca fica pela metade
+func (s *LocalStore) write(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+	res := make([]float32, len(v))
+	if norm == 0 {
+		return res
+	}
+	norm = math.Sqrt(norm)
+	for i, f := range v {
+		res[i] = float32(float64(f) / norm)
+	}
+	return res
+}
+
+// dot produto escalar, desenrolado em 4 acumuladores (é o ponto mais quente da busca)
+func dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
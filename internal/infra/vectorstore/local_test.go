@@ -0,0 +1,131 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+func testRecords(n int) []gateway.VectorRecord {
+	records := make([]gateway.VectorRecord, n)
+	for i := range records {
+		v := make([]float32, 8)
+		v[i%8] = 1
+		v[(i+1)%8] = float32(i%5) / 10
+		records[i] = gateway.VectorRecord{
+			ID:       strconv.Itoa(i),
+			Vector:   v,
+			Metadata: map[string]string{"group": strconv.Itoa(i % 3)},
+		}
+	}
+	return records
+}
+
+func TestLocalStoreFlushAndReload(t *testing.T) {
+	for _, kind := range []string{KindFlat, KindHNSW} {
+		t.Run(kind, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "vectors.gob")
+			ctx := context.Background()
+			store, err := NewLocalStore(path, kind, DefaultHNSWConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Upsert(ctx, "kb", testRecords(40)); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Delete(ctx, "kb", []string{"0", "1"}); err != nil {
+				t.Fatal(err)
+			}
+			//as alteracoes nao gravam o arquivo, somente o Flush
+			if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("expected no file before Flush, got %v", err)
+			}
+			if err := store.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			reloaded, err := NewLocalStore(path, kind, DefaultHNSWConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := gateway.VectorQuery{Vector: testRecords(40)[9].Vector, TopK: 5, Filter: map[string]string{"group": "0"}}
+			want, err := store.Query(ctx, "kb", query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := reloaded.Query(ctx, "kb", query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(want) == 0 || !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected %v after reload, got %v", want, got)
+			}
+			for _, m := range got {
+				if m.ID == "0" {
+					t.Fatal("deleted vector came back after reload")
+				}
+			}
+		})
+	}
+}
+
+func TestLocalStoreReloadConvertsKind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.gob")
+	ctx := context.Background()
+	flat, err := NewLocalStore(path, KindFlat, DefaultHNSWConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flat.Upsert(ctx, "kb", testRecords(20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := flat.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	hnsw, err := NewLocalStore(path, KindHNSW, DefaultHNSWConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, err := hnsw.Query(ctx, "kb", gateway.VectorQuery{Vector: testRecords(20)[3].Vector, TopK: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].ID != "3" {
+		t.Fatalf("expected vector 3 from the converted index, got %v", matches)
+	}
+}
+
+func TestLocalStoreRunFlushesOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.gob")
+	store, err := NewLocalStore(path, KindFlat, DefaultHNSWConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upsert(context.Background(), "kb", testRecords(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.Run(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the index to be saved on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+)
+
+// WebAPIKeyHandler api de administracao das chaves de api dos usuarios
+type WebAPIKeyHandler struct {
+	CreateUseCase apikey.CreateAPIKeyUseCase
+	ListUseCase   apikey.ListAPIKeysUseCase
+	RevokeUseCase apikey.RevokeAPIKeyUseCase
+}
+
+func NewWebAPIKeyHandler(create apikey.CreateAPIKeyUseCase, list apikey.ListAPIKeysUseCase, revoke apikey.RevokeAPIKeyUseCase) *WebAPIKeyHandler {
+	return &WebAPIKeyHandler{
+		CreateUseCase: create,
+		ListUseCase:   list,
+		RevokeUseCase: revoke,
+	}
+}
+
+// Keys GET /admin/api-keys?user_id= lista as chaves, POST cria uma chave e retorna a chave uma unica vez
+func (h *WebAPIKeyHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.ListUseCase.Execute(r.Context(), apikey.ListAPIKeysInputDTO{UserID: r.URL.Query().Get("user_id")})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodPost:
+		var dto apikey.CreateAPIKeyInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.CreateUseCase.Execute(r.Context(), dto)
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// Key DELETE /admin/api-keys/{keyID} revoga a chave
+func (h *WebAPIKeyHandler) Key(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	if err := h.RevokeUseCase.Execute(r.Context(), apikey.RevokeAPIKeyInputDTO{ID: chi.URLParam(r, "keyID")}); err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/assistant"
+)
+
+// WebAssistantHandler api de administracao dos assistentes
+type WebAssistantHandler struct {
+	CreateUseCase assistant.CreateAssistantUseCase
+	GetUseCase    assistant.GetAssistantUseCase
+	ListUseCase   assistant.ListAssistantsUseCase
+	UpdateUseCase assistant.UpdateAssistantUseCase
+	DeleteUseCase assistant.DeleteAssistantUseCase
+}
+
+func NewWebAssistantHandler(create assistant.CreateAssistantUseCase, get assistant.GetAssistantUseCase, list assistant.ListAssistantsUseCase, update assistant.UpdateAssistantUseCase, remove assistant.DeleteAssistantUseCase) *WebAssistantHandler {
+	return &WebAssistantHandler{
+		CreateUseCase: create,
+		GetUseCase:    get,
+		ListUseCase:   list,
+		UpdateUseCase: update,
+		DeleteUseCase: remove,
+	}
+}
+
+// Assistants GET /admin/assistants lista os assistentes, POST cria um assistente
+func (h *WebAssistantHandler) Assistants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.ListUseCase.Execute(r.Context())
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodPost:
+		var dto assistant.AssistantInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.CreateUseCase.Execute(r.Context(), dto)
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// Assistant GET, PUT (substitui a config) e DELETE /admin/assistants/{assistantID}
+func (h *WebAssistantHandler) Assistant(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "assistantID")
+
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.GetUseCase.Execute(r.Context(), assistant.GetAssistantInputDTO{ID: id})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodPut:
+		var dto assistant.AssistantInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.UpdateUseCase.Execute(r.Context(), assistant.UpdateAssistantInputDTO{ID: id, AssistantInputDTO: dto})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodDelete:
+		if err := h.DeleteUseCase.Execute(r.Context(), assistant.DeleteAssistantInputDTO{ID: id}); err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
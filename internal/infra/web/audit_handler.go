@@ -0,0 +1,94 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+// WebAuditHandler api de administracao do log de auditoria: exportacao e verificacao da cadeia
+type WebAuditHandler struct {
+	ExportUseCase audit.ExportAuditLogUseCase
+	VerifyUseCase audit.VerifyAuditLogUseCase
+}
+
+func NewWebAuditHandler(export audit.ExportAuditLogUseCase, verify audit.VerifyAuditLogUseCase) *WebAuditHandler {
+	return &WebAuditHandler{
+		ExportUseCase: export,
+		VerifyUseCase: verify,
+	}
+}
+
+// Export GET /admin/audit?after_seq=&type=&from=&to=&limit= eventos em ordem de seq, from e to em RFC3339.
+// next_seq é o after_seq da proxima pagina
+func (h *WebAuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := queryLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid limit")
+		return
+	}
+	afterSeq, err := querySeq(query.Get("after_seq"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid after_seq")
+		return
+	}
+	from, err := queryTime(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid from, expected RFC3339")
+		return
+	}
+	to, err := queryTime(query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid to, expected RFC3339")
+		return
+	}
+
+	result, err := h.ExportUseCase.Execute(r.Context(), audit.ExportAuditLogInputDTO{
+		AfterSeq: afterSeq,
+		Type:     query.Get("type"),
+		From:     from,
+		To:       to,
+		Limit:    limit,
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// Verify GET /admin/audit/verify?anchor_seq=&anchor_hash= confere a cadeia inteira
+func (h *WebAuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	anchorSeq, err := querySeq(r.URL.Query().Get("anchor_seq"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid anchor_seq")
+		return
+	}
+	result, err := h.VerifyUseCase.Execute(r.Context(), audit.VerifyAuditLogInputDTO{
+		AnchorSeq:  anchorSeq,
+		AnchorHash: r.URL.Query().Get("anchor_hash"),
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+func querySeq(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
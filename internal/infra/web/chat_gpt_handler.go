@@ -11,36 +11,29 @@ import (
 type WebChatGPTHandler struct {
 	CompletionUseCase chatcompletion.ChatCompletionUseCase
 	Config            chatcompletion.ChatCompletionConfigInputDTO
-	AuthToken         string
 }
 
-func NewWebChatGPTHandler(usecase chatcompletion.ChatCompletionUseCase, config chatcompletion.ChatCompletionConfigInputDTO, token string) *WebChatGPTHandler {
+func NewWebChatGPTHandler(usecase chatcompletion.ChatCompletionUseCase, config chatcompletion.ChatCompletionConfigInputDTO) *WebChatGPTHandler {
 	return &WebChatGPTHandler{
 		CompletionUseCase: usecase,
 		Config:            config,
-		AuthToken:         token,
 	}
 }
 
 func (h *WebChatGPTHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	if r.Header.Get("Authorization") != h.AuthToken {
-		w.WriteHeader(http.StatusUnauthorized)
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
 		return
 	}
 
 	if !json.Valid(body) {
-		http.Error(w, "invalid json body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
 		return
 	}
 
@@ -48,18 +41,18 @@ func (h *WebChatGPTHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	//inserir o conteudo do body no dto
 	err = json.Unmarshal(body, &dto)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "invalid_body", err.Error())
 		return
 	}
 	dto.Config = h.Config
 
 	result, err := h.CompletionUseCase.Execute(r.Context(), dto)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		WriteDomainError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
 }
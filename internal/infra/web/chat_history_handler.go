@@ -0,0 +1,93 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	chathistory "github.com/ruhancs/virtual-assistant/internal/usecase/chat_history"
+)
+
+type WebChatHistoryHandler struct {
+	ListChatsUseCase    chathistory.ListChatsUseCase
+	ListMessagesUseCase chathistory.ListMessagesUseCase
+}
+
+func NewWebChatHistoryHandler(listChats chathistory.ListChatsUseCase, listMessages chathistory.ListMessagesUseCase) *WebChatHistoryHandler {
+	return &WebChatHistoryHandler{
+		ListChatsUseCase:    listChats,
+		ListMessagesUseCase: listMessages,
+	}
+}
+
+// ListChats GET /chats?user_id=&cursor=&limit=
+func (h *WebChatHistoryHandler) ListChats(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	limit, err := queryLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid limit")
+		return
+	}
+
+	result, err := h.ListChatsUseCase.Execute(r.Context(), chathistory.ListChatsInputDTO{
+		UserID: r.URL.Query().Get("user_id"),
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// ListMessages GET /chats/{chatID}/messages?user_id=&cursor=&limit=
+func (h *WebChatHistoryHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	if !checkMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	limit, err := queryLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid limit")
+		return
+	}
+
+	result, err := h.ListMessagesUseCase.Execute(r.Context(), chathistory.ListMessagesInputDTO{
+		ChatID: chi.URLParam(r, "chatID"),
+		UserID: r.URL.Query().Get("user_id"),
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+func checkMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return false
+	}
+	return true
+}
+
+func queryLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(v)
+}
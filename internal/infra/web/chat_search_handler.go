@@ -0,0 +1,66 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	chatsearch "github.com/ruhancs/virtual-assistant/internal/usecase/chat_search"
+)
+
+type WebChatSearchHandler struct {
+	SearchUseCase chatsearch.SearchMessagesUseCase
+}
+
+func NewWebChatSearchHandler(usecase chatsearch.SearchMessagesUseCase) *WebChatSearchHandler {
+	return &WebChatSearchHandler{
+		SearchUseCase: usecase,
+	}
+}
+
+// Handle GET /search?user_id=&q=&chat_id=&role=&from=&to=&cursor=&limit=, from e to em RFC3339
+func (h *WebChatSearchHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := queryLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid limit")
+		return
+	}
+	from, err := queryTime(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid from, expected RFC3339")
+		return
+	}
+	to, err := queryTime(query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid to, expected RFC3339")
+		return
+	}
+
+	result, err := h.SearchUseCase.Execute(r.Context(), chatsearch.SearchMessagesInputDTO{
+		UserID: query.Get("user_id"),
+		Query:  query.Get("q"),
+		ChatID: query.Get("chat_id"),
+		Role:   query.Get("role"),
+		From:   from,
+		To:     to,
+		Cursor: query.Get("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+func queryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	chatshare "github.com/ruhancs/virtual-assistant/internal/usecase/chat_share"
+)
+
+type WebChatShareHandler struct {
+	ShareUseCase chatshare.ShareChatUseCase
+}
+
+func NewWebChatShareHandler(usecase chatshare.ShareChatUseCase) *WebChatShareHandler {
+	return &WebChatShareHandler{
+		ShareUseCase: usecase,
+	}
+}
+
+func (h *WebChatShareHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	var dto chatshare.ShareChatInputDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+		return
+	}
+
+	result, err := h.ShareUseCase.Execute(r.Context(), dto)
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/knowledge"
+)
+
+type WebDocumentHandler struct {
+	IngestUseCase knowledge.IngestDocumentUseCase
+	DeleteUseCase knowledge.DeleteDocumentUseCase
+}
+
+func NewWebDocumentHandler(ingest knowledge.IngestDocumentUseCase, remove knowledge.DeleteDocumentUseCase) *WebDocumentHandler {
+	return &WebDocumentHandler{
+		IngestUseCase: ingest,
+		DeleteUseCase: remove,
+	}
+}
+
+// Ingest POST /documents
+func (h *WebDocumentHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	var dto knowledge.IngestDocumentInputDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+		return
+	}
+
+	result, err := h.IngestUseCase.Execute(r.Context(), dto)
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// Delete DELETE /documents/{documentID}
+func (h *WebDocumentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	err := h.DeleteUseCase.Execute(r.Context(), knowledge.DeleteDocumentInputDTO{
+		DocumentID: chi.URLParam(r, "documentID"),
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
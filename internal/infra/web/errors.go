@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// httpError converte os erros do dominio em status code http e codigo do corpo de erro
+func httpError(err error) (int, string) {
+	switch {
+	case errors.Is(err, entity.ErrChatNotFound):
+		return http.StatusNotFound, "chat_not_found"
+	case errors.Is(err, entity.ErrDocumentNotFound):
+		return http.StatusNotFound, "document_not_found"
+	case errors.Is(err, entity.ErrPromptTemplateNotFound):
+		return http.StatusNotFound, "prompt_template_not_found"
+	case errors.Is(err, entity.ErrAssistantNotFound):
+		return http.StatusNotFound, "assistant_not_found"
+	case errors.Is(err, entity.ErrTenantNotFound):
+		return http.StatusNotFound, "tenant_not_found"
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		return http.StatusNotFound, "api_key_not_found"
+	case errors.Is(err, entity.ErrUnauthenticated):
+		return http.StatusUnauthorized, "unauthenticated"
+	case errors.Is(err, entity.ErrForbidden),
+		errors.Is(err, entity.ErrMissingScope):
+		return http.StatusForbidden, "forbidden"
+	case errors.Is(err, entity.ErrChatEnded):
+		return http.StatusConflict, "chat_ended"
+	case errors.Is(err, entity.ErrInvalidChat),
+		errors.Is(err, entity.ErrInvalidConfig),
+		errors.Is(err, entity.ErrInvalidMessage),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, entity.ErrInvalidDocument),
+		errors.Is(err, entity.ErrInvalidPromptTemplate),
+		errors.Is(err, entity.ErrInvalidAssistant),
+		errors.Is(err, entity.ErrInvalidTenant),
+		errors.Is(err, entity.ErrInvalidAPIKey),
+		errors.Is(err, entity.ErrInvalidUser):
+		return http.StatusBadRequest, "invalid_argument"
+	case errors.Is(err, entity.ErrContextOverflow):
+		return http.StatusRequestEntityTooLarge, "context_overflow"
+	case errors.Is(err, entity.ErrContentRejected):
+		return http.StatusUnprocessableEntity, "content_rejected"
+	case errors.Is(err, entity.ErrQuotaExceeded):
+		return http.StatusTooManyRequests, "quota_exceeded"
+	case errors.Is(err, entity.ErrProviderRateLimited):
+		return http.StatusTooManyRequests, "provider_rate_limited"
+	case errors.Is(err, entity.ErrProviderUnavailable):
+		return http.StatusServiceUnavailable, "provider_unavailable"
+	case errors.Is(err, entity.ErrProviderRejected):
+		return http.StatusBadGateway, "provider_rejected"
+	case errors.Is(err, entity.ErrInvalidOutput):
+		return http.StatusBadGateway, "invalid_output"
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error: ErrorBody{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+// WriteDomainError responde o erro do dominio no formato de erro da api, usado tambem pelos middlewares
+func WriteDomainError(w http.ResponseWriter, err error) {
+	status, code := httpError(err)
+	message := err.Error()
+	//nao expor detalhes de erros internos (db, etc) para o cliente
+	if status == http.StatusInternalServerError {
+		message = "internal server error"
+	}
+	writeError(w, status, code, message)
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	prompttemplate "github.com/ruhancs/virtual-assistant/internal/usecase/prompt_template"
+)
+
+// WebPromptTemplateHandler api de administracao dos templates
+type WebPromptTemplateHandler struct {
+	CreateUseCase   prompttemplate.CreatePromptTemplateUseCase
+	ListUseCase     prompttemplate.ListPromptTemplatesUseCase
+	ActivateUseCase prompttemplate.ActivatePromptTemplateUseCase
+	DeleteUseCase   prompttemplate.DeletePromptTemplateUseCase
+}
+
+func NewWebPromptTemplateHandler(create prompttemplate.CreatePromptTemplateUseCase, list prompttemplate.ListPromptTemplatesUseCase, activate prompttemplate.ActivatePromptTemplateUseCase, remove prompttemplate.DeletePromptTemplateUseCase) *WebPromptTemplateHandler {
+	return &WebPromptTemplateHandler{
+		CreateUseCase:   create,
+		ListUseCase:     list,
+		ActivateUseCase: activate,
+		DeleteUseCase:   remove,
+	}
+}
+
+// Templates GET /admin/prompt-templates lista as versoes ativas, POST cria uma nova versao
+func (h *WebPromptTemplateHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.ListUseCase.Execute(r.Context(), prompttemplate.ListPromptTemplatesInputDTO{})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodPost:
+		var dto prompttemplate.CreatePromptTemplateInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.CreateUseCase.Execute(r.Context(), dto)
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// Template GET /admin/prompt-templates/{name} lista todas as versoes, DELETE remove o template
+func (h *WebPromptTemplateHandler) Template(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.ListUseCase.Execute(r.Context(), prompttemplate.ListPromptTemplatesInputDTO{Name: name})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodDelete:
+		err := h.DeleteUseCase.Execute(r.Context(), prompttemplate.DeletePromptTemplateInputDTO{Name: name})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// Activate POST /admin/prompt-templates/{name}/versions/{version}/activate
+func (h *WebPromptTemplateHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid version")
+		return
+	}
+
+	result, err := h.ActivateUseCase.Execute(r.Context(), prompttemplate.ActivatePromptTemplateInputDTO{
+		Name:    chi.URLParam(r, "name"),
+		Version: version,
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+)
+
+// WebTenantHandler api de administracao dos tenants
+type WebTenantHandler struct {
+	CreateUseCase tenant.CreateTenantUseCase
+	ListUseCase   tenant.ListTenantsUseCase
+	UpdateUseCase tenant.UpdateTenantUseCase
+	DeleteUseCase tenant.DeleteTenantUseCase
+}
+
+func NewWebTenantHandler(create tenant.CreateTenantUseCase, list tenant.ListTenantsUseCase, update tenant.UpdateTenantUseCase, remove tenant.DeleteTenantUseCase) *WebTenantHandler {
+	return &WebTenantHandler{
+		CreateUseCase: create,
+		ListUseCase:   list,
+		UpdateUseCase: update,
+		DeleteUseCase: remove,
+	}
+}
+
+// Tenants GET /admin/tenants lista os tenants, POST cria um tenant e retorna o token de api dele
+func (h *WebTenantHandler) Tenants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		result, err := h.ListUseCase.Execute(r.Context())
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodPost:
+		var dto tenant.TenantInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.CreateUseCase.Execute(r.Context(), dto)
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// Tenant PUT (substitui a config) e DELETE /admin/tenants/{tenantID}
+func (h *WebTenantHandler) Tenant(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "tenantID")
+
+	switch r.Method {
+	case http.MethodPut:
+		var dto tenant.TenantInputDTO
+		if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_body", "invalid json body")
+			return
+		}
+		result, err := h.UpdateUseCase.Execute(r.Context(), tenant.UpdateTenantInputDTO{ID: id, TenantInputDTO: dto})
+		if err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		writeJSON(w, result)
+	case http.MethodDelete:
+		if err := h.DeleteUseCase.Execute(r.Context(), tenant.DeleteTenantInputDTO{ID: id}); err != nil {
+			WriteDomainError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
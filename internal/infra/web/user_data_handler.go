@@ -0,0 +1,84 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	userdata "github.com/ruhancs/virtual-assistant/internal/usecase/user_data"
+)
+
+// extensao do arquivo baixado em cada formato
+var exportExtensions = map[string]string{
+	userdata.FormatJSON:     "json",
+	userdata.FormatMarkdown: "md",
+	userdata.FormatZip:      "zip",
+}
+
+// WebUserDataHandler api de administracao dos pedidos de acesso e exclusao dos dados de um usuario (lgpd/gdpr)
+type WebUserDataHandler struct {
+	ExportUseCase userdata.ExportUserDataUseCase
+	DeleteUseCase userdata.DeleteUserDataUseCase
+}
+
+func NewWebUserDataHandler(export userdata.ExportUserDataUseCase, remove userdata.DeleteUserDataUseCase) *WebUserDataHandler {
+	return &WebUserDataHandler{
+		ExportUseCase: export,
+		DeleteUseCase: remove,
+	}
+}
+
+// Export GET /admin/users/{userID}/export?format=json|markdown|zip&tenant_id= baixa os dados do usuario no tenant,
+// sem tenant_id no tenant padrao
+func (h *WebUserDataHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = userdata.FormatJSON
+	}
+	contentType, ok := userdata.ContentTypes[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid_argument", "invalid format, use json, markdown or zip")
+		return
+	}
+
+	userID := chi.URLParam(r, "userID")
+	result, err := h.ExportUseCase.Execute(r.Context(), userdata.ExportUserDataInputDTO{
+		UserID:   userID,
+		TenantID: r.URL.Query().Get("tenant_id"),
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	body, err := userdata.Encode(result, format)
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	filename := "user-data-" + userID + "." + exportExtensions[format]
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// User DELETE /admin/users/{userID}?tenant_id= apaga os dados do usuario no tenant e retorna quanto foi apagado
+func (h *WebUserDataHandler) User(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	result, err := h.DeleteUseCase.Execute(r.Context(), userdata.DeleteUserDataInputDTO{
+		UserID:   chi.URLParam(r, "userID"),
+		TenantID: r.URL.Query().Get("tenant_id"),
+	})
+	if err != nil {
+		WriteDomainError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
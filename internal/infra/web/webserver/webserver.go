@@ -1,6 +1,7 @@
 package webserver
 
 import (
+	"crypto/tls"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -10,7 +11,10 @@ import (
 type WebServer struct {
 	Router        chi.Router
 	Handlers      map[string]http.HandlerFunc
+	Middlewares   map[string][]func(http.Handler) http.Handler //middlewares de cada rota
+	Global        []func(http.Handler) http.Handler            //middlewares de todas as rotas, vazio usa o logger do chi
 	WebServerPort string
+	TLSConfig     *tls.Config //nil roda sem tls
 }
 
 func NewWebServer(port string) *WebServer {
@@ -18,19 +22,38 @@ func NewWebServer(port string) *WebServer {
 		WebServerPort: port,
 		Router: chi.NewRouter(),
 		Handlers: make(map[string]http.HandlerFunc),
+		Middlewares: make(map[string][]func(http.Handler) http.Handler),
 	}
 }
 
-func (server *WebServer) AddHandler(path string, handler http.HandlerFunc) {
+// Use adiciona middlewares aplicados em todas as rotas
+func (server *WebServer) Use(middlewares ...func(http.Handler) http.Handler) {
+	server.Global = append(server.Global, middlewares...)
+}
+
+// AddHandler registra a rota, middlewares rodam somente nela (autenticacao, cota, etc)
+func (server *WebServer) AddHandler(path string, handler http.HandlerFunc, middlewares ...func(http.Handler) http.Handler) {
 	server.Handlers[path] = handler
+	server.Middlewares[path] = middlewares
 }
 
 func (server *WebServer) Start() error {
-	server.Router.Use(middleware.Logger)
+	if len(server.Global) == 0 {
+		server.Router.Use(middleware.Logger)
+	}
+	server.Router.Use(server.Global...)
 	for path,handle := range server.Handlers {
-		server.Router.Handle(path,handle)
+		server.Router.With(server.Middlewares[path]...).Handle(path,handle)
 	}
 	
+	if server.TLSConfig != nil {
+		//certificado vem do TLSConfig, que recarrega os arquivos sem reiniciar
+		httpServer := &http.Server{Addr: server.WebServerPort, Handler: server.Router, TLSConfig: server.TLSConfig}
+		if err := httpServer.ListenAndServeTLS("", ""); err != nil {
+			panic(err.Error())
+		}
+		return nil
+	}
 	if err := http.ListenAndServe(server.WebServerPort, server.Router); err != nil {
 		panic(err.Error())
 	}
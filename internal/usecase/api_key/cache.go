@@ -0,0 +1,84 @@
+package apikey
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// DefaultCacheTTL tempo que uma chave resolvida fica no cache, tambem é a precisao do last_used_at
+const DefaultCacheTTL = time.Minute
+
+// maximo de chaves no cache, quando enche as entradas vencidas sao descartadas
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	key     *entity.APIKey // nil para chave desconhecida, evita consultar o banco a cada tentativa
+	expires time.Time
+}
+
+// Cache chaves de api resolvidas por hash, evita uma consulta no banco por requisicao.
+// Cache nil nao guarda nada
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{
+		ttl:     ttl,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+func (c *Cache) get(hash string, now time.Time) (*entity.APIKey, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[hash]
+	if !ok || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry.key, true
+}
+
+func (c *Cache) put(hash string, key *entity.APIKey, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for h, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, h)
+			}
+		}
+		//todas validas: recomeca o cache em vez de crescer sem limite
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = map[string]cacheEntry{}
+		}
+	}
+	c.entries[hash] = cacheEntry{key: key, expires: now.Add(c.ttl)}
+}
+
+// Forget remove a chave do cache, usado na revogacao
+func (c *Cache) Forget(keyID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hash, entry := range c.entries {
+		if entry.key != nil && entry.key.ID == keyID {
+			delete(c.entries, hash)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type CreateAPIKeyInputDTO struct {
+	UserID    string     `json:"user_id"`
+	TenantID  string     `json:"tenant_id,omitempty"` //vazio usa o tenant padrao
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` //vazio nao expira
+}
+
+type APIKeyOutputDTO struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	TenantID   string     `json:"tenant_id,omitempty"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyOutputDTO struct {
+	APIKeyOutputDTO
+	Key string `json:"key"` //chave de api, nao é possivel consulta-la depois
+}
+
+type CreateAPIKeyUseCase struct {
+	APIKeyGateway gateway.APIKeyGateway
+	TenantGateway gateway.TenantGateway // opcional, nil aceita somente o tenant padrao
+}
+
+func NewCreateAPIKeyUseCase(apiKeyGateway gateway.APIKeyGateway, tenantGateway gateway.TenantGateway) *CreateAPIKeyUseCase {
+	return &CreateAPIKeyUseCase{
+		APIKeyGateway: apiKeyGateway,
+		TenantGateway: tenantGateway,
+	}
+}
+
+func (uc *CreateAPIKeyUseCase) Execute(ctx context.Context, input CreateAPIKeyInputDTO) (*CreateAPIKeyOutputDTO, error) {
+	apiKey, key, err := entity.NewAPIKey(input.UserID, input.TenantID, input.Name, input.Scopes, input.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.TenantID != "" {
+		if uc.TenantGateway == nil {
+			return nil, fmt.Errorf("%w: tenants are not enabled", entity.ErrInvalidAPIKey)
+		}
+		if _, err := uc.TenantGateway.FindTenantByID(ctx, apiKey.TenantID); err != nil {
+			return nil, fmt.Errorf("%w: tenant: %s", entity.ErrInvalidAPIKey, err)
+		}
+	}
+
+	if err := uc.APIKeyGateway.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, fmt.Errorf("error saving api key: %w", err)
+	}
+	return &CreateAPIKeyOutputDTO{
+		APIKeyOutputDTO: apiKeyOutput(apiKey),
+		Key:             key,
+	}, nil
+}
+
+func apiKeyOutput(key *entity.APIKey) APIKeyOutputDTO {
+	return APIKeyOutputDTO{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		TenantID:   key.TenantID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListAPIKeysInputDTO struct {
+	UserID string `json:"user_id"` //vazio lista as chaves de todos os usuarios
+}
+
+type ListAPIKeysOutputDTO struct {
+	Keys []APIKeyOutputDTO `json:"keys"`
+}
+
+type ListAPIKeysUseCase struct {
+	APIKeyGateway gateway.APIKeyGateway
+}
+
+func NewListAPIKeysUseCase(apiKeyGateway gateway.APIKeyGateway) *ListAPIKeysUseCase {
+	return &ListAPIKeysUseCase{
+		APIKeyGateway: apiKeyGateway,
+	}
+}
+
+func (uc *ListAPIKeysUseCase) Execute(ctx context.Context, input ListAPIKeysInputDTO) (*ListAPIKeysOutputDTO, error) {
+	keys, err := uc.APIKeyGateway.ListAPIKeys(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	output := &ListAPIKeysOutputDTO{Keys: []APIKeyOutputDTO{}}
+	for _, key := range keys {
+		output.Keys = append(output.Keys, apiKeyOutput(key))
+	}
+	return output, nil
+}
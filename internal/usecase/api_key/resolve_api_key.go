@@ -0,0 +1,53 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ResolveAPIKeyUseCase struct {
+	APIKeyGateway gateway.APIKeyGateway
+	Cache         *Cache // opcional, nil consulta o banco a cada requisicao
+}
+
+func NewResolveAPIKeyUseCase(apiKeyGateway gateway.APIKeyGateway, cache *Cache) *ResolveAPIKeyUseCase {
+	return &ResolveAPIKeyUseCase{
+		APIKeyGateway: apiKeyGateway,
+		Cache:         cache,
+	}
+}
+
+// Execute busca a chave ativa, chave desconhecida, revogada ou expirada retorna ErrAPIKeyNotFound.
+// O last_used_at é atualizado quando a chave é buscada no banco, uma vez por ttl do cache
+func (uc *ResolveAPIKeyUseCase) Execute(ctx context.Context, key string) (*entity.APIKey, error) {
+	now := time.Now()
+	hash := entity.HashAPIKey(key)
+	apiKey, ok := uc.Cache.get(hash, now)
+	if !ok {
+		var err error
+		apiKey, err = uc.APIKeyGateway.FindAPIKeyByHash(ctx, hash)
+		if err != nil && !errors.Is(err, entity.ErrAPIKeyNotFound) {
+			return nil, fmt.Errorf("error fetching api key: %w", err)
+		}
+		if apiKey != nil && apiKey.Active(now) {
+			if err := uc.APIKeyGateway.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+				return nil, fmt.Errorf("error updating api key last use: %w", err)
+			}
+			apiKey.LastUsedAt = &now
+		}
+		uc.Cache.put(hash, apiKey, now)
+	}
+
+	if apiKey == nil {
+		return nil, entity.ErrAPIKeyNotFound
+	}
+	if !apiKey.Active(now) {
+		return nil, fmt.Errorf("%w: key is revoked or expired", entity.ErrAPIKeyNotFound)
+	}
+	return apiKey, nil
+}
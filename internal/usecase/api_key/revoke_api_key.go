@@ -0,0 +1,34 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type RevokeAPIKeyInputDTO struct {
+	ID string `json:"id"`
+}
+
+type RevokeAPIKeyUseCase struct {
+	APIKeyGateway gateway.APIKeyGateway
+	Cache         *Cache // opcional, cache da autenticacao deste processo, limpo na hora
+}
+
+func NewRevokeAPIKeyUseCase(apiKeyGateway gateway.APIKeyGateway, cache *Cache) *RevokeAPIKeyUseCase {
+	return &RevokeAPIKeyUseCase{
+		APIKeyGateway: apiKeyGateway,
+		Cache:         cache,
+	}
+}
+
+// Execute revoga a chave. Outros processos deixam de aceitar a chave quando a entrada do cache deles expira
+func (uc *RevokeAPIKeyUseCase) Execute(ctx context.Context, input RevokeAPIKeyInputDTO) error {
+	if err := uc.APIKeyGateway.RevokeAPIKey(ctx, input.ID, time.Now()); err != nil {
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+	uc.Cache.Forget(input.ID)
+	return nil
+}
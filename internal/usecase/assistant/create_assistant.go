@@ -0,0 +1,128 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+type AssistantInputDTO struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description,omitempty"`
+	SystemPrompt     string   `json:"system_prompt,omitempty"`     //msg inicial dos chats
+	PromptTemplate   string   `json:"prompt_template,omitempty"`   //template da msg inicial, tem prioridade sobre system_prompt
+	ToolProfile      string   `json:"tool_profile,omitempty"`      //vazio usa o perfil padrao
+	KnowledgeBaseID  string   `json:"knowledge_base_id,omitempty"` //vazio usa a base padrao
+	Model            string   `json:"model"`
+	ModelMaxTokens   int      `json:"model_max_tokens"`
+	Temperature      float32  `json:"temperature"`
+	TopP             float32  `json:"top_p"`
+	N                int      `json:"n"`
+	Stop             []string `json:"stop,omitempty"`
+	MaxTokens        int      `json:"max_tokens"`
+	PresencePenalty  float32  `json:"presence_penalty"`
+	FrequencyPenalty float32  `json:"frequency_penalty"`
+}
+
+type AssistantOutputDTO struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	SystemPrompt     string    `json:"system_prompt"`
+	PromptTemplate   string    `json:"prompt_template,omitempty"`
+	ToolProfile      string    `json:"tool_profile,omitempty"`
+	KnowledgeBaseID  string    `json:"knowledge_base_id,omitempty"`
+	Model            string    `json:"model"`
+	ModelMaxTokens   int       `json:"model_max_tokens"`
+	Temperature      float32   `json:"temperature"`
+	TopP             float32   `json:"top_p"`
+	N                int       `json:"n"`
+	Stop             []string  `json:"stop,omitempty"`
+	MaxTokens        int       `json:"max_tokens"`
+	PresencePenalty  float32   `json:"presence_penalty"`
+	FrequencyPenalty float32   `json:"frequency_penalty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type CreateAssistantUseCase struct {
+	AssistantGateway gateway.AssistantGateway
+	Tools            *tools.Registry // opcional, valida o perfil de ferramentas do assistente
+}
+
+func NewCreateAssistantUseCase(assistantGateway gateway.AssistantGateway, registry *tools.Registry) *CreateAssistantUseCase {
+	return &CreateAssistantUseCase{
+		AssistantGateway: assistantGateway,
+		Tools:            registry,
+	}
+}
+
+func (uc *CreateAssistantUseCase) Execute(ctx context.Context, input AssistantInputDTO) (*AssistantOutputDTO, error) {
+	assistant, err := entity.NewAssistant(input.Name, input.Description, input.SystemPrompt, chatConfig(input))
+	if err != nil {
+		return nil, err
+	}
+	assistant.PromptTemplate = input.PromptTemplate
+	assistant.ToolProfile = input.ToolProfile
+	assistant.KnowledgeBaseID = input.KnowledgeBaseID
+	if err := validate(assistant, uc.Tools); err != nil {
+		return nil, err
+	}
+
+	if err := uc.AssistantGateway.CreateAssistant(ctx, assistant); err != nil {
+		return nil, fmt.Errorf("error saving assistant: %w", err)
+	}
+	output := assistantOutput(assistant)
+	return &output, nil
+}
+
+// validate revalida o assistente com os campos opcionais e confere se o perfil de ferramentas existe
+func validate(assistant *entity.Assistant, registry *tools.Registry) error {
+	if err := assistant.Validate(); err != nil {
+		return err
+	}
+	if _, err := registry.ForProfile(assistant.ToolProfile); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidAssistant, err)
+	}
+	return nil
+}
+
+func chatConfig(input AssistantInputDTO) *entity.ChatConfig {
+	return &entity.ChatConfig{
+		Model:            entity.NewModel(input.Model, input.ModelMaxTokens),
+		Temperature:      input.Temperature,
+		TopP:             input.TopP,
+		N:                input.N,
+		Stop:             input.Stop,
+		MaxTokens:        input.MaxTokens,
+		PresencePenalty:  input.PresencePenalty,
+		FrequencyPenalty: input.FrequencyPenalty,
+	}
+}
+
+func assistantOutput(assistant *entity.Assistant) AssistantOutputDTO {
+	return AssistantOutputDTO{
+		ID:               assistant.ID,
+		Name:             assistant.Name,
+		Description:      assistant.Description,
+		SystemPrompt:     assistant.SystemPrompt,
+		PromptTemplate:   assistant.PromptTemplate,
+		ToolProfile:      assistant.ToolProfile,
+		KnowledgeBaseID:  assistant.KnowledgeBaseID,
+		Model:            assistant.Config.Model.Name,
+		ModelMaxTokens:   assistant.Config.Model.MaxTokens,
+		Temperature:      assistant.Config.Temperature,
+		TopP:             assistant.Config.TopP,
+		N:                assistant.Config.N,
+		Stop:             assistant.Config.Stop,
+		MaxTokens:        assistant.Config.MaxTokens,
+		PresencePenalty:  assistant.Config.PresencePenalty,
+		FrequencyPenalty: assistant.Config.FrequencyPenalty,
+		CreatedAt:        assistant.CreatedAt,
+		UpdatedAt:        assistant.UpdatedAt,
+	}
+}
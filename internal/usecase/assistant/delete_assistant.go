@@ -0,0 +1,29 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type DeleteAssistantInputDTO struct {
+	ID string `json:"id"`
+}
+
+type DeleteAssistantUseCase struct {
+	AssistantGateway gateway.AssistantGateway
+}
+
+func NewDeleteAssistantUseCase(assistantGateway gateway.AssistantGateway) *DeleteAssistantUseCase {
+	return &DeleteAssistantUseCase{
+		AssistantGateway: assistantGateway,
+	}
+}
+
+func (uc *DeleteAssistantUseCase) Execute(ctx context.Context, input DeleteAssistantInputDTO) error {
+	if err := uc.AssistantGateway.DeleteAssistant(ctx, input.ID); err != nil {
+		return fmt.Errorf("error deleting assistant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type GetAssistantInputDTO struct {
+	ID string `json:"id"`
+}
+
+type GetAssistantUseCase struct {
+	AssistantGateway gateway.AssistantGateway
+}
+
+func NewGetAssistantUseCase(assistantGateway gateway.AssistantGateway) *GetAssistantUseCase {
+	return &GetAssistantUseCase{
+		AssistantGateway: assistantGateway,
+	}
+}
+
+func (uc *GetAssistantUseCase) Execute(ctx context.Context, input GetAssistantInputDTO) (*AssistantOutputDTO, error) {
+	assistant, err := uc.AssistantGateway.FindAssistantByID(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching assistant: %w", err)
+	}
+	output := assistantOutput(assistant)
+	return &output, nil
+}
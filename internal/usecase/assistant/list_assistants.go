@@ -0,0 +1,34 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListAssistantsOutputDTO struct {
+	Assistants []AssistantOutputDTO `json:"assistants"`
+}
+
+type ListAssistantsUseCase struct {
+	AssistantGateway gateway.AssistantGateway
+}
+
+func NewListAssistantsUseCase(assistantGateway gateway.AssistantGateway) *ListAssistantsUseCase {
+	return &ListAssistantsUseCase{
+		AssistantGateway: assistantGateway,
+	}
+}
+
+func (uc *ListAssistantsUseCase) Execute(ctx context.Context) (*ListAssistantsOutputDTO, error) {
+	assistants, err := uc.AssistantGateway.ListAssistants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing assistants: %w", err)
+	}
+	output := &ListAssistantsOutputDTO{Assistants: []AssistantOutputDTO{}}
+	for _, assistant := range assistants {
+		output.Assistants = append(output.Assistants, assistantOutput(assistant))
+	}
+	return output, nil
+}
@@ -0,0 +1,54 @@
+package assistant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+type UpdateAssistantInputDTO struct {
+	ID string `json:"id"`
+	AssistantInputDTO
+}
+
+type UpdateAssistantUseCase struct {
+	AssistantGateway gateway.AssistantGateway
+	Tools            *tools.Registry // opcional, valida o perfil de ferramentas do assistente
+}
+
+func NewUpdateAssistantUseCase(assistantGateway gateway.AssistantGateway, registry *tools.Registry) *UpdateAssistantUseCase {
+	return &UpdateAssistantUseCase{
+		AssistantGateway: assistantGateway,
+		Tools:            registry,
+	}
+}
+
+// Execute substitui a config do assistente, vale para os chats novos, os existentes mantem o modelo
+// e a msg inicial com que foram criados
+func (uc *UpdateAssistantUseCase) Execute(ctx context.Context, input UpdateAssistantInputDTO) (*AssistantOutputDTO, error) {
+	assistant, err := uc.AssistantGateway.FindAssistantByID(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching assistant: %w", err)
+	}
+
+	assistant.Name = input.Name
+	assistant.Description = input.Description
+	assistant.Config = chatConfig(input.AssistantInputDTO)
+	assistant.SystemPrompt = input.SystemPrompt
+	assistant.PromptTemplate = input.PromptTemplate
+	assistant.ToolProfile = input.ToolProfile
+	assistant.KnowledgeBaseID = input.KnowledgeBaseID
+	assistant.UpdatedAt = time.Now()
+	if err := validate(assistant, uc.Tools); err != nil {
+		return nil, err
+	}
+
+	if err := uc.AssistantGateway.SaveAssistant(ctx, assistant); err != nil {
+		return nil, fmt.Errorf("error saving assistant: %w", err)
+	}
+	output := assistantOutput(assistant)
+	return &output, nil
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// CompletionConfig config usada na geracao da resposta
+type CompletionConfig struct {
+	Model            string   `json:"model"`
+	Temperature      float32  `json:"temperature"`
+	TopP             float32  `json:"top_p"`
+	MaxTokens        int      `json:"max_tokens"`
+	Stop             []string `json:"stop,omitempty"`
+	PresencePenalty  float32  `json:"presence_penalty"`
+	FrequencyPenalty float32  `json:"frequency_penalty"`
+	PromptTemplate   string   `json:"prompt_template,omitempty"`
+	TemplateVersion  int      `json:"template_version,omitempty"`
+	AssistantID      string   `json:"assistant_id,omitempty"`
+	ToolProfile      string   `json:"tool_profile,omitempty"`
+	KnowledgeBaseID  string   `json:"knowledge_base_id,omitempty"`
+	ResponseSchema   bool     `json:"response_schema,omitempty"`
+}
+
+// Completion dados do evento chat.completion. O log nao pode ser alterado, entao guarda somente os ids das msgs
+// e o sha256 do conteudo salvo no chat: o conteudo fica nas msgs (cifradas) e sai com a exclusao do usuario
+type Completion struct {
+	ChatID            string           `json:"chat_id"`
+	Stream            bool             `json:"stream"`
+	Config            CompletionConfig `json:"config"`
+	RequestMessageID  string           `json:"request_message_id"`
+	RequestHash       string           `json:"request_hash"`
+	ResponseMessageID string           `json:"response_message_id"`
+	ResponseHash      string           `json:"response_hash"`
+	Moderated         bool             `json:"moderated,omitempty"` //resposta cortada ou trocada pela moderacao
+}
+
+// NewCompletion evento da resposta ao usuario com a config do chat
+func NewCompletion(chat *entity.Chat, request *entity.Message, response *entity.Message) Completion {
+	completion := Completion{
+		ChatID:            chat.ID,
+		RequestMessageID:  request.ID,
+		RequestHash:       ContentHash(request.Content),
+		ResponseMessageID: response.ID,
+		ResponseHash:      ContentHash(response.Content),
+		Config: CompletionConfig{
+			Temperature:      chat.Config.Temperature,
+			TopP:             chat.Config.TopP,
+			MaxTokens:        chat.Config.MaxTokens,
+			Stop:             chat.Config.Stop,
+			PresencePenalty:  chat.Config.PresencePenalty,
+			FrequencyPenalty: chat.Config.FrequencyPenalty,
+			PromptTemplate:   chat.PromptTemplate,
+			TemplateVersion:  chat.TemplateVersion,
+			AssistantID:      chat.AssistantID,
+		},
+	}
+	if chat.Config.Model != nil {
+		completion.Config.Model = chat.Config.Model.Name
+	}
+	for _, verdict := range response.Moderation {
+		if verdict.Flagged {
+			completion.Moderated = true
+		}
+	}
+	return completion
+}
+
+// ContentHash sha256 em hex do conteudo de uma msg, confere a msg guardada com o evento de auditoria
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
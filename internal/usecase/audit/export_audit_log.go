@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+const (
+	defaultExportLimit = 100
+	maxExportLimit     = 1000
+)
+
+type ExportAuditLogInputDTO struct {
+	AfterSeq int64     `json:"after_seq"` //cursor, seq do ultimo evento da pagina anterior
+	Type     string    `json:"type"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Limit    int       `json:"limit"`
+}
+
+type ExportAuditLogOutputDTO struct {
+	Events  []*entity.AuditEvent `json:"events"`
+	NextSeq int64                `json:"next_seq,omitempty"` //cursor da proxima pagina, zero na ultima
+}
+
+type ExportAuditLogUseCase struct {
+	AuditGateway gateway.AuditGateway
+}
+
+func NewExportAuditLogUseCase(auditGateway gateway.AuditGateway) *ExportAuditLogUseCase {
+	return &ExportAuditLogUseCase{
+		AuditGateway: auditGateway,
+	}
+}
+
+// Execute eventos em ordem de seq com os hashes, para verificacao fora do servico
+func (uc *ExportAuditLogUseCase) Execute(ctx context.Context, input ExportAuditLogInputDTO) (*ExportAuditLogOutputDTO, error) {
+	if input.Limit <= 0 {
+		input.Limit = defaultExportLimit
+	}
+	if input.Limit > maxExportLimit {
+		input.Limit = maxExportLimit
+	}
+	events, err := uc.AuditGateway.ListAuditEvents(ctx, entity.AuditFilter{
+		AfterSeq: input.AfterSeq,
+		Type:     input.Type,
+		From:     input.From,
+		To:       input.To,
+		Limit:    input.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit events: %w", err)
+	}
+	output := &ExportAuditLogOutputDTO{Events: events}
+	if output.Events == nil {
+		output.Events = []*entity.AuditEvent{}
+	}
+	if len(events) == input.Limit {
+		output.NextSeq = events[len(events)-1].Seq
+	}
+	return output, nil
+}
@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// maxConflictRetries novas tentativas quando outra instancia grava o mesmo seq
+const maxConflictRetries = 5
+
+// Logger grava os eventos no log de auditoria, o gateway encadeia cada evento ao ultimo gravado.
+// Logger nil nao grava nada (auditoria desligada)
+type Logger struct {
+	AuditGateway gateway.AuditGateway
+}
+
+func NewLogger(auditGateway gateway.AuditGateway) *Logger {
+	return &Logger{
+		AuditGateway: auditGateway,
+	}
+}
+
+// Record grava o evento com o usuario, o tenant e o id da requisicao do contexto. data é serializado em json
+func (l *Logger) Record(ctx context.Context, eventType string, action string, resource string, data interface{}) error {
+	if l == nil {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error encoding audit event: %w", err)
+	}
+	//o sink pode devolver o json compactado, o hash é calculado na forma que sera lida de volta
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return fmt.Errorf("error encoding audit event: %w", err)
+	}
+
+	event := entity.NewAuditEvent(eventType, action, resource, compact.Bytes())
+	if principal := entity.PrincipalFromContext(ctx); principal != nil {
+		event.UserID = principal.UserID
+	}
+	event.TenantID = entity.TenantID(ctx)
+	event.RequestID = entity.RequestID(ctx)
+
+	for attempt := 0; ; attempt++ {
+		err = l.AuditGateway.AppendAuditEvent(ctx, event)
+		if err == nil {
+			return nil
+		}
+		//outra instancia gravou o mesmo seq, o gateway encadeia de novo no fim atual
+		if !errors.Is(err, entity.ErrAuditConflict) || attempt >= maxConflictRetries {
+			return fmt.Errorf("error recording audit event: %w", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// verifyPageSize eventos lidos por consulta na verificacao
+const verifyPageSize = 1000
+
+type VerifyAuditLogInputDTO struct {
+	//hash de um evento anotado antes (ancora), detecta a remocao de eventos do fim do log. Opcional
+	AnchorSeq  int64  `json:"anchor_seq,omitempty"`
+	AnchorHash string `json:"anchor_hash,omitempty"`
+}
+
+type VerifyAuditLogOutputDTO struct {
+	Valid    bool   `json:"valid"`
+	Events   int64  `json:"events"`
+	LastSeq  int64  `json:"last_seq"`
+	LastHash string `json:"last_hash"`
+	BrokenAt int64  `json:"broken_at,omitempty"` //seq do primeiro evento invalido
+	Reason   string `json:"reason,omitempty"`
+}
+
+type VerifyAuditLogUseCase struct {
+	AuditGateway gateway.AuditGateway
+}
+
+func NewVerifyAuditLogUseCase(auditGateway gateway.AuditGateway) *VerifyAuditLogUseCase {
+	return &VerifyAuditLogUseCase{
+		AuditGateway: auditGateway,
+	}
+}
+
+// Execute percorre a cadeia inteira conferindo a sequencia, o prev_hash e o hash de cada evento.
+// Cadeia quebrada nao é erro, vem no resultado com o primeiro evento invalido
+func (uc *VerifyAuditLogUseCase) Execute(ctx context.Context, input VerifyAuditLogInputDTO) (*VerifyAuditLogOutputDTO, error) {
+	output := &VerifyAuditLogOutputDTO{Valid: true, LastHash: entity.AuditGenesisHash}
+	anchorFound := false
+	for {
+		events, err := uc.AuditGateway.ListAuditEvents(ctx, entity.AuditFilter{AfterSeq: output.LastSeq, Limit: verifyPageSize})
+		if err != nil {
+			return nil, fmt.Errorf("error listing audit events: %w", err)
+		}
+		for _, event := range events {
+			reason := ""
+			switch {
+			case event.Seq != output.LastSeq+1:
+				reason = fmt.Sprintf("expected seq %d, found %d", output.LastSeq+1, event.Seq)
+			case event.PrevHash != output.LastHash:
+				reason = "prev_hash does not match the previous event"
+			case event.ComputeHash() != event.Hash:
+				reason = "hash does not match the event content"
+			case event.Seq == input.AnchorSeq && event.Hash != input.AnchorHash:
+				reason = "hash does not match the anchor"
+			}
+			if reason != "" {
+				return broken(output, event.Seq, reason), nil
+			}
+			anchorFound = anchorFound || event.Seq == input.AnchorSeq
+			output.Events++
+			output.LastSeq = event.Seq
+			output.LastHash = event.Hash
+		}
+		if len(events) < verifyPageSize {
+			break
+		}
+	}
+	if input.AnchorSeq > 0 && !anchorFound {
+		return broken(output, input.AnchorSeq, "anchor event is missing, the log was truncated"), nil
+	}
+	return output, nil
+}
+
+func broken(output *VerifyAuditLogOutputDTO, seq int64, reason string) *VerifyAuditLogOutputDTO {
+	output.Valid = false
+	output.BrokenAt = seq
+	output.Reason = reason
+	return output
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// memoryAudit log de auditoria em memoria, na ordem de insercao
+type memoryAudit struct {
+	events []*entity.AuditEvent
+}
+
+func (m *memoryAudit) AppendAuditEvent(ctx context.Context, event *entity.AuditEvent) error {
+	var last *entity.AuditEvent
+	if len(m.events) > 0 {
+		last = m.events[len(m.events)-1]
+	}
+	event.Chain(last)
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryAudit) ListAuditEvents(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	var res []*entity.AuditEvent
+	for _, event := range m.events {
+		if event.Seq <= filter.AfterSeq {
+			continue
+		}
+		res = append(res, event)
+		if filter.Limit > 0 && len(res) == filter.Limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func newMemoryAudit(t *testing.T, n int) *memoryAudit {
+	t.Helper()
+	m := &memoryAudit{}
+	for i := 1; i <= n; i++ {
+		event := entity.NewAuditEvent(entity.AuditAccess, "GET /chats", fmt.Sprintf("chat-%d", i), json.RawMessage(fmt.Sprintf(`{"status":%d}`, 200+i%3)))
+		event.UserID = "user-1"
+		if err := m.AppendAuditEvent(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestVerifyAuditLogValidChain(t *testing.T) {
+	//mais de uma pagina da verificacao
+	m := newMemoryAudit(t, verifyPageSize*2+5)
+	output, err := NewVerifyAuditLogUseCase(m).Execute(context.Background(), VerifyAuditLogInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := m.events[len(m.events)-1]
+	if !output.Valid || output.Events != int64(len(m.events)) || output.LastSeq != last.Seq || output.LastHash != last.Hash {
+		t.Fatalf("expected a valid chain up to %d, got %+v", last.Seq, output)
+	}
+	if m.events[0].Seq != 1 || m.events[0].PrevHash != entity.AuditGenesisHash {
+		t.Fatalf("expected the first event linked to the genesis hash, got %d %s", m.events[0].Seq, m.events[0].PrevHash)
+	}
+
+	empty, err := NewVerifyAuditLogUseCase(&memoryAudit{}).Execute(context.Background(), VerifyAuditLogInputDTO{})
+	if err != nil || !empty.Valid || empty.Events != 0 || empty.LastHash != entity.AuditGenesisHash {
+		t.Fatalf("expected an empty log to be valid, got %+v %v", empty, err)
+	}
+}
+
+func TestVerifyAuditLogDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(m *memoryAudit)
+		input    VerifyAuditLogInputDTO
+		brokenAt int64
+		reason   string
+	}{
+		{
+			name:     "data changed",
+			tamper:   func(m *memoryAudit) { m.events[2].Data = json.RawMessage(`{"status":500}`) },
+			brokenAt: 3,
+			reason:   "hash does not match the event content",
+		},
+		{
+			name:     "user changed",
+			tamper:   func(m *memoryAudit) { m.events[4].UserID = "user-2" },
+			brokenAt: 5,
+			reason:   "hash does not match the event content",
+		},
+		{
+			name: "prev hash changed and hash recomputed",
+			tamper: func(m *memoryAudit) {
+				m.events[2].PrevHash = m.events[0].Hash
+				m.events[2].Hash = m.events[2].ComputeHash()
+			},
+			brokenAt: 3,
+			reason:   "prev_hash does not match the previous event",
+		},
+		{
+			name: "event rewritten with a new hash",
+			tamper: func(m *memoryAudit) {
+				m.events[2].Action = "DELETE /chats"
+				m.events[2].Hash = m.events[2].ComputeHash()
+			},
+			//o evento alterado tem hash valido, a cadeia quebra no seguinte
+			brokenAt: 4,
+			reason:   "prev_hash does not match the previous event",
+		},
+		{
+			name:     "event removed",
+			tamper:   func(m *memoryAudit) { m.events = append(m.events[:3:3], m.events[4:]...) },
+			brokenAt: 5,
+			reason:   "expected seq 4, found 5",
+		},
+		{
+			name:     "tail truncated",
+			tamper:   func(m *memoryAudit) { m.events = m.events[:6] },
+			input:    VerifyAuditLogInputDTO{AnchorSeq: 8},
+			brokenAt: 8,
+			reason:   "anchor event is missing, the log was truncated",
+		},
+		{
+			name:     "anchor hash differs",
+			tamper:   func(m *memoryAudit) {},
+			input:    VerifyAuditLogInputDTO{AnchorSeq: 7, AnchorHash: entity.AuditGenesisHash},
+			brokenAt: 7,
+			reason:   "hash does not match the anchor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMemoryAudit(t, 10)
+			if tt.input.AnchorSeq > 0 && tt.input.AnchorHash == "" {
+				tt.input.AnchorHash = m.events[tt.input.AnchorSeq-1].Hash
+			}
+			tt.tamper(m)
+			output, err := NewVerifyAuditLogUseCase(m).Execute(context.Background(), tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output.Valid || output.BrokenAt != tt.brokenAt || output.Reason != tt.reason {
+				t.Fatalf("expected broken at %d (%s), got %+v", tt.brokenAt, tt.reason, output)
+			}
+		})
+	}
+}
+
+func TestVerifyAuditLogWithAnchor(t *testing.T) {
+	m := newMemoryAudit(t, 10)
+	output, err := NewVerifyAuditLogUseCase(m).Execute(context.Background(), VerifyAuditLogInputDTO{AnchorSeq: 7, AnchorHash: m.events[6].Hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.Valid || output.LastSeq != 10 {
+		t.Fatalf("expected a valid chain with the anchor, got %+v", output)
+	}
+}
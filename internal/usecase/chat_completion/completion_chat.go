@@ -2,106 +2,295 @@ package chatcompletion
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
 	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/completion"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/knowledge"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/moderation"
+	prompttemplate "github.com/ruhancs/virtual-assistant/internal/usecase/prompt_template"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/recall"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/structured"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
 	openai "github.com/sashabaranov/go-openai"
 )
 
-type ChatCompletionConfigInputDTO struct {
-	Model                string
-	ModelMaxTokens       int
-	Temperature          float32  // 0.0 to 1.0
-	TopP                 float32  // 0.0 to 1.0 - to a low value, like 0.1, the model will be very conservative in its word choices, and will tend to generate relatively predictable prompts
-	N                    int      // number of messages to generate
-	Stop                 []string // list of tokens to stop on
-	MaxTokens            int      // number of tokens to generate
-	PresencePenalty      float32  // -2.0 to 2.0 - Number between -2.0 and 2.0. Positive values penalize new tokens based on whether they appear in the text so far, increasing the model's likelihood to talk about new topics.
-	FrequencyPenalty     float32  // -2.0 to 2.0 - Number between -2.0 and 2.0. Positive values penalize new tokens based on their existing frequency in the text so far, increasing the model's likelihood to talk about new topics.
-	InitialSystemMessage string
-}
-
-type ChatCompletionInputDTO struct {
-	ChatID      string                       `json:"chat_id,omitempty"`
-	UserID      string                       `json:"user_id"`
-	UserMessage string                       `json:"user_message"`
-	Config      ChatCompletionConfigInputDTO `json:"config"`
-}
+// entrada e citacoes iguais as do stream, definidas no pacote completion
+type (
+	ChatCompletionConfigInputDTO = completion.ConfigInputDTO
+	ChatCompletionInputDTO       = completion.InputDTO
+	CitationOutputDTO            = completion.CitationOutputDTO
+)
 
 type ChatCompletionOutputDTO struct {
-	ChatID  string `json:"chat_id"`
-	UserID  string `json:"user_id"`
-	Content string `json:"content"`
+	ChatID    string              `json:"chat_id"`
+	UserID    string              `json:"user_id"`
+	Content   string              `json:"content"`
+	Citations []CitationOutputDTO `json:"citations,omitempty"`
+	Object    json.RawMessage     `json:"object,omitempty"` //resposta validada no response_schema
 }
 
 type ChatCompletionUseCase struct {
 	ChatGateway  gateway.ChatGateway
 	OpenAIClient *openai.Client
+	Recaller     *recall.Recaller                            // opcional, nil desliga a recuperacao de msgs apagadas
+	Retriever    *knowledge.Retriever                        // opcional, nil desliga a busca na base de conhecimento
+	Tools        *tools.Registry                             // opcional, ferramentas que o modelo pode chamar
+	Templates    *prompttemplate.RenderPromptTemplateUseCase // opcional, nil sempre usa a msg inicial da config
+	Assistants   gateway.AssistantGateway                    // opcional, nil desliga os assistentes
+	Usage        *tenant.Usage                               // opcional, nil nao registra o uso dos tenants (a cota é checada no middleware)
+	Redactor     *redact.Redactor                            // opcional, nil envia os dados pessoais sem redacao ao provedor
+	Moderator    *moderation.Moderator                       // opcional, nil nao modera a msg do usuario nem a resposta
+	Audit        *audit.Logger                               // opcional, nil nao grava as respostas no log de auditoria
 }
 
-func NewChatCompletionUseCase(chatGateway gateway.ChatGateway, openAIClient *openai.Client) *ChatCompletionUseCase {
+func NewChatCompletionUseCase(chatGateway gateway.ChatGateway, openAIClient *openai.Client, recaller *recall.Recaller, retriever *knowledge.Retriever, registry *tools.Registry, templates *prompttemplate.RenderPromptTemplateUseCase, assistants gateway.AssistantGateway, usage *tenant.Usage, redactor *redact.Redactor, moderator *moderation.Moderator, auditLogger *audit.Logger) *ChatCompletionUseCase {
 	return &ChatCompletionUseCase{
 		ChatGateway:  chatGateway,
 		OpenAIClient: openAIClient,
+		Recaller:     recaller,
+		Retriever:    retriever,
+		Tools:        registry,
+		Templates:    templates,
+		Assistants:   assistants,
+		Usage:        usage,
+		Redactor:     redactor,
+		Moderator:    moderator,
+		Audit:        auditLogger,
 	}
 }
 
 func (uc *ChatCompletionUseCase) Execute(ctx context.Context, input ChatCompletionInputDTO) (*ChatCompletionOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = callerID
+	//chat existente segue o assistente com que foi criado
 	chat, err := uc.ChatGateway.FindChatByID(ctx, input.ChatID)
+	if err != nil && !errors.Is(err, entity.ErrChatNotFound) {
+		return nil, fmt.Errorf("error fetching existing chat: %w", err)
+	}
+	if chat != nil {
+		if !chat.CanAccess(input.UserID) {
+			return nil, entity.ErrForbidden
+		}
+		if input.AssistantID != "" && input.AssistantID != chat.AssistantID {
+			return nil, fmt.Errorf("%w: chat belongs to another assistant", entity.ErrInvalidConfig)
+		}
+		input.AssistantID = chat.AssistantID
+	}
+	//chat novo sem assistente usa o assistente padrao do tenant
+	org := entity.TenantFromContext(ctx)
+	if chat == nil && input.AssistantID == "" && org != nil {
+		input.AssistantID = org.DefaultAssistantID
+	}
+	input, err = completion.WithAssistant(ctx, uc.Assistants, input)
 	if err != nil {
-		if err.Error() == "chat not found" {
-			chat, err = createNewChat(input)
-			if err != nil {
-				return nil, errors.New("error creating new chat: " + err.Error())
-			}
-			err = uc.ChatGateway.CreateChat(ctx, chat)
-			if err != nil {
-				return nil, errors.New("error persisting new chat: " + err.Error())
-			}
-		} else {
-			return nil, errors.New("error fetching existing chat: " + err.Error())
+		return nil, err
+	}
+	model := input.Config.Model
+	if chat != nil {
+		model = chat.Config.Model.Name
+	}
+	if !org.AllowsModel(model) {
+		return nil, fmt.Errorf("%w: model %s is not allowed for the tenant", entity.ErrInvalidConfig, model)
+	}
+
+	//perfil de ferramentas e response_schema sao validados antes de criar o chat
+	input.ToolProfile = completion.ToolProfile(ctx, chat, input)
+	registry, err := uc.Tools.ForProfile(input.ToolProfile)
+	if err != nil {
+		return nil, err
+	}
+	var schema *structured.Schema
+	if len(input.ResponseSchema) > 0 {
+		schema, err = structured.Compile(input.ResponseSchema)
+		if err != nil {
+			return nil, err
 		}
 	}
+	maxRetries := input.Config.SchemaMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = structured.DefaultMaxRetries
+	}
 
-	userMessage, err := entity.NewMessage("user", input.UserMessage, chat.Config.Model)
+	//dados pessoais trocados por placeholders no que vai ao provedor, o vault devolve os valores na resposta
+	vault := uc.Redactor.NewVault()
+	//moderacao da msg do usuario antes de criar o chat, no mesmo texto que o provedor recebe
+	inputReview, err := uc.Moderator.Input(ctx, vault.Redact(input.UserMessage))
 	if err != nil {
-		return nil, errors.New("error creating new message: " + err.Error())
+		return nil, err
 	}
+	if inputReview.Changed {
+		input.UserMessage = vault.Restore(inputReview.Content)
+	}
+
+	if chat == nil {
+		prompt, err := completion.InitialPrompt(ctx, uc.Templates, input)
+		if err != nil {
+			return nil, err
+		}
+		chat, err = completion.NewChat(input, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("error creating new chat: %w", err)
+		}
+		err = uc.ChatGateway.CreateChat(ctx, chat)
+		if err != nil {
+			return nil, fmt.Errorf("error persisting new chat: %w", err)
+		}
+	}
+
+	userMessage, err := entity.NewMessage("user", vault.Store(input.UserMessage), chat.Config.Model)
+	if err != nil {
+		return nil, fmt.Errorf("error creating new message: %w", err)
+	}
+	userMessage.Moderation = inputReview.Verdicts
 	err = chat.AddMessage(userMessage)
 	if err != nil {
-		return nil, errors.New("error adding new message: " + err.Error())
+		return nil, fmt.Errorf("error adding new message: %w", err)
 	}
 
-	messages := []openai.ChatCompletionMessage{}
-	for _, msg := range chat.Messages {
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+	//msgs de contexto (base de conhecimento e msgs relembradas) entram em todas as rodadas, mas nao sao salvas no chat
+	var contextMsgs []*entity.Message
+	budget := recall.Budget(chat)
+
+	//instrucao do formato json vai no fim da conversa, depois das msgs do chat
+	var instruction *entity.Message
+	if schema != nil {
+		instruction, err = schema.Instruction(chat.Config.Model)
+		if err != nil {
+			return nil, fmt.Errorf("error creating response schema instruction: %w", err)
+		}
+		budget -= instruction.GetQTDTokens()
 	}
 
-	resp, err := uc.OpenAIClient.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
+	//trechos da base de conhecimento, tem prioridade sobre as msgs relembradas no orcamento de tokens
+	var citations []entity.Citation
+	if uc.Retriever != nil {
+		var excerpts *entity.Message
+		excerpts, citations, err = uc.Retriever.ContextMessage(ctx, input.KnowledgeBaseID, chat.Config.Model, vault, input.UserMessage, budget)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving knowledge base excerpts: %w", err)
+		}
+		if excerpts != nil {
+			contextMsgs = append(contextMsgs, excerpts)
+			budget -= excerpts.GetQTDTokens()
+		}
+	}
+
+	//msgs antigas, que ja sairam do contexto, relevantes para a msg atual
+	if uc.Recaller != nil {
+		recalled, err := uc.Recaller.ContextMessage(ctx, chat, vault, input.UserMessage, budget)
+		if err != nil {
+			return nil, fmt.Errorf("error recalling erased messages: %w", err)
+		}
+		if recalled != nil {
+			contextMsgs = append(contextMsgs, recalled)
+		}
+	}
+
+	//o modelo pode pedir ferramentas, que sao executadas e devolvidas a ele ate vir a resposta final.
+	//Com response_schema a resposta final é validada e as falhas voltam ao modelo para correcao
+	var content string
+	var object json.RawMessage
+	var retryMsgs []openai.ChatCompletionMessage
+	toolRounds, retries := 0, 0
+	for {
+		messages := completion.ChatMessages(chat, contextMsgs)
+		if instruction != nil {
+			messages = append(messages, openai.ChatCompletionMessage{Role: instruction.Role, Content: instruction.Content})
+		}
+		request := openai.ChatCompletionRequest{
 			Model:            chat.Config.Model.Name,
-			Messages:         messages,
+			Messages:         completion.RedactMessages(vault, append(messages, retryMsgs...)),
 			MaxTokens:        chat.Config.MaxTokens,
 			Temperature:      chat.Config.Temperature,
 			TopP:             chat.Config.TopP,
 			PresencePenalty:  chat.Config.PresencePenalty,
 			FrequencyPenalty: chat.Config.FrequencyPenalty,
 			Stop:             chat.Config.Stop,
-		},
-	)
+		}
+		if schema != nil && schema.ObjectMode() {
+			request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+		}
+		if registry.Len() > 0 {
+			request.Tools = completion.ToolDefinitions(registry)
+			//na ultima rodada e nas correcoes do json o modelo tem que responder sem ferramentas
+			if toolRounds >= registry.MaxRounds || retries > 0 {
+				request.ToolChoice = "none"
+			}
+		}
+
+		resp, err := uc.OpenAIClient.CreateChatCompletion(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("error openai: %w", completion.ProviderError(err))
+		}
+		//todas as rodadas contam na cota, inclusive as de ferramentas e as correcoes do json
+		if err := uc.Usage.Record(ctx, resp.Usage.TotalTokens); err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("error openai: %w", entity.NewProviderError(0, errors.New("empty response")))
+		}
+
+		reply := resp.Choices[0].Message
+		if len(reply.ToolCalls) > 0 && registry.Len() > 0 {
+			if err := completion.RunTools(ctx, registry, chat, vault, reply.Content, reply.ToolCalls); err != nil {
+				return nil, err
+			}
+			toolRounds++
+			continue
+		}
+
+		content = reply.Content
+		if schema == nil {
+			break
+		}
+		object, err = schema.Parse(content)
+		if err == nil {
+			break
+		}
+		var validationErr *structured.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("error validating response: %w", err)
+		}
+		if retries >= maxRetries {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidOutput, validationErr)
+		}
+		retries++
+		//as tentativas invalidas nao sao salvas no chat
+		retry, err := structured.RetryMessage(validationErr, chat.Config.Model)
+		if err != nil {
+			return nil, fmt.Errorf("error creating retry message: %w", err)
+		}
+		retryMsgs = append(retryMsgs,
+			openai.ChatCompletionMessage{Role: "assistant", Content: content},
+			openai.ChatCompletionMessage{Role: retry.Role, Content: retry.Content},
+		)
+	}
+
+	//moderacao da resposta antes de chegar ao usuario, texto cortado ou trocado perde o objeto do response_schema
+	outputReview, err := uc.Moderator.Output(ctx, content, false)
 	if err != nil {
-		return nil, errors.New("error openai: " + err.Error())
+		return nil, err
+	}
+	if outputReview.Changed {
+		content, object = outputReview.Content, nil
 	}
 
-	assistant, err := entity.NewMessage("assistant", resp.Choices[0].Message.Content, chat.Config.Model)
+	assistant, err := entity.NewMessage("assistant", vault.Store(content), chat.Config.Model)
 	if err != nil {
 		return nil, err
 	}
+	assistant.Moderation = outputReview.Verdicts
 	err = chat.AddMessage(assistant)
 	if err != nil {
 		return nil, err
@@ -112,35 +301,22 @@ func (uc *ChatCompletionUseCase) Execute(ctx context.Context, input ChatCompleti
 		return nil, err
 	}
 
+	//a resposta so é entregue depois de gravada na auditoria
+	entry := audit.NewCompletion(chat, userMessage, assistant)
+	entry.Config.ToolProfile = input.ToolProfile
+	entry.Config.KnowledgeBaseID = input.KnowledgeBaseID
+	entry.Config.ResponseSchema = schema != nil
+	if err := uc.Audit.Record(ctx, entity.AuditCompletion, "chat.completion", chat.ID, entry); err != nil {
+		return nil, err
+	}
+
 	output := &ChatCompletionOutputDTO{
-		ChatID:  chat.ID,
-		UserID:  input.UserID,
-		Content: resp.Choices[0].Message.Content,
+		ChatID:    chat.ID,
+		UserID:    input.UserID,
+		Content:   vault.Restore(content),
+		Citations: completion.Citations(citations),
+		Object:    vault.RestoreJSON(object),
 	}
 
 	return output, nil
 }
-
-func createNewChat(input ChatCompletionInputDTO) (*entity.Chat, error) {
-	model := entity.NewModel(input.Config.Model, input.Config.ModelMaxTokens)
-	chatConfig := &entity.ChatConfig{
-		Temperature:      input.Config.Temperature,
-		TopP:             input.Config.TopP,
-		N:                input.Config.N,
-		Stop:             input.Config.Stop,
-		MaxTokens:        input.Config.MaxTokens,
-		PresencePenalty:  input.Config.PresencePenalty,
-		FrequencyPenalty: input.Config.FrequencyPenalty,
-		Model:            model,
-	}
-
-	initialMessage, err := entity.NewMessage("system", input.Config.InitialSystemMessage, model)
-	if err != nil {
-		return nil, errors.New("error creating initial message: " + err.Error())
-	}
-	chat, err := entity.NewChat(input.UserID, initialMessage, chatConfig)
-	if err != nil {
-		return nil, errors.New("error creating new chat: " + err.Error())
-	}
-	return chat, nil
-}
\ No newline at end of file
@@ -2,103 +2,225 @@ package chatcompletionstream
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 
 	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
 	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/completion"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/knowledge"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/moderation"
+	prompttemplate "github.com/ruhancs/virtual-assistant/internal/usecase/prompt_template"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/recall"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/structured"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tenant"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
 	openai "github.com/sashabaranov/go-openai" //comunicacao com chat gpt
 )
 
-// configuracao para enviar ao execute, para configurar a api do chat gpt
-type ChatCompletionConfigInputDTO struct {
-	Model                string
-	ModelMaxTokens       int
-	Temperature          float32
-	TopP                 float32
-	N                    int
-	Stop                 []string
-	MaxTokens            int
-	PresencePenalty      float32
-	FrequencyPenalty     float32
-	InitialSystemMessage string
-}
-
-// dados que o usuario envia para o chat gpt
-type ChatCompletionInputDTO struct {
-	ChatID      string
-	UserID      string
-	UserMessage string
-	Config      ChatCompletionConfigInputDTO
-}
+// entrada e citacoes iguais as da completion http, definidas no pacote completion
+type (
+	ChatCompletionConfigInputDTO = completion.ConfigInputDTO
+	ChatCompletionInputDTO       = completion.InputDTO
+	CitationOutputDTO            = completion.CitationOutputDTO
+)
 
 type ChatCompletionOutputDTO struct {
-	ChatID  string
-	UserID  string
-	Content string //resposta do chat gpt
+	ChatID    string
+	UserID    string
+	Content   string //resposta do chat gpt
+	Citations []CitationOutputDTO
+	Object    json.RawMessage //resposta validada no response_schema, somente na ultima msg do stream
 }
 
 type ChatCompletionUseCase struct {
 	Gateway      gateway.ChatGateway
-	OpenAIClient *openai.Client //comunicacao com api do chat gpt
-	Stream       chan ChatCompletionOutputDTO
+	OpenAIClient *openai.Client                              //comunicacao com api do chat gpt
+	Recaller     *recall.Recaller                            // opcional, nil desliga a recuperacao de msgs apagadas
+	Retriever    *knowledge.Retriever                        // opcional, nil desliga a busca na base de conhecimento
+	Tools        *tools.Registry                             // opcional, ferramentas que o modelo pode chamar
+	Templates    *prompttemplate.RenderPromptTemplateUseCase // opcional, nil sempre usa a msg inicial da config
+	Assistants   gateway.AssistantGateway                    // opcional, nil desliga os assistentes
+	Usage        *tenant.Usage                               // opcional, nil nao registra o uso dos tenants (a cota é checada no middleware)
+	Redactor     *redact.Redactor                            // opcional, nil envia os dados pessoais sem redacao ao provedor
+	Moderator    *moderation.Moderator                       // opcional, nil nao modera a msg do usuario nem a resposta
+	Audit        *audit.Logger                               // opcional, nil nao grava as respostas no log de auditoria
 }
 
-func NewChatCompletionUseCase(gateway gateway.ChatGateway, openAIChatClient *openai.Client, stream chan ChatCompletionOutputDTO) *ChatCompletionUseCase {
+func NewChatCompletionUseCase(gateway gateway.ChatGateway, openAIChatClient *openai.Client, recaller *recall.Recaller, retriever *knowledge.Retriever, registry *tools.Registry, templates *prompttemplate.RenderPromptTemplateUseCase, assistants gateway.AssistantGateway, usage *tenant.Usage, redactor *redact.Redactor, moderator *moderation.Moderator, auditLogger *audit.Logger) *ChatCompletionUseCase {
 	return &ChatCompletionUseCase{
 		Gateway:      gateway,
 		OpenAIClient: openAIChatClient,
-		Stream:       stream,
+		Recaller:     recaller,
+		Retriever:    retriever,
+		Tools:        registry,
+		Templates:    templates,
+		Assistants:   assistants,
+		Usage:        usage,
+		Redactor:     redactor,
+		Moderator:    moderator,
+		Audit:        auditLogger,
 	}
 }
 
-func (usecase *ChatCompletionUseCase) Execute(ctx context.Context, userInput ChatCompletionInputDTO) (*ChatCompletionOutputDTO, error) {
-	//checar se o chat existe
+// Execute envia as partes da resposta em stream, canal de cada chamada que o chamador fecha depois do retorno
+func (usecase *ChatCompletionUseCase) Execute(ctx context.Context, userInput ChatCompletionInputDTO, stream chan<- ChatCompletionOutputDTO) (*ChatCompletionOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, userInput.UserID)
+	if err != nil {
+		return nil, err
+	}
+	userInput.UserID = callerID
+	//checar se o chat existe, chat existente segue o assistente com que foi criado
 	chat, err := usecase.Gateway.FindChatByID(ctx, userInput.ChatID)
+	if err != nil && !errors.Is(err, entity.ErrChatNotFound) {
+		return nil, fmt.Errorf("error fetching existing chat: %w", err)
+	}
+	if chat != nil {
+		//somente o dono do chat ou usuarios com acesso compartilhado podem continuar a conversa
+		if !chat.CanAccess(userInput.UserID) {
+			return nil, entity.ErrForbidden
+		}
+		if userInput.AssistantID != "" && userInput.AssistantID != chat.AssistantID {
+			return nil, fmt.Errorf("%w: chat belongs to another assistant", entity.ErrInvalidConfig)
+		}
+		userInput.AssistantID = chat.AssistantID
+	}
+	//chat novo sem assistente usa o assistente padrao do tenant
+	org := entity.TenantFromContext(ctx)
+	if chat == nil && userInput.AssistantID == "" && org != nil {
+		userInput.AssistantID = org.DefaultAssistantID
+	}
+	userInput, err = completion.WithAssistant(ctx, usecase.Assistants, userInput)
 	if err != nil {
-		if err.Error() == "chat not found" {
-			//criar novo chat (entity)
-			chat, err = createNewChat(userInput)
-			if err != nil {
-				return nil, errors.New("error to create the chat: " + err.Error())
-			}
-			//inserir o novo chat no db
-			err = usecase.Gateway.CreateChat(ctx, chat)
-			if err != nil {
-				return nil, errors.New("error to save the chat on db: " + err.Error())
-			}
-		} else {
-			return nil, errors.New("error fetching existing chat: " + err.Error())
+		return nil, err
+	}
+	model := userInput.Config.Model
+	if chat != nil {
+		model = chat.Config.Model.Name
+	}
+	if !org.AllowsModel(model) {
+		return nil, fmt.Errorf("%w: model %s is not allowed for the tenant", entity.ErrInvalidConfig, model)
+	}
+
+	//perfil de ferramentas e response_schema sao validados antes de criar o chat
+	userInput.ToolProfile = completion.ToolProfile(ctx, chat, userInput)
+	registry, err := usecase.Tools.ForProfile(userInput.ToolProfile)
+	if err != nil {
+		return nil, err
+	}
+	var schema *structured.Schema
+	if len(userInput.ResponseSchema) > 0 {
+		schema, err = structured.Compile(userInput.ResponseSchema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	maxRetries := userInput.Config.SchemaMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = structured.DefaultMaxRetries
+	}
+
+	//dados pessoais trocados por placeholders no que vai ao provedor, o vault devolve os valores na resposta
+	vault := usecase.Redactor.NewVault()
+	//moderacao da msg do usuario antes de criar o chat, no mesmo texto que o provedor recebe
+	inputReview, err := usecase.Moderator.Input(ctx, vault.Redact(userInput.UserMessage))
+	if err != nil {
+		return nil, err
+	}
+	if inputReview.Changed {
+		userInput.UserMessage = vault.Restore(inputReview.Content)
+	}
+
+	if chat == nil {
+		//criar novo chat (entity)
+		prompt, err := completion.InitialPrompt(ctx, usecase.Templates, userInput)
+		if err != nil {
+			return nil, err
+		}
+		chat, err = completion.NewChat(userInput, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("error to create the chat: %w", err)
+		}
+		//inserir o novo chat no db
+		err = usecase.Gateway.CreateChat(ctx, chat)
+		if err != nil {
+			return nil, fmt.Errorf("error to save the chat on db: %w", err)
 		}
 	}
 
 	//criacao da message para enviar ao chat
-	userMessage, err := entity.NewMessage("user", userInput.UserMessage, chat.Config.Model)
+	userMessage, err := entity.NewMessage("user", vault.Store(userInput.UserMessage), chat.Config.Model)
 	if err != nil {
-		return nil, errors.New("error creating user msg: " + err.Error())
+		return nil, fmt.Errorf("error creating user msg: %w", err)
 	}
+	userMessage.Moderation = inputReview.Verdicts
 
 	err = chat.AddMessage(userMessage)
 	if err != nil {
-		return nil, errors.New("error to add new user msg: " + err.Error())
+		return nil, fmt.Errorf("error to add new user msg: %w", err)
 	}
 
-	//adicionar todas messages do chat em messages, no formato da api do openai
-	messages := []openai.ChatCompletionMessage{}
-	for _, msg := range chat.Messages {
-		messages = append(messages, openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+	//msgs de contexto (base de conhecimento e msgs relembradas) entram em todas as rodadas, mas nao sao salvas no chat
+	var contextMsgs []*entity.Message
+	budget := recall.Budget(chat)
+
+	//instrucao do formato json vai no fim da conversa, depois das msgs do chat
+	var instruction *entity.Message
+	if schema != nil {
+		instruction, err = schema.Instruction(chat.Config.Model)
+		if err != nil {
+			return nil, fmt.Errorf("error creating response schema instruction: %w", err)
+		}
+		budget -= instruction.GetQTDTokens()
 	}
 
-	//enviar o contexto de messages ao chat para ele retornar a resposta
-	respStream, err := usecase.OpenAIClient.CreateChatCompletionStream(
-		ctx,
-		openai.ChatCompletionRequest{
+	//trechos da base de conhecimento, tem prioridade sobre as msgs relembradas no orcamento de tokens
+	var citations []CitationOutputDTO
+	if usecase.Retriever != nil {
+		excerpts, found, err := usecase.Retriever.ContextMessage(ctx, userInput.KnowledgeBaseID, chat.Config.Model, vault, userInput.UserMessage, budget)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving knowledge base excerpts: %w", err)
+		}
+		if excerpts != nil {
+			contextMsgs = append(contextMsgs, excerpts)
+			budget -= excerpts.GetQTDTokens()
+			citations = completion.Citations(found)
+		}
+	}
+
+	//msgs antigas, que ja sairam do contexto, relevantes para a msg atual
+	if usecase.Recaller != nil {
+		recalled, err := usecase.Recaller.ContextMessage(ctx, chat, vault, userInput.UserMessage, budget)
+		if err != nil {
+			return nil, fmt.Errorf("error recalling erased messages: %w", err)
+		}
+		if recalled != nil {
+			contextMsgs = append(contextMsgs, recalled)
+		}
+	}
+
+	//o modelo pode pedir ferramentas, que sao executadas e devolvidas a ele ate vir a resposta final.
+	//Com response_schema a resposta final é validada e as falhas voltam ao modelo para correcao,
+	//o stream recomeca o conteudo a cada tentativa
+	var fullResponse strings.Builder //strings.builder() permiter adicionar mais dados a string
+	var object json.RawMessage
+	var retryMsgs []openai.ChatCompletionMessage
+	var outputReview *moderation.Review
+	toolRounds, retries := 0, 0
+	for {
+		messages := completion.ChatMessages(chat, contextMsgs)
+		if instruction != nil {
+			messages = append(messages, openai.ChatCompletionMessage{Role: instruction.Role, Content: instruction.Content})
+		}
+		request := openai.ChatCompletionRequest{
 			Model:            chat.Config.Model.Name,
-			Messages:         messages,
+			Messages:         completion.RedactMessages(vault, append(messages, retryMsgs...)),
 			MaxTokens:        chat.Config.MaxTokens,
 			Temperature:      chat.Config.Temperature,
 			TopP:             chat.Config.TopP,
@@ -106,79 +228,208 @@ func (usecase *ChatCompletionUseCase) Execute(ctx context.Context, userInput Cha
 			FrequencyPenalty: chat.Config.FrequencyPenalty,
 			Stop:             chat.Config.Stop,
 			Stream:           true, //conforme vai gerando a msg ja vai enviando, nao espera a msg estar totalmente pronta
-		},
-	)
-	if err != nil {
-		return nil, errors.New("error creating chat completion: " + err.Error())
-	}
+		}
+		if schema != nil && schema.ObjectMode() {
+			request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+		}
+		if registry.Len() > 0 {
+			request.Tools = completion.ToolDefinitions(registry)
+			//na ultima rodada e nas correcoes do json o modelo tem que responder sem ferramentas
+			if toolRounds >= registry.MaxRounds || retries > 0 {
+				request.ToolChoice = "none"
+			}
+		}
+		//o uso de tokens vem no ultimo pedaco do stream, somente pedido quando a cota é controlada
+		if usecase.Usage.Enabled(ctx) {
+			request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+		}
 
-	//observar a msg de resposta do chat gpt conforme ele envia
-	var fullResponse strings.Builder//strings.builder() permiter adicionar mais dados a string
-	for {
-		response, err := respStream.Recv()
-		// erro que indica que a msg acabou
-		if errors.Is(err, io.EOF) {
+		//enviar o contexto de messages ao chat para ele retornar a resposta
+		respStream, err := usecase.OpenAIClient.CreateChatCompletionStream(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("error creating chat completion: %w", completion.ProviderError(err))
+		}
+
+		//observar a msg de resposta do chat gpt conforme ele envia
+		fullResponse.Reset()
+		outputReview = nil
+		var calls []openai.ToolCall
+		var usedTokens int
+		for {
+			response, err := respStream.Recv()
+			// erro que indica que a msg acabou
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				respStream.Close()
+				return nil, fmt.Errorf("error streming response: %w", completion.ProviderError(err))
+			}
+			if response.Usage != nil {
+				usedTokens = response.Usage.TotalTokens
+			}
+			//resposta ja cortada pela moderacao, o stream so é lido ate o fim para registrar o uso
+			if len(response.Choices) == 0 || outputReview != nil {
+				continue
+			}
+			delta := response.Choices[0].Delta
+			//pedidos de ferramenta chegam em pedacos, identificados pelo index
+			if len(delta.ToolCalls) > 0 {
+				calls = mergeToolCalls(calls, delta.ToolCalls)
+				continue
+			}
+			if delta.Content == "" {
+				continue
+			}
+			//inserir conforme chega a resposta do chat gpt em fullResponse
+			fullResponse.WriteString(delta.Content)
+			//politicas incrementais antes de enviar o pedaco, o trecho sinalizado nao chega ao usuario
+			review, err := usecase.Moderator.Output(ctx, fullResponse.String(), true)
+			if err != nil {
+				respStream.Close()
+				return nil, err
+			}
+			if review.Changed {
+				outputReview = review
+				fullResponse.Reset()
+				fullResponse.WriteString(review.Content)
+			}
+
+			//montar o output do chat
+			r := ChatCompletionOutputDTO{
+				ChatID:    chat.ID,
+				UserID:    userInput.UserID,
+				Content:   vault.RestorePartial(fullResponse.String()),
+				Citations: citations,
+			}
+			//inserir a saida no canal, para ser enviado por outra thread, que sera utilizado com grpc para saida
+			stream <- r
+		}
+		respStream.Close()
+		//todas as rodadas contam na cota, inclusive as de ferramentas e as correcoes do json
+		if err := usecase.Usage.Record(ctx, usedTokens); err != nil {
+			return nil, err
+		}
+		if outputReview != nil {
 			break
 		}
+
+		if len(calls) > 0 && registry.Len() > 0 {
+			if err := completion.RunTools(ctx, registry, chat, vault, fullResponse.String(), calls); err != nil {
+				return nil, err
+			}
+			toolRounds++
+			continue
+		}
+
+		//politicas que rodam somente na resposta completa (provedor), a troca vai na ultima msg do stream
+		outputReview, err = usecase.Moderator.Output(ctx, fullResponse.String(), false)
 		if err != nil {
-			return nil, errors.New("error streming response: " + err.Error())
+			return nil, err
+		}
+		if outputReview.Changed {
+			fullResponse.Reset()
+			fullResponse.WriteString(outputReview.Content)
+			stream <- ChatCompletionOutputDTO{
+				ChatID:    chat.ID,
+				UserID:    userInput.UserID,
+				Content:   vault.Restore(fullResponse.String()),
+				Citations: citations,
+			}
+			break
 		}
-		//inserir conforme chega a resposta do chat gpt em fullResponse
-		fullResponse.WriteString(response.Choices[0].Delta.Content)
 
-		//montar o output do chat
-		r := ChatCompletionOutputDTO{
-			ChatID:  chat.ID,
-			UserID:  userInput.UserID,
-			Content: fullResponse.String(),
+		if schema == nil {
+			break
+		}
+		object, err = schema.Parse(fullResponse.String())
+		if err == nil {
+			//ultima msg do stream leva o objeto validado
+			stream <- ChatCompletionOutputDTO{
+				ChatID:    chat.ID,
+				UserID:    userInput.UserID,
+				Content:   vault.Restore(fullResponse.String()),
+				Citations: citations,
+				Object:    vault.RestoreJSON(object),
+			}
+			break
+		}
+		var validationErr *structured.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("error validating response: %w", err)
+		}
+		if retries >= maxRetries {
+			return nil, fmt.Errorf("%w: %s", entity.ErrInvalidOutput, validationErr)
+		}
+		retries++
+		//as tentativas invalidas nao sao salvas no chat
+		retry, err := structured.RetryMessage(validationErr, chat.Config.Model)
+		if err != nil {
+			return nil, fmt.Errorf("error creating retry message: %w", err)
 		}
-		//inserir a saida no canal, para ser enviado por outra thread, que sera utilizado com grpc para saida
-		usecase.Stream <- r
+		retryMsgs = append(retryMsgs,
+			openai.ChatCompletionMessage{Role: "assistant", Content: fullResponse.String()},
+			openai.ChatCompletionMessage{Role: retry.Role, Content: retry.Content},
+		)
 	}
 
 	//criar msgs igual ao contexto de msgs enviadas ao chat para ser salva no db
-	assistant, err := entity.NewMessage("assistant", fullResponse.String(), chat.Config.Model)
+	assistant, err := entity.NewMessage("assistant", vault.Store(fullResponse.String()), chat.Config.Model)
 	if err != nil {
-		return nil, errors.New("error to create new message: " + err.Error())
+		return nil, fmt.Errorf("error to create new message: %w", err)
 	}
+	assistant.Moderation = outputReview.Verdicts
 	err = chat.AddMessage(assistant)
 	if err != nil {
-		return nil, errors.New("error to add message: " + err.Error())
+		return nil, fmt.Errorf("error to add message: %w", err)
 	}
 	//salvar dados do chat
 	err = usecase.Gateway.SaveChat(ctx, chat)
 	if err != nil {
-		return nil, errors.New("error save chat on db: " + err.Error())
+		return nil, fmt.Errorf("error save chat on db: %w", err)
+	}
+
+	entry := audit.NewCompletion(chat, userMessage, assistant)
+	entry.Stream = true
+	entry.Config.ToolProfile = userInput.ToolProfile
+	entry.Config.KnowledgeBaseID = userInput.KnowledgeBaseID
+	entry.Config.ResponseSchema = schema != nil
+	if err := usecase.Audit.Record(ctx, entity.AuditCompletion, "chat.completion", chat.ID, entry); err != nil {
+		return nil, err
 	}
 
 	return &ChatCompletionOutputDTO{
-		ChatID:  chat.ID,
-		UserID:  userInput.UserID,
-		Content: fullResponse.String(),
+		ChatID:    chat.ID,
+		UserID:    userInput.UserID,
+		Content:   vault.Restore(fullResponse.String()),
+		Citations: citations,
+		Object:    vault.RestoreJSON(object),
 	}, nil
 }
 
-func createNewChat(input ChatCompletionInputDTO) (*entity.Chat, error) {
-	model := entity.NewModel(input.Config.Model, input.Config.ModelMaxTokens)
-	chatConfig := &entity.ChatConfig{
-		Temperature:      input.Config.Temperature,
-		TopP:             input.Config.TopP,
-		N:                input.Config.N,
-		Stop:             input.Config.Stop,
-		MaxTokens:        input.Config.ModelMaxTokens,
-		PresencePenalty:  input.Config.PresencePenalty,
-		FrequencyPenalty: input.Config.FrequencyPenalty,
-		Model:            model,
-	}
-	initialMessage, err := entity.NewMessage("system", input.Config.InitialSystemMessage, model)
-	if err != nil {
-		return nil, errors.New("error to create initial message: " + err.Error())
-	}
-
-	chat, err := entity.NewChat(input.UserID, initialMessage, chatConfig)
-	if err != nil {
-		return nil, errors.New("error to create new chat: " + err.Error())
+// mergeToolCalls junta os pedacos de pedidos de ferramenta recebidos no stream
+func mergeToolCalls(calls []openai.ToolCall, deltas []openai.ToolCall) []openai.ToolCall {
+	for _, d := range deltas {
+		//sem index, um id novo abre outra chamada e o resto continua a ultima
+		i := len(calls)
+		if d.Index != nil {
+			i = *d.Index
+		} else if d.ID == "" && len(calls) > 0 {
+			i = len(calls) - 1
+		}
+		if i < 0 {
+			continue
+		}
+		for len(calls) <= i {
+			calls = append(calls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		}
+		if d.ID != "" {
+			calls[i].ID = d.ID
+		}
+		if d.Function.Name != "" {
+			calls[i].Function.Name = d.Function.Name
+		}
+		calls[i].Function.Arguments += d.Function.Arguments
 	}
-
-	return chat, nil
+	return calls
 }
@@ -0,0 +1,73 @@
+package chathistory
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// os cursores sao opacos para o cliente, json em base64 url
+type chatCursor struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        string    `json:"i"`
+}
+
+type messageCursor struct {
+	Order int `json:"o"`
+}
+
+func encodeCursor(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(cursor string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return entity.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return entity.ErrInvalidCursor
+	}
+	return nil
+}
+
+func decodeChatCursor(cursor string) (*gateway.ChatCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	var c chatCursor
+	if err := decodeCursor(cursor, &c); err != nil {
+		return nil, err
+	}
+	return &gateway.ChatCursor{UpdatedAt: c.UpdatedAt, ID: c.ID}, nil
+}
+
+func decodeMessageCursor(cursor string) (*int, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	var c messageCursor
+	if err := decodeCursor(cursor, &c); err != nil {
+		return nil, err
+	}
+	return &c.Order, nil
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
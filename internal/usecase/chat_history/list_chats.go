@@ -0,0 +1,89 @@
+package chathistory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListChatsInputDTO struct {
+	UserID string `json:"user_id"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type ChatSummaryOutputDTO struct {
+	ChatID     string    `json:"chat_id"`
+	UserID     string    `json:"user_id"`
+	Status     string    `json:"status"`
+	Model      string    `json:"model"`
+	TokenUsage int       `json:"token_usage"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	PromptTemplate  string `json:"prompt_template,omitempty"` //template da msg inicial, vazio quando veio da config
+	TemplateVersion int    `json:"template_version,omitempty"`
+	AssistantID     string `json:"assistant_id,omitempty"`
+}
+
+type ListChatsOutputDTO struct {
+	Chats      []ChatSummaryOutputDTO `json:"chats"`
+	NextCursor string                 `json:"next_cursor,omitempty"` //vazio quando nao ha mais paginas
+}
+
+type ListChatsUseCase struct {
+	ChatGateway gateway.ChatGateway
+}
+
+func NewListChatsUseCase(chatGateway gateway.ChatGateway) *ListChatsUseCase {
+	return &ListChatsUseCase{
+		ChatGateway: chatGateway,
+	}
+}
+
+func (uc *ListChatsUseCase) Execute(ctx context.Context, input ListChatsInputDTO) (*ListChatsOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = callerID
+	after, err := decodeChatCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageLimit(input.Limit)
+
+	//busca um a mais para saber se existe proxima pagina
+	chats, err := uc.ChatGateway.ListChatsByUserID(ctx, input.UserID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("error listing chats: %w", err)
+	}
+
+	output := &ListChatsOutputDTO{Chats: []ChatSummaryOutputDTO{}}
+	if len(chats) > limit {
+		chats = chats[:limit]
+		last := chats[len(chats)-1]
+		output.NextCursor = encodeCursor(chatCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+
+	for _, chat := range chats {
+		output.Chats = append(output.Chats, ChatSummaryOutputDTO{
+			ChatID:     chat.ID,
+			UserID:     chat.UserID,
+			Status:     chat.Status,
+			Model:      chat.Config.Model.Name,
+			TokenUsage: chat.TokenUsage,
+			CreatedAt:  chat.CreatedAt,
+			UpdatedAt:  chat.UpdatedAt,
+
+			PromptTemplate:  chat.PromptTemplate,
+			TemplateVersion: chat.TemplateVersion,
+			AssistantID:     chat.AssistantID,
+		})
+	}
+	return output, nil
+}
@@ -0,0 +1,87 @@
+package chathistory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListMessagesInputDTO struct {
+	ChatID string `json:"chat_id"`
+	UserID string `json:"user_id"`
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type MessageOutputDTO struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Tokens    int       `json:"tokens"`
+	Erased    bool      `json:"erased"` //fora do contexto enviado ao modelo
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListMessagesOutputDTO struct {
+	ChatID     string             `json:"chat_id"`
+	Messages   []MessageOutputDTO `json:"messages"` //da mais recente para a mais antiga
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type ListMessagesUseCase struct {
+	ChatGateway gateway.ChatGateway
+}
+
+func NewListMessagesUseCase(chatGateway gateway.ChatGateway) *ListMessagesUseCase {
+	return &ListMessagesUseCase{
+		ChatGateway: chatGateway,
+	}
+}
+
+func (uc *ListMessagesUseCase) Execute(ctx context.Context, input ListMessagesInputDTO) (*ListMessagesOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = callerID
+	before, err := decodeMessageCursor(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := pageLimit(input.Limit)
+
+	chat, err := uc.ChatGateway.FindChatSummaryByID(ctx, input.ChatID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching chat: %w", err)
+	}
+	if !chat.CanAccess(input.UserID) {
+		return nil, entity.ErrForbidden
+	}
+
+	messages, err := uc.ChatGateway.ListMessagesByChatID(ctx, chat.ID, before, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("error listing messages: %w", err)
+	}
+
+	output := &ListMessagesOutputDTO{ChatID: chat.ID, Messages: []MessageOutputDTO{}}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		output.NextCursor = encodeCursor(messageCursor{Order: messages[len(messages)-1].Order})
+	}
+
+	for _, msg := range messages {
+		output.Messages = append(output.Messages, MessageOutputDTO{
+			ID:        msg.Message.ID,
+			Role:      msg.Message.Role,
+			Content:   msg.Message.Content,
+			Tokens:    msg.Message.Tokens,
+			Erased:    msg.Erased,
+			CreatedAt: msg.Message.CreatedAt,
+		})
+	}
+	return output, nil
+}
@@ -0,0 +1,137 @@
+package chatsearch
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Highlighter monta um trecho da msg em volta do primeiro termo encontrado,
+// com todos os termos da busca entre Pre e Post
+type Highlighter struct {
+	Pre        string
+	Post       string
+	SnippetLen int // tamanho aproximado do trecho em caracteres, 0 retorna a msg inteira
+}
+
+func NewHighlighter(pre, post string, snippetLen int) Highlighter {
+	return Highlighter{
+		Pre:        pre,
+		Post:       post,
+		SnippetLen: snippetLen,
+	}
+}
+
+type span struct {
+	start, end int
+}
+
+func (h Highlighter) Highlight(content, query string) string {
+	terms := queryTerms(query)
+	lower := strings.ToLower(content)
+
+	//alguns caracteres mudam de tamanho em bytes no lower case, nesse caso nao marca os termos
+	if len(lower) != len(content) {
+		terms = nil
+	}
+
+	var spans []span
+	for _, term := range terms {
+		for i := 0; i < len(lower); {
+			idx := strings.Index(lower[i:], term)
+			if idx < 0 {
+				break
+			}
+			start := i + idx
+			spans = append(spans, span{start, start + len(term)})
+			i = start + len(term)
+		}
+	}
+	spans = mergeSpans(spans)
+
+	from, to := 0, len(content)
+	if h.SnippetLen > 0 && utf8.RuneCountInString(content) > h.SnippetLen {
+		center := 0
+		if len(spans) > 0 {
+			center = spans[0].start
+		}
+		from, to = window(content, center, h.SnippetLen)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		b.WriteString(content[pos:start])
+		b.WriteString(h.Pre)
+		b.WriteString(content[start:end])
+		b.WriteString(h.Post)
+		pos = end
+	}
+	b.WriteString(content[pos:to])
+	if to < len(content) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// queryTerms separa a busca em termos, removendo os operadores do modo boolean do mysql
+func queryTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	seen := map[string]bool{}
+	var terms []string
+	for _, f := range fields {
+		if !seen[f] {
+			seen[f] = true
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+func mergeSpans(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []span
+	for _, s := range spans {
+		if len(merged) > 0 && s.start <= merged[len(merged)-1].end {
+			if s.end > merged[len(merged)-1].end {
+				merged[len(merged)-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// window retorna os limites (em bytes, sem cortar runas) de um trecho de size caracteres em volta de center
+func window(content string, center, size int) (int, int) {
+	runes := []int{}
+	for i := range content {
+		runes = append(runes, i)
+	}
+	centerRune := sort.SearchInts(runes, center)
+	startRune := centerRune - size/3
+	if startRune < 0 {
+		startRune = 0
+	}
+	endRune := startRune + size
+	if endRune > len(runes) {
+		endRune = len(runes)
+		startRune = max(0, endRune-size)
+	}
+	end := len(content)
+	if endRune < len(runes) {
+		end = runes[endRune]
+	}
+	return runes[startRune], end
+}
@@ -0,0 +1,144 @@
+package chatsearch
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type SearchMessagesInputDTO struct {
+	UserID string    `json:"user_id"`
+	Query  string    `json:"query"`
+	ChatID string    `json:"chat_id,omitempty"`
+	Role   string    `json:"role,omitempty"`
+	From   time.Time `json:"from,omitempty"`
+	To     time.Time `json:"to,omitempty"`
+	Cursor string    `json:"cursor,omitempty"`
+	Limit  int       `json:"limit,omitempty"`
+}
+
+type SearchResultOutputDTO struct {
+	ChatID    string    `json:"chat_id"`
+	MessageID string    `json:"message_id"`
+	Role      string    `json:"role"`
+	Snippet   string    `json:"snippet"` //trecho da msg com os termos entre os marcadores
+	Erased    bool      `json:"erased"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchMessagesOutputDTO struct {
+	Results    []SearchResultOutputDTO `json:"results"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type SearchMessagesUseCase struct {
+	SearchGateway gateway.MessageSearchGateway // nil com a busca desligada (SEARCH_ENABLED=false ou msgs cifradas)
+	Highlighter   Highlighter
+}
+
+func NewSearchMessagesUseCase(searchGateway gateway.MessageSearchGateway) *SearchMessagesUseCase {
+	return &SearchMessagesUseCase{
+		SearchGateway: searchGateway,
+		Highlighter:   NewHighlighter("<mark>", "</mark>", 160),
+	}
+}
+
+func (uc *SearchMessagesUseCase) Execute(ctx context.Context, input SearchMessagesInputDTO) (*SearchMessagesOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = callerID
+	if uc.SearchGateway == nil {
+		return nil, fmt.Errorf("%w: message search is disabled", entity.ErrInvalidConfig)
+	}
+	if strings.TrimSpace(input.Query) == "" {
+		return nil, fmt.Errorf("%w: query is empty", entity.ErrInvalidMessage)
+	}
+	if input.UserID == "" {
+		return nil, fmt.Errorf("%w: user id is empty", entity.ErrInvalidChat)
+	}
+
+	offset, err := decodeOffset(input.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	results, err := uc.SearchGateway.SearchMessages(ctx, gateway.MessageSearch{
+		UserID: input.UserID,
+		Query:  input.Query,
+		ChatID: input.ChatID,
+		Role:   input.Role,
+		From:   input.From,
+		To:     input.To,
+		Offset: offset,
+		Limit:  limit + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching messages: %w", err)
+	}
+
+	output := &SearchMessagesOutputDTO{Results: []SearchResultOutputDTO{}}
+	if len(results) > limit {
+		results = results[:limit]
+		output.NextCursor = encodeOffset(offset + limit)
+	}
+
+	for _, res := range results {
+		output.Results = append(output.Results, SearchResultOutputDTO{
+			ChatID:    res.ChatID,
+			MessageID: res.Message.ID,
+			Role:      res.Message.Role,
+			Snippet:   uc.Highlighter.Highlight(res.Message.Content, input.Query),
+			Erased:    res.Erased,
+			Score:     res.Score,
+			CreatedAt: res.Message.CreatedAt,
+		})
+	}
+	return output, nil
+}
+
+// a busca é ordenada por relevancia, o cursor guarda o offset
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+func encodeOffset(offset int) string {
+	b, _ := json.Marshal(searchCursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeOffset(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, entity.ErrInvalidCursor
+	}
+	var c searchCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Offset < 0 {
+		return 0, entity.ErrInvalidCursor
+	}
+	return c.Offset, nil
+}
@@ -0,0 +1,18 @@
+package chatsearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func TestSearchMessagesDisabled(t *testing.T) {
+	//sem gateway, busca desligada ou msgs cifradas
+	uc := NewSearchMessagesUseCase(nil)
+	_, err := uc.Execute(context.Background(), SearchMessagesInputDTO{UserID: "user-1", Query: "refund"})
+	if !errors.Is(err, entity.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+}
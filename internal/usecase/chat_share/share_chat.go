@@ -0,0 +1,69 @@
+package chatshare
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ShareChatInputDTO struct {
+	ChatID       string `json:"chat_id"`
+	UserID       string `json:"user_id"`        //dono do chat
+	SharedUserID string `json:"shared_user_id"` //usuario que recebe ou perde o acesso
+	Revoke       bool   `json:"revoke,omitempty"`
+}
+
+type ShareChatOutputDTO struct {
+	ChatID     string   `json:"chat_id"`
+	UserID     string   `json:"user_id"`
+	SharedWith []string `json:"shared_with"`
+}
+
+type ShareChatUseCase struct {
+	ChatGateway gateway.ChatGateway
+}
+
+func NewShareChatUseCase(chatGateway gateway.ChatGateway) *ShareChatUseCase {
+	return &ShareChatUseCase{
+		ChatGateway: chatGateway,
+	}
+}
+
+func (uc *ShareChatUseCase) Execute(ctx context.Context, input ShareChatInputDTO) (*ShareChatOutputDTO, error) {
+	//com token assinado o usuario vem das claims, user_id de outro usuario no corpo é recusado
+	callerID, err := entity.CallerUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = callerID
+	chat, err := uc.ChatGateway.FindChatByID(ctx, input.ChatID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching chat: %w", err)
+	}
+
+	//somente o dono pode conceder ou remover acesso
+	if !chat.IsOwner(input.UserID) {
+		return nil, entity.ErrForbidden
+	}
+
+	if input.Revoke {
+		chat.Unshare(input.SharedUserID)
+	} else {
+		if err := chat.Share(input.SharedUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = uc.ChatGateway.SaveChat(ctx, chat)
+	if err != nil {
+		return nil, fmt.Errorf("error saving chat: %w", err)
+	}
+
+	return &ShareChatOutputDTO{
+		ChatID:     chat.ID,
+		UserID:     chat.UserID,
+		SharedWith: chat.SharedWith,
+	}, nil
+}
@@ -0,0 +1,164 @@
+// Package completion partes comuns das duas formas de completion, a resposta inteira (http) e o stream (grpc)
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	prompttemplate "github.com/ruhancs/virtual-assistant/internal/usecase/prompt_template"
+)
+
+type ConfigInputDTO struct {
+	Model                 string
+	ModelMaxTokens        int
+	Temperature           float32  // 0.0 to 1.0
+	TopP                  float32  // 0.0 to 1.0 - to a low value, like 0.1, the model will be very conservative in its word choices, and will tend to generate relatively predictable prompts
+	N                     int      // number of messages to generate
+	Stop                  []string // list of tokens to stop on
+	MaxTokens             int      // number of tokens to generate
+	PresencePenalty       float32  // -2.0 to 2.0 - Number between -2.0 and 2.0. Positive values penalize new tokens based on whether they appear in the text so far, increasing the model's likelihood to talk about new topics.
+	FrequencyPenalty      float32  // -2.0 to 2.0 - Number between -2.0 and 2.0. Positive values penalize new tokens based on their existing frequency in the text so far, increasing the model's likelihood to talk about new topics.
+	InitialSystemMessage  string
+	SchemaMaxRetries      int    // novas tentativas quando a resposta nao valida no response_schema, 0 usa o padrao
+	DefaultPromptTemplate string // template da msg inicial dos chats novos, vazio ou nao cadastrado usa InitialSystemMessage
+}
+
+type InputDTO struct {
+	ChatID                string            `json:"chat_id,omitempty"`
+	UserID                string            `json:"user_id"`
+	UserMessage           string            `json:"user_message"`
+	KnowledgeBaseID       string            `json:"knowledge_base_id,omitempty"`       //vazio usa a base padrao
+	ToolProfile           string            `json:"-"`                                 //perfil de ferramentas, resolvido no servidor (ToolProfile), nunca vem do chamador
+	ResponseSchema        json.RawMessage   `json:"response_schema,omitempty"`         //json schema da resposta, vazio responde em texto livre
+	PromptTemplate        string            `json:"prompt_template,omitempty"`         //template da msg inicial do chat novo, vazio usa o padrao da config
+	PromptTemplateVersion int               `json:"prompt_template_version,omitempty"` //0 usa a versao ativa
+	TemplateVariables     map[string]string `json:"template_variables,omitempty"`      //valores das {{variaveis}} do template
+	AssistantID           string            `json:"assistant_id,omitempty"`            //assistente do chat novo, chats existentes seguem o assistente com que foram criados
+	Config                ConfigInputDTO    `json:"config"`
+}
+
+// WithAssistant aplica o assistente na entrada: config do modelo, msg inicial e, quando definidos,
+// o perfil de ferramentas e a base de conhecimento, que tem prioridade sobre os da requisicao
+func WithAssistant(ctx context.Context, assistants gateway.AssistantGateway, input InputDTO) (InputDTO, error) {
+	input.ToolProfile = ""
+	if input.AssistantID == "" {
+		return input, nil
+	}
+	if assistants == nil {
+		return input, fmt.Errorf("%w: assistants are not enabled", entity.ErrInvalidConfig)
+	}
+	assistant, err := assistants.FindAssistantByID(ctx, input.AssistantID)
+	if err != nil {
+		return input, fmt.Errorf("error fetching assistant: %w", err)
+	}
+
+	config := assistant.Config
+	initialMessage := assistant.SystemPrompt
+	//assistente somente com template usa a msg da config se o template nao existir
+	if initialMessage == "" {
+		initialMessage = input.Config.InitialSystemMessage
+	}
+	input.Config = ConfigInputDTO{
+		Model:                 config.Model.Name,
+		ModelMaxTokens:        config.Model.MaxTokens,
+		Temperature:           config.Temperature,
+		TopP:                  config.TopP,
+		N:                     config.N,
+		Stop:                  config.Stop,
+		MaxTokens:             config.MaxTokens,
+		PresencePenalty:       config.PresencePenalty,
+		FrequencyPenalty:      config.FrequencyPenalty,
+		InitialSystemMessage:  initialMessage,
+		SchemaMaxRetries:      input.Config.SchemaMaxRetries,
+		DefaultPromptTemplate: assistant.PromptTemplate,
+	}
+	if assistant.ToolProfile != "" {
+		input.ToolProfile = assistant.ToolProfile
+	}
+	if assistant.KnowledgeBaseID != "" {
+		input.KnowledgeBaseID = assistant.KnowledgeBaseID
+	}
+	return input, nil
+}
+
+// ToolProfile perfil de ferramentas do chat, resolvido no servidor. O chat existente segue o perfil com que foi criado,
+// o novo usa o do assistente (aplicado pelo WithAssistant) ou o do tenant. Vazio usa o perfil default do registry
+func ToolProfile(ctx context.Context, chat *entity.Chat, input InputDTO) string {
+	if chat != nil {
+		return chat.ToolProfile
+	}
+	if input.ToolProfile != "" {
+		return input.ToolProfile
+	}
+	if tenant := entity.TenantFromContext(ctx); tenant != nil {
+		return tenant.ToolProfile
+	}
+	return ""
+}
+
+// InitialPrompt renderiza o template pedido ou o padrao da config, nil usa a InitialSystemMessage
+func InitialPrompt(ctx context.Context, templates *prompttemplate.RenderPromptTemplateUseCase, input InputDTO) (*prompttemplate.RenderPromptTemplateOutputDTO, error) {
+	if templates == nil {
+		if input.PromptTemplate != "" {
+			return nil, fmt.Errorf("%w: prompt templates are not enabled", entity.ErrInvalidConfig)
+		}
+		return nil, nil
+	}
+	name, version := input.PromptTemplate, input.PromptTemplateVersion
+	if name == "" {
+		name, version = input.Config.DefaultPromptTemplate, 0
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	prompt, err := templates.Execute(ctx, prompttemplate.RenderPromptTemplateInputDTO{
+		Name:      name,
+		Version:   version,
+		UserID:    input.UserID,
+		Variables: input.TemplateVariables,
+	})
+	//template padrao ainda nao cadastrado, segue com a msg da config
+	if input.PromptTemplate == "" && errors.Is(err, entity.ErrPromptTemplateNotFound) {
+		return nil, nil
+	}
+	return prompt, err
+}
+
+// NewChat chat novo com a config da entrada e a msg inicial do template, sem template usa a InitialSystemMessage
+func NewChat(input InputDTO, prompt *prompttemplate.RenderPromptTemplateOutputDTO) (*entity.Chat, error) {
+	model := entity.NewModel(input.Config.Model, input.Config.ModelMaxTokens)
+	chatConfig := &entity.ChatConfig{
+		Temperature:      input.Config.Temperature,
+		TopP:             input.Config.TopP,
+		N:                input.Config.N,
+		Stop:             input.Config.Stop,
+		MaxTokens:        input.Config.MaxTokens,
+		PresencePenalty:  input.Config.PresencePenalty,
+		FrequencyPenalty: input.Config.FrequencyPenalty,
+		Model:            model,
+	}
+	systemMessage := input.Config.InitialSystemMessage
+	if prompt != nil {
+		systemMessage = prompt.Content
+	}
+	initialMessage, err := entity.NewMessage("system", systemMessage, model)
+	if err != nil {
+		return nil, fmt.Errorf("error creating initial message: %w", err)
+	}
+	chat, err := entity.NewChat(input.UserID, initialMessage, chatConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating new chat: %w", err)
+	}
+	if prompt != nil {
+		chat.PromptTemplate = prompt.Name
+		chat.TemplateVersion = prompt.Version
+	}
+	chat.AssistantID = input.AssistantID
+	chat.ToolProfile = input.ToolProfile
+	return chat, nil
+}
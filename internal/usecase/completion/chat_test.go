@@ -0,0 +1,42 @@
+package completion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func TestToolProfile(t *testing.T) {
+	tenantCtx := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "t1", ToolProfile: "tenant"})
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		chat  *entity.Chat
+		input InputDTO
+		want  string
+	}{
+		{name: "existing chat keeps its profile", ctx: tenantCtx, chat: &entity.Chat{ToolProfile: "saved"}, input: InputDTO{ToolProfile: "assistant"}, want: "saved"},
+		{name: "existing chat without profile", ctx: tenantCtx, chat: &entity.Chat{}, input: InputDTO{ToolProfile: "assistant"}, want: ""},
+		{name: "assistant profile", ctx: tenantCtx, input: InputDTO{ToolProfile: "assistant"}, want: "assistant"},
+		{name: "tenant profile", ctx: tenantCtx, want: "tenant"},
+		{name: "default tenant", ctx: context.Background(), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToolProfile(tt.ctx, tt.chat, tt.input); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithAssistantIgnoresCallerToolProfile(t *testing.T) {
+	input, err := WithAssistant(context.Background(), nil, InputDTO{ToolProfile: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.ToolProfile != "" {
+		t.Fatalf("expected the caller profile to be dropped, got %q", input.ToolProfile)
+	}
+}
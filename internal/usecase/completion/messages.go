@@ -0,0 +1,134 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// CitationOutputDTO trecho da base de conhecimento usado na resposta, Index é o numero [n] citado no texto
+type CitationOutputDTO struct {
+	Index      int     `json:"index"`
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	ChunkIndex int     `json:"chunk_index"`
+	Start      int     `json:"start"`
+	End        int     `json:"end"`
+	Score      float32 `json:"score"`
+}
+
+// ProviderError converte os erros do client da openai para os erros do dominio
+func ProviderError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, openai.ErrChatCompletionInvalidModel) {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidConfig, err)
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		if code, ok := apiErr.Code.(string); ok && code == "context_length_exceeded" {
+			return fmt.Errorf("%w: %s", entity.ErrContextOverflow, apiErr.Message)
+		}
+		return entity.NewProviderError(apiErr.HTTPStatusCode, err)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return entity.NewProviderError(reqErr.HTTPStatusCode, err)
+	}
+	return entity.NewProviderError(0, err)
+}
+
+// ChatMessages msgs do chat no formato da api, com as msgs de contexto logo depois da msg inicial de sistema
+func ChatMessages(chat *entity.Chat, contextMsgs []*entity.Message) []openai.ChatCompletionMessage {
+	messages := make([]openai.ChatCompletionMessage, 0, len(chat.Messages)+len(contextMsgs))
+	pending := map[string]bool{}
+	for i, msg := range chat.Messages {
+		if i == 0 && msg.Role != "system" || i == 1 && chat.Messages[0].Role == "system" {
+			messages = append(messages, contextMessages(contextMsgs)...)
+		}
+		//resultado cuja chamada saiu do contexto é rejeitado pela api
+		if msg.Role == "tool" {
+			if !pending[msg.ToolCallID] {
+				continue
+			}
+			delete(pending, msg.ToolCallID)
+		}
+		for _, call := range msg.ToolCalls {
+			pending[call.ID] = true
+		}
+		messages = append(messages, ToOpenAIMessage(msg))
+	}
+	if len(chat.Messages) == 1 && chat.Messages[0].Role == "system" {
+		messages = append(messages, contextMessages(contextMsgs)...)
+	}
+	return messages
+}
+
+func contextMessages(contextMsgs []*entity.Message) []openai.ChatCompletionMessage {
+	messages := make([]openai.ChatCompletionMessage, len(contextMsgs))
+	for i, msg := range contextMsgs {
+		messages[i] = openai.ChatCompletionMessage{Role: msg.Role, Content: msg.Content}
+	}
+	return messages
+}
+
+func ToOpenAIMessage(msg *entity.Message) openai.ChatCompletionMessage {
+	res := openai.ChatCompletionMessage{
+		Role:       msg.Role,
+		Content:    msg.Content,
+		ToolCallID: msg.ToolCallID,
+	}
+	if msg.Role == "function" {
+		res.Name = msg.Name
+	}
+	for _, call := range msg.ToolCalls {
+		res.ToolCalls = append(res.ToolCalls, openai.ToolCall{
+			ID:   call.ID,
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionCall{
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			},
+		})
+	}
+	return res
+}
+
+// RedactMessages redige o conteudo e os argumentos das ferramentas de todas as msgs enviadas ao provedor
+func RedactMessages(vault *redact.Vault, messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	if vault == nil {
+		return messages
+	}
+	for i := range messages {
+		messages[i].Content = vault.Redact(messages[i].Content)
+		for j := range messages[i].ToolCalls {
+			messages[i].ToolCalls[j].Function.Arguments = vault.Redact(messages[i].ToolCalls[j].Function.Arguments)
+		}
+	}
+	return messages
+}
+
+// Citations citacoes da resposta, numeradas na ordem dos trechos enviados ao modelo
+func Citations(citations []entity.Citation) []CitationOutputDTO {
+	if len(citations) == 0 {
+		return nil
+	}
+	res := make([]CitationOutputDTO, len(citations))
+	for i, c := range citations {
+		res[i] = CitationOutputDTO{
+			Index:      i + 1,
+			DocumentID: c.DocumentID,
+			Title:      c.Title,
+			ChunkIndex: c.ChunkIndex,
+			Start:      c.Start,
+			End:        c.End,
+			Score:      c.Score,
+		}
+	}
+	return res
+}
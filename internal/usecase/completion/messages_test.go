@@ -0,0 +1,107 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func roles(messages []openai.ChatCompletionMessage) string {
+	var res []string
+	for _, msg := range messages {
+		res = append(res, msg.Role+":"+msg.Content)
+	}
+	return strings.Join(res, " ")
+}
+
+func TestChatMessages(t *testing.T) {
+	system := &entity.Message{Role: "system", Content: "sys"}
+	user := &entity.Message{Role: "user", Content: "q"}
+	call := &entity.Message{Role: "assistant", ToolCalls: []entity.ToolCall{{ID: "call-1", Name: "current_time", Arguments: "{}"}}}
+	result := &entity.Message{Role: "tool", Content: "r1", ToolCallID: "call-1", Name: "current_time"}
+	orphan := &entity.Message{Role: "tool", Content: "r0", ToolCallID: "call-0", Name: "current_time"}
+	answer := &entity.Message{Role: "assistant", Content: "a"}
+	recalled := []*entity.Message{{Role: "system", Content: "ctx"}}
+
+	tests := []struct {
+		name     string
+		messages []*entity.Message
+		context  []*entity.Message
+		want     string
+	}{
+		{"context after the system message", []*entity.Message{system, user, answer}, recalled, "system:sys system:ctx user:q assistant:a"},
+		{"context first without system message", []*entity.Message{user, answer}, recalled, "system:ctx user:q assistant:a"},
+		{"only the system message", []*entity.Message{system}, recalled, "system:sys system:ctx"},
+		{"tool call and result", []*entity.Message{system, user, call, result, answer}, nil, "system:sys user:q assistant: tool:r1 assistant:a"},
+		//a chamada saiu do contexto, o resultado sozinho é rejeitado pela api
+		{"result without its call", []*entity.Message{system, orphan, user, answer}, nil, "system:sys user:q assistant:a"},
+		{"result answered twice", []*entity.Message{system, call, result, result}, nil, "system:sys assistant: tool:r1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roles(ChatMessages(&entity.Chat{Messages: tt.messages}, tt.context))
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	msg := ToOpenAIMessage(call)
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].ID != "call-1" || msg.ToolCalls[0].Type != openai.ToolTypeFunction || msg.ToolCalls[0].Function.Name != "current_time" {
+		t.Fatalf("unexpected tool calls %+v", msg.ToolCalls)
+	}
+	if msg := ToOpenAIMessage(result); msg.ToolCallID != "call-1" || msg.Name != "" {
+		t.Fatalf("expected the tool result linked to its call, got %+v", msg)
+	}
+}
+
+func TestRunTools(t *testing.T) {
+	registry := tools.NewRegistry(0)
+	var got string
+	err := registry.Register(tools.Tool{Name: "send_mail", Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		got = string(arguments)
+		return "sent to ana@example.com", nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redactor, err := redact.NewRedactor([]string{redact.DetectorEmail}, nil, "test-key", redact.StoreRedacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := redactor.NewVault()
+	placeholder := vault.Redact("ana@example.com")
+
+	chat := &entity.Chat{Status: "active", Config: &entity.ChatConfig{Model: entity.NewModel("gpt-4o", 1000)}}
+	calls := []openai.ToolCall{
+		{ID: "call-1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "send_mail", Arguments: `{"to":"` + placeholder + `"}`}},
+		{ID: "call-2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "missing", Arguments: `{}`}},
+	}
+	if err := RunTools(context.Background(), registry, chat, vault, "", calls); err != nil {
+		t.Fatal(err)
+	}
+
+	//a ferramenta recebe o valor original, o chat guarda o placeholder
+	if got != `{"to":"ana@example.com"}` {
+		t.Fatalf("expected the original value in the tool arguments, got %s", got)
+	}
+	if len(chat.Messages) != 3 {
+		t.Fatalf("expected the call and two results, got %d messages", len(chat.Messages))
+	}
+	request, sent, missing := chat.Messages[0], chat.Messages[1], chat.Messages[2]
+	if request.Role != "assistant" || len(request.ToolCalls) != 2 || request.ToolCalls[0].Arguments != `{"to":"`+placeholder+`"}` {
+		t.Fatalf("expected the redacted call stored, got %+v", request.ToolCalls)
+	}
+	if sent.Role != "tool" || sent.ToolCallID != "call-1" || sent.Name != "send_mail" || strings.Contains(sent.Content, "ana@example.com") {
+		t.Fatalf("expected the redacted result stored, got %+v", sent)
+	}
+	if missing.ToolCallID != "call-2" || !strings.Contains(missing.Content, `unknown tool`) {
+		t.Fatalf("expected the unknown tool error sent back to the model, got %+v", missing)
+	}
+}
@@ -0,0 +1,66 @@
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ToolDefinitions ferramentas do registro no formato da api
+func ToolDefinitions(registry *tools.Registry) []openai.Tool {
+	var res []openai.Tool
+	for _, tool := range registry.Tools() {
+		res = append(res, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	return res
+}
+
+// RunTools salva no chat o pedido do modelo e o resultado de cada ferramenta chamada
+// As ferramentas rodam com os valores originais dos placeholders, o chat salva conforme a politica de redacao
+func RunTools(ctx context.Context, registry *tools.Registry, chat *entity.Chat, vault *redact.Vault, content string, calls []openai.ToolCall) error {
+	toolCalls := make([]entity.ToolCall, len(calls))
+	stored := make([]entity.ToolCall, len(calls))
+	for i, call := range calls {
+		toolCalls[i] = entity.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: string(vault.RestoreJSON(json.RawMessage(call.Function.Arguments))),
+		}
+		stored[i] = toolCalls[i]
+		stored[i].Arguments = vault.Store(toolCalls[i].Arguments)
+	}
+	request, err := entity.NewToolCallMessage(vault.Store(content), stored, chat.Config.Model)
+	if err != nil {
+		return fmt.Errorf("error creating tool call message: %w", err)
+	}
+	if err := chat.AddMessage(request); err != nil {
+		return fmt.Errorf("error adding tool call message: %w", err)
+	}
+
+	for _, call := range toolCalls {
+		result, err := registry.Execute(ctx, call)
+		if err != nil {
+			return fmt.Errorf("error executing tool %s: %w", call.Name, err)
+		}
+		msg, err := entity.NewToolResultMessage(call.ID, call.Name, vault.Store(result), chat.Config.Model)
+		if err != nil {
+			return fmt.Errorf("error creating tool result message: %w", err)
+		}
+		if err := chat.AddMessage(msg); err != nil {
+			return fmt.Errorf("error adding tool result message: %w", err)
+		}
+	}
+	return nil
+}
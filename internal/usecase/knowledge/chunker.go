@@ -0,0 +1,176 @@
+package knowledge
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	tiktoken_go "github.com/j178/tiktoken-go"
+)
+
+const (
+	// modelo usado para contar tokens dos chunks (cl100k, o mesmo dos modelos de embedding da openai)
+	tokenizerModel      = "gpt-3.5-turbo"
+	DefaultChunkSize    = 400
+	DefaultChunkOverlap = 50
+)
+
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// Chunk trecho do texto, Start e End sao offsets em bytes no texto extraido
+type Chunk struct {
+	Content string
+	Tokens  int
+	Start   int
+	End     int
+}
+
+// Chunker divide o texto em trechos de ate Size tokens, repetindo ate Overlap tokens do fim do trecho anterior.
+// Os trechos respeitam paragrafos e frases sempre que possivel
+type Chunker struct {
+	Size    int
+	Overlap int
+	Count   func(text string) int
+}
+
+func NewChunker(size, overlap int) *Chunker {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+	return &Chunker{
+		Size:    size,
+		Overlap: overlap,
+		Count:   countTokens,
+	}
+}
+
+func countTokens(text string) int {
+	return tiktoken_go.CountTokens(tokenizerModel, text)
+}
+
+type segment struct {
+	start  int
+	end    int
+	tokens int
+}
+
+func (c *Chunker) Split(text string) []Chunk {
+	segments := c.segments(text)
+	var chunks []Chunk
+	for i := 0; i < len(segments); {
+		j, tokens := i, 0
+		for j < len(segments) && (j == i || tokens+segments[j].tokens <= c.Size) {
+			tokens += segments[j].tokens
+			j++
+		}
+		content := text[segments[i].start:segments[j-1].end]
+		chunks = append(chunks, Chunk{
+			Content: content,
+			Tokens:  c.Count(content),
+			Start:   segments[i].start,
+			End:     segments[j-1].end,
+		})
+		if j == len(segments) {
+			break
+		}
+
+		//o proximo chunk comeca nos ultimos segmentos deste que cabem no overlap, sempre avancando
+		k, overlap := j, 0
+		for k-1 > i && overlap+segments[k-1].tokens <= c.Overlap {
+			k--
+			overlap += segments[k].tokens
+		}
+		i = k
+	}
+	return chunks
+}
+
+// segments quebra o texto em paragrafos, paragrafos grandes em frases e frases grandes em palavras
+func (c *Chunker) segments(text string) []segment {
+	var segments []segment
+	for _, p := range spans(text, 0, len(text), paragraphSpans) {
+		tokens := c.Count(text[p[0]:p[1]])
+		if tokens <= c.Size {
+			segments = append(segments, segment{start: p[0], end: p[1], tokens: tokens})
+			continue
+		}
+		for _, s := range spans(text, p[0], p[1], sentenceSpans) {
+			tokens := c.Count(text[s[0]:s[1]])
+			if tokens <= c.Size {
+				segments = append(segments, segment{start: s[0], end: s[1], tokens: tokens})
+				continue
+			}
+			for _, w := range spans(text, s[0], s[1], wordSpans) {
+				segments = append(segments, segment{start: w[0], end: w[1], tokens: c.Count(text[w[0]:w[1]])})
+			}
+		}
+	}
+	return segments
+}
+
+// spans aplica split em text[start:end] e devolve os offsets absolutos de cada parte
+func spans(text string, start, end int, split func(string) [][2]int) [][2]int {
+	parts := split(text[start:end])
+	for i := range parts {
+		parts[i][0] += start
+		parts[i][1] += start
+	}
+	return parts
+}
+
+func paragraphSpans(text string) [][2]int {
+	var res [][2]int
+	last := 0
+	for _, loc := range paragraphBreak.FindAllStringIndex(text, -1) {
+		res = appendTrimmed(res, text, last, loc[0])
+		last = loc[1]
+	}
+	return appendTrimmed(res, text, last, len(text))
+}
+
+// sentenceSpans quebra depois de . ! ? seguidos de espaco
+func sentenceSpans(text string) [][2]int {
+	var res [][2]int
+	last := 0
+	for i := 0; i < len(text)-1; i++ {
+		if (text[i] == '.' || text[i] == '!' || text[i] == '?') && unicode.IsSpace(rune(text[i+1])) {
+			res = appendTrimmed(res, text, last, i+1)
+			last = i + 1
+		}
+	}
+	return appendTrimmed(res, text, last, len(text))
+}
+
+func wordSpans(text string) [][2]int {
+	var res [][2]int
+	start := -1
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				res = append(res, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		res = append(res, [2]int{start, len(text)})
+	}
+	return res
+}
+
+// appendTrimmed adiciona text[start:end] sem os espacos das pontas, ignorando partes vazias
+func appendTrimmed(res [][2]int, text string, start, end int) [][2]int {
+	part := text[start:end]
+	trimmed := strings.TrimLeftFunc(part, unicode.IsSpace)
+	start += len(part) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+	if trimmed == "" {
+		return res
+	}
+	return append(res, [2]int{start, start + len(trimmed)})
+}
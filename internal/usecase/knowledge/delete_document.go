@@ -0,0 +1,53 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type DeleteDocumentInputDTO struct {
+	DocumentID string `json:"document_id"`
+}
+
+type DeleteDocumentUseCase struct {
+	KnowledgeGateway gateway.KnowledgeGateway
+	VectorStore      gateway.VectorStore
+}
+
+func NewDeleteDocumentUseCase(knowledgeGateway gateway.KnowledgeGateway, vectorStore gateway.VectorStore) *DeleteDocumentUseCase {
+	return &DeleteDocumentUseCase{
+		KnowledgeGateway: knowledgeGateway,
+		VectorStore:      vectorStore,
+	}
+}
+
+// Execute remove o documento e os chunks, documento inexistente retorna ErrDocumentNotFound
+func (uc *DeleteDocumentUseCase) Execute(ctx context.Context, input DeleteDocumentInputDTO) error {
+	doc, err := uc.KnowledgeGateway.FindDocumentByID(ctx, input.DocumentID)
+	if err != nil {
+		return fmt.Errorf("error fetching document: %w", err)
+	}
+
+	//remove os vetores antes, se falhar o documento continua la e a remocao pode ser repetida
+	chunks, err := uc.KnowledgeGateway.FindChunksByDocumentID(ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching document chunks: %w", err)
+	}
+	ids := map[string][]string{}
+	for _, chunk := range chunks {
+		ids[chunk.Model] = append(ids[chunk.Model], chunk.ID)
+	}
+	for model, chunkIDs := range ids {
+		if err := uc.VectorStore.Delete(ctx, namespace(model), chunkIDs); err != nil {
+			return fmt.Errorf("error deleting document vectors: %w", err)
+		}
+	}
+
+	err = uc.KnowledgeGateway.DeleteDocument(ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("error deleting document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package knowledge
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"golang.org/x/net/html"
+)
+
+var (
+	mdFence    = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeading  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdListItem = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+	mdQuote    = regexp.MustCompile(`^\s*>\s?`)
+	mdImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdEmphasis = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// ExtractText converte o conteudo do documento em texto puro, os offsets dos chunks sao sobre esse texto
+func ExtractText(contentType, content string) (string, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var text string
+	switch contentType {
+	case "text/plain":
+		text = content
+	case "text/markdown":
+		text = extractMarkdown(content)
+	case "text/html":
+		var err error
+		text, err = extractHTML(content)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid html: %s", entity.ErrInvalidDocument, err)
+		}
+	default:
+		return "", fmt.Errorf("%w: unsupported content type %q", entity.ErrInvalidDocument, contentType)
+	}
+	text = strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+	if text == "" {
+		return "", fmt.Errorf("%w: document has no text", entity.ErrInvalidDocument)
+	}
+	return text, nil
+}
+
+// extractMarkdown remove a marcacao mais comum, mantendo o texto e a quebra em paragrafos
+func extractMarkdown(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if mdFence.MatchString(line) {
+			lines[i] = ""
+			continue
+		}
+		line = mdHeading.ReplaceAllString(line, "")
+		line = mdListItem.ReplaceAllString(line, "")
+		line = mdQuote.ReplaceAllString(line, "")
+		line = mdImage.ReplaceAllString(line, "$1")
+		line = mdLink.ReplaceAllString(line, "$1")
+		lines[i] = mdEmphasis.ReplaceAllString(line, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tags de bloco viram quebra de paragrafo
+var htmlBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "br": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "table": true, "ul": true, "ol": true,
+}
+
+func extractHTML(content string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "script", "style", "noscript", "head", "template":
+				return
+			}
+		}
+		if n.Type == html.TextNode {
+			//espacos em sequencia viram um so, como no navegador
+			words := strings.Fields(n.Data)
+			if len(words) == 0 {
+				if n.Data != "" {
+					b.WriteByte(' ')
+				}
+			} else {
+				if isSpace(n.Data[0]) {
+					b.WriteByte(' ')
+				}
+				b.WriteString(strings.Join(words, " "))
+				if isSpace(n.Data[len(n.Data)-1]) {
+					b.WriteByte(' ')
+				}
+			}
+		}
+		block := n.Type == html.ElementNode && htmlBlocks[n.Data]
+		if block {
+			b.WriteString("\n\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			b.WriteString("\n\n")
+		}
+	}
+	walk(doc)
+
+	//remove espacos nas pontas das linhas
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,138 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// qtd de chunks enviados por chamada ao provedor de embeddings
+const embedBatchSize = 64
+
+type IngestDocumentInputDTO struct {
+	KnowledgeBaseID string `json:"knowledge_base_id,omitempty"`
+	Title           string `json:"title"`
+	Source          string `json:"source,omitempty"`
+	ContentType     string `json:"content_type"`
+	Content         string `json:"content"`
+}
+
+type IngestDocumentOutputDTO struct {
+	DocumentID      string `json:"document_id"`
+	KnowledgeBaseID string `json:"knowledge_base_id"`
+	Chunks          int    `json:"chunks"`
+	Tokens          int    `json:"tokens"`
+}
+
+type IngestDocumentUseCase struct {
+	KnowledgeGateway gateway.KnowledgeGateway
+	VectorStore      gateway.VectorStore
+	Embedder         gateway.EmbeddingProvider
+	Chunker          *Chunker
+}
+
+func NewIngestDocumentUseCase(knowledgeGateway gateway.KnowledgeGateway, vectorStore gateway.VectorStore, embedder gateway.EmbeddingProvider, chunker *Chunker) *IngestDocumentUseCase {
+	return &IngestDocumentUseCase{
+		KnowledgeGateway: knowledgeGateway,
+		VectorStore:      vectorStore,
+		Embedder:         embedder,
+		Chunker:          chunker,
+	}
+}
+
+func (uc *IngestDocumentUseCase) Execute(ctx context.Context, input IngestDocumentInputDTO) (*IngestDocumentOutputDTO, error) {
+	if input.ContentType == "" {
+		input.ContentType = "text/plain"
+	}
+	doc, err := entity.NewDocument(input.KnowledgeBaseID, input.Title, input.Source, input.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := ExtractText(doc.ContentType, input.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	pieces := uc.Chunker.Split(text)
+	chunks := make([]*entity.DocumentChunk, len(pieces))
+	now := time.Now()
+	for i, piece := range pieces {
+		chunks[i] = &entity.DocumentChunk{
+			ID:              uuid.New().String(),
+			DocumentID:      doc.ID,
+			KnowledgeBaseID: doc.KnowledgeBaseID,
+			Index:           i,
+			Content:         piece.Content,
+			Tokens:          piece.Tokens,
+			Start:           piece.Start,
+			End:             piece.End,
+			Model:           uc.Embedder.ModelName(),
+			CreatedAt:       now,
+		}
+		doc.Tokens += piece.Tokens
+	}
+
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		texts := make([]string, end-start)
+		for i, chunk := range chunks[start:end] {
+			texts[i] = chunk.Content
+		}
+		vectors, err := uc.Embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("error embedding document chunks: %w", err)
+		}
+		for i, chunk := range chunks[start:end] {
+			chunk.Vector = vectors[i]
+		}
+	}
+
+	err = uc.KnowledgeGateway.CreateDocument(ctx, doc, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("error saving document: %w", err)
+	}
+
+	records := make([]gateway.VectorRecord, len(chunks))
+	for i, chunk := range chunks {
+		records[i] = chunkRecord(chunk)
+	}
+	err = uc.VectorStore.Upsert(ctx, namespace(uc.Embedder.ModelName()), records)
+	if err != nil {
+		//sem os vetores o documento nunca seria encontrado, desfaz a ingestao
+		if delErr := uc.KnowledgeGateway.DeleteDocument(ctx, doc.ID); delErr != nil {
+			return nil, fmt.Errorf("error indexing document: %w (rollback failed: %s)", err, delErr)
+		}
+		return nil, fmt.Errorf("error indexing document: %w", err)
+	}
+
+	return &IngestDocumentOutputDTO{
+		DocumentID:      doc.ID,
+		KnowledgeBaseID: doc.KnowledgeBaseID,
+		Chunks:          len(chunks),
+		Tokens:          doc.Tokens,
+	}, nil
+}
+
+// namespace no vector store dos chunks de documentos, separado por modelo de embedding
+func namespace(model string) string {
+	return "documents:" + model
+}
+
+func chunkRecord(chunk *entity.DocumentChunk) gateway.VectorRecord {
+	return gateway.VectorRecord{
+		ID:     chunk.ID,
+		Vector: chunk.Vector,
+		Metadata: map[string]string{
+			"knowledge_base_id": chunk.KnowledgeBaseID,
+			"document_id":       chunk.DocumentID,
+		},
+	}
+}
@@ -0,0 +1,153 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+)
+
+const (
+	contextHeader = "Use the following excerpts from the knowledge base when they are relevant to the answer. " +
+		"Cite the excerpts you use by their number, like [1]. If the excerpts do not answer the question, say so."
+	// tokens extras por trecho no bloco de contexto (numero, titulo, separadores)
+	excerptOverhead = 16
+	// candidatos buscados por trecho usado, sobra para os que nao cabem no orcamento
+	candidatesPerExcerpt = 3
+)
+
+// Retriever busca na base de conhecimento os chunks mais proximos da msg do usuario
+type Retriever struct {
+	KnowledgeGateway gateway.KnowledgeGateway
+	VectorStore      gateway.VectorStore
+	Embedder         gateway.EmbeddingProvider
+	KnowledgeBaseID  string // base usada quando a requisicao nao informa uma
+	TopK             int
+	MinScore         float32
+}
+
+func NewRetriever(knowledgeGateway gateway.KnowledgeGateway, vectorStore gateway.VectorStore, embedder gateway.EmbeddingProvider, knowledgeBaseID string, topK int, minScore float32) *Retriever {
+	if knowledgeBaseID == "" {
+		knowledgeBaseID = entity.DefaultKnowledgeBaseID
+	}
+	if topK <= 0 {
+		topK = 4
+	}
+	return &Retriever{
+		KnowledgeGateway: knowledgeGateway,
+		VectorStore:      vectorStore,
+		Embedder:         embedder,
+		KnowledgeBaseID:  knowledgeBaseID,
+		TopK:             topK,
+		MinScore:         minScore,
+	}
+}
+
+type scoredChunk struct {
+	chunk *entity.DocumentChunk
+	score float32
+}
+
+// ContextMessage retorna uma msg de sistema com os trechos que cabem em budget tokens e as citacoes
+// na ordem da numeracao usada na msg, nil quando a base nao tem nada relevante.
+// A msg do usuario passa pelo vault antes de ir ao provedor de embeddings, vault nil envia o texto como esta
+func (r *Retriever) ContextMessage(ctx context.Context, knowledgeBaseID string, model *entity.Model, vault *redact.Vault, query string, budget int) (*entity.Message, []entity.Citation, error) {
+	if budget <= 0 {
+		return nil, nil, nil
+	}
+	if knowledgeBaseID == "" {
+		knowledgeBaseID = r.KnowledgeBaseID
+	}
+
+	queryVectors, err := r.Embedder.Embed(ctx, []string{vault.Redact(query)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error embedding user message: %w", err)
+	}
+
+	matches, err := r.VectorStore.Query(ctx, namespace(r.Embedder.ModelName()), gateway.VectorQuery{
+		Vector:   queryVectors[0],
+		TopK:     r.TopK * candidatesPerExcerpt,
+		MinScore: r.MinScore,
+		Filter:   map[string]string{"knowledge_base_id": knowledgeBaseID},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error querying knowledge base: %w", err)
+	}
+
+	ranked := make([]scoredChunk, 0, len(matches))
+	for _, match := range matches {
+		chunk, err := r.KnowledgeGateway.FindChunkByID(ctx, match.ID)
+		if err != nil {
+			//vetor de documento removido que ainda nao saiu do indice
+			if errors.Is(err, entity.ErrDocumentNotFound) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("error fetching document chunk: %w", err)
+		}
+		ranked = append(ranked, scoredChunk{chunk: chunk, score: match.Score})
+	}
+
+	//pega os mais relevantes que cabem no orcamento de tokens
+	used := excerptOverhead * 2
+	var selected []scoredChunk
+	for _, s := range ranked {
+		if len(selected) == r.TopK {
+			break
+		}
+		cost := s.chunk.Tokens + excerptOverhead
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		selected = append(selected, s)
+	}
+	if len(selected) == 0 {
+		return nil, nil, nil
+	}
+
+	titles := map[string]string{}
+	var b strings.Builder
+	b.WriteString(contextHeader)
+	citations := make([]entity.Citation, 0, len(selected))
+	for i, s := range selected {
+		title, ok := titles[s.chunk.DocumentID]
+		if !ok {
+			doc, err := r.KnowledgeGateway.FindDocumentByID(ctx, s.chunk.DocumentID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error fetching document: %w", err)
+			}
+			title = doc.Title
+			titles[s.chunk.DocumentID] = title
+		}
+
+		b.WriteString("\n\n[")
+		b.WriteString(strconv.Itoa(i + 1))
+		b.WriteString("] ")
+		b.WriteString(title)
+		b.WriteString("\n")
+		b.WriteString(s.chunk.Content)
+
+		citations = append(citations, entity.Citation{
+			DocumentID: s.chunk.DocumentID,
+			Title:      title,
+			ChunkIndex: s.chunk.Index,
+			Start:      s.chunk.Start,
+			End:        s.chunk.End,
+			Score:      s.score,
+		})
+	}
+
+	msg, err := entity.NewMessage("system", b.String(), model)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.GetQTDTokens() > budget {
+		return nil, nil, nil
+	}
+	return msg, citations, nil
+}
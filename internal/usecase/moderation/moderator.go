@@ -0,0 +1,124 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// DefaultReplacement resposta entregue no lugar do texto sinalizado com a acao replace
+const DefaultReplacement = "Sorry, I can't help with that."
+
+// Moderator roda as politicas na msg do usuario, antes do modelo, e na resposta, antes do usuario
+type Moderator struct {
+	InputPolicies  []Policy
+	OutputPolicies []Policy
+	InputAction    string // reject (padrao) ou truncate
+	OutputAction   string // reject (padrao), truncate ou replace
+	Replacement    string
+}
+
+// NewModerator retorna nil quando nao tem nenhuma politica (moderacao desligada)
+func NewModerator(input []Policy, output []Policy, inputAction string, outputAction string, replacement string) (*Moderator, error) {
+	if len(input) == 0 && len(output) == 0 {
+		return nil, nil
+	}
+	if inputAction == "" {
+		inputAction = entity.ModerationReject
+	}
+	if inputAction != entity.ModerationReject && inputAction != entity.ModerationTruncate {
+		return nil, fmt.Errorf("invalid moderation input action %q, use reject or truncate", inputAction)
+	}
+	switch outputAction {
+	case "":
+		outputAction = entity.ModerationReject
+	case entity.ModerationReject, entity.ModerationTruncate, entity.ModerationReplace:
+	default:
+		return nil, fmt.Errorf("invalid moderation output action %q, use reject, truncate or replace", outputAction)
+	}
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	return &Moderator{
+		InputPolicies:  input,
+		OutputPolicies: output,
+		InputAction:    inputAction,
+		OutputAction:   outputAction,
+		Replacement:    replacement,
+	}, nil
+}
+
+// Review texto liberado depois da moderacao e os veredictos das politicas
+type Review struct {
+	Content  string
+	Verdicts []entity.ModerationVerdict
+	Flagged  bool
+	Changed  bool // texto cortado ou trocado, o resto da resposta é descartado
+}
+
+// Input modera a msg do usuario. reject retorna ErrContentRejected e truncate corta antes do trecho sinalizado
+func (m *Moderator) Input(ctx context.Context, text string) (*Review, error) {
+	if m == nil {
+		return &Review{Content: text}, nil
+	}
+	return m.review(ctx, entity.ModerationInput, m.InputPolicies, m.InputAction, text, false)
+}
+
+// Output modera a resposta do modelo, partial roda somente as politicas incrementais no texto parcial do stream
+func (m *Moderator) Output(ctx context.Context, text string, partial bool) (*Review, error) {
+	if m == nil {
+		return &Review{Content: text}, nil
+	}
+	return m.review(ctx, entity.ModerationOutput, m.OutputPolicies, m.OutputAction, text, partial)
+}
+
+func (m *Moderator) review(ctx context.Context, stage string, policies []Policy, action string, text string, partial bool) (*Review, error) {
+	review := &Review{Content: text}
+	cut := len(text)
+	var categories []string
+	for _, policy := range policies {
+		if partial && !policy.Incremental() {
+			continue
+		}
+		result, err := policy.Check(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("error running moderation policy %s: %w", policy.Name(), err)
+		}
+		verdictAction := entity.ModerationAllow
+		if result.Flagged {
+			verdictAction = action
+			review.Flagged = true
+			categories = append(categories, result.Categories...)
+			//posicao desconhecida nao deixa nada para cortar
+			if result.Cut < cut {
+				cut = max(result.Cut, 0)
+			}
+		}
+		review.Verdicts = append(review.Verdicts, verdict(policy, stage, result, verdictAction))
+	}
+	if !review.Flagged {
+		return review, nil
+	}
+
+	if action == entity.ModerationTruncate {
+		review.Content = strings.TrimRightFunc(text[:cut], unicode.IsSpace)
+	}
+	switch {
+	case action == entity.ModerationReject,
+		action == entity.ModerationTruncate && review.Content == "" && stage == entity.ModerationInput:
+		return nil, fmt.Errorf("%w: %s", entity.ErrContentRejected, strings.Join(categories, ", "))
+	case action == entity.ModerationReplace || review.Content == "":
+		//resposta sem nada antes do trecho sinalizado é trocada pela msg padrao
+		review.Content = m.Replacement
+		for i := range review.Verdicts {
+			if review.Verdicts[i].Flagged {
+				review.Verdicts[i].Action = entity.ModerationReplace
+			}
+		}
+	}
+	review.Changed = true
+	return review, nil
+}
@@ -0,0 +1,188 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// fakePolicy politica com resultado fixo, conta as chamadas
+type fakePolicy struct {
+	name        string
+	incremental bool
+	result      *Result
+	err         error
+	calls       int
+}
+
+func (p *fakePolicy) Name() string      { return p.name }
+func (p *fakePolicy) Incremental() bool { return p.incremental }
+
+func (p *fakePolicy) Check(ctx context.Context, text string) (*Result, error) {
+	p.calls++
+	return p.result, p.err
+}
+
+func flagAt(cut int, categories ...string) *Result {
+	return &Result{Flagged: true, Categories: categories, Reason: "flagged", Cut: cut}
+}
+
+func TestModeratorActions(t *testing.T) {
+	const text = "hello there, forbidden words follow"
+	tests := []struct {
+		name     string
+		stage    string
+		action   string
+		result   *Result
+		want     string // conteudo liberado
+		rejected bool
+		changed  bool
+		verdict  string // acao gravada no veredicto
+	}{
+		{"input allowed", entity.ModerationInput, entity.ModerationReject, allowed, text, false, false, entity.ModerationAllow},
+		{"input reject", entity.ModerationInput, entity.ModerationReject, flagAt(13, "hate"), "", true, false, ""},
+		{"input truncate", entity.ModerationInput, entity.ModerationTruncate, flagAt(13, "hate"), "hello there,", false, true, entity.ModerationTruncate},
+		{"input truncate at 0", entity.ModerationInput, entity.ModerationTruncate, flagAt(0, "hate"), "", true, false, ""},
+		{"input truncate at unknown position", entity.ModerationInput, entity.ModerationTruncate, flagAt(-1, "hate"), "", true, false, ""},
+		{"output allowed", entity.ModerationOutput, entity.ModerationReplace, allowed, text, false, false, entity.ModerationAllow},
+		{"output reject", entity.ModerationOutput, entity.ModerationReject, flagAt(13, "violence"), "", true, false, ""},
+		{"output truncate", entity.ModerationOutput, entity.ModerationTruncate, flagAt(6, "violence"), "hello", false, true, entity.ModerationTruncate},
+		{"output truncate at 0", entity.ModerationOutput, entity.ModerationTruncate, flagAt(0, "violence"), "blocked answer", false, true, entity.ModerationReplace},
+		{"output truncate at unknown position", entity.ModerationOutput, entity.ModerationTruncate, flagAt(-1, "violence"), "blocked answer", false, true, entity.ModerationReplace},
+		{"output replace", entity.ModerationOutput, entity.ModerationReplace, flagAt(13, "violence"), "blocked answer", false, true, entity.ModerationReplace},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &fakePolicy{name: "fake", incremental: true, result: tt.result}
+			m := &Moderator{InputAction: tt.action, OutputAction: tt.action, Replacement: "blocked answer"}
+			var review *Review
+			var err error
+			if tt.stage == entity.ModerationInput {
+				m.InputPolicies = []Policy{policy}
+				review, err = m.Input(context.Background(), text)
+			} else {
+				m.OutputPolicies = []Policy{policy}
+				review, err = m.Output(context.Background(), text, false)
+			}
+
+			if tt.rejected {
+				if !errors.Is(err, entity.ErrContentRejected) {
+					t.Fatalf("expected ErrContentRejected, got %v %+v", err, review)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if review.Content != tt.want || review.Changed != tt.changed || review.Flagged != tt.result.Flagged {
+				t.Fatalf("expected %q changed=%v, got %+v", tt.want, tt.changed, review)
+			}
+			if len(review.Verdicts) != 1 {
+				t.Fatalf("expected one verdict, got %v", review.Verdicts)
+			}
+			v := review.Verdicts[0]
+			if v.Policy != "fake" || v.Stage != tt.stage || v.Action != tt.verdict || v.Flagged != tt.result.Flagged {
+				t.Fatalf("unexpected verdict %+v", v)
+			}
+		})
+	}
+}
+
+func TestModeratorRejectReportsCategories(t *testing.T) {
+	m := &Moderator{
+		InputPolicies: []Policy{
+			&fakePolicy{name: "a", result: flagAt(3, "hate")},
+			&fakePolicy{name: "b", result: allowed},
+			&fakePolicy{name: "c", result: flagAt(1, "spam", "scam")},
+		},
+		InputAction: entity.ModerationReject,
+	}
+	_, err := m.Input(context.Background(), "some text")
+	if !errors.Is(err, entity.ErrContentRejected) || err.Error() != "content rejected by moderation: hate, spam, scam" {
+		t.Fatalf("expected the categories of every flagged policy, got %v", err)
+	}
+}
+
+func TestModeratorTruncatesAtTheFirstFlag(t *testing.T) {
+	m := &Moderator{
+		OutputPolicies: []Policy{
+			&fakePolicy{name: "late", incremental: true, result: flagAt(12, "a")},
+			&fakePolicy{name: "early", incremental: true, result: flagAt(6, "b")},
+		},
+		OutputAction: entity.ModerationTruncate,
+		Replacement:  DefaultReplacement,
+	}
+	review, err := m.Output(context.Background(), "first second third", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Content != "first" {
+		t.Fatalf("expected the text before the first flag, got %q", review.Content)
+	}
+}
+
+func TestModeratorPartialRunsOnlyIncrementalPolicies(t *testing.T) {
+	incremental := &fakePolicy{name: "blocklist", incremental: true, result: allowed}
+	provider := &fakePolicy{name: "provider", result: flagAt(-1, "violence")}
+	m := &Moderator{
+		OutputPolicies: []Policy{incremental, provider},
+		OutputAction:   entity.ModerationReplace,
+		Replacement:    DefaultReplacement,
+	}
+
+	review, err := m.Output(context.Background(), "partial answer", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 0 || incremental.calls != 1 {
+		t.Fatalf("expected only the incremental policy on partial text, got %d and %d calls", incremental.calls, provider.calls)
+	}
+	if review.Flagged || review.Content != "partial answer" || len(review.Verdicts) != 1 {
+		t.Fatalf("expected the partial text allowed, got %+v", review)
+	}
+
+	//no texto completo todas as politicas rodam
+	review, err = m.Output(context.Background(), "full answer", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.calls != 1 || !review.Flagged || review.Content != DefaultReplacement || len(review.Verdicts) != 2 {
+		t.Fatalf("expected the full text replaced, got %+v", review)
+	}
+}
+
+func TestModeratorPolicyError(t *testing.T) {
+	m := &Moderator{InputPolicies: []Policy{&fakePolicy{name: "provider", err: errors.New("timeout")}}, InputAction: entity.ModerationReject}
+	if _, err := m.Input(context.Background(), "text"); err == nil || errors.Is(err, entity.ErrContentRejected) {
+		t.Fatalf("expected the policy error, got %v", err)
+	}
+}
+
+func TestNewModerator(t *testing.T) {
+	policy := &fakePolicy{name: "fake", result: allowed}
+	m, err := NewModerator([]Policy{policy}, nil, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.InputAction != entity.ModerationReject || m.OutputAction != entity.ModerationReject || m.Replacement != DefaultReplacement {
+		t.Fatalf("expected the defaults, got %+v", m)
+	}
+	if _, err := NewModerator([]Policy{policy}, nil, entity.ModerationReplace, "", ""); err == nil {
+		t.Fatal("expected replace to be invalid for the input")
+	}
+	if _, err := NewModerator(nil, []Policy{policy}, "", "drop", ""); err == nil {
+		t.Fatal("expected an invalid output action")
+	}
+
+	//sem politicas a moderacao fica desligada e o moderator nil libera o texto
+	m, err = NewModerator(nil, nil, "", "", "")
+	if err != nil || m != nil {
+		t.Fatalf("expected a nil moderator, got %v %v", m, err)
+	}
+	review, err := m.Output(context.Background(), "text", false)
+	if err != nil || review.Content != "text" || review.Flagged {
+		t.Fatalf("expected the text allowed, got %+v %v", review, err)
+	}
+}
@@ -0,0 +1,178 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// Result resultado de uma politica, Cut é a posicao (bytes) do primeiro trecho sinalizado, -1 quando desconhecida
+type Result struct {
+	Flagged    bool
+	Categories []string
+	Reason     string
+	Cut        int
+}
+
+// Policy regra de moderacao aplicada na msg do usuario e na resposta.
+// Incremental indica que a politica é barata e roda a cada pedaco do stream,
+// as demais rodam somente no texto completo
+type Policy interface {
+	Name() string
+	Incremental() bool
+	Check(ctx context.Context, text string) (*Result, error)
+}
+
+var allowed = &Result{Cut: -1}
+
+// Blocklist palavras (sem diferenciar maiusculas, palavra inteira) e regex proibidas
+type Blocklist struct {
+	patterns []*regexp.Regexp
+}
+
+// BlocklistConfig arquivo json da blocklist
+type BlocklistConfig struct {
+	Words    []string `json:"words"`
+	Patterns []string `json:"patterns"`
+}
+
+// LoadBlocklist le o json com as palavras e regex, arquivo vazio retorna nil (sem blocklist)
+func LoadBlocklist(path string) (*Blocklist, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading moderation blocklist file: %w", err)
+	}
+	var config BlocklistConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("error parsing moderation blocklist file: %w", err)
+	}
+	return NewBlocklist(config.Words, config.Patterns)
+}
+
+func NewBlocklist(words []string, patterns []string) (*Blocklist, error) {
+	blocklist := &Blocklist{}
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		blocklist.patterns = append(blocklist.patterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(word)+`\b`))
+	}
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation blocklist pattern %q: %w", pattern, err)
+		}
+		blocklist.patterns = append(blocklist.patterns, regex)
+	}
+	return blocklist, nil
+}
+
+func (b *Blocklist) Name() string {
+	return "blocklist"
+}
+
+func (b *Blocklist) Incremental() bool {
+	return true
+}
+
+// Check sinaliza o trecho proibido que aparece primeiro no texto
+func (b *Blocklist) Check(ctx context.Context, text string) (*Result, error) {
+	first := -1
+	for _, regex := range b.patterns {
+		if loc := regex.FindStringIndex(text); loc != nil && (first < 0 || loc[0] < first) {
+			first = loc[0]
+		}
+	}
+	if first < 0 {
+		return allowed, nil
+	}
+	return &Result{Flagged: true, Categories: []string{"blocklist"}, Reason: "text contains blocked terms", Cut: first}, nil
+}
+
+// MaxLength tamanho maximo do texto em caracteres
+type MaxLength struct {
+	max int
+}
+
+// NewMaxLength retorna nil quando max <= 0 (sem limite)
+func NewMaxLength(max int) *MaxLength {
+	if max <= 0 {
+		return nil
+	}
+	return &MaxLength{max: max}
+}
+
+func (m *MaxLength) Name() string {
+	return "max_length"
+}
+
+func (m *MaxLength) Incremental() bool {
+	return true
+}
+
+func (m *MaxLength) Check(ctx context.Context, text string) (*Result, error) {
+	if utf8.RuneCountInString(text) <= m.max {
+		return allowed, nil
+	}
+	//posicao em bytes do primeiro caractere acima do limite
+	cut, count := 0, 0
+	for i := range text {
+		if count == m.max {
+			cut = i
+			break
+		}
+		count++
+	}
+	return &Result{Flagged: true, Categories: []string{"length"}, Reason: fmt.Sprintf("text exceeds %d characters", m.max), Cut: cut}, nil
+}
+
+// Provider politica que consulta o provedor de moderacao, roda somente no texto completo
+type Provider struct {
+	provider gateway.ModerationProvider
+}
+
+func NewProvider(provider gateway.ModerationProvider) *Provider {
+	return &Provider{provider: provider}
+}
+
+func (p *Provider) Name() string {
+	return "provider"
+}
+
+func (p *Provider) Incremental() bool {
+	return false
+}
+
+func (p *Provider) Check(ctx context.Context, text string) (*Result, error) {
+	result, err := p.provider.Moderate(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Flagged {
+		return allowed, nil
+	}
+	return &Result{Flagged: true, Categories: result.Categories, Reason: "flagged by the moderation provider", Cut: -1}, nil
+}
+
+// verdict resultado da politica no formato salvo na msg
+func verdict(policy Policy, stage string, result *Result, action string) entity.ModerationVerdict {
+	return entity.ModerationVerdict{
+		Policy:     policy.Name(),
+		Stage:      stage,
+		Flagged:    result.Flagged,
+		Categories: result.Categories,
+		Reason:     result.Reason,
+		Action:     action,
+	}
+}
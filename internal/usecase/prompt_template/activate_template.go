@@ -0,0 +1,40 @@
+package prompttemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ActivatePromptTemplateInputDTO struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+type ActivatePromptTemplateUseCase struct {
+	TemplateGateway gateway.PromptTemplateGateway
+}
+
+func NewActivatePromptTemplateUseCase(templateGateway gateway.PromptTemplateGateway) *ActivatePromptTemplateUseCase {
+	return &ActivatePromptTemplateUseCase{
+		TemplateGateway: templateGateway,
+	}
+}
+
+// Execute troca a versao usada pelos novos chats, os chats existentes mantem a versao com que foram criados
+func (uc *ActivatePromptTemplateUseCase) Execute(ctx context.Context, input ActivatePromptTemplateInputDTO) (*PromptTemplateOutputDTO, error) {
+	if input.Version <= 0 {
+		return nil, fmt.Errorf("%w: version must be greater than 0", entity.ErrInvalidPromptTemplate)
+	}
+	if err := uc.TemplateGateway.ActivatePromptTemplate(ctx, input.Name, input.Version); err != nil {
+		return nil, fmt.Errorf("error activating prompt template: %w", err)
+	}
+	template, err := uc.TemplateGateway.FindPromptTemplate(ctx, input.Name, input.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching prompt template: %w", err)
+	}
+	output := templateOutput(template)
+	return &output, nil
+}
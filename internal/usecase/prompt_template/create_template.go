@@ -0,0 +1,71 @@
+package prompttemplate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type CreatePromptTemplateInputDTO struct {
+	Name        string            `json:"name"`
+	Content     string            `json:"content"`
+	Description string            `json:"description,omitempty"`
+	Defaults    map[string]string `json:"defaults,omitempty"` //valores das variaveis nao informadas na requisicao
+	Draft       bool              `json:"draft,omitempty"`    //grava a versao sem torna-la a ativa
+}
+
+type PromptTemplateOutputDTO struct {
+	Name        string            `json:"name"`
+	Version     int               `json:"version"`
+	Content     string            `json:"content"`
+	Description string            `json:"description"`
+	Defaults    map[string]string `json:"defaults"`
+	Variables   []string          `json:"variables"`
+	Active      bool              `json:"active"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type CreatePromptTemplateUseCase struct {
+	TemplateGateway gateway.PromptTemplateGateway
+}
+
+func NewCreatePromptTemplateUseCase(templateGateway gateway.PromptTemplateGateway) *CreatePromptTemplateUseCase {
+	return &CreatePromptTemplateUseCase{
+		TemplateGateway: templateGateway,
+	}
+}
+
+// Execute cria a proxima versao do template, o nome novo comeca na versao 1
+func (uc *CreatePromptTemplateUseCase) Execute(ctx context.Context, input CreatePromptTemplateInputDTO) (*PromptTemplateOutputDTO, error) {
+	template, err := entity.NewPromptTemplate(input.Name, input.Content, input.Description, input.Defaults)
+	if err != nil {
+		return nil, err
+	}
+	template.Active = !input.Draft
+
+	if err := uc.TemplateGateway.CreatePromptTemplate(ctx, template); err != nil {
+		return nil, fmt.Errorf("error saving prompt template: %w", err)
+	}
+	output := templateOutput(template)
+	return &output, nil
+}
+
+func templateOutput(template *entity.PromptTemplate) PromptTemplateOutputDTO {
+	variables := template.Variables()
+	if variables == nil {
+		variables = []string{}
+	}
+	return PromptTemplateOutputDTO{
+		Name:        template.Name,
+		Version:     template.Version,
+		Content:     template.Content,
+		Description: template.Description,
+		Defaults:    template.Defaults,
+		Variables:   variables,
+		Active:      template.Active,
+		CreatedAt:   template.CreatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package prompttemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type DeletePromptTemplateInputDTO struct {
+	Name string `json:"name"`
+}
+
+type DeletePromptTemplateUseCase struct {
+	TemplateGateway gateway.PromptTemplateGateway
+}
+
+func NewDeletePromptTemplateUseCase(templateGateway gateway.PromptTemplateGateway) *DeletePromptTemplateUseCase {
+	return &DeletePromptTemplateUseCase{
+		TemplateGateway: templateGateway,
+	}
+}
+
+// Execute remove todas as versoes do template, template inexistente retorna ErrPromptTemplateNotFound
+func (uc *DeletePromptTemplateUseCase) Execute(ctx context.Context, input DeletePromptTemplateInputDTO) error {
+	if err := uc.TemplateGateway.DeletePromptTemplate(ctx, input.Name); err != nil {
+		return fmt.Errorf("error deleting prompt template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package prompttemplate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListPromptTemplatesInputDTO struct {
+	Name string `json:"name,omitempty"` //vazio lista a versao ativa de cada template, senao todas as versoes do template
+}
+
+type ListPromptTemplatesOutputDTO struct {
+	Templates []PromptTemplateOutputDTO `json:"templates"`
+}
+
+type ListPromptTemplatesUseCase struct {
+	TemplateGateway gateway.PromptTemplateGateway
+}
+
+func NewListPromptTemplatesUseCase(templateGateway gateway.PromptTemplateGateway) *ListPromptTemplatesUseCase {
+	return &ListPromptTemplatesUseCase{
+		TemplateGateway: templateGateway,
+	}
+}
+
+func (uc *ListPromptTemplatesUseCase) Execute(ctx context.Context, input ListPromptTemplatesInputDTO) (*ListPromptTemplatesOutputDTO, error) {
+	var templates []*entity.PromptTemplate
+	var err error
+	if input.Name == "" {
+		templates, err = uc.TemplateGateway.ListPromptTemplates(ctx)
+	} else {
+		templates, err = uc.TemplateGateway.ListPromptTemplateVersions(ctx, input.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing prompt templates: %w", err)
+	}
+
+	output := &ListPromptTemplatesOutputDTO{Templates: []PromptTemplateOutputDTO{}}
+	for _, template := range templates {
+		output.Templates = append(output.Templates, templateOutput(template))
+	}
+	return output, nil
+}
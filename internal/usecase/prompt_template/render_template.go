@@ -0,0 +1,60 @@
+package prompttemplate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type RenderPromptTemplateInputDTO struct {
+	Name      string
+	Version   int // 0 usa a versao ativa
+	UserID    string
+	Variables map[string]string
+}
+
+type RenderPromptTemplateOutputDTO struct {
+	Name    string
+	Version int
+	Content string
+}
+
+type RenderPromptTemplateUseCase struct {
+	TemplateGateway gateway.PromptTemplateGateway
+}
+
+func NewRenderPromptTemplateUseCase(templateGateway gateway.PromptTemplateGateway) *RenderPromptTemplateUseCase {
+	return &RenderPromptTemplateUseCase{
+		TemplateGateway: templateGateway,
+	}
+}
+
+// Execute monta a msg inicial do chat. Alem das variaveis da requisicao (user_name, locale, campos proprios)
+// user_id, date e datetime sao preenchidas pelo servico e nao podem ser sobrescritas
+func (uc *RenderPromptTemplateUseCase) Execute(ctx context.Context, input RenderPromptTemplateInputDTO) (*RenderPromptTemplateOutputDTO, error) {
+	template, err := uc.TemplateGateway.FindPromptTemplate(ctx, input.Name, input.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching prompt template %s: %w", input.Name, err)
+	}
+
+	now := time.Now().UTC()
+	values := make(map[string]string, len(input.Variables)+3)
+	for k, v := range input.Variables {
+		values[k] = v
+	}
+	values["user_id"] = input.UserID
+	values["date"] = now.Format("2006-01-02")
+	values["datetime"] = now.Format(time.RFC3339)
+
+	content, err := template.Render(values)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderPromptTemplateOutputDTO{
+		Name:    template.Name,
+		Version: template.Version,
+		Content: content,
+	}, nil
+}
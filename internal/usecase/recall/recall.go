@@ -0,0 +1,185 @@
+package recall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+)
+
+const (
+	contextHeader = "Relevant earlier messages from this conversation, no longer in the recent history:"
+	// tokens extras por msg no bloco de contexto (role, separadores)
+	messageOverhead = 8
+	// maximo de msgs apagadas sem embedding que sao indexadas por turno
+	maxBackfill = 64
+)
+
+// Recaller busca, entre as msgs apagadas do contexto, as mais relevantes para a msg atual do usuario
+type Recaller struct {
+	Embedder   gateway.EmbeddingProvider
+	Embeddings gateway.MessageEmbeddingGateway
+	TopK       int
+	MinScore   float32
+}
+
+func NewRecaller(embedder gateway.EmbeddingProvider, embeddings gateway.MessageEmbeddingGateway, topK int, minScore float32) *Recaller {
+	if topK <= 0 {
+		topK = 5
+	}
+	return &Recaller{
+		Embedder:   embedder,
+		Embeddings: embeddings,
+		TopK:       topK,
+		MinScore:   minScore,
+	}
+}
+
+type scored struct {
+	message *entity.Message
+	order   int
+	score   float32
+}
+
+// ContextMessage retorna uma msg de sistema com as msgs relembradas que cabem em budget tokens,
+// nil quando nao ha nada relevante ou nao ha espaco. A msg atual e as apagadas passam pelo vault
+// antes de ir ao provedor de embeddings, vault nil envia o texto como esta
+func (r *Recaller) ContextMessage(ctx context.Context, chat *entity.Chat, vault *redact.Vault, query string, budget int) (*entity.Message, error) {
+	candidates := recallable(chat)
+	if len(candidates) == 0 || budget <= 0 {
+		return nil, nil
+	}
+
+	vectors, err := r.vectors(ctx, chat, vault, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors, err := r.Embedder.Embed(ctx, []string{vault.Redact(query)})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding user message: %w", err)
+	}
+
+	var ranked []scored
+	for i, msg := range candidates {
+		vector, ok := vectors[msg.ID]
+		if !ok {
+			continue
+		}
+		score := entity.CosineSimilarity(queryVectors[0], vector)
+		if score < r.MinScore {
+			continue
+		}
+		ranked = append(ranked, scored{message: msg, order: i, score: score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	//pega as mais relevantes que cabem no orcamento de tokens
+	used := messageOverhead
+	var selected []scored
+	for _, s := range ranked {
+		if len(selected) == r.TopK {
+			break
+		}
+		cost := s.message.GetQTDTokens() + messageOverhead
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		selected = append(selected, s)
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	//volta para a ordem da conversa
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].order < selected[j].order
+	})
+
+	var b strings.Builder
+	b.WriteString(contextHeader)
+	for _, s := range selected {
+		b.WriteString("\n\n[")
+		b.WriteString(s.message.Role)
+		b.WriteString("]: ")
+		b.WriteString(s.message.Content)
+	}
+
+	msg, err := entity.NewMessage("system", b.String(), chat.Config.Model)
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetQTDTokens() > budget {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// recallable msgs apagadas de usuario e assistente, a msg inicial de sistema e os pedidos de ferramenta sem texto nao entram
+func recallable(chat *entity.Chat) []*entity.Message {
+	var messages []*entity.Message
+	for _, msg := range chat.ErasedMessages {
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// vectors carrega os embeddings das msgs e gera os que ainda nao existem
+func (r *Recaller) vectors(ctx context.Context, chat *entity.Chat, vault *redact.Vault, messages []*entity.Message) (map[string][]float32, error) {
+	stored, err := r.Embeddings.FindMessageEmbeddingsByChatID(ctx, chat.ID, r.Embedder.ModelName())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching message embeddings: %w", err)
+	}
+	vectors := make(map[string][]float32, len(stored))
+	for _, e := range stored {
+		vectors[e.MessageID] = e.Vector
+	}
+
+	var missing []*entity.Message
+	for i := len(messages) - 1; i >= 0 && len(missing) < maxBackfill; i-- {
+		if _, ok := vectors[messages[i].ID]; !ok {
+			missing = append(missing, messages[i])
+		}
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	texts := make([]string, len(missing))
+	for i, msg := range missing {
+		texts[i] = vault.Redact(msg.Content)
+	}
+	embedded, err := r.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("error embedding messages: %w", err)
+	}
+
+	embeddings := make([]*entity.MessageEmbedding, len(missing))
+	for i, msg := range missing {
+		vectors[msg.ID] = embedded[i]
+		embeddings[i] = &entity.MessageEmbedding{
+			MessageID: msg.ID,
+			ChatID:    chat.ID,
+			Model:     r.Embedder.ModelName(),
+			Vector:    embedded[i],
+		}
+	}
+	if err := r.Embeddings.SaveMessageEmbeddings(ctx, embeddings); err != nil {
+		return nil, fmt.Errorf("error saving message embeddings: %w", err)
+	}
+	return vectors, nil
+}
+
+// Budget tokens livres no contexto depois das msgs do chat e da resposta esperada
+func Budget(chat *entity.Chat) int {
+	return chat.Config.Model.GetMaxToken() - chat.TokenUsage - chat.Config.MaxTokens
+}
@@ -0,0 +1,67 @@
+package recall
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/redact"
+)
+
+// recordingEmbedder guarda os textos recebidos, no lugar do provedor de embeddings
+type recordingEmbedder struct {
+	texts []string
+}
+
+func (e *recordingEmbedder) ModelName() string { return "test" }
+
+func (e *recordingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.texts = append(e.texts, texts...)
+	res := make([][]float32, len(texts))
+	for i := range texts {
+		res[i] = []float32{1, 0}
+	}
+	return res, nil
+}
+
+type memoryEmbeddings struct{}
+
+func (memoryEmbeddings) SaveMessageEmbeddings(ctx context.Context, embeddings []*entity.MessageEmbedding) error {
+	return nil
+}
+
+func (memoryEmbeddings) FindMessageEmbeddingsByChatID(ctx context.Context, chatID string, model string) ([]*entity.MessageEmbedding, error) {
+	return nil, nil
+}
+
+func TestContextMessageRedactsBeforeEmbedding(t *testing.T) {
+	redactor, err := redact.NewRedactor([]string{redact.DetectorEmail}, nil, "test-key", redact.StoreRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedder := &recordingEmbedder{}
+	//MinScore acima do maximo: nada é selecionado, o teste olha somente o que foi ao provedor
+	recaller := NewRecaller(embedder, memoryEmbeddings{}, 5, 2)
+	chat := &entity.Chat{
+		ID:     "chat-1",
+		Config: &entity.ChatConfig{Model: entity.NewModel("gpt-3.5-turbo", 4096)},
+		ErasedMessages: []*entity.Message{
+			{ID: "m1", Role: "user", Content: "my email is alice@example.com", Tokens: 8},
+			{ID: "m2", Role: "assistant", Content: "noted", Tokens: 2},
+		},
+	}
+
+	_, err = recaller.ContextMessage(context.Background(), chat, redactor.NewVault(), "write to alice@example.com", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embedder.texts) != 3 {
+		t.Fatalf("expected the 2 erased messages and the query to be embedded, got %v", embedder.texts)
+	}
+	for _, text := range embedder.texts {
+		if strings.Contains(text, "alice@example.com") {
+			t.Fatalf("personal data sent to the embeddings provider: %q", text)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package redact
+
+import "regexp"
+
+// detectores builtin
+const (
+	DetectorEmail = "email"
+	DetectorPhone = "phone"
+	DetectorCard  = "card"
+	DetectorCPF   = "cpf"
+	DetectorCNPJ  = "cnpj"
+)
+
+// builtinOrder ordem de aplicacao, os numeros com digito verificador antes do telefone
+var builtinOrder = []string{DetectorEmail, DetectorCNPJ, DetectorCPF, DetectorCard, DetectorPhone}
+
+var builtinRules = map[string]rule{
+	DetectorEmail: {
+		name:  "EMAIL",
+		regex: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	},
+	DetectorCNPJ: {
+		name:  "CNPJ",
+		regex: regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`),
+		valid: validCNPJ,
+	},
+	DetectorCPF: {
+		name:  "CPF",
+		regex: regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`),
+		valid: validCPF,
+	},
+	DetectorCard: {
+		name:  "CARD",
+		regex: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid: validCard,
+	},
+	DetectorPhone: {
+		name: "PHONE",
+		//+55 (11) 91234-5678, 11 91234 5678, (11) 3456-7890, 91234-5678, +1 415 555 0100
+		regex: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,3}\)|\b\d{2,3})[ .-]?\d{3,5}[ .-]?\d{4}\b|\b\d{4,5}-\d{4}\b`),
+	},
+}
+
+func digits(s string) []int {
+	var res []int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			res = append(res, int(c-'0'))
+		}
+	}
+	return res
+}
+
+func allEqual(d []int) bool {
+	for _, n := range d {
+		if n != d[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// validCard numero de cartao com 13 a 19 digitos e digito de luhn valido
+func validCard(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 || allEqual(d) {
+		return false
+	}
+	sum := 0
+	for i := range d {
+		n := d[len(d)-1-i]
+		if i%2 == 1 {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+// checkDigit digito verificador modulo 11 do cpf e do cnpj
+func checkDigit(d []int, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+	return 0
+}
+
+func validCPF(s string) bool {
+	d := digits(s)
+	if len(d) != 11 || allEqual(d) {
+		return false
+	}
+	return checkDigit(d, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[9] &&
+		checkDigit(d, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[10]
+}
+
+func validCNPJ(s string) bool {
+	d := digits(s)
+	if len(d) != 14 || allEqual(d) {
+		return false
+	}
+	return checkDigit(d, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[12] &&
+		checkDigit(d, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[13]
+}
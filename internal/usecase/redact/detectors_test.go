@@ -0,0 +1,68 @@
+package redact
+
+import "testing"
+
+func TestCheckDigits(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid func(string) bool
+		value string
+		want  bool
+	}{
+		{"cpf formatted", validCPF, "529.982.247-25", true},
+		{"cpf bare", validCPF, "52998224725", true},
+		{"cpf wrong first digit", validCPF, "529.982.247-15", false},
+		{"cpf wrong second digit", validCPF, "52998224726", false},
+		{"cpf repeated digits", validCPF, "111.111.111-11", false},
+		{"cpf short", validCPF, "5299822472", false},
+		{"cnpj formatted", validCNPJ, "11.222.333/0001-81", true},
+		{"cnpj bare", validCNPJ, "11222333000181", true},
+		{"cnpj wrong first digit", validCNPJ, "11.222.333/0001-91", false},
+		{"cnpj wrong second digit", validCNPJ, "11222333000182", false},
+		{"cnpj repeated digits", validCNPJ, "00.000.000/0000-00", false},
+		{"card bare", validCard, "4111111111111111", true},
+		{"card with spaces", validCard, "4111 1111 1111 1111", true},
+		{"card with dashes", validCard, "5500-0000-0000-0004", true},
+		{"card 13 digits", validCard, "4222222222222", true},
+		{"card wrong luhn", validCard, "4111 1111 1111 1112", false},
+		{"card repeated digits", validCard, "0000 0000 0000 0000", false},
+		{"card too short", validCard, "411111111111", false},
+		{"card too long", validCard, "41111111111111111111", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.valid(tt.value); got != tt.want {
+				t.Fatalf("%s: expected %v, got %v", tt.value, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuiltinRegexes(t *testing.T) {
+	tests := []struct {
+		detector string
+		text     string
+		want     string // trecho encontrado, vazio quando nao deve encontrar nada
+	}{
+		{DetectorEmail, "write to ana.silva+news@mail.example.com.br today", "ana.silva+news@mail.example.com.br"},
+		{DetectorEmail, "my handle is @ana on the forum", ""},
+		{DetectorEmail, "ana@localhost is not a public address", ""},
+		{DetectorPhone, "call +55 (11) 91234-5678 now", "+55 (11) 91234-5678"},
+		{DetectorPhone, "call 11 91234 5678 now", "11 91234 5678"},
+		{DetectorPhone, "office (11) 3456-7890", "(11) 3456-7890"},
+		{DetectorPhone, "mobile 91234-5678", "91234-5678"},
+		{DetectorPhone, "us +1 415 555 0100", "+1 415 555 0100"},
+		{DetectorPhone, "order 12345 shipped", ""},
+		{DetectorCPF, "cpf 529.982.247-25.", "529.982.247-25"},
+		{DetectorCNPJ, "cnpj 11.222.333/0001-81.", "11.222.333/0001-81"},
+		{DetectorCard, "card 4111-1111-1111-1111 exp", "4111-1111-1111-1111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.detector+" "+tt.text, func(t *testing.T) {
+			got := builtinRules[tt.detector].regex.FindString(tt.text)
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// politicas de persistencia das msgs
+const (
+	StoreRaw      = "raw"      // salva o texto original, a redacao vale somente para o provedor
+	StoreRedacted = "redacted" // salva o texto com os placeholders
+)
+
+// placeholder [TIPO_xxxxxxxx], o sufixo é o hmac do valor: o mesmo valor tem o mesmo placeholder em todas as msgs
+var placeholderRegex = regexp.MustCompile(`\[[A-Z][A-Z0-9_]*_[0-9a-f]{8}\]`)
+
+// maxPlaceholderLen tamanho maximo de um placeholder, usado para segurar o fim do stream
+const maxPlaceholderLen = 64
+
+// Pattern regex customizada, Name vira o tipo do placeholder
+type Pattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+type rule struct {
+	name  string
+	regex *regexp.Regexp
+	valid func(string) bool // opcional, descarta os falsos positivos (digitos verificadores)
+}
+
+// Redactor troca dados pessoais por placeholders antes do texto sair do servico
+type Redactor struct {
+	rules []rule
+	key   []byte
+	store string
+}
+
+// NewRedactor detectors sao os detectores builtin (email, phone, card, cpf, cnpj) e patterns as regex customizadas,
+// que tem prioridade. Retorna nil quando nao tem nenhuma regra (redacao desligada).
+// key assina os placeholders, vazia gera uma chave aleatoria valida ate o servico reiniciar
+func NewRedactor(detectors []string, patterns []Pattern, key string, store string) (*Redactor, error) {
+	if len(detectors) == 0 && len(patterns) == 0 {
+		return nil, nil
+	}
+	switch store {
+	case "":
+		store = StoreRaw
+	case StoreRaw, StoreRedacted:
+	default:
+		return nil, fmt.Errorf("invalid redaction store policy %q, use raw or redacted", store)
+	}
+
+	r := &Redactor{store: store, key: []byte(key)}
+	if key == "" {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			return nil, fmt.Errorf("error generating redaction key: %w", err)
+		}
+	}
+	for _, p := range patterns {
+		name := placeholderName(p.Name)
+		if name == "" {
+			return nil, fmt.Errorf("redaction pattern %q has an invalid name", p.Name)
+		}
+		regex, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", p.Name, err)
+		}
+		r.rules = append(r.rules, rule{name: name, regex: regex})
+	}
+	enabled := map[string]bool{}
+	for _, detector := range detectors {
+		if _, ok := builtinRules[detector]; !ok {
+			return nil, fmt.Errorf("unknown redaction detector %q", detector)
+		}
+		enabled[detector] = true
+	}
+	//detectores mais especificos primeiro, telefone casa com qualquer sequencia de digitos
+	for _, detector := range builtinOrder {
+		if enabled[detector] {
+			r.rules = append(r.rules, builtinRules[detector])
+		}
+	}
+	return r, nil
+}
+
+// LoadPatterns le o json com a lista de regex customizadas, arquivo vazio nao tem regex
+func LoadPatterns(path string) ([]Pattern, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading redaction patterns file: %w", err)
+	}
+	var patterns []Pattern
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return nil, fmt.Errorf("error parsing redaction patterns file: %w", err)
+	}
+	return patterns, nil
+}
+
+// placeholderName nome do tipo em maiusculas, somente letras, digitos e _
+func placeholderName(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return ""
+	}
+	for _, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '_' {
+			return ""
+		}
+	}
+	return name
+}
+
+// NewVault guarda os valores trocados durante uma requisicao, para devolver o texto original ao usuario.
+// Redactor nil retorna um vault nil, que nao altera nada
+func (r *Redactor) NewVault() *Vault {
+	if r == nil {
+		return nil
+	}
+	return &Vault{redactor: r, values: map[string]string{}}
+}
+
+func (r *Redactor) placeholder(name string, value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(name + ":" + value))
+	return "[" + name + "_" + hex.EncodeToString(mac.Sum(nil)[:4]) + "]"
+}
+
+type match struct {
+	start, end int
+	name       string
+}
+
+// Vault placeholders e valores originais de uma requisicao
+type Vault struct {
+	redactor *Redactor
+	values   map[string]string
+}
+
+// Redact troca os dados pessoais do texto pelos placeholders. Texto ja redigido nao muda
+func (v *Vault) Redact(text string) string {
+	if v == nil || text == "" {
+		return text
+	}
+	//placeholders ja presentes ficam como estao, as regex customizadas podem casar com o sufixo
+	var matches []match
+	for _, loc := range placeholderRegex.FindAllStringIndex(text, -1) {
+		matches = append(matches, match{start: loc[0], end: loc[1]})
+	}
+	for _, rule := range v.redactor.rules {
+		for _, loc := range rule.regex.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] || overlaps(matches, loc[0], loc[1]) {
+				continue
+			}
+			if rule.valid != nil && !rule.valid(text[loc[0]:loc[1]]) {
+				continue
+			}
+			matches = append(matches, match{start: loc[0], end: loc[1], name: rule.name})
+		}
+	}
+	if len(matches) == 0 {
+		return text
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var res strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m.name == "" {
+			continue
+		}
+		value := text[m.start:m.end]
+		placeholder := v.redactor.placeholder(m.name, value)
+		v.values[placeholder] = value
+		res.WriteString(text[last:m.start])
+		res.WriteString(placeholder)
+		last = m.end
+	}
+	res.WriteString(text[last:])
+	return res.String()
+}
+
+func overlaps(matches []match, start, end int) bool {
+	for _, m := range matches {
+		if start < m.end && m.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore devolve os valores originais dos placeholders conhecidos, os demais ficam como estao
+func (v *Vault) Restore(text string) string {
+	if v == nil || len(v.values) == 0 {
+		return text
+	}
+	return placeholderRegex.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := v.values[placeholder]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// RestorePartial Restore do texto parcial do stream, segura um placeholder que ainda nao chegou inteiro
+func (v *Vault) RestorePartial(text string) string {
+	if v == nil || len(v.values) == 0 {
+		return text
+	}
+	if i := strings.LastIndexByte(text, '['); i >= 0 && len(text)-i < maxPlaceholderLen && !strings.ContainsRune(text[i:], ']') {
+		text = text[:i]
+	}
+	return v.Restore(text)
+}
+
+// RestoreJSON Restore dentro das strings do json, os valores sao escapados
+func (v *Vault) RestoreJSON(raw json.RawMessage) json.RawMessage {
+	if v == nil || len(v.values) == 0 || len(raw) == 0 {
+		return raw
+	}
+	return json.RawMessage(placeholderRegex.ReplaceAllStringFunc(string(raw), func(placeholder string) string {
+		value, ok := v.values[placeholder]
+		if !ok {
+			return placeholder
+		}
+		escaped, _ := json.Marshal(value)
+		return string(escaped[1 : len(escaped)-1])
+	}))
+}
+
+// Store texto a salvar no chat conforme a politica: redigido, ou original com os placeholders restaurados
+func (v *Vault) Store(text string) string {
+	if v == nil {
+		return text
+	}
+	if v.redactor.store == StoreRedacted {
+		return v.Redact(text)
+	}
+	return v.Restore(text)
+}
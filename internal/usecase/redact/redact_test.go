@@ -0,0 +1,233 @@
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var allDetectors = []string{DetectorEmail, DetectorPhone, DetectorCard, DetectorCPF, DetectorCNPJ}
+
+func newTestRedactor(t *testing.T, patterns []Pattern, store string) *Redactor {
+	t.Helper()
+	r, err := NewRedactor(allDetectors, patterns, "test-key", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRedactReplacesPersonalData(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		value string
+		kind  string // tipo do placeholder, vazio quando o texto nao muda
+	}{
+		{"email", "mail me at ana@example.com please", "ana@example.com", "EMAIL"},
+		{"phone", "my number is (11) 91234-5678", "(11) 91234-5678", "PHONE"},
+		{"card", "pay with 4111 1111 1111 1111 now", "4111 1111 1111 1111", "CARD"},
+		{"cpf formatted", "cpf 529.982.247-25 ok", "529.982.247-25", "CPF"},
+		{"cpf bare", "cpf 52998224725 ok", "52998224725", "CPF"},
+		{"cnpj formatted", "cnpj 11.222.333/0001-81 ok", "11.222.333/0001-81", "CNPJ"},
+		{"cnpj bare", "cnpj 11222333000181 ok", "11222333000181", "CNPJ"},
+		{"invalid cpf", "cpf 529.982.247-26 ok", "", ""},
+		{"invalid card", "ref 4111 1111 1111 1112 ok", "", ""},
+		{"no personal data", "what is the refund policy?", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newTestRedactor(t, nil, "").NewVault()
+			redacted := vault.Redact(tt.text)
+			if tt.kind == "" {
+				if redacted != tt.text {
+					t.Fatalf("expected %q unchanged, got %q", tt.text, redacted)
+				}
+				return
+			}
+			if strings.Contains(redacted, tt.value) {
+				t.Fatalf("expected %q to be redacted, got %q", tt.value, redacted)
+			}
+			placeholder := regexp.MustCompile(`\[` + tt.kind + `_[0-9a-f]{8}\]`).FindString(redacted)
+			if placeholder == "" || strings.Replace(tt.text, tt.value, placeholder, 1) != redacted {
+				t.Fatalf("expected a %s placeholder in place of %q, got %q", tt.kind, tt.value, redacted)
+			}
+			if restored := vault.Restore(redacted); restored != tt.text {
+				t.Fatalf("expected %q restored, got %q", tt.text, restored)
+			}
+		})
+	}
+}
+
+func TestRedactPlaceholdersAreStable(t *testing.T) {
+	r := newTestRedactor(t, nil, "")
+	first := r.NewVault().Redact("ana@example.com")
+	second := r.NewVault().Redact("again ana@example.com and bia@example.com")
+	if !strings.Contains(second, first) {
+		t.Fatalf("expected the same placeholder %s for the same value, got %q", first, second)
+	}
+	if strings.Count(second, "[EMAIL_") != 2 || strings.Count(second, first) != 1 {
+		t.Fatalf("expected different placeholders for different values, got %q", second)
+	}
+	//texto ja redigido nao muda
+	vault := r.NewVault()
+	redacted := vault.Redact("ana@example.com")
+	if again := vault.Redact(redacted); again != redacted {
+		t.Fatalf("expected the redacted text unchanged, got %q", again)
+	}
+	//outra chave gera outro placeholder
+	other, err := NewRedactor(allDetectors, nil, "other-key", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.NewVault().Redact("ana@example.com") == first {
+		t.Fatal("expected placeholders signed by another key to differ")
+	}
+}
+
+func TestRedactCustomPatterns(t *testing.T) {
+	r := newTestRedactor(t, []Pattern{
+		{Name: "order_id", Pattern: `ORD-\d{6}`},
+		//tem prioridade sobre o detector de telefone
+		{Name: "employee", Pattern: `EMP 91234-5678`},
+	}, "")
+	vault := r.NewVault()
+	text := "order ORD-123456 by EMP 91234-5678, customer phone 91234-5678"
+	redacted := vault.Redact(text)
+	for _, want := range []string{`\[ORDER_ID_[0-9a-f]{8}\]`, `\[EMPLOYEE_[0-9a-f]{8}\]`, `\[PHONE_[0-9a-f]{8}\]`} {
+		if !regexp.MustCompile(want).MatchString(redacted) {
+			t.Fatalf("expected %s in %q", want, redacted)
+		}
+	}
+	if strings.Contains(redacted, "ORD-") || strings.Contains(redacted, "5678") {
+		t.Fatalf("expected every value redacted, got %q", redacted)
+	}
+	if restored := vault.Restore(redacted); restored != text {
+		t.Fatalf("expected %q restored, got %q", text, restored)
+	}
+}
+
+func TestNewRedactorErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		detectors []string
+		patterns  []Pattern
+		store     string
+	}{
+		{"unknown detector", []string{"passport"}, nil, ""},
+		{"invalid pattern name", nil, []Pattern{{Name: "1st", Pattern: `x`}}, ""},
+		{"empty pattern name", nil, []Pattern{{Name: " ", Pattern: `x`}}, ""},
+		{"invalid regex", nil, []Pattern{{Name: "id", Pattern: `(`}}, ""},
+		{"invalid store", []string{DetectorEmail}, nil, "hashed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRedactor(tt.detectors, tt.patterns, "", tt.store); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	//sem regras a redacao fica desligada e o vault nil nao altera nada
+	r, err := NewRedactor(nil, nil, "", "")
+	if err != nil || r != nil {
+		t.Fatalf("expected a nil redactor, got %v %v", r, err)
+	}
+	vault := r.NewVault()
+	if got := vault.Redact("ana@example.com"); got != "ana@example.com" {
+		t.Fatalf("expected the text unchanged, got %q", got)
+	}
+	if got := vault.Store("ana@example.com"); got != "ana@example.com" {
+		t.Fatalf("expected the text unchanged, got %q", got)
+	}
+}
+
+func TestRestorePartialAcrossChunks(t *testing.T) {
+	vault := newTestRedactor(t, nil, "").NewVault()
+	placeholder := vault.Redact("ana@example.com")
+	answer := "Sure, I will write to " + placeholder + " tomorrow."
+
+	//o stream envia o texto acumulado a cada pedaco, o placeholder chega dividido em varios pedacos
+	var chunks []string
+	for i := 0; i < len(answer); i += 5 {
+		chunks = append(chunks, answer[i:min(i+5, len(answer))])
+	}
+	var full strings.Builder
+	var last string
+	for _, chunk := range chunks {
+		full.WriteString(chunk)
+		partial := vault.RestorePartial(full.String())
+		if strings.Contains(partial, "[") {
+			t.Fatalf("partial placeholder sent to the client: %q", partial)
+		}
+		if !strings.HasPrefix(partial, last) {
+			t.Fatalf("expected the partial text to grow, got %q after %q", partial, last)
+		}
+		last = partial
+	}
+	if want := "Sure, I will write to ana@example.com tomorrow."; last != want {
+		t.Fatalf("expected %q, got %q", want, last)
+	}
+
+	//colchete sem placeholder aparece quando o texto fecha ou passa do tamanho maximo
+	text := "see [note] and [" + strings.Repeat("x", maxPlaceholderLen)
+	if got := vault.RestorePartial(text); got != text {
+		t.Fatalf("expected brackets that are not placeholders to be kept, got %q", got)
+	}
+}
+
+func TestRestoreJSON(t *testing.T) {
+	vault := newTestRedactor(t, []Pattern{{Name: "nickname", Pattern: `"Ana"`}}, "").NewVault()
+	email := vault.Redact("ana@example.com")
+	nickname := vault.Redact(`"Ana"`)
+	raw := json.RawMessage(`{"to":"` + email + `","greeting":"hi ` + nickname + `","unknown":"[EMAIL_00000000]"}`)
+
+	restored := vault.RestoreJSON(raw)
+	var got map[string]string
+	if err := json.Unmarshal(restored, &got); err != nil {
+		t.Fatalf("expected valid json, got %s: %v", restored, err)
+	}
+	want := map[string]string{"to": "ana@example.com", "greeting": `hi "Ana"`, "unknown": "[EMAIL_00000000]"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+	if empty := vault.RestoreJSON(nil); empty != nil {
+		t.Fatalf("expected nil json unchanged, got %s", empty)
+	}
+}
+
+func TestStorePolicy(t *testing.T) {
+	tests := []struct {
+		store    string
+		redacted bool
+	}{
+		{"", false},
+		{StoreRaw, false},
+		{StoreRedacted, true},
+	}
+	for _, tt := range tests {
+		t.Run("store "+tt.store, func(t *testing.T) {
+			vault := newTestRedactor(t, nil, tt.store).NewVault()
+			original := "my email is ana@example.com"
+			//a msg do usuario vai redigida ao provedor e a resposta volta com o placeholder
+			answer := "I will write to " + vault.Redact(original)[len("my email is "):]
+
+			userStored, answerStored := vault.Store(original), vault.Store(answer)
+			if tt.redacted {
+				if strings.Contains(userStored, "ana@example.com") || strings.Contains(answerStored, "ana@example.com") {
+					t.Fatalf("expected the redacted text stored, got %q and %q", userStored, answerStored)
+				}
+				if vault.Restore(answerStored) != "I will write to ana@example.com" {
+					t.Fatalf("expected the stored placeholder to restore, got %q", answerStored)
+				}
+				return
+			}
+			if userStored != original || answerStored != "I will write to ana@example.com" {
+				t.Fatalf("expected the original text stored, got %q and %q", userStored, answerStored)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+// Policies regras de retencao, a mais especifica vale: tenant e assistente, assistente, tenant e a geral.
+// No mesmo nivel vale a primeira do arquivo
+type Policies []entity.RetentionPolicy
+
+// LoadPolicies le o json com a lista de regras, arquivo vazio retorna nil (retencao desligada)
+func LoadPolicies(path string) (Policies, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading retention policies file: %w", err)
+	}
+	var policies Policies
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("error parsing retention policies file: %w", err)
+	}
+	return NewPolicies(policies)
+}
+
+// NewPolicies valida as regras, acao vazia vira delete
+func NewPolicies(policies []entity.RetentionPolicy) (Policies, error) {
+	res := make(Policies, 0, len(policies))
+	for _, policy := range policies {
+		switch policy.Action {
+		case "":
+			policy.Action = entity.RetentionDelete
+		case entity.RetentionDelete, entity.RetentionAnonymize:
+		default:
+			return nil, fmt.Errorf("invalid retention action %q, use delete or anonymize", policy.Action)
+		}
+		if policy.InactiveDays < 0 || policy.ErasedDays < 0 {
+			return nil, fmt.Errorf("retention days can not be negative")
+		}
+		res = append(res, policy)
+	}
+	return res, nil
+}
+
+// Match regra do chat, nil quando nenhuma regra vale para o tenant e o assistente
+func (p Policies) Match(tenantID string, assistantID string) *entity.RetentionPolicy {
+	var best *entity.RetentionPolicy
+	bestScore := -1
+	for i := range p {
+		policy := &p[i]
+		if (policy.TenantID != "" && policy.TenantID != tenantID) || (policy.AssistantID != "" && policy.AssistantID != assistantID) {
+			continue
+		}
+		//assistente é mais especifico que o tenant
+		score := 0
+		if policy.AssistantID != "" {
+			score += 2
+		}
+		if policy.TenantID != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
+	}
+	return best
+}
+
+// minDays menor prazo positivo entre as regras, 0 quando nenhuma regra remove os dados
+func (p Policies) minDays(days func(entity.RetentionPolicy) int) int {
+	res := 0
+	for _, policy := range p {
+		if d := days(policy); d > 0 && (res == 0 || d < res) {
+			res = d
+		}
+	}
+	return res
+}
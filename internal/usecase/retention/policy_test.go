@@ -0,0 +1,127 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func TestPoliciesMatch(t *testing.T) {
+	policies := Policies{
+		{InactiveDays: 1},                                        // 0 geral
+		{TenantID: "t1", InactiveDays: 2},                        // 1 tenant
+		{AssistantID: "a1", InactiveDays: 3},                     // 2 assistente
+		{TenantID: "t1", AssistantID: "a1", InactiveDays: 4},     // 3 tenant e assistente
+		{TenantID: "t1", AssistantID: "a1", InactiveDays: 5},     // 4 repetida, perde para a primeira
+		{TenantID: "t2", InactiveDays: 6},                        // 5 tenant
+		{TenantID: "t2", InactiveDays: 7},                        // 6 repetida, perde para a primeira
+		{InactiveDays: 8},                                        // 7 geral repetida
+		{TenantID: "t3", AssistantID: "a3", InactiveDays: 9},     // 8 so vale para os dois juntos
+		{TenantID: "t4", AssistantID: "a4", ErasedDays: 10},      // 9
+		{TenantID: "t4", AssistantID: "other", InactiveDays: 11}, // 10
+	}
+	tests := []struct {
+		name      string
+		tenant    string
+		assistant string
+		want      int // indice da regra esperada
+	}{
+		{"tenant and assistant", "t1", "a1", 3},
+		{"assistant before tenant", "t1", "a2", 1},
+		{"assistant in another tenant", "t2", "a1", 2},
+		{"tenant", "t2", "a2", 5},
+		{"tenant without assistant", "t2", "", 5},
+		{"global", "t9", "a9", 0},
+		{"global without tenant", "", "", 0},
+		{"pair needs both", "t3", "a9", 0},
+		{"pair", "t3", "a3", 8},
+		{"other assistant in the tenant", "t4", "a4", 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policies.Match(tt.tenant, tt.assistant)
+			if got != &policies[tt.want] {
+				t.Fatalf("expected policy %d %+v, got %+v", tt.want, policies[tt.want], got)
+			}
+		})
+	}
+
+	//sem regra geral o chat de outro tenant nao tem regra
+	if got := policies[1:7].Match("t9", "a9"); got != nil {
+		t.Fatalf("expected no policy, got %+v", got)
+	}
+	if got := Policies(nil).Match("t1", "a1"); got != nil {
+		t.Fatalf("expected no policy, got %+v", got)
+	}
+}
+
+func TestPoliciesMinDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies Policies
+		inactive int
+		erased   int
+	}{
+		{"no policies", nil, 0, 0},
+		{"keep forever", Policies{{}, {TenantID: "t1"}}, 0, 0},
+		{"smallest positive", Policies{{InactiveDays: 30, ErasedDays: 0}, {TenantID: "t1", InactiveDays: 7, ErasedDays: 90}, {AssistantID: "a1", InactiveDays: 0, ErasedDays: 14}}, 7, 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inactive := tt.policies.minDays(func(p entity.RetentionPolicy) int { return p.InactiveDays })
+			erased := tt.policies.minDays(func(p entity.RetentionPolicy) int { return p.ErasedDays })
+			if inactive != tt.inactive || erased != tt.erased {
+				t.Fatalf("expected %d and %d days, got %d and %d", tt.inactive, tt.erased, inactive, erased)
+			}
+		})
+	}
+}
+
+func TestNewPolicies(t *testing.T) {
+	policies, err := NewPolicies([]entity.RetentionPolicy{{InactiveDays: 30}, {TenantID: "t1", Action: entity.RetentionAnonymize}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policies[0].Action != entity.RetentionDelete || policies[1].Action != entity.RetentionAnonymize {
+		t.Fatalf("expected delete as the default action, got %+v", policies)
+	}
+
+	invalid := [][]entity.RetentionPolicy{
+		{{Action: "archive"}},
+		{{InactiveDays: -1}},
+		{{ErasedDays: -1}},
+	}
+	for _, p := range invalid {
+		if _, err := NewPolicies(p); err == nil {
+			t.Fatalf("expected an error for %+v", p)
+		}
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	policies, err := LoadPolicies("")
+	if err != nil || policies != nil {
+		t.Fatalf("expected retention disabled, got %v %v", policies, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "retention.json")
+	if err := os.WriteFile(path, []byte(`[{"tenant_id":"t1","inactive_days":30,"erased_days":7},{"action":"anonymize","inactive_days":90}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policies, err = LoadPolicies(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 2 || policies[0].TenantID != "t1" || policies[0].Action != entity.RetentionDelete || policies[1].Action != entity.RetentionAnonymize {
+		t.Fatalf("unexpected policies %+v", policies)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"tenant_id":"t1"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicies(path); err == nil {
+		t.Fatal("expected an error for a file that is not a list")
+	}
+}
@@ -0,0 +1,167 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+const (
+	DefaultBatchSize = 100
+	DefaultMaxPerRun = 1000
+)
+
+type PurgeDataInputDTO struct {
+	DryRun bool `json:"dry_run"` //somente conta o que seria removido
+}
+
+// PurgeDataOutputDTO linhas removidas na execucao, no dry run as que seriam removidas
+type PurgeDataOutputDTO struct {
+	DryRun          bool          `json:"dry_run"`
+	ChatsDeleted    int           `json:"chats_deleted"`
+	ChatsAnonymized int           `json:"chats_anonymized"`
+	MessagesDeleted int           `json:"messages_deleted"`
+	Truncated       bool          `json:"truncated"` //parou no limite da execucao, o resto fica para a proxima
+	Duration        time.Duration `json:"duration"`
+}
+
+type PurgeDataUseCase struct {
+	RetentionGateway gateway.RetentionGateway
+	Policies         Policies
+	BatchSize        int // linhas lidas por consulta
+	MaxPerRun        int // linhas removidas por execucao, de chats e de msgs separadamente
+}
+
+func NewPurgeDataUseCase(retentionGateway gateway.RetentionGateway, policies Policies, batchSize int, maxPerRun int) *PurgeDataUseCase {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if maxPerRun <= 0 {
+		maxPerRun = DefaultMaxPerRun
+	}
+	return &PurgeDataUseCase{
+		RetentionGateway: retentionGateway,
+		Policies:         policies,
+		BatchSize:        batchSize,
+		MaxPerRun:        maxPerRun,
+	}
+}
+
+// Execute aplica as regras nos chats inativos e nas msgs apagadas do contexto.
+// Msg apagada que estava carregada em um chat em andamento pode voltar no SaveChat, e sai na proxima execucao
+func (uc *PurgeDataUseCase) Execute(ctx context.Context, input PurgeDataInputDTO) (*PurgeDataOutputDTO, error) {
+	start := time.Now()
+	output := &PurgeDataOutputDTO{DryRun: input.DryRun}
+	if err := uc.purgeChats(ctx, start, input.DryRun, output); err != nil {
+		return output, fmt.Errorf("error purging inactive chats: %w", err)
+	}
+	if err := uc.purgeErasedMessages(ctx, start, input.DryRun, output); err != nil {
+		return output, fmt.Errorf("error purging erased messages: %w", err)
+	}
+	output.Duration = time.Since(start)
+	return output, nil
+}
+
+func (uc *PurgeDataUseCase) purgeChats(ctx context.Context, now time.Time, dryRun bool, output *PurgeDataOutputDTO) error {
+	minDays := uc.Policies.minDays(func(p entity.RetentionPolicy) int { return p.InactiveDays })
+	if minDays == 0 {
+		return nil
+	}
+	//chats anonimizados so interessam quando alguma regra apaga chats
+	includeAnonymized := false
+	for _, policy := range uc.Policies {
+		includeAnonymized = includeAnonymized || (policy.Action == entity.RetentionDelete && policy.InactiveDays > 0)
+	}
+
+	var cursor *gateway.ChatCursor
+	purged := 0
+	for {
+		chats, err := uc.RetentionGateway.ListInactiveChats(ctx, daysBefore(now, minDays), includeAnonymized, cursor, uc.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, chat := range chats {
+			cursor = &gateway.ChatCursor{UpdatedAt: chat.UpdatedAt, ID: chat.ID}
+			policy := uc.Policies.Match(chat.TenantID, chat.AssistantID)
+			if policy == nil || policy.InactiveDays == 0 || !chat.UpdatedAt.Before(daysBefore(now, policy.InactiveDays)) {
+				continue
+			}
+			if policy.Action == entity.RetentionAnonymize && chat.Anonymized {
+				continue
+			}
+			if purged >= uc.MaxPerRun {
+				output.Truncated = true
+				return nil
+			}
+			done := true
+			if !dryRun {
+				if policy.Action == entity.RetentionAnonymize {
+					done, err = uc.RetentionGateway.AnonymizeChat(ctx, chat)
+				} else {
+					done, err = uc.RetentionGateway.DeleteInactiveChat(ctx, chat)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			//chat com atividade depois da listagem fica
+			if !done {
+				continue
+			}
+			purged++
+			if policy.Action == entity.RetentionAnonymize {
+				output.ChatsAnonymized++
+			} else {
+				output.ChatsDeleted++
+			}
+		}
+		if len(chats) < uc.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (uc *PurgeDataUseCase) purgeErasedMessages(ctx context.Context, now time.Time, dryRun bool, output *PurgeDataOutputDTO) error {
+	minDays := uc.Policies.minDays(func(p entity.RetentionPolicy) int { return p.ErasedDays })
+	if minDays == 0 {
+		return nil
+	}
+	afterID := ""
+	for {
+		messages, err := uc.RetentionGateway.ListErasedMessages(ctx, daysBefore(now, minDays), afterID, uc.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			afterID = message.ID
+			policy := uc.Policies.Match(message.TenantID, message.AssistantID)
+			if policy == nil || policy.ErasedDays == 0 || !message.CreatedAt.Before(daysBefore(now, policy.ErasedDays)) {
+				continue
+			}
+			if output.MessagesDeleted >= uc.MaxPerRun {
+				output.Truncated = true
+				return nil
+			}
+			done := true
+			if !dryRun {
+				done, err = uc.RetentionGateway.DeleteErasedMessage(ctx, message.ID)
+				if err != nil {
+					return err
+				}
+			}
+			if done {
+				output.MessagesDeleted++
+			}
+		}
+		if len(messages) < uc.BatchSize {
+			return nil
+		}
+	}
+}
+
+func daysBefore(now time.Time, days int) time.Time {
+	return now.Add(-time.Duration(days) * 24 * time.Hour)
+}
@@ -0,0 +1,230 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// fakeRetention gateway em memoria, active tem os ids que tiveram atividade depois da listagem
+type fakeRetention struct {
+	chats      []*entity.InactiveChat
+	messages   []*entity.ErasedMessage
+	active     map[string]bool
+	deleted    []string
+	anonymized []string
+	erased     []string
+	listCalls  int
+	before     time.Time
+	anonymous  bool
+}
+
+func (f *fakeRetention) ListInactiveChats(ctx context.Context, before time.Time, includeAnonymized bool, after *gateway.ChatCursor, limit int) ([]*entity.InactiveChat, error) {
+	f.listCalls++
+	f.before, f.anonymous = before, includeAnonymized
+	sort.Slice(f.chats, func(i, j int) bool {
+		if f.chats[i].UpdatedAt.Equal(f.chats[j].UpdatedAt) {
+			return f.chats[i].ID < f.chats[j].ID
+		}
+		return f.chats[i].UpdatedAt.Before(f.chats[j].UpdatedAt)
+	})
+	var res []*entity.InactiveChat
+	for _, chat := range f.chats {
+		if !chat.UpdatedAt.Before(before) || (chat.Anonymized && !includeAnonymized) {
+			continue
+		}
+		if after != nil && (chat.UpdatedAt.Before(after.UpdatedAt) || (chat.UpdatedAt.Equal(after.UpdatedAt) && chat.ID <= after.ID)) {
+			continue
+		}
+		res = append(res, chat)
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRetention) DeleteInactiveChat(ctx context.Context, chat *entity.InactiveChat) (bool, error) {
+	if f.active[chat.ID] {
+		return false, nil
+	}
+	f.deleted = append(f.deleted, chat.ID)
+	return true, nil
+}
+
+func (f *fakeRetention) AnonymizeChat(ctx context.Context, chat *entity.InactiveChat) (bool, error) {
+	if f.active[chat.ID] {
+		return false, nil
+	}
+	f.anonymized = append(f.anonymized, chat.ID)
+	return true, nil
+}
+
+func (f *fakeRetention) ListErasedMessages(ctx context.Context, before time.Time, afterID string, limit int) ([]*entity.ErasedMessage, error) {
+	var res []*entity.ErasedMessage
+	for _, message := range f.messages {
+		if !message.CreatedAt.Before(before) || message.ID <= afterID {
+			continue
+		}
+		res = append(res, message)
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeRetention) DeleteErasedMessage(ctx context.Context, messageID string) (bool, error) {
+	if f.active[messageID] {
+		return false, nil
+	}
+	f.erased = append(f.erased, messageID)
+	return true, nil
+}
+
+func daysAgo(days int) time.Time {
+	return time.Now().Add(-time.Duration(days)*24*time.Hour - time.Hour)
+}
+
+// newFakeRetention chats e msgs com a idade em dias, cada tenant com uma regra diferente
+func newFakeRetention() *fakeRetention {
+	f := &fakeRetention{active: map[string]bool{}}
+	chat := func(id, tenant, assistant string, days int, anonymized bool) {
+		f.chats = append(f.chats, &entity.InactiveChat{ID: id, TenantID: tenant, AssistantID: assistant, Anonymized: anonymized, UpdatedAt: daysAgo(days)})
+	}
+	chat("c01", "t1", "a1", 40, false)  // geral 30 dias, apaga
+	chat("c02", "t1", "a1", 20, false)  // novo demais para a regra geral
+	chat("c03", "t2", "a1", 15, false)  // tenant t2 10 dias, anonimiza
+	chat("c04", "t2", "a1", 15, true)   // ja anonimizado
+	chat("c05", "t2", "a2", 15, false)  // assistente a2 em t2 mantem para sempre
+	chat("c06", "t2", "a3", 200, false) // tenant t2, anonimiza
+	chat("c07", "t3", "a1", 8, false)   // tenant t3 7 dias, apaga
+	chat("c08", "t3", "a1", 8, true)    // anonimizado mas a regra apaga
+	chat("c09", "t3", "a1", 3, false)   // novo
+	chat("c10", "t1", "a1", 90, false)  // geral, apaga
+	msg := func(id, tenant string, days int) {
+		f.messages = append(f.messages, &entity.ErasedMessage{ID: id, ChatID: "c", TenantID: tenant, AssistantID: "a1", CreatedAt: daysAgo(days)})
+	}
+	msg("m01", "t1", 10) // geral 5 dias
+	msg("m02", "t1", 3)
+	msg("m03", "t2", 3) // t2 nao apaga msgs
+	msg("m04", "t3", 2) // t3 1 dia
+	msg("m05", "t3", 6)
+	msg("m06", "t1", 6)
+	return f
+}
+
+func newTestPolicies(t *testing.T) Policies {
+	t.Helper()
+	policies, err := NewPolicies([]entity.RetentionPolicy{
+		{InactiveDays: 30, ErasedDays: 5},
+		{TenantID: "t2", Action: entity.RetentionAnonymize, InactiveDays: 10},
+		{TenantID: "t2", AssistantID: "a2"},
+		{TenantID: "t3", InactiveDays: 7, ErasedDays: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policies
+}
+
+func TestPurgeData(t *testing.T) {
+	f := newFakeRetention()
+	//lotes pequenos para passar pelo cursor
+	uc := NewPurgeDataUseCase(f, newTestPolicies(t), 2, 0)
+	output, err := uc.Execute(context.Background(), PurgeDataInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.ChatsDeleted != 4 || output.ChatsAnonymized != 2 || output.MessagesDeleted != 4 || output.Truncated || output.DryRun {
+		t.Fatalf("unexpected output %+v", output)
+	}
+	if got := fmt.Sprint(f.deleted, f.anonymized, f.erased); got != "[c10 c01 c07 c08] [c06 c03] [m01 m04 m05 m06]" {
+		t.Fatalf("unexpected purge %s", got)
+	}
+	//a listagem usa o menor prazo entre as regras
+	if cutoff := time.Since(f.before); cutoff < 7*24*time.Hour || cutoff > 7*24*time.Hour+time.Minute {
+		t.Fatalf("expected chats listed with the 7 days cutoff, got %s", cutoff)
+	}
+	if !f.anonymous {
+		t.Fatal("expected anonymized chats listed when a policy deletes chats")
+	}
+}
+
+func TestPurgeDataDryRun(t *testing.T) {
+	f := newFakeRetention()
+	output, err := NewPurgeDataUseCase(f, newTestPolicies(t), 3, 0).Execute(context.Background(), PurgeDataInputDTO{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.DryRun || output.ChatsDeleted != 4 || output.ChatsAnonymized != 2 || output.MessagesDeleted != 4 {
+		t.Fatalf("expected the same counts as a real run, got %+v", output)
+	}
+	if len(f.deleted)+len(f.anonymized)+len(f.erased) != 0 {
+		t.Fatalf("expected nothing removed in a dry run, got %v %v %v", f.deleted, f.anonymized, f.erased)
+	}
+}
+
+func TestPurgeDataMaxPerRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxPerRun int
+		dryRun    bool
+		chats     int
+		messages  int
+		truncated bool
+	}{
+		{"under the limit", 6, false, 6, 4, false},
+		{"chats truncated", 5, false, 5, 4, true},
+		{"both truncated", 3, false, 3, 3, true},
+		{"dry run truncated", 2, true, 2, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRetention()
+			output, err := NewPurgeDataUseCase(f, newTestPolicies(t), 4, tt.maxPerRun).Execute(context.Background(), PurgeDataInputDTO{DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatal(err)
+			}
+			chats := output.ChatsDeleted + output.ChatsAnonymized
+			if chats != tt.chats || output.MessagesDeleted != tt.messages || output.Truncated != tt.truncated {
+				t.Fatalf("expected %d chats and %d messages (truncated %v), got %+v", tt.chats, tt.messages, tt.truncated, output)
+			}
+			if !tt.dryRun && (len(f.deleted)+len(f.anonymized) != chats || len(f.erased) != tt.messages) {
+				t.Fatalf("expected the counts to match the removed rows, got %v %v %v", f.deleted, f.anonymized, f.erased)
+			}
+		})
+	}
+}
+
+func TestPurgeDataSkipsActiveChats(t *testing.T) {
+	f := newFakeRetention()
+	f.active["c01"], f.active["c03"], f.active["m01"] = true, true, true
+	output, err := NewPurgeDataUseCase(f, newTestPolicies(t), 0, 0).Execute(context.Background(), PurgeDataInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.ChatsDeleted != 3 || output.ChatsAnonymized != 1 || output.MessagesDeleted != 3 {
+		t.Fatalf("expected the chats with new activity kept, got %+v", output)
+	}
+}
+
+func TestPurgeDataWithoutDays(t *testing.T) {
+	f := newFakeRetention()
+	policies, err := NewPolicies([]entity.RetentionPolicy{{TenantID: "t1", Action: entity.RetentionAnonymize}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := NewPurgeDataUseCase(f, policies, 0, 0).Execute(context.Background(), PurgeDataInputDTO{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.listCalls != 0 || output.ChatsDeleted+output.ChatsAnonymized+output.MessagesDeleted != 0 {
+		t.Fatalf("expected nothing listed when every policy keeps the data, got %d calls %+v", f.listCalls, output)
+	}
+}
@@ -0,0 +1,154 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const (
+	// tentativas extras quando a resposta nao valida no schema
+	DefaultMaxRetries = 2
+
+	instructionHeader = "Respond only with a JSON value that is valid against the following JSON Schema. " +
+		"Do not add explanations, markdown or any text outside the JSON.\n\nJSON Schema:\n"
+	retryHeader = "Your previous response is not valid against the JSON Schema:\n"
+	retryFooter = "\nRespond again with only the corrected JSON."
+
+	// nome usado para o schema dentro do compilador, referencias externas nao sao carregadas
+	schemaURL = "mem:///response_schema.json"
+)
+
+// Schema response_schema da requisicao compilado
+type Schema struct {
+	raw    []byte
+	schema *jsonschema.Schema
+	object bool
+}
+
+// ValidationError resposta do modelo que nao é json ou nao valida no schema
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+func Compile(raw json.RawMessage) (*Schema, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, fmt.Errorf("%w: response_schema is not valid json: %s", entity.ErrInvalidConfig, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external schema %s is not allowed", url)
+	}
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(compact.Bytes())); err != nil {
+		return nil, fmt.Errorf("%w: invalid response_schema: %s", entity.ErrInvalidConfig, err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid response_schema: %s", entity.ErrInvalidConfig, err)
+	}
+
+	var root struct {
+		Type interface{} `json:"type"`
+	}
+	json.Unmarshal(compact.Bytes(), &root)
+
+	return &Schema{
+		raw:    compact.Bytes(),
+		schema: schema,
+		object: root.Type == "object",
+	}, nil
+}
+
+// ObjectMode raiz do schema é um objeto, permite ligar o modo json da api
+func (s *Schema) ObjectMode() bool {
+	return s.object
+}
+
+// Instruction msg de sistema pedindo a resposta no formato do schema
+func (s *Schema) Instruction(model *entity.Model) (*entity.Message, error) {
+	return entity.NewMessage("system", instructionHeader+string(s.raw), model)
+}
+
+// Parse extrai o json da resposta (aceitando bloco ```json) e valida no schema, retorna o json compacto
+func (s *Schema) Parse(content string) (json.RawMessage, error) {
+	content = stripFence(content)
+
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, &ValidationError{Problems: []string{"response is not valid JSON: " + err.Error()}}
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, &ValidationError{Problems: []string{"response has text after the JSON value"}}
+	}
+
+	if err := s.schema.Validate(value); err != nil {
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+		return nil, &ValidationError{Problems: problems(validationErr)}
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(content)); err != nil {
+		return nil, &ValidationError{Problems: []string{"response is not valid JSON: " + err.Error()}}
+	}
+	return compact.Bytes(), nil
+}
+
+// RetryMessage msg de usuario com os problemas da resposta anterior, pedindo a correcao
+func RetryMessage(err *ValidationError, model *entity.Model) (*entity.Message, error) {
+	var b strings.Builder
+	b.WriteString(retryHeader)
+	for _, problem := range err.Problems {
+		b.WriteString("- ")
+		b.WriteString(problem)
+		b.WriteString("\n")
+	}
+	b.WriteString(retryFooter)
+	return entity.NewMessage("user", b.String(), model)
+}
+
+// problems somente as falhas das folhas da arvore de validacao, que apontam o campo e o motivo
+func problems(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{location + ": " + err.Message}
+	}
+	var res []string
+	for _, cause := range err.Causes {
+		res = append(res, problems(cause)...)
+	}
+	return res
+}
+
+func stripFence(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	content = strings.TrimPrefix(content, "```")
+	if i := strings.IndexByte(content, '\n'); i >= 0 {
+		content = content[i+1:]
+	}
+	content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+type TenantInputDTO struct {
+	Name               string   `json:"name"`
+	DefaultAssistantID string   `json:"default_assistant_id,omitempty"` //assistente dos chats novos que nao escolhem um
+	AllowedModels      []string `json:"allowed_models,omitempty"`       //vazio libera todos os modelos
+	MonthlyTokenQuota  int      `json:"monthly_token_quota,omitempty"`  //0 sem limite
+	ToolProfile        string   `json:"tool_profile,omitempty"`         //perfil de ferramentas dos chats cujo assistente nao define um, vazio usa o perfil default
+}
+
+type TenantOutputDTO struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	DefaultAssistantID string    `json:"default_assistant_id,omitempty"`
+	AllowedModels      []string  `json:"allowed_models"`
+	MonthlyTokenQuota  int       `json:"monthly_token_quota"`
+	ToolProfile        string    `json:"tool_profile,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type CreateTenantOutputDTO struct {
+	TenantOutputDTO
+	Token string `json:"token"` //token de api do tenant, nao é possivel consulta-lo depois
+}
+
+type CreateTenantUseCase struct {
+	TenantGateway    gateway.TenantGateway
+	AssistantGateway gateway.AssistantGateway
+	Tools            *tools.Registry // opcional, confere se o perfil de ferramentas existe
+}
+
+func NewCreateTenantUseCase(tenantGateway gateway.TenantGateway, assistantGateway gateway.AssistantGateway, registry *tools.Registry) *CreateTenantUseCase {
+	return &CreateTenantUseCase{
+		TenantGateway:    tenantGateway,
+		AssistantGateway: assistantGateway,
+		Tools:            registry,
+	}
+}
+
+func (uc *CreateTenantUseCase) Execute(ctx context.Context, input TenantInputDTO) (*CreateTenantOutputDTO, error) {
+	tenant, token, err := entity.NewTenant(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	tenant.DefaultAssistantID = input.DefaultAssistantID
+	tenant.AllowedModels = input.AllowedModels
+	tenant.MonthlyTokenQuota = input.MonthlyTokenQuota
+	tenant.ToolProfile = input.ToolProfile
+	if err := validate(ctx, tenant, uc.AssistantGateway, uc.Tools); err != nil {
+		return nil, err
+	}
+
+	if err := uc.TenantGateway.CreateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("error saving tenant: %w", err)
+	}
+	return &CreateTenantOutputDTO{
+		TenantOutputDTO: tenantOutput(tenant),
+		Token:           token,
+	}, nil
+}
+
+// validate revalida o tenant com os campos opcionais, o perfil de ferramentas tem que existir
+// e o assistente padrao tem que existir e usar um modelo liberado para o tenant
+func validate(ctx context.Context, tenant *entity.Tenant, assistants gateway.AssistantGateway, registry *tools.Registry) error {
+	if err := tenant.Validate(); err != nil {
+		return err
+	}
+	if _, err := registry.ForProfile(tenant.ToolProfile); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidTenant, err)
+	}
+	if tenant.DefaultAssistantID == "" {
+		return nil
+	}
+	assistant, err := assistants.FindAssistantByID(ctx, tenant.DefaultAssistantID)
+	if err != nil {
+		return fmt.Errorf("%w: default assistant: %s", entity.ErrInvalidTenant, err)
+	}
+	if !tenant.AllowsModel(assistant.Config.Model.Name) {
+		return fmt.Errorf("%w: default assistant model %s is not allowed", entity.ErrInvalidTenant, assistant.Config.Model.Name)
+	}
+	return nil
+}
+
+func tenantOutput(tenant *entity.Tenant) TenantOutputDTO {
+	models := tenant.AllowedModels
+	if models == nil {
+		models = []string{}
+	}
+	return TenantOutputDTO{
+		ID:                 tenant.ID,
+		Name:               tenant.Name,
+		DefaultAssistantID: tenant.DefaultAssistantID,
+		AllowedModels:      models,
+		MonthlyTokenQuota:  tenant.MonthlyTokenQuota,
+		ToolProfile:        tenant.ToolProfile,
+		CreatedAt:          tenant.CreatedAt,
+		UpdatedAt:          tenant.UpdatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type DeleteTenantInputDTO struct {
+	ID string `json:"id"`
+}
+
+type DeleteTenantUseCase struct {
+	TenantGateway gateway.TenantGateway
+}
+
+func NewDeleteTenantUseCase(tenantGateway gateway.TenantGateway) *DeleteTenantUseCase {
+	return &DeleteTenantUseCase{
+		TenantGateway: tenantGateway,
+	}
+}
+
+// Execute remove o tenant, o token dele deixa de autenticar na hora
+func (uc *DeleteTenantUseCase) Execute(ctx context.Context, input DeleteTenantInputDTO) error {
+	if err := uc.TenantGateway.DeleteTenant(ctx, input.ID); err != nil {
+		return fmt.Errorf("error deleting tenant: %w", err)
+	}
+	return nil
+}
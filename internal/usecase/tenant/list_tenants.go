@@ -0,0 +1,34 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+type ListTenantsOutputDTO struct {
+	Tenants []TenantOutputDTO `json:"tenants"`
+}
+
+type ListTenantsUseCase struct {
+	TenantGateway gateway.TenantGateway
+}
+
+func NewListTenantsUseCase(tenantGateway gateway.TenantGateway) *ListTenantsUseCase {
+	return &ListTenantsUseCase{
+		TenantGateway: tenantGateway,
+	}
+}
+
+func (uc *ListTenantsUseCase) Execute(ctx context.Context) (*ListTenantsOutputDTO, error) {
+	tenants, err := uc.TenantGateway.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tenants: %w", err)
+	}
+	output := &ListTenantsOutputDTO{Tenants: []TenantOutputDTO{}}
+	for _, tenant := range tenants {
+		output.Tenants = append(output.Tenants, tenantOutput(tenant))
+	}
+	return output, nil
+}
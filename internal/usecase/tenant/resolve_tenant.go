@@ -0,0 +1,35 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// ResolveTenantInputDTO informar o token de api do tenant ou o id vindo das claims de um token assinado
+type ResolveTenantInputDTO struct {
+	Token string
+	ID    string
+}
+
+type ResolveTenantUseCase struct {
+	TenantGateway gateway.TenantGateway
+}
+
+func NewResolveTenantUseCase(tenantGateway gateway.TenantGateway) *ResolveTenantUseCase {
+	return &ResolveTenantUseCase{
+		TenantGateway: tenantGateway,
+	}
+}
+
+// Execute busca o tenant da requisicao, token ou id desconhecido retorna ErrTenantNotFound
+func (uc *ResolveTenantUseCase) Execute(ctx context.Context, input ResolveTenantInputDTO) (*entity.Tenant, error) {
+	switch {
+	case input.ID != "":
+		return uc.TenantGateway.FindTenantByID(ctx, input.ID)
+	case input.Token != "":
+		return uc.TenantGateway.FindTenantByTokenHash(ctx, entity.HashTenantToken(input.Token))
+	}
+	return nil, entity.ErrTenantNotFound
+}
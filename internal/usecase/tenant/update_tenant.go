@@ -0,0 +1,53 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/tools"
+)
+
+type UpdateTenantInputDTO struct {
+	ID string `json:"id"`
+	TenantInputDTO
+}
+
+type UpdateTenantUseCase struct {
+	TenantGateway    gateway.TenantGateway
+	AssistantGateway gateway.AssistantGateway
+	Tools            *tools.Registry // opcional, confere se o perfil de ferramentas existe
+}
+
+func NewUpdateTenantUseCase(tenantGateway gateway.TenantGateway, assistantGateway gateway.AssistantGateway, registry *tools.Registry) *UpdateTenantUseCase {
+	return &UpdateTenantUseCase{
+		TenantGateway:    tenantGateway,
+		AssistantGateway: assistantGateway,
+		Tools:            registry,
+	}
+}
+
+// Execute substitui a config do tenant, o token de api nao muda
+func (uc *UpdateTenantUseCase) Execute(ctx context.Context, input UpdateTenantInputDTO) (*TenantOutputDTO, error) {
+	tenant, err := uc.TenantGateway.FindTenantByID(ctx, input.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tenant: %w", err)
+	}
+
+	tenant.Name = input.Name
+	tenant.DefaultAssistantID = input.DefaultAssistantID
+	tenant.AllowedModels = input.AllowedModels
+	tenant.MonthlyTokenQuota = input.MonthlyTokenQuota
+	tenant.ToolProfile = input.ToolProfile
+	tenant.UpdatedAt = time.Now()
+	if err := validate(ctx, tenant, uc.AssistantGateway, uc.Tools); err != nil {
+		return nil, err
+	}
+
+	if err := uc.TenantGateway.SaveTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("error saving tenant: %w", err)
+	}
+	output := tenantOutput(tenant)
+	return &output, nil
+}
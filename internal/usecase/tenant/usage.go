@@ -0,0 +1,60 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+)
+
+// Usage controla a cota mensal de tokens do tenant da requisicao.
+// Usage nil ou requisicao do tenant padrao nao tem limite nem registro de uso
+type Usage struct {
+	TenantGateway gateway.TenantGateway
+}
+
+func NewUsage(tenantGateway gateway.TenantGateway) *Usage {
+	return &Usage{
+		TenantGateway: tenantGateway,
+	}
+}
+
+// Check retorna ErrQuotaExceeded quando o tenant ja usou a cota do mes
+func (u *Usage) Check(ctx context.Context) error {
+	tenant := entity.TenantFromContext(ctx)
+	if u == nil || tenant == nil || tenant.MonthlyTokenQuota == 0 {
+		return nil
+	}
+	used, err := u.TenantGateway.FindTenantUsage(ctx, tenant.ID, Period(time.Now()))
+	if err != nil {
+		return fmt.Errorf("error fetching tenant usage: %w", err)
+	}
+	if used >= tenant.MonthlyTokenQuota {
+		return fmt.Errorf("%w: %d of %d tokens used this month", entity.ErrQuotaExceeded, used, tenant.MonthlyTokenQuota)
+	}
+	return nil
+}
+
+// Record soma os tokens cobrados pela api do modelo no uso do mes do tenant
+func (u *Usage) Record(ctx context.Context, tokens int) error {
+	tenant := entity.TenantFromContext(ctx)
+	if u == nil || tenant == nil || tokens <= 0 {
+		return nil
+	}
+	if err := u.TenantGateway.AddTenantUsage(ctx, tenant.ID, Period(time.Now()), tokens); err != nil {
+		return fmt.Errorf("error recording tenant usage: %w", err)
+	}
+	return nil
+}
+
+// Enabled informa se a requisicao tem o uso registrado, usado para pedir o uso nas respostas em stream
+func (u *Usage) Enabled(ctx context.Context) bool {
+	return u != nil && entity.TenantFromContext(ctx) != nil
+}
+
+// Period mes da cota (UTC), no formato YYYY-MM
+func Period(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Builtin ferramentas que acompanham o servico, habilitadas pelo nome na config TOOLS
+var Builtin = map[string]Tool{
+	"current_time": CurrentTime,
+}
+
+var CurrentTime = Tool{
+	Name:        "current_time",
+	Description: "Returns the current date and time, optionally in an IANA time zone such as America/Sao_Paulo.",
+	Parameters: json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"timezone": {"type": "string", "description": "IANA time zone name, defaults to UTC"}
+		}
+	}`),
+	Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+		var args struct {
+			Timezone string `json:"timezone"`
+		}
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		loc := time.UTC
+		if args.Timezone != "" {
+			var err error
+			loc, err = time.LoadLocation(args.Timezone)
+			if err != nil {
+				return "", fmt.Errorf("unknown time zone %q", args.Timezone)
+			}
+		}
+		now := time.Now().In(loc)
+		b, err := json.Marshal(map[string]string{
+			"time":     now.Format(time.RFC3339),
+			"weekday":  now.Weekday().String(),
+			"timezone": loc.String(),
+		})
+		return string(b), err
+	},
+}
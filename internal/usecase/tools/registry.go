@@ -0,0 +1,201 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+const (
+	// tempo maximo de execucao de cada chamada, quando a ferramenta nao define o seu
+	callTimeout = 30 * time.Second
+	// resultados maiores sao cortados para nao estourar o contexto do modelo
+	maxResultLength  = 16000
+	DefaultMaxRounds = 5
+	// perfil dos chats cujo assistente e tenant nao definem nenhum
+	DefaultProfile = "default"
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Func executa a ferramenta com os argumentos json gerados pelo modelo, o retorno é enviado ao modelo como texto
+type Func func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Tool ferramenta que o modelo pode chamar, Parameters é o json schema dos argumentos
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage
+	Call        Func
+	Timeout     time.Duration // zero usa o padrao de 30s
+}
+
+// Registry ferramentas disponiveis para o modelo nos use cases de completion.
+// MaxRounds limita as rodadas de chamadas por resposta, na ultima o modelo tem que responder sem ferramentas
+type Registry struct {
+	MaxRounds int
+	mu        sync.RWMutex
+	tools     map[string]Tool
+	profiles  map[string][]string
+}
+
+func NewRegistry(maxRounds int) *Registry {
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxRounds
+	}
+	return &Registry{
+		MaxRounds: maxRounds,
+		tools:     make(map[string]Tool),
+		profiles:  make(map[string][]string),
+	}
+}
+
+func (r *Registry) Register(tool Tool) error {
+	if !validName.MatchString(tool.Name) {
+		return fmt.Errorf("invalid tool name %q", tool.Name)
+	}
+	if tool.Call == nil {
+		return fmt.Errorf("tool %s has no function", tool.Name)
+	}
+	if len(tool.Parameters) == 0 {
+		tool.Parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(tool.Parameters, &schema); err != nil {
+		return fmt.Errorf("tool %s: parameters must be a json schema object: %w", tool.Name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[tool.Name]; ok {
+		return fmt.Errorf("tool %s already registered", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+	return nil
+}
+
+// SetProfile define as ferramentas liberadas para um perfil de chat, patterns no formato do path.Match (ex: files_*)
+func (r *Registry) SetProfile(name string, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("profile %s: invalid pattern %q", name, pattern)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.profiles[name] = patterns
+	return nil
+}
+
+// ForProfile registry somente com as ferramentas liberadas para o perfil.
+// Perfil vazio usa o default quando ele existe, senao nenhuma ferramenta é liberada
+func (r *Registry) ForProfile(name string) (*Registry, error) {
+	if r == nil {
+		return nil, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name == "" {
+		name = DefaultProfile
+		if _, ok := r.profiles[name]; !ok {
+			return NewRegistry(r.MaxRounds), nil
+		}
+	}
+	patterns, ok := r.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown tool profile %s", entity.ErrInvalidConfig, name)
+	}
+
+	res := NewRegistry(r.MaxRounds)
+	for toolName, tool := range r.tools {
+		if Match(patterns, toolName) {
+			res.tools[toolName] = tool
+		}
+	}
+	return res, nil
+}
+
+// Match informa se o nome bate com algum dos patterns
+func Match(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Tools ferramentas registradas ordenadas pelo nome
+func (r *Registry) Tools() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]Tool, 0, len(r.tools))
+	for _, tool := range r.tools {
+		res = append(res, tool)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func (r *Registry) Len() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.tools)
+}
+
+// Execute executa a chamada pedida pelo modelo. Erros da ferramenta voltam como resultado
+// para o modelo poder corrigir os argumentos ou responder sem ela, so o cancelamento do ctx é retornado
+func (r *Registry) Execute(ctx context.Context, call entity.ToolCall) (string, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[call.Name]
+	r.mu.RUnlock()
+	if !ok {
+		return errorResult(fmt.Errorf("unknown tool %q", call.Name)), nil
+	}
+
+	arguments := json.RawMessage(call.Arguments)
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+	if !json.Valid(arguments) {
+		return errorResult(fmt.Errorf("arguments are not valid json")), nil
+	}
+
+	timeout := tool.Timeout
+	if timeout <= 0 {
+		timeout = callTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := tool.Call(callCtx, arguments)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return errorResult(err), nil
+	}
+	if result == "" {
+		result = "{}"
+	}
+	if len(result) > maxResultLength {
+		result = result[:maxResultLength] + "...(truncated)"
+	}
+	return result, nil
+}
+
+func errorResult(err error) string {
+	b, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(b)
+}
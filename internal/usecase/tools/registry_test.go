@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+)
+
+func newTestRegistry(t *testing.T, names ...string) *Registry {
+	t.Helper()
+	r := NewRegistry(0)
+	for _, name := range names {
+		err := r.Register(Tool{
+			Name: name,
+			Call: func(ctx context.Context, arguments json.RawMessage) (string, error) { return "ok", nil },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func toolNames(r *Registry) []string {
+	var names []string
+	for _, tool := range r.Tools() {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestForProfileWithoutDefaultDeniesEverything(t *testing.T) {
+	r := newTestRegistry(t, "current_time", "files_read", "files_write")
+
+	res, err := r.ForProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Len() != 0 {
+		t.Fatalf("expected no tools without a default profile, got %v", toolNames(res))
+	}
+}
+
+func TestForProfileFiltersTools(t *testing.T) {
+	r := newTestRegistry(t, "current_time", "files_read", "files_write")
+	if err := r.SetProfile(DefaultProfile, []string{"current_time"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetProfile("files", []string{"files_*"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := r.ForProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := toolNames(res); len(names) != 1 || names[0] != "current_time" {
+		t.Fatalf("expected the default profile, got %v", names)
+	}
+	res, err = r.ForProfile("files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := toolNames(res); len(names) != 2 || names[0] != "files_read" || names[1] != "files_write" {
+		t.Fatalf("expected the files tools, got %v", names)
+	}
+	if _, err := r.ForProfile("unknown"); !errors.Is(err, entity.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for an unknown profile, got %v", err)
+	}
+}
+
+func TestForProfileNilRegistry(t *testing.T) {
+	var r *Registry
+	res, err := r.ForProfile("anything")
+	if err != nil || res.Len() != 0 {
+		t.Fatalf("expected no tools without a registry, got %v %v", res, err)
+	}
+}
+
+func TestRegisterValidatesTools(t *testing.T) {
+	call := func(ctx context.Context, arguments json.RawMessage) (string, error) { return "ok", nil }
+	tests := []struct {
+		name string
+		tool Tool
+	}{
+		{"invalid name", Tool{Name: "current time", Call: call}},
+		{"empty name", Tool{Name: "", Call: call}},
+		{"no function", Tool{Name: "current_time"}},
+		{"invalid schema", Tool{Name: "current_time", Call: call, Parameters: json.RawMessage(`[1]`)}},
+		{"duplicated", Tool{Name: "files_read", Call: call}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, "files_read")
+			if err := r.Register(tt.tool); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	//sem parametros o schema vazio é enviado ao modelo
+	r := newTestRegistry(t, "current_time")
+	if params := string(r.Tools()[0].Parameters); params != `{"type":"object","properties":{}}` {
+		t.Fatalf("expected the empty schema, got %s", params)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	r := NewRegistry(0)
+	tools := []Tool{
+		{Name: "echo", Call: func(ctx context.Context, arguments json.RawMessage) (string, error) { return string(arguments), nil }},
+		{Name: "empty", Call: func(ctx context.Context, arguments json.RawMessage) (string, error) { return "", nil }},
+		{Name: "fails", Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			return "", errors.New("file not found")
+		}},
+		{Name: "large", Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			return strings.Repeat("x", maxResultLength+10), nil
+		}},
+		{Name: "slow", Timeout: 10 * time.Millisecond, Call: func(ctx context.Context, arguments json.RawMessage) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	}
+	for _, tool := range tools {
+		if err := r.Register(tool); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		call      entity.ToolCall
+		want      string
+		wantError string // erro devolvido ao modelo no resultado
+	}{
+		{"result", entity.ToolCall{Name: "echo", Arguments: `{"a":1}`}, `{"a":1}`, ""},
+		{"empty arguments", entity.ToolCall{Name: "echo"}, `{}`, ""},
+		{"empty result", entity.ToolCall{Name: "empty"}, `{}`, ""},
+		{"unknown tool", entity.ToolCall{Name: "missing"}, "", `unknown tool "missing"`},
+		{"invalid arguments", entity.ToolCall{Name: "echo", Arguments: `{"a":`}, "", "arguments are not valid json"},
+		{"tool error", entity.ToolCall{Name: "fails"}, "", "file not found"},
+		{"timeout", entity.ToolCall{Name: "slow"}, "", context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.Execute(context.Background(), tt.call)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantError != "" {
+				var res map[string]string
+				if err := json.Unmarshal([]byte(result), &res); err != nil || res["error"] != tt.wantError {
+					t.Fatalf("expected the error %q in the result, got %s", tt.wantError, result)
+				}
+				return
+			}
+			if result != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, result)
+			}
+		})
+	}
+
+	result, err := r.Execute(context.Background(), entity.ToolCall{Name: "large"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != maxResultLength+len("...(truncated)") || !strings.HasSuffix(result, "...(truncated)") {
+		t.Fatalf("expected the result truncated, got %d bytes", len(result))
+	}
+
+	//o cancelamento da requisicao é retornado, nao vai para o modelo
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Execute(ctx, entity.ToolCall{Name: "slow"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package userdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// formatos do arquivo exportado
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatZip      = "zip" // user_data.json e user_data.md
+)
+
+// ContentTypes content type http de cada formato
+var ContentTypes = map[string]string{
+	FormatJSON:     "application/json",
+	FormatMarkdown: "text/markdown; charset=utf-8",
+	FormatZip:      "application/zip",
+}
+
+// Encode arquivo da exportacao no formato pedido, vazio usa json
+func Encode(output *ExportUserDataOutputDTO, format string) ([]byte, error) {
+	switch format {
+	case "", FormatJSON:
+		return json.MarshalIndent(output, "", "  ")
+	case FormatMarkdown:
+		return Markdown(output), nil
+	case FormatZip:
+		return archive(output)
+	}
+	return nil, fmt.Errorf("unknown export format %q, use json, markdown or zip", format)
+}
+
+func archive(output *ExportUserDataOutputDTO) ([]byte, error) {
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"user_data.json", data},
+		{"user_data.md", Markdown(output)},
+	}
+	for _, file := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: output.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Markdown exportacao legivel para entregar ao usuario, com as conversas na ordem
+func Markdown(output *ExportUserDataOutputDTO) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Data export for user %s\n\n", output.UserID)
+	fmt.Fprintf(&b, "Exported at %s.\n\n", output.ExportedAt.Format(time.RFC3339))
+	if output.TenantID != "" {
+		fmt.Fprintf(&b, "- Tenant: %s\n", output.TenantID)
+	}
+	fmt.Fprintf(&b, "- Chats: %d\n- Messages: %d\n- Tokens used: %d\n", output.Usage.Chats, output.Usage.Messages, output.Usage.Tokens)
+
+	for _, chat := range output.Chats {
+		fmt.Fprintf(&b, "\n## Chat %s\n\n", chat.ID)
+		fmt.Fprintf(&b, "- Status: %s\n- Model: %s\n- Tokens used: %d\n", chat.Status, chat.Model, chat.TokenUsage)
+		if chat.TenantID != "" {
+			fmt.Fprintf(&b, "- Tenant: %s\n", chat.TenantID)
+		}
+		if chat.AssistantID != "" {
+			fmt.Fprintf(&b, "- Assistant: %s\n", chat.AssistantID)
+		}
+		if len(chat.SharedWith) > 0 {
+			fmt.Fprintf(&b, "- Shared with: %s\n", strings.Join(chat.SharedWith, ", "))
+		}
+		fmt.Fprintf(&b, "- Created at: %s\n- Updated at: %s\n", chat.CreatedAt.Format(time.RFC3339), chat.UpdatedAt.Format(time.RFC3339))
+		for _, msg := range chat.Messages {
+			fmt.Fprintf(&b, "\n### %s · %s", msg.Role, msg.CreatedAt.Format(time.RFC3339))
+			if msg.Erased {
+				b.WriteString(" · erased")
+			}
+			b.WriteString("\n\n")
+			if msg.Content != "" {
+				b.WriteString(msg.Content)
+				b.WriteString("\n")
+			}
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&b, "\nTool call `%s`: `%s`\n", call.Name, call.Arguments)
+			}
+		}
+	}
+
+	if len(output.SharedChats) > 0 {
+		b.WriteString("\n## Chats shared with the user\n\n")
+		for _, share := range output.SharedChats {
+			fmt.Fprintf(&b, "- %s, shared at %s\n", share.ChatID, share.SharedAt.Format(time.RFC3339))
+		}
+	}
+	if len(output.APIKeys) > 0 {
+		b.WriteString("\n## API keys\n\n")
+		for _, key := range output.APIKeys {
+			status := "active"
+			if key.RevokedAt != nil {
+				status = "revoked"
+			}
+			fmt.Fprintf(&b, "- %s (%s…), scopes %s, created at %s, %s\n", key.Name, key.Prefix, strings.Join(key.Scopes, ", "), key.CreatedAt.Format(time.RFC3339), status)
+		}
+	}
+	return []byte(b.String())
+}
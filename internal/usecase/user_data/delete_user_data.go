@@ -0,0 +1,72 @@
+package userdata
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+type DeleteUserDataInputDTO struct {
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"` //vazio é o tenant padrao, chamador de um tenant so acessa o proprio
+}
+
+type DeleteUserDataOutputDTO struct {
+	UserID          string `json:"user_id"`
+	TenantID        string `json:"tenant_id,omitempty"`
+	ChatsDeleted    int    `json:"chats_deleted"`
+	MessagesDeleted int    `json:"messages_deleted"`
+	SharesDeleted   int    `json:"shares_deleted"`
+	APIKeysDeleted  int    `json:"api_keys_deleted"`
+}
+
+type DeleteUserDataUseCase struct {
+	UserDataGateway gateway.UserDataGateway
+	Cache           *apikey.Cache // opcional, cache da autenticacao deste processo, limpo na hora
+	Audit           *audit.Logger // opcional
+}
+
+func NewDeleteUserDataUseCase(userDataGateway gateway.UserDataGateway, cache *apikey.Cache, auditLogger *audit.Logger) *DeleteUserDataUseCase {
+	return &DeleteUserDataUseCase{
+		UserDataGateway: userDataGateway,
+		Cache:           cache,
+		Audit:           auditLogger,
+	}
+}
+
+// Execute apaga os dados do usuario no tenant e registra a exclusao, somente com as quantidades, no log de auditoria.
+// Os eventos de auditoria do usuario ficam (o log nao pode ser alterado sem quebrar a cadeia de hashes),
+// eles guardam somente ids e hashes das msgs, o conteudo sai com os chats
+func (uc *DeleteUserDataUseCase) Execute(ctx context.Context, input DeleteUserDataInputDTO) (*DeleteUserDataOutputDTO, error) {
+	if err := validateUserID(input.UserID); err != nil {
+		return nil, err
+	}
+	ctx, err := tenantContext(ctx, input.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	deletion, err := uc.UserDataGateway.DeleteUserData(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting user data: %w", err)
+	}
+	for _, keyID := range deletion.APIKeyIDs {
+		uc.Cache.Forget(keyID)
+	}
+
+	output := &DeleteUserDataOutputDTO{
+		UserID:          input.UserID,
+		TenantID:        entity.TenantID(ctx),
+		ChatsDeleted:    deletion.Chats,
+		MessagesDeleted: deletion.Messages,
+		SharesDeleted:   deletion.Shares,
+		APIKeysDeleted:  len(deletion.APIKeyIDs),
+	}
+	if err := uc.Audit.Record(ctx, entity.AuditPrivacy, "user.delete", input.UserID, output); err != nil {
+		return output, fmt.Errorf("user data deleted but the audit entry failed: %w", err)
+	}
+	return output, nil
+}
@@ -0,0 +1,52 @@
+package userdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+func TestDeleteUserData(t *testing.T) {
+	gateway := &fakeUserData{deletion: &entity.UserDataDeletion{Chats: 2, Messages: 9, Shares: 1, APIKeyIDs: []string{"key-1", "key-2"}}}
+	log := &memoryAudit{}
+	ctx := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "acme"})
+	output, err := NewDeleteUserDataUseCase(gateway, nil, audit.NewLogger(log)).Execute(ctx, DeleteUserDataInputDTO{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DeleteUserDataOutputDTO{UserID: "alice", TenantID: "acme", ChatsDeleted: 2, MessagesDeleted: 9, SharesDeleted: 1, APIKeysDeleted: 2}
+	if *output != want {
+		t.Fatalf("expected %+v, got %+v", want, *output)
+	}
+	if len(log.events) != 1 || log.events[0].Action != "user.delete" || log.events[0].Resource != "alice" || log.events[0].TenantID != "acme" {
+		t.Fatalf("expected the deletion audited, got %+v", log.events)
+	}
+	if !bytes.Contains(log.events[0].Data, []byte(`"messages_deleted":9`)) {
+		t.Fatalf("expected the counts in the audit entry, got %s", log.events[0].Data)
+	}
+}
+
+func TestDeleteUserDataErrors(t *testing.T) {
+	//falha no banco nao grava auditoria
+	log := &memoryAudit{}
+	gateway := &fakeUserData{err: errors.New("connection lost")}
+	if _, err := NewDeleteUserDataUseCase(gateway, nil, audit.NewLogger(log)).Execute(context.Background(), DeleteUserDataInputDTO{UserID: "alice"}); err == nil || len(log.events) != 0 {
+		t.Fatalf("expected the gateway error without an audit entry, got %v %v", err, log.events)
+	}
+
+	//dados ja apagados, a falha da auditoria volta com a saida
+	log = &memoryAudit{err: errors.New("audit sink down")}
+	gateway = &fakeUserData{deletion: &entity.UserDataDeletion{Chats: 1}}
+	output, err := NewDeleteUserDataUseCase(gateway, nil, audit.NewLogger(log)).Execute(context.Background(), DeleteUserDataInputDTO{UserID: "alice"})
+	if err == nil || output == nil || output.ChatsDeleted != 1 {
+		t.Fatalf("expected the deletion output with the audit error, got %+v %v", output, err)
+	}
+
+	if _, err := NewDeleteUserDataUseCase(&fakeUserData{}, nil, nil).Execute(context.Background(), DeleteUserDataInputDTO{}); !errors.Is(err, entity.ErrInvalidUser) {
+		t.Fatalf("expected ErrInvalidUser, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package userdata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/domain/gateway"
+	apikey "github.com/ruhancs/virtual-assistant/internal/usecase/api_key"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+type ExportUserDataInputDTO struct {
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"` //vazio é o tenant padrao, chamador de um tenant so acessa o proprio
+}
+
+type MessageExportDTO struct {
+	ID         string                     `json:"id"`
+	Role       string                     `json:"role"`
+	Content    string                     `json:"content"`
+	Tokens     int                        `json:"tokens"`
+	Model      string                     `json:"model,omitempty"`
+	Erased     bool                       `json:"erased"` //fora do contexto enviado ao modelo
+	ToolCalls  []entity.ToolCall          `json:"tool_calls,omitempty"`
+	ToolCallID string                     `json:"tool_call_id,omitempty"`
+	Name       string                     `json:"name,omitempty"`
+	Moderation []entity.ModerationVerdict `json:"moderation,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+}
+
+type ChatExportDTO struct {
+	ID              string             `json:"id"`
+	TenantID        string             `json:"tenant_id,omitempty"`
+	AssistantID     string             `json:"assistant_id,omitempty"`
+	Status          string             `json:"status"`
+	Model           string             `json:"model"`
+	TokenUsage      int                `json:"token_usage"`
+	PromptTemplate  string             `json:"prompt_template,omitempty"`
+	TemplateVersion int                `json:"template_version,omitempty"`
+	SharedWith      []string           `json:"shared_with,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	Messages        []MessageExportDTO `json:"messages"` //ativas e apagadas, na ordem do chat
+}
+
+// SharedChatExportDTO chat de outro usuario compartilhado com o usuario, o conteudo pertence ao dono
+type SharedChatExportDTO struct {
+	ChatID   string    `json:"chat_id"`
+	TenantID string    `json:"tenant_id,omitempty"`
+	SharedAt time.Time `json:"shared_at"`
+}
+
+type UsageExportDTO struct {
+	Chats    int `json:"chats"`
+	Messages int `json:"messages"`
+	Tokens   int `json:"tokens"`
+}
+
+// ExportUserDataOutputDTO arquivo com os dados do usuario. Feedback nao é guardado pelo servico, por isso nao aparece
+type ExportUserDataOutputDTO struct {
+	UserID      string                   `json:"user_id"`
+	TenantID    string                   `json:"tenant_id,omitempty"`
+	ExportedAt  time.Time                `json:"exported_at"`
+	Usage       UsageExportDTO           `json:"usage"`
+	Chats       []ChatExportDTO          `json:"chats"`
+	SharedChats []SharedChatExportDTO    `json:"shared_chats"`
+	APIKeys     []apikey.APIKeyOutputDTO `json:"api_keys"` //sem o hash da chave
+}
+
+type ExportUserDataUseCase struct {
+	UserDataGateway gateway.UserDataGateway
+	Audit           *audit.Logger // opcional
+}
+
+func NewExportUserDataUseCase(userDataGateway gateway.UserDataGateway, auditLogger *audit.Logger) *ExportUserDataUseCase {
+	return &ExportUserDataUseCase{
+		UserDataGateway: userDataGateway,
+		Audit:           auditLogger,
+	}
+}
+
+// Execute junta os dados do usuario no tenant e registra a exportacao no log de auditoria
+func (uc *ExportUserDataUseCase) Execute(ctx context.Context, input ExportUserDataInputDTO) (*ExportUserDataOutputDTO, error) {
+	if err := validateUserID(input.UserID); err != nil {
+		return nil, err
+	}
+	ctx, err := tenantContext(ctx, input.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := uc.UserDataGateway.FindUserData(ctx, input.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding user data: %w", err)
+	}
+
+	output := &ExportUserDataOutputDTO{
+		UserID:      data.UserID,
+		TenantID:    data.TenantID,
+		ExportedAt:  time.Now().UTC(),
+		Chats:       []ChatExportDTO{},
+		SharedChats: []SharedChatExportDTO{},
+		APIKeys:     []apikey.APIKeyOutputDTO{},
+	}
+	for _, chat := range data.Chats {
+		exported := chatExport(chat)
+		output.Usage.Chats++
+		output.Usage.Messages += len(exported.Messages)
+		output.Usage.Tokens += chat.TokenUsage
+		output.Chats = append(output.Chats, exported)
+	}
+	for _, share := range data.SharedChats {
+		output.SharedChats = append(output.SharedChats, SharedChatExportDTO{
+			ChatID:   share.ChatID,
+			TenantID: share.TenantID,
+			SharedAt: share.CreatedAt,
+		})
+	}
+	for _, key := range data.APIKeys {
+		output.APIKeys = append(output.APIKeys, apikey.APIKeyOutputDTO{
+			ID:         key.ID,
+			UserID:     key.UserID,
+			TenantID:   key.TenantID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     key.Scopes,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			RevokedAt:  key.RevokedAt,
+			CreatedAt:  key.CreatedAt,
+		})
+	}
+
+	if err := uc.Audit.Record(ctx, entity.AuditPrivacy, "user.export", input.UserID, output.Usage); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+func chatExport(chat *entity.Chat) ChatExportDTO {
+	exported := ChatExportDTO{
+		ID:              chat.ID,
+		TenantID:        chat.TenantID,
+		AssistantID:     chat.AssistantID,
+		Status:          chat.Status,
+		Model:           chat.Config.Model.Name,
+		TokenUsage:      chat.TokenUsage,
+		PromptTemplate:  chat.PromptTemplate,
+		TemplateVersion: chat.TemplateVersion,
+		SharedWith:      chat.SharedWith,
+		CreatedAt:       chat.CreatedAt,
+		UpdatedAt:       chat.UpdatedAt,
+		Messages:        []MessageExportDTO{},
+	}
+	//as apagadas sairam do contexto antes das ativas, no mesmo segundo a ordem fica a da lista
+	for _, msg := range chat.ErasedMessages {
+		exported.Messages = append(exported.Messages, messageExport(msg, true))
+	}
+	for _, msg := range chat.Messages {
+		exported.Messages = append(exported.Messages, messageExport(msg, false))
+	}
+	sort.SliceStable(exported.Messages, func(i, j int) bool {
+		return exported.Messages[i].CreatedAt.Before(exported.Messages[j].CreatedAt)
+	})
+	return exported
+}
+
+func messageExport(msg *entity.Message, erased bool) MessageExportDTO {
+	exported := MessageExportDTO{
+		ID:         msg.ID,
+		Role:       msg.Role,
+		Content:    msg.Content,
+		Tokens:     msg.Tokens,
+		Erased:     erased,
+		ToolCalls:  msg.ToolCalls,
+		ToolCallID: msg.ToolCallID,
+		Name:       msg.Name,
+		Moderation: msg.Moderation,
+		CreatedAt:  msg.CreatedAt,
+	}
+	if msg.Model != nil {
+		exported.Model = msg.Model.Name
+	}
+	return exported
+}
+
+func validateUserID(userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return fmt.Errorf("%w: user id is empty", entity.ErrInvalidUser)
+	}
+	return nil
+}
+
+// tenantContext contexto com o tenant dos dados pedidos. O chamador autenticado por um tenant fica no proprio tenant,
+// sem tenant no contexto (administracao) vale o tenant do input
+func tenantContext(ctx context.Context, tenantID string) (context.Context, error) {
+	if current := entity.TenantFromContext(ctx); current != nil {
+		if tenantID != "" && tenantID != current.ID {
+			return nil, fmt.Errorf("%w: tenant %s belongs to another caller", entity.ErrForbidden, tenantID)
+		}
+		return ctx, nil
+	}
+	if tenantID == "" {
+		return ctx, nil
+	}
+	return entity.ContextWithTenant(ctx, &entity.Tenant{ID: tenantID}), nil
+}
@@ -0,0 +1,231 @@
+package userdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruhancs/virtual-assistant/internal/domain/entity"
+	"github.com/ruhancs/virtual-assistant/internal/usecase/audit"
+)
+
+// fakeUserData gateway em memoria, guarda o tenant do contexto de cada chamada
+type fakeUserData struct {
+	data     *entity.UserData
+	deletion *entity.UserDataDeletion
+	err      error
+	tenant   string
+	calls    int
+}
+
+func (f *fakeUserData) FindUserData(ctx context.Context, userID string) (*entity.UserData, error) {
+	f.calls++
+	f.tenant = entity.TenantID(ctx)
+	return f.data, f.err
+}
+
+func (f *fakeUserData) DeleteUserData(ctx context.Context, userID string) (*entity.UserDataDeletion, error) {
+	f.calls++
+	f.tenant = entity.TenantID(ctx)
+	return f.deletion, f.err
+}
+
+// memoryAudit guarda os eventos gravados, sem encadear
+type memoryAudit struct {
+	events []*entity.AuditEvent
+	err    error
+}
+
+func (m *memoryAudit) AppendAuditEvent(ctx context.Context, event *entity.AuditEvent) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *memoryAudit) ListAuditEvents(ctx context.Context, filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	return m.events, nil
+}
+
+func testUserData() *entity.UserData {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	model := &entity.Model{Name: "gpt-4o"}
+	msg := func(id, role, content string, minutes int) *entity.Message {
+		return &entity.Message{ID: id, Role: role, Content: content, Tokens: 3, Model: model, CreatedAt: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	return &entity.UserData{
+		UserID:   "alice",
+		TenantID: "acme",
+		Chats: []*entity.Chat{
+			{
+				ID:             "chat-1",
+				UserID:         "alice",
+				TenantID:       "acme",
+				Status:         "active",
+				TokenUsage:     30,
+				Config:         &entity.ChatConfig{Model: model},
+				SharedWith:     []string{"bob"},
+				ErasedMessages: []*entity.Message{msg("m1", "user", "first question", 1), msg("m2", "assistant", "first answer", 2)},
+				Messages:       []*entity.Message{msg("m3", "user", "second question", 3), msg("m4", "assistant", "second answer", 4)},
+				CreatedAt:      base,
+				UpdatedAt:      base.Add(4 * time.Minute),
+			},
+			{
+				ID:         "chat-2",
+				UserID:     "alice",
+				TenantID:   "acme",
+				Status:     "ended",
+				TokenUsage: 12,
+				Config:     &entity.ChatConfig{Model: model},
+				Messages:   []*entity.Message{msg("m5", "user", "hello", 10)},
+				CreatedAt:  base.Add(10 * time.Minute),
+				UpdatedAt:  base.Add(10 * time.Minute),
+			},
+		},
+		SharedChats: []entity.ChatShare{{ChatID: "chat-9", TenantID: "acme", CreatedAt: base}},
+		APIKeys:     []*entity.APIKey{{ID: "key-1", UserID: "alice", TenantID: "acme", Name: "cli", Prefix: "va_1234", KeyHash: "secret-hash", Scopes: []string{"chat"}, CreatedAt: base}},
+	}
+}
+
+func TestExportUserData(t *testing.T) {
+	gateway := &fakeUserData{data: testUserData()}
+	log := &memoryAudit{}
+	output, err := NewExportUserDataUseCase(gateway, audit.NewLogger(log)).Execute(context.Background(), ExportUserDataInputDTO{UserID: "alice", TenantID: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gateway.tenant != "acme" {
+		t.Fatalf("expected the data read in tenant acme, got %q", gateway.tenant)
+	}
+	if output.Usage != (UsageExportDTO{Chats: 2, Messages: 5, Tokens: 42}) {
+		t.Fatalf("unexpected usage %+v", output.Usage)
+	}
+	//as apagadas entram na ordem do chat, antes das ativas
+	var ids []string
+	for _, msg := range output.Chats[0].Messages {
+		ids = append(ids, msg.ID)
+		if msg.Erased != (msg.ID == "m1" || msg.ID == "m2") {
+			t.Fatalf("unexpected erased flag on %s", msg.ID)
+		}
+	}
+	if strings.Join(ids, ",") != "m1,m2,m3,m4" {
+		t.Fatalf("expected the messages in chat order, got %v", ids)
+	}
+	if len(output.SharedChats) != 1 || len(output.APIKeys) != 1 || output.Chats[0].Model != "gpt-4o" {
+		t.Fatalf("unexpected export %+v", output)
+	}
+
+	//o hash da chave de api nunca sai na exportacao
+	data, err := Encode(output, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret-hash")) {
+		t.Fatal("expected the api key hash left out of the export")
+	}
+
+	if len(log.events) != 1 || log.events[0].Type != entity.AuditPrivacy || log.events[0].Action != "user.export" || log.events[0].Resource != "alice" || log.events[0].TenantID != "acme" {
+		t.Fatalf("expected the export audited, got %+v", log.events)
+	}
+	if bytes.Contains(log.events[0].Data, []byte("question")) {
+		t.Fatalf("expected only counts in the audit entry, got %s", log.events[0].Data)
+	}
+}
+
+func TestExportUserDataTenantScope(t *testing.T) {
+	callerCtx := entity.ContextWithTenant(context.Background(), &entity.Tenant{ID: "acme"})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		tenant  string
+		want    string // tenant usado na consulta
+		wantErr error
+	}{
+		{"admin picks the tenant", context.Background(), "acme", "acme", nil},
+		{"admin default tenant", context.Background(), "", "", nil},
+		{"caller own tenant", callerCtx, "acme", "acme", nil},
+		{"caller without tenant in the input", callerCtx, "", "acme", nil},
+		{"caller another tenant", callerCtx, "globex", "", entity.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &fakeUserData{data: &entity.UserData{UserID: "alice"}, deletion: &entity.UserDataDeletion{}}
+			_, err := NewExportUserDataUseCase(gateway, nil).Execute(tt.ctx, ExportUserDataInputDTO{UserID: "alice", TenantID: tt.tenant})
+			_, deleteErr := NewDeleteUserDataUseCase(gateway, nil, nil).Execute(tt.ctx, DeleteUserDataInputDTO{UserID: "alice", TenantID: tt.tenant})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !errors.Is(deleteErr, tt.wantErr) || gateway.calls != 0 {
+					t.Fatalf("expected %v without reading the data, got %v %v", tt.wantErr, err, deleteErr)
+				}
+				return
+			}
+			if err != nil || deleteErr != nil {
+				t.Fatal(err, deleteErr)
+			}
+			if gateway.tenant != tt.want {
+				t.Fatalf("expected tenant %q, got %q", tt.want, gateway.tenant)
+			}
+		})
+	}
+
+	if _, err := NewExportUserDataUseCase(&fakeUserData{}, nil).Execute(context.Background(), ExportUserDataInputDTO{UserID: " "}); !errors.Is(err, entity.ErrInvalidUser) {
+		t.Fatalf("expected ErrInvalidUser, got %v", err)
+	}
+}
+
+func TestEncodeFormats(t *testing.T) {
+	output, err := NewExportUserDataUseCase(&fakeUserData{data: testUserData()}, nil).Execute(context.Background(), ExportUserDataInputDTO{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Encode(output, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ExportUserDataOutputDTO
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.UserID != "alice" || len(decoded.Chats) != 2 {
+		t.Fatalf("expected json by default, got %v", err)
+	}
+
+	markdown, err := Encode(output, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Data export for user alice", "## Chat chat-1", "· erased", "second answer", "## Chats shared with the user", "- cli (va_1234…)"} {
+		if !strings.Contains(string(markdown), want) {
+			t.Fatalf("expected %q in the markdown export", want)
+		}
+	}
+
+	archived, err := Encode(output, FormatZip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archived), int64(len(archived)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	if !bytes.Equal(files["user_data.json"], data) || !bytes.Equal(files["user_data.md"], markdown) {
+		t.Fatalf("expected the json and markdown exports in the zip, got %d files", len(files))
+	}
+
+	if _, err := Encode(output, "csv"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
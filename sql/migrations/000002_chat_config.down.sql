@@ -0,0 +1,26 @@
+ALTER TABLE `messages`
+    MODIFY tokens SMALLINT NOT NULL,
+    MODIFY model VARCHAR(20) NOT NULL,
+    MODIFY order_msg SMALLINT NOT NULL,
+    MODIFY created_at TIMESTAMP NOT NULL;
+
+ALTER TABLE `chats`
+    MODIFY token_usage SMALLINT NOT NULL,
+    MODIFY model VARCHAR(20) NOT NULL,
+    MODIFY model_max_tokens SMALLINT NOT NULL,
+    MODIFY temperature DECIMAL(3,2) NOT NULL,
+    MODIFY top_p DECIMAL(3,2) NOT NULL,
+    MODIFY n SMALLINT NOT NULL,
+    MODIFY max_tokens SMALLINT NOT NULL,
+    MODIFY presence_penalty DECIMAL(3,2) NOT NULL,
+    MODIFY frequency_penalty DECIMAL(3,2) NOT NULL;
+
+ALTER TABLE `chats` MODIFY initial_message_id TEXT NOT NULL;
+UPDATE `chats` c SET c.initial_message_id = COALESCE(
+    (SELECT m.content FROM `messages` m WHERE m.id = c.initial_message_id),
+    ''
+);
+
+ALTER TABLE `chats` MODIFY stop TEXT NOT NULL;
+UPDATE `chats` SET stop = LEFT(COALESCE(JSON_UNQUOTE(JSON_EXTRACT(stop, '$[0]')), ''), 20);
+ALTER TABLE `chats` MODIFY stop VARCHAR(20) NOT NULL;
@@ -0,0 +1,28 @@
+-- stop passa a ser uma lista (JSON) e initial_message_id guarda o id da mensagem de sistema
+-- (sem FK, SaveChat reescreve as mensagens do chat a cada turno)
+ALTER TABLE `chats` MODIFY stop TEXT NOT NULL;
+UPDATE `chats` SET stop = IF(stop = '', JSON_ARRAY(), JSON_ARRAY(stop));
+ALTER TABLE `chats` MODIFY stop JSON NOT NULL;
+
+UPDATE `chats` c SET c.initial_message_id = COALESCE(
+    (SELECT m.id FROM `messages` m WHERE m.chat_id = c.id AND m.role = 'system' ORDER BY m.erased DESC, m.order_msg ASC LIMIT 1),
+    ''
+);
+
+ALTER TABLE `chats`
+    MODIFY initial_message_id VARCHAR(36) NOT NULL,
+    MODIFY token_usage INT NOT NULL,
+    MODIFY model VARCHAR(64) NOT NULL,
+    MODIFY model_max_tokens INT NOT NULL,
+    MODIFY temperature DOUBLE NOT NULL,
+    MODIFY top_p DOUBLE NOT NULL,
+    MODIFY n INT NOT NULL,
+    MODIFY max_tokens INT NOT NULL,
+    MODIFY presence_penalty DOUBLE NOT NULL,
+    MODIFY frequency_penalty DOUBLE NOT NULL;
+
+ALTER TABLE `messages`
+    MODIFY tokens INT NOT NULL,
+    MODIFY model VARCHAR(64) NOT NULL,
+    MODIFY order_msg INT NOT NULL,
+    MODIFY created_at TIMESTAMP(6) NOT NULL;
@@ -0,0 +1,8 @@
+DROP INDEX idx_chat_shares_user ON `chat_shares`;
+DROP INDEX idx_messages_chat_order ON `messages`;
+DROP INDEX idx_chats_user_updated ON `chats`;
+
+UPDATE `messages` m
+    JOIN (SELECT chat_id, COUNT(*) AS erased_count FROM `messages` WHERE erased = 1 GROUP BY chat_id) e ON e.chat_id = m.chat_id
+    SET m.order_msg = m.order_msg - e.erased_count
+    WHERE m.erased = 0;
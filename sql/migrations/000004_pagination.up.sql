@@ -0,0 +1,10 @@
+-- order_msg passa a ser a posicao da msg no historico completo do chat (apagadas + ativas),
+-- assim a posicao nao muda quando a msg sai do contexto e pode ser usada como cursor
+UPDATE `messages` m
+    JOIN (SELECT chat_id, COUNT(*) AS erased_count FROM `messages` WHERE erased = 1 GROUP BY chat_id) e ON e.chat_id = m.chat_id
+    SET m.order_msg = m.order_msg + e.erased_count
+    WHERE m.erased = 0;
+
+CREATE INDEX idx_chats_user_updated ON `chats` (user_id, updated_at, id);
+CREATE INDEX idx_messages_chat_order ON `messages` (chat_id, order_msg);
+CREATE INDEX idx_chat_shares_user ON `chat_shares` (user_id);
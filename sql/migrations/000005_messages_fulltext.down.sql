@@ -0,0 +1 @@
+DROP INDEX ft_messages_content ON `messages`;
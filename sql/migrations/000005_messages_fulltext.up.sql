@@ -0,0 +1 @@
+CREATE FULLTEXT INDEX ft_messages_content ON `messages` (content);
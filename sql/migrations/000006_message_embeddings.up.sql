@@ -0,0 +1,12 @@
+-- sem FK para messages, SaveChat apaga e reinsere as mensagens a cada turno
+CREATE TABLE IF NOT EXISTS `message_embeddings` (
+    message_id VARCHAR(36) NOT NULL,
+    chat_id VARCHAR(36) NOT NULL,
+    model VARCHAR(64) NOT NULL,
+    dimensions INT NOT NULL,
+    vector MEDIUMBLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (message_id, model),
+    INDEX idx_message_embeddings_chat (chat_id, model),
+    FOREIGN KEY (chat_id) REFERENCES chats (id) ON DELETE CASCADE
+);
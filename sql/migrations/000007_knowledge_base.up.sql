@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS `documents` (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    knowledge_base_id VARCHAR(64) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    source TEXT NOT NULL,
+    content_type VARCHAR(32) NOT NULL,
+    tokens INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    INDEX idx_documents_knowledge_base (knowledge_base_id)
+);
+
+CREATE TABLE IF NOT EXISTS `document_chunks` (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    document_id VARCHAR(36) NOT NULL,
+    knowledge_base_id VARCHAR(64) NOT NULL,
+    chunk_index INT NOT NULL,
+    content MEDIUMTEXT NOT NULL,
+    tokens INT NOT NULL,
+    start_offset INT NOT NULL,
+    end_offset INT NOT NULL,
+    model VARCHAR(64) NOT NULL,
+    dimensions INT NOT NULL,
+    vector MEDIUMBLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    INDEX idx_document_chunks_knowledge_base (knowledge_base_id, model),
+    FOREIGN KEY (document_id) REFERENCES documents (id) ON DELETE CASCADE
+);
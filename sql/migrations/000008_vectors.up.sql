@@ -0,0 +1,10 @@
+-- vector store em tabela do proprio mysql, a busca é feita na aplicacao sobre os vetores do namespace
+CREATE TABLE IF NOT EXISTS `vectors` (
+    namespace VARCHAR(128) NOT NULL,
+    id VARCHAR(64) NOT NULL,
+    dimensions INT NOT NULL,
+    vector MEDIUMBLOB NOT NULL,
+    metadata JSON NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (namespace, id)
+);
@@ -0,0 +1,5 @@
+DELETE FROM messages WHERE role IN ('tool', 'function');
+ALTER TABLE messages
+    DROP COLUMN tool_calls,
+    DROP COLUMN tool_call_id,
+    DROP COLUMN name;
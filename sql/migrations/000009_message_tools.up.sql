@@ -0,0 +1,5 @@
+-- chamadas de ferramentas feitas pelo assistente e os resultados enviados de volta ao modelo
+ALTER TABLE messages
+    ADD COLUMN tool_calls JSON NULL,
+    ADD COLUMN tool_call_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN name VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+ALTER TABLE `chats`
+    DROP COLUMN prompt_template,
+    DROP COLUMN template_version;
+
+DROP TABLE IF EXISTS `prompt_templates`;
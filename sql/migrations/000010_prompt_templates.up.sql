@@ -0,0 +1,16 @@
+-- templates versionados da msg inicial de sistema, somente uma versao ativa por nome
+CREATE TABLE IF NOT EXISTS `prompt_templates` (
+    name VARCHAR(64) NOT NULL,
+    version INT NOT NULL,
+    content TEXT NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    defaults JSON NOT NULL,
+    active BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (name, version)
+);
+
+-- template e versao usados para criar a msg inicial de cada chat
+ALTER TABLE `chats`
+    ADD COLUMN prompt_template VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN template_version INT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE `chats`
+    DROP COLUMN assistant_id;
+
+DROP TABLE IF EXISTS `assistants`;
@@ -0,0 +1,26 @@
+-- assistentes hospedados pelo servico, cada um com a propria config de modelo
+CREATE TABLE IF NOT EXISTS `assistants` (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    model VARCHAR(64) NOT NULL,
+    model_max_tokens INT NOT NULL,
+    temperature DOUBLE NOT NULL,
+    top_p DOUBLE NOT NULL,
+    n INT NOT NULL,
+    stop JSON NOT NULL,
+    max_tokens INT NOT NULL,
+    presence_penalty DOUBLE NOT NULL,
+    frequency_penalty DOUBLE NOT NULL,
+    system_prompt TEXT NOT NULL,
+    prompt_template VARCHAR(64) NOT NULL,
+    tool_profile VARCHAR(64) NOT NULL,
+    knowledge_base_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE KEY assistants_name (name)
+);
+
+-- assistente que atende o chat, vazio nos chats criados com a config do servico
+ALTER TABLE `chats`
+    ADD COLUMN assistant_id VARCHAR(36) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX idx_chats_tenant_user ON `chats`;
+
+ALTER TABLE `chats`
+    DROP COLUMN tenant_id;
+
+DROP TABLE IF EXISTS `tenant_usage`;
+DROP TABLE IF EXISTS `tenants`;
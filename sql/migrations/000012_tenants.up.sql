@@ -0,0 +1,27 @@
+-- empresas clientes atendidas pela instalacao, cada uma com o proprio token de api
+CREATE TABLE IF NOT EXISTS `tenants` (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    default_assistant_id VARCHAR(36) NOT NULL,
+    allowed_models JSON NOT NULL,
+    monthly_token_quota INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE KEY tenants_token_hash (token_hash)
+);
+
+-- tokens usados por tenant em cada mes (YYYY-MM), base da cota mensal
+CREATE TABLE IF NOT EXISTS `tenant_usage` (
+    tenant_id VARCHAR(36) NOT NULL,
+    period CHAR(7) NOT NULL,
+    tokens BIGINT NOT NULL,
+    PRIMARY KEY (tenant_id, period),
+    FOREIGN KEY (tenant_id) REFERENCES tenants (id) ON DELETE CASCADE
+);
+
+-- chats existentes ficam no tenant padrao (vazio)
+ALTER TABLE `chats`
+    ADD COLUMN tenant_id VARCHAR(36) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_chats_tenant_user ON `chats` (tenant_id, user_id, updated_at, id);
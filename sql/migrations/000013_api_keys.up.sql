@@ -0,0 +1,16 @@
+-- chaves de api dos usuarios, guardadas somente como hash
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(12) NOT NULL,
+    key_hash CHAR(64) NOT NULL,
+    scopes JSON NOT NULL,
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE KEY api_keys_key_hash (key_hash),
+    INDEX idx_api_keys_user (user_id, created_at)
+);
@@ -0,0 +1,3 @@
+-- veredictos das politicas de moderacao da msg do usuario e da resposta
+ALTER TABLE messages
+    ADD COLUMN moderation JSON NULL;
@@ -0,0 +1,18 @@
+-- log de auditoria encadeado por hash, somente insercao
+CREATE TABLE IF NOT EXISTS `audit_log` (
+    seq BIGINT NOT NULL PRIMARY KEY,
+    id VARCHAR(36) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(36) NOT NULL,
+    request_id VARCHAR(128) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    resource VARCHAR(255) NOT NULL,
+    data MEDIUMTEXT NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    UNIQUE KEY audit_log_id (id),
+    INDEX idx_audit_log_type (type, seq),
+    INDEX idx_audit_log_created (created_at)
+);
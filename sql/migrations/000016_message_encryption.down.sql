@@ -0,0 +1,4 @@
+ALTER TABLE messages
+    DROP INDEX idx_messages_key_id,
+    DROP COLUMN key_id,
+    MODIFY content TEXT NOT NULL;
@@ -0,0 +1,6 @@
+-- chave mestra que cifrou o conteudo da msg, vazio quando o conteudo esta em texto puro.
+-- o conteudo cifrado vai em base64 e precisa de mais espaco que o texto
+ALTER TABLE messages
+    MODIFY content MEDIUMTEXT NOT NULL,
+    ADD COLUMN key_id VARCHAR(64) NOT NULL DEFAULT '',
+    ADD INDEX idx_messages_key_id (key_id, id);
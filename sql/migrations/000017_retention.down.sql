@@ -0,0 +1,2 @@
+DROP INDEX idx_messages_erased_created ON `messages`;
+DROP INDEX idx_chats_updated ON `chats`;
@@ -0,0 +1,3 @@
+-- varredura da retencao: chats por inatividade e msgs apagadas por idade
+CREATE INDEX idx_chats_updated ON `chats` (updated_at, id);
+CREATE INDEX idx_messages_erased_created ON `messages` (erased, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE `chats` DROP COLUMN tool_profile;
+ALTER TABLE `tenants` DROP COLUMN tool_profile;
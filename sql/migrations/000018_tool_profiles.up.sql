@@ -0,0 +1,7 @@
+-- perfil de ferramentas resolvido no servidor: o tenant define o padrao dos seus chats
+-- e cada chat guarda o perfil com que foi criado. Vazio usa o perfil default, sem ele nenhuma ferramenta
+ALTER TABLE `tenants`
+    ADD COLUMN tool_profile VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE `chats`
+    ADD COLUMN tool_profile VARCHAR(64) NOT NULL DEFAULT '';
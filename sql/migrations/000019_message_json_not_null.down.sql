@@ -0,0 +1,3 @@
+ALTER TABLE `messages`
+    MODIFY tool_calls JSON NULL,
+    MODIFY moderation JSON NULL;
@@ -0,0 +1,7 @@
+-- NULL nao cabe no json.RawMessage das queries, msgs sem chamadas de ferramentas ou veredictos guardam uma lista vazia
+UPDATE `messages` SET tool_calls = JSON_ARRAY() WHERE tool_calls IS NULL;
+UPDATE `messages` SET moderation = JSON_ARRAY() WHERE moderation IS NULL;
+
+ALTER TABLE `messages`
+    MODIFY tool_calls JSON NOT NULL,
+    MODIFY moderation JSON NOT NULL;
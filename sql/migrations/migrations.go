@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// arquivos .sql embutidos no binario, usados pelo comando migrate
+//
+//go:embed *.sql
+var FS embed.FS
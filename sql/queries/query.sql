@@ -1,25 +1,281 @@
 -- name: CreateChat :exec
 INSERT INTO chats 
-    (id, user_id, initial_message_id, status, token_usage, model, model_max_tokens,temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at)
-    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+    (id, user_id, initial_message_id, status, token_usage, model, model_max_tokens,temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, created_at, updated_at, prompt_template, template_version, assistant_id, tenant_id, tool_profile)
+    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
 
 -- name: AddMessage :exec
-INSERT INTO messages (id, chat_id, role, content, tokens, model, erased, order_msg, created_at) VALUES(?,?,?,?,?,?,?,?,?);
+INSERT INTO messages (id, chat_id, role, content, tokens, model, erased, order_msg, created_at, tool_calls, tool_call_id, name, moderation, key_id) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?);
 
 -- name: FindChatByID :one
-SELECT * FROM chats WHERE id = ?;
+SELECT * FROM chats WHERE id = ? AND tenant_id = ?;
 
 -- name: FindMessagesByChatID :many
-SELECT * FROM messages WHERE erased=0 and chat_id = ? order by order_msg asc;
+SELECT m.* FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 0 AND m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg ASC;
 
 -- name: FindErasedMessagesByChatID :many
-SELECT * FROM messages WHERE erased=1 and chat_id = ? order by order_msg asc;
+SELECT m.* FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 1 AND m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg ASC;
 
 -- name: SaveChat :exec
-UPDATE chats SET user_id = ?, initial_message_id = ?, status = ?, token_usage = ?, model = ?, model_max_tokens=?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, updated_at = ? WHERE id = ?;
+UPDATE chats SET user_id = ?, initial_message_id = ?, status = ?, token_usage = ?, model = ?, model_max_tokens=?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, updated_at = ? WHERE id = ? AND tenant_id = ?;
 
--- name: DeleteChatMessages :exec
-DELETE FROM messages WHERE chat_id = ?;
+-- name: FindMessageStatesByChatID :many
+SELECT m.id, m.erased, m.order_msg FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ?;
 
--- name: DeleteErasedChatMessages :exec
-DELETE FROM messages WHERE erased=1 and chat_id = ?;
\ No newline at end of file
+-- name: MarkMessageErased :exec
+UPDATE messages SET erased = 1 WHERE id = ? AND chat_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?);
+
+-- name: AddChatShare :exec
+INSERT INTO chat_shares (chat_id, user_id, created_at) VALUES(?,?,?);
+
+-- name: FindChatSharesByChatID :many
+SELECT s.user_id FROM chat_shares s
+    JOIN chats c ON c.id = s.chat_id
+WHERE s.chat_id = ? AND c.tenant_id = ?
+ORDER BY s.user_id ASC;
+
+-- name: DeleteChatShares :exec
+DELETE FROM chat_shares WHERE chat_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?);
+
+-- name: ListChatsByUserID :many
+SELECT * FROM chats
+WHERE tenant_id = sqlc.arg(tenant_id)
+    AND (user_id = sqlc.arg(user_id) OR id IN (SELECT chat_id FROM chat_shares WHERE chat_shares.user_id = sqlc.arg(user_id)))
+    AND (updated_at < sqlc.arg(updated_at) OR (updated_at = sqlc.arg(updated_at) AND id < sqlc.arg(id)))
+ORDER BY updated_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListMessagesByChatID :many
+SELECT m.* FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ? AND m.order_msg < ?
+ORDER BY m.order_msg DESC
+LIMIT ?;
+
+-- name: SearchMessages :many
+SELECT m.id, m.chat_id, m.role, m.content, m.key_id, m.erased, m.order_msg, m.created_at,
+    MATCH(m.content) AGAINST (sqlc.arg(query) IN NATURAL LANGUAGE MODE) AS score
+FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE MATCH(m.content) AGAINST (sqlc.arg(query) IN NATURAL LANGUAGE MODE)
+    AND c.tenant_id = sqlc.arg(tenant_id)
+    AND (c.user_id = sqlc.arg(user_id) OR c.id IN (SELECT chat_id FROM chat_shares WHERE chat_shares.user_id = sqlc.arg(user_id)))
+    AND (sqlc.arg(chat_id) = '' OR m.chat_id = sqlc.arg(chat_id))
+    AND (sqlc.arg(role) = '' OR m.role = sqlc.arg(role))
+    AND m.created_at >= sqlc.arg(created_from) AND m.created_at <= sqlc.arg(created_to)
+ORDER BY score DESC, m.created_at DESC, m.id DESC
+LIMIT ? OFFSET ?;
+
+-- name: AddMessageEmbedding :exec
+INSERT INTO message_embeddings (message_id, chat_id, model, dimensions, vector, created_at) VALUES(?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE dimensions = VALUES(dimensions), vector = VALUES(vector), created_at = VALUES(created_at);
+
+-- name: FindMessageEmbeddingsByChatID :many
+SELECT e.* FROM message_embeddings e
+    JOIN chats c ON c.id = e.chat_id
+WHERE e.chat_id = ? AND c.tenant_id = ? AND e.model = ?;
+
+-- name: CreateDocument :exec
+INSERT INTO documents (id, knowledge_base_id, title, source, content_type, tokens, created_at) VALUES(?,?,?,?,?,?,?);
+
+-- name: AddDocumentChunk :exec
+INSERT INTO document_chunks
+    (id, document_id, knowledge_base_id, chunk_index, content, tokens, start_offset, end_offset, model, dimensions, vector, created_at)
+    VALUES(?,?,?,?,?,?,?,?,?,?,?,?);
+
+-- name: FindDocumentByID :one
+SELECT * FROM documents WHERE id = ?;
+
+-- name: DeleteDocument :exec
+DELETE FROM documents WHERE id = ?;
+
+-- name: FindChunkByID :one
+SELECT * FROM document_chunks WHERE id = ?;
+
+-- name: FindChunksByDocumentID :many
+SELECT * FROM document_chunks WHERE document_id = ? ORDER BY chunk_index;
+
+-- name: UpsertVector :exec
+INSERT INTO vectors (namespace, id, dimensions, vector, metadata, updated_at) VALUES(?,?,?,?,?,?)
+ON DUPLICATE KEY UPDATE dimensions = VALUES(dimensions), vector = VALUES(vector), metadata = VALUES(metadata), updated_at = VALUES(updated_at);
+
+-- name: DeleteVector :exec
+DELETE FROM vectors WHERE namespace = ? AND id = ?;
+
+-- name: FindVectorsByNamespace :many
+SELECT * FROM vectors WHERE namespace = ?;
+
+-- name: FindLatestPromptTemplateVersion :one
+SELECT CAST(COALESCE(MAX(version), 0) AS SIGNED) FROM prompt_templates WHERE name = ? FOR UPDATE;
+
+-- name: CreatePromptTemplate :exec
+INSERT INTO prompt_templates (name, version, content, description, defaults, active, created_at) VALUES(?,?,?,?,?,?,?);
+
+-- name: DeactivatePromptTemplates :exec
+UPDATE prompt_templates SET active = 0 WHERE name = ?;
+
+-- name: ActivatePromptTemplateVersion :execrows
+UPDATE prompt_templates SET active = 1 WHERE name = ? AND version = ?;
+
+-- name: FindActivePromptTemplate :one
+SELECT * FROM prompt_templates WHERE name = ? AND active = 1;
+
+-- name: FindPromptTemplateVersion :one
+SELECT * FROM prompt_templates WHERE name = ? AND version = ?;
+
+-- name: ListActivePromptTemplates :many
+SELECT * FROM prompt_templates WHERE active = 1 ORDER BY name;
+
+-- name: ListPromptTemplateVersions :many
+SELECT * FROM prompt_templates WHERE name = ? ORDER BY version DESC;
+
+-- name: DeletePromptTemplate :execrows
+DELETE FROM prompt_templates WHERE name = ?;
+
+-- name: CreateAssistant :exec
+INSERT INTO assistants (id, name, description, model, model_max_tokens, temperature, top_p, n, stop, max_tokens, presence_penalty, frequency_penalty, system_prompt, prompt_template, tool_profile, knowledge_base_id, created_at, updated_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?);
+
+-- name: FindAssistantByID :one
+SELECT * FROM assistants WHERE id = ?;
+
+-- name: ListAssistants :many
+SELECT * FROM assistants ORDER BY name;
+
+-- name: SaveAssistant :exec
+UPDATE assistants SET name = ?, description = ?, model = ?, model_max_tokens = ?, temperature = ?, top_p = ?, n = ?, stop = ?, max_tokens = ?, presence_penalty = ?, frequency_penalty = ?, system_prompt = ?, prompt_template = ?, tool_profile = ?, knowledge_base_id = ?, updated_at = ? WHERE id = ?;
+
+-- name: DeleteAssistant :execrows
+DELETE FROM assistants WHERE id = ?;
+
+-- name: CreateTenant :exec
+INSERT INTO tenants (id, name, token_hash, default_assistant_id, allowed_models, monthly_token_quota, created_at, updated_at, tool_profile) VALUES(?,?,?,?,?,?,?,?,?);
+
+-- name: FindTenantByID :one
+SELECT * FROM tenants WHERE id = ?;
+
+-- name: FindTenantByTokenHash :one
+SELECT * FROM tenants WHERE token_hash = ?;
+
+-- name: ListTenants :many
+SELECT * FROM tenants ORDER BY name;
+
+-- name: SaveTenant :exec
+UPDATE tenants SET name = ?, default_assistant_id = ?, allowed_models = ?, monthly_token_quota = ?, tool_profile = ?, updated_at = ? WHERE id = ?;
+
+-- name: DeleteTenant :execrows
+DELETE FROM tenants WHERE id = ?;
+
+-- name: AddTenantUsage :exec
+INSERT INTO tenant_usage (tenant_id, period, tokens) VALUES(?,?,?)
+ON DUPLICATE KEY UPDATE tokens = tokens + VALUES(tokens);
+
+-- name: FindTenantUsage :one
+SELECT CAST(COALESCE(SUM(tokens), 0) AS SIGNED) FROM tenant_usage WHERE tenant_id = ? AND period = ?;
+
+-- name: CreateAPIKey :exec
+INSERT INTO api_keys (id, user_id, tenant_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at) VALUES(?,?,?,?,?,?,?,?,?,?,?);
+
+-- name: FindAPIKeyByHash :one
+SELECT * FROM api_keys WHERE key_hash = ?;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys ORDER BY created_at DESC;
+
+-- name: ListAPIKeysByUserID :many
+SELECT * FROM api_keys WHERE user_id = ? ORDER BY created_at DESC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys SET last_used_at = ? WHERE id = ?;
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_log (seq, id, type, user_id, tenant_id, request_id, action, resource, data, prev_hash, hash, created_at) VALUES(?,?,?,?,?,?,?,?,?,?,?,?);
+
+-- name: FindLastAuditEvent :one
+SELECT * FROM audit_log ORDER BY seq DESC LIMIT 1 FOR UPDATE;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_log
+WHERE seq > sqlc.arg(after_seq)
+    AND (sqlc.arg(type) = '' OR type = sqlc.arg(type))
+    AND created_at >= sqlc.arg(created_from) AND created_at <= sqlc.arg(created_to)
+ORDER BY seq
+LIMIT ?;
+
+-- name: ListMessagesForRotation :many
+SELECT id, content, tool_calls, key_id FROM messages WHERE key_id <> ? AND id > ? ORDER BY id LIMIT ?;
+
+-- name: UpdateMessageContent :execrows
+UPDATE messages SET content = ?, tool_calls = ?, key_id = ? WHERE id = ? AND key_id = ?;
+
+-- name: ListInactiveChats :many
+SELECT id, tenant_id, assistant_id, user_id, updated_at FROM chats
+WHERE updated_at < sqlc.arg(before)
+    AND (user_id <> '' OR sqlc.arg(include_anonymized) = TRUE)
+    AND (updated_at > sqlc.arg(after_updated_at) OR (updated_at = sqlc.arg(after_updated_at) AND id > sqlc.arg(after_id)))
+ORDER BY updated_at, id
+LIMIT ?;
+
+-- name: DeleteInactiveChat :execrows
+DELETE FROM chats WHERE id = ? AND updated_at = ?;
+
+-- name: AnonymizeChat :execrows
+UPDATE chats SET user_id = '', status = 'ended' WHERE id = ? AND updated_at = ?;
+
+-- name: AnonymizeChatMessages :exec
+UPDATE messages SET content = '', key_id = '', tool_calls = JSON_ARRAY(), name = '' WHERE chat_id = ?;
+
+-- name: DeleteChatEmbeddings :exec
+DELETE FROM message_embeddings WHERE chat_id = ?;
+
+-- name: ListErasedMessagesBefore :many
+SELECT m.id, m.chat_id, c.tenant_id, c.assistant_id, m.created_at FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.erased = 1 AND m.created_at < sqlc.arg(before) AND m.id <> c.initial_message_id AND m.id > sqlc.arg(after_id)
+ORDER BY m.id
+LIMIT ?;
+
+-- name: DeleteErasedMessage :execrows
+DELETE FROM messages WHERE id = ? AND erased = 1;
+
+-- name: DeleteMessageEmbeddings :exec
+DELETE FROM message_embeddings WHERE message_id = ?;
+
+-- name: ListChatsByOwner :many
+SELECT * FROM chats WHERE tenant_id = ? AND user_id = ? ORDER BY created_at, id;
+
+-- name: FindAllMessagesByChatID :many
+SELECT m.* FROM messages m
+    JOIN chats c ON c.id = m.chat_id
+WHERE m.chat_id = ? AND c.tenant_id = ?
+ORDER BY m.order_msg;
+
+-- name: ListChatSharesByUserID :many
+SELECT s.chat_id, c.tenant_id, s.created_at FROM chat_shares s
+    JOIN chats c ON c.id = s.chat_id
+WHERE c.tenant_id = ? AND s.user_id = ?
+ORDER BY s.created_at, s.chat_id;
+
+-- name: CountMessagesByOwner :one
+SELECT COUNT(*) FROM messages m JOIN chats c ON c.id = m.chat_id WHERE c.tenant_id = ? AND c.user_id = ?;
+
+-- name: DeleteChatsByOwner :execrows
+DELETE FROM chats WHERE tenant_id = ? AND user_id = ?;
+
+-- name: DeleteChatSharesByUserID :execrows
+DELETE FROM chat_shares WHERE user_id = ? AND chat_id IN (SELECT id FROM chats WHERE tenant_id = ?);
+
+-- name: ListTenantAPIKeysByUserID :many
+SELECT * FROM api_keys WHERE tenant_id = ? AND user_id = ? ORDER BY created_at DESC;
+
+-- name: DeleteAPIKeysByUserID :execrows
+DELETE FROM api_keys WHERE tenant_id = ? AND user_id = ?;